@@ -17,16 +17,50 @@ limitations under the License.
 package v1beta1
 
 import (
+	machineryconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *CloudStackCluster) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	dst := dstRaw.(*v1beta3.CloudStackCluster)
-	return Convert_v1beta1_CloudStackCluster_To_v1beta3_CloudStackCluster(src, dst, nil)
+	if err := Convert_v1beta1_CloudStackCluster_To_v1beta3_CloudStackCluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data
+	restored := &v1beta3.CloudStackCluster{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	// v1beta1 only holds the control plane endpoint and the zones, so everything else comes from the hub data.
+	spec := restored.Spec
+	spec.ControlPlaneEndpoint = dst.Spec.ControlPlaneEndpoint
+	if !zonesFromDiscovery(src, restored) {
+		spec.FailureDomains = restoreFailureDomains(dst.Spec.FailureDomains, restored.Spec.FailureDomains)
+	}
+	dst.Spec = spec
+	status := restored.Status
+	status.FailureDomains = dst.Status.FailureDomains
+	status.Ready = dst.Status.Ready
+	dst.Status = status
+	return nil
 }
 
 func (dst *CloudStackCluster) ConvertFrom(srcRaw conversion.Hub) error { // nolint
 	src := srcRaw.(*v1beta3.CloudStackCluster)
-	return Convert_v1beta3_CloudStackCluster_To_v1beta1_CloudStackCluster(src, dst, nil)
+	if err := Convert_v1beta3_CloudStackCluster_To_v1beta1_CloudStackCluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+func Convert_v1beta3_Network_To_v1beta1_Network(in *v1beta3.Network, out *Network, s machineryconversion.Scope) error { // nolint
+	return autoConvert_v1beta3_Network_To_v1beta1_Network(in, out, s)
 }
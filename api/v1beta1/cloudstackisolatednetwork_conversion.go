@@ -37,6 +37,23 @@ func (src *CloudStackIsolatedNetwork) ConvertTo(dstRaw conversion.Hub) error { /
 	if restored.Spec.FailureDomainName != "" {
 		dst.Spec.FailureDomainName = restored.Spec.FailureDomainName
 	}
+	dst.Spec.Tags = restored.Spec.Tags
+	dst.Spec.Offering = restored.Spec.Offering
+	dst.Spec.CIDR = restored.Spec.CIDR
+	dst.Spec.Gateway = restored.Spec.Gateway
+	dst.Spec.Netmask = restored.Spec.Netmask
+	dst.Spec.NetworkDomain = restored.Spec.NetworkDomain
+	dst.Spec.DNS = restored.Spec.DNS
+	dst.Spec.IPv6DNS = restored.Spec.IPv6DNS
+	dst.Status.IPv6CIDR = restored.Status.IPv6CIDR
+	dst.Status.VPCID = restored.Status.VPCID
+	dst.Status.WorkerTierID = restored.Status.WorkerTierID
+	dst.Status.ACLListID = restored.Status.ACLListID
+	dst.Status.WorkloadLBRules = restored.Status.WorkloadLBRules
+	dst.Status.VirtualRouters = restored.Status.VirtualRouters
+	dst.Status.LastRestartTime = restored.Status.LastRestartTime
+	dst.Status.TagsHash = restored.Status.TagsHash
+	dst.Status.Conditions = restored.Status.Conditions
 	return nil
 }
 
@@ -56,3 +73,7 @@ func (dst *CloudStackIsolatedNetwork) ConvertFrom(srcRaw conversion.Hub) error {
 func Convert_v1beta3_CloudStackIsolatedNetworkSpec_To_v1beta1_CloudStackIsolatedNetworkSpec(in *v1beta3.CloudStackIsolatedNetworkSpec, out *CloudStackIsolatedNetworkSpec, s machineryconversion.Scope) error { // nolint
 	return autoConvert_v1beta3_CloudStackIsolatedNetworkSpec_To_v1beta1_CloudStackIsolatedNetworkSpec(in, out, s)
 }
+
+func Convert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIsolatedNetworkStatus(in *v1beta3.CloudStackIsolatedNetworkStatus, out *CloudStackIsolatedNetworkStatus, s machineryconversion.Scope) error { // nolint
+	return autoConvert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIsolatedNetworkStatus(in, out, s)
+}
@@ -40,12 +40,23 @@ func (src *CloudStackMachine) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	if restored.Spec.UncompressedUserData != nil {
 		dst.Spec.UncompressedUserData = restored.Spec.UncompressedUserData
 	}
+	if restored.Spec.AdditionalTags != nil {
+		dst.Spec.AdditionalTags = restored.Spec.AdditionalTags
+	}
+	dst.Spec.Host = restored.Spec.Host
+	dst.Spec.ExplicitDedicationAffinityGroup = restored.Spec.ExplicitDedicationAffinityGroup
+	dst.Spec.DeploymentPlanner = restored.Spec.DeploymentPlanner
 	if restored.Status.Status != nil {
 		dst.Status.Status = restored.Status.Status
 	}
 	if restored.Status.Reason != nil {
 		dst.Status.Reason = restored.Status.Reason
 	}
+	dst.Status.RemovedFromLoadBalancerAt = restored.Status.RemovedFromLoadBalancerAt
+	dst.Status.HostID = restored.Status.HostID
+	dst.Status.HostName = restored.Status.HostName
+	dst.Status.Hibernated = restored.Status.Hibernated
+	dst.Status.TagsHash = restored.Status.TagsHash
 	return nil
 }
 
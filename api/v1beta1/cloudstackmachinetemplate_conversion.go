@@ -43,6 +43,12 @@ func (src *CloudStackMachineTemplate) ConvertTo(dstRaw conversion.Hub) error { /
 	if restored.Spec.Template.Spec.UncompressedUserData != nil {
 		dst.Spec.Template.Spec.UncompressedUserData = restored.Spec.Template.Spec.UncompressedUserData
 	}
+	if restored.Spec.Template.Spec.AdditionalTags != nil {
+		dst.Spec.Template.Spec.AdditionalTags = restored.Spec.Template.Spec.AdditionalTags
+	}
+	dst.Spec.Template.Spec.Host = restored.Spec.Template.Spec.Host
+	dst.Spec.Template.Spec.ExplicitDedicationAffinityGroup = restored.Spec.Template.Spec.ExplicitDedicationAffinityGroup
+	dst.Spec.Template.Spec.DeploymentPlanner = restored.Spec.Template.Spec.DeploymentPlanner
 	return nil
 }
 
@@ -18,9 +18,9 @@ package v1beta1
 
 import (
 	"context"
-	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	conv "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
@@ -50,16 +50,23 @@ func Convert_v1beta1_CloudStackCluster_To_v1beta3_CloudStackCluster(in *CloudSta
 
 //nolint:golint,revive,stylecheck
 func Convert_v1beta3_CloudStackCluster_To_v1beta1_CloudStackCluster(in *v1beta3.CloudStackCluster, out *CloudStackCluster, scope conv.Scope) error {
-	if len(in.Spec.FailureDomains) < 1 {
-		return fmt.Errorf("v1beta3 to v1beta1 conversion not supported when < 1 failure domain is provided. Input CloudStackCluster spec %s", in.Spec)
+	// Clusters relying on failure domain discovery may not list any failure domains, so the discovered ones stand in.
+	failureDomains := in.Spec.FailureDomains
+	if len(failureDomains) == 0 {
+		failureDomains = in.Status.DiscoveredFailureDomains
 	}
 	out.ObjectMeta = in.ObjectMeta
 	out.Spec = CloudStackClusterSpec{
-		Account:              in.Spec.FailureDomains[0].Account,
-		Domain:               in.Spec.FailureDomains[0].Domain,
-		Zones:                getZones(in),
+		Zones:                getZones(failureDomains),
 		ControlPlaneEndpoint: in.Spec.ControlPlaneEndpoint,
 	}
+	if len(failureDomains) > 0 {
+		out.Spec.Account = failureDomains[0].Account
+		out.Spec.Domain = failureDomains[0].Domain
+	} else if discovery := in.Spec.FailureDomainDiscovery; discovery != nil {
+		out.Spec.Account = discovery.Account
+		out.Spec.Domain = discovery.Domain
+	}
 
 	out.Status = CloudStackClusterStatus{
 		FailureDomains: in.Status.FailureDomains,
@@ -69,9 +76,9 @@ func Convert_v1beta3_CloudStackCluster_To_v1beta1_CloudStackCluster(in *v1beta3.
 }
 
 // getZones maps failure domains to zones
-func getZones(csCluster *v1beta3.CloudStackCluster) []Zone {
+func getZones(failureDomains []v1beta3.CloudStackFailureDomainSpec) []Zone {
 	var zones []Zone
-	for _, failureDomain := range csCluster.Spec.FailureDomains {
+	for _, failureDomain := range failureDomains {
 		zone := failureDomain.Zone
 		zones = append(zones, Zone{
 			Name: zone.Name,
@@ -86,6 +93,63 @@ func getZones(csCluster *v1beta3.CloudStackCluster) []Zone {
 	return zones
 }
 
+// restoreFailureDomains restores the failure domains a v1beta3 cluster had before down-conversion. Zones are kept in
+// the order of the failure domains they came from, so each failure domain converted from a zone is replaced by the
+// restored one at the same position, if it is still in the same zone, with the fields v1beta1 holds taken over.
+func restoreFailureDomains(converted, restored []v1beta3.CloudStackFailureDomainSpec) []v1beta3.CloudStackFailureDomainSpec {
+	// v1beta1 has a single account and domain, taken from the first failure domain on down-conversion. Only take them
+	// over if they were changed since, so failure domains in other accounts keep theirs.
+	accountChanged := len(converted) > 0 && len(restored) > 0 &&
+		(converted[0].Account != restored[0].Account || converted[0].Domain != restored[0].Domain)
+
+	failureDomains := make([]v1beta3.CloudStackFailureDomainSpec, 0, len(converted))
+	for i, failureDomain := range converted {
+		if i >= len(restored) || !sameZone(failureDomain.Zone, restored[i].Zone) {
+			failureDomains = append(failureDomains, failureDomain)
+			continue
+		}
+		restoredFailureDomain := *restored[i].DeepCopy()
+		restoredFailureDomain.Zone.ID = failureDomain.Zone.ID
+		restoredFailureDomain.Zone.Name = failureDomain.Zone.Name
+		restoredFailureDomain.Zone.Network.ID = failureDomain.Zone.Network.ID
+		restoredFailureDomain.Zone.Network.Name = failureDomain.Zone.Network.Name
+		restoredFailureDomain.Zone.Network.Type = failureDomain.Zone.Network.Type
+		if accountChanged {
+			restoredFailureDomain.Account = failureDomain.Account
+			restoredFailureDomain.Domain = failureDomain.Domain
+		}
+		failureDomains = append(failureDomains, restoredFailureDomain)
+	}
+	return failureDomains
+}
+
+// zonesFromDiscovery returns whether a v1beta1 cluster still has the zones, account and domain its v1beta3 cluster,
+// which doesn't list any failure domains, was down-converted with. Those came from the discovered failure domains, so
+// they don't belong in the v1beta3 spec unless changed.
+func zonesFromDiscovery(src *CloudStackCluster, restored *v1beta3.CloudStackCluster) bool {
+	if len(restored.Spec.FailureDomains) > 0 {
+		return false
+	}
+	downConverted := &CloudStackCluster{}
+	if err := Convert_v1beta3_CloudStackCluster_To_v1beta1_CloudStackCluster(restored, downConverted, nil); err != nil {
+		return false
+	}
+	return src.Spec.Account == downConverted.Spec.Account && src.Spec.Domain == downConverted.Spec.Domain &&
+		equality.Semantic.DeepEqual(src.Spec.Zones, downConverted.Spec.Zones)
+}
+
+// sameZone returns whether two zone specs identify the same zone and network.
+func sameZone(zone, other v1beta3.CloudStackZoneSpec) bool {
+	if zone.ID != "" && other.ID != "" {
+		if zone.ID != other.ID {
+			return false
+		}
+	} else if zone.Name != other.Name {
+		return false
+	}
+	return zone.Network.Name == other.Network.Name
+}
+
 // GetFailureDomains maps v1beta1 zones to v1beta3 failure domains.
 func GetFailureDomains(csCluster *CloudStackCluster) ([]v1beta3.CloudStackFailureDomainSpec, error) {
 	var failureDomains []v1beta3.CloudStackFailureDomainSpec
@@ -17,13 +17,20 @@ limitations under the License.
 package v1beta1_test
 
 import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	v1beta1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 )
 
 var _ = Describe("Conversion", func() {
@@ -136,22 +143,138 @@ var _ = Describe("Conversion", func() {
 			Ω(converted).Should(Equal(expectedResult))
 		})
 
-		It("Returns error when len(failureDomains) < 1", func() {
+		It("Converts the discovered failure domains of a cluster without any in its spec to zones", func() {
 			csCluster := &v1beta3.CloudStackCluster{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "cluster1",
 					Namespace: "namespace1",
 				},
 				Spec: v1beta3.CloudStackClusterSpec{
+					FailureDomainDiscovery: &v1beta3.FailureDomainDiscovery{
+						Account: "account1",
+						Domain:  "domain1",
+						Network: v1beta3.Network{Name: "network-{zone}"},
+					},
 					ControlPlaneEndpoint: clusterv1.APIEndpoint{
 						Host: "endpoint1",
 						Port: 443,
 					},
 				},
-				Status: v1beta3.CloudStackClusterStatus{},
+				Status: v1beta3.CloudStackClusterStatus{
+					DiscoveredFailureDomains: []v1beta3.CloudStackFailureDomainSpec{
+						{
+							Name:    "zone1",
+							Zone:    v1beta3.CloudStackZoneSpec{ID: "zone1-id", Name: "zone1", Network: v1beta3.Network{Name: "network-zone1"}},
+							Account: "account1",
+							Domain:  "domain1",
+						},
+					},
+				},
 			}
-			err := v1beta1.Convert_v1beta3_CloudStackCluster_To_v1beta1_CloudStackCluster(csCluster, nil, nil)
-			Ω(err).Should(HaveOccurred())
+			converted := &v1beta1.CloudStackCluster{}
+			Ω(v1beta1.Convert_v1beta3_CloudStackCluster_To_v1beta1_CloudStackCluster(csCluster, converted, nil)).Should(Succeed())
+			Ω(converted.Spec.Zones).Should(Equal([]v1beta1.Zone{
+				{ID: "zone1-id", Name: "zone1", Network: v1beta1.Network{Name: "network-zone1"}}}))
+			Ω(converted.Spec.Account).Should(Equal("account1"))
+			Ω(converted.Spec.Domain).Should(Equal("domain1"))
+
+			csCluster.Status.DiscoveredFailureDomains = nil
+			converted = &v1beta1.CloudStackCluster{}
+			Ω(v1beta1.Convert_v1beta3_CloudStackCluster_To_v1beta1_CloudStackCluster(csCluster, converted, nil)).Should(Succeed())
+			Ω(converted.Spec.Zones).Should(BeEmpty())
+			Ω(converted.Spec.Account).Should(Equal("account1"))
+		})
+
+		It("Keeps discovered failure domains out of the spec on a round-trip", func() {
+			csCluster := &v1beta3.CloudStackCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "namespace1"},
+				Spec: v1beta3.CloudStackClusterSpec{
+					FailureDomainDiscovery: &v1beta3.FailureDomainDiscovery{Network: v1beta3.Network{Name: "network"}},
+				},
+				Status: v1beta3.CloudStackClusterStatus{
+					DiscoveredFailureDomains: []v1beta3.CloudStackFailureDomainSpec{
+						{Name: "zone1", Zone: v1beta3.CloudStackZoneSpec{ID: "zone1-id", Network: v1beta3.Network{Name: "network"}}},
+					},
+				},
+			}
+			spoke := &v1beta1.CloudStackCluster{}
+			Ω(spoke.ConvertFrom(csCluster)).Should(Succeed())
+			hub := &v1beta3.CloudStackCluster{}
+			Ω(spoke.ConvertTo(hub)).Should(Succeed())
+			Ω(hub.Spec.FailureDomains).Should(BeEmpty())
+			Ω(hub.Status.DiscoveredFailureDomains).Should(Equal(csCluster.Status.DiscoveredFailureDomains))
 		})
 	})
 })
+
+func TestFuzzyConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta3.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("for CloudStackCluster", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme:      scheme,
+		Hub:         &v1beta3.CloudStackCluster{},
+		Spoke:       &v1beta1.CloudStackCluster{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{fuzzFuncs},
+	}))
+}
+
+func fuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		hubCloudStackClusterSpecFuzzer,
+		hubCloudStackClusterStatusFuzzer,
+		spokeCloudStackClusterSpecFuzzer,
+		spokeCloudStackClusterStatusFuzzer,
+	}
+}
+
+// hubCloudStackClusterSpecFuzzer sets zone IDs, as converting a zone without one back looks its ID up.
+func hubCloudStackClusterSpecFuzzer(in *v1beta3.CloudStackClusterSpec, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	setZoneIDs(in.FailureDomains, c)
+}
+
+// hubCloudStackClusterStatusFuzzer sets the zone IDs of discovered failure domains, which stand in for the failure
+// domains of clusters without any.
+func hubCloudStackClusterStatusFuzzer(in *v1beta3.CloudStackClusterStatus, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	setZoneIDs(in.DiscoveredFailureDomains, c)
+}
+
+func setZoneIDs(failureDomains []v1beta3.CloudStackFailureDomainSpec, c fuzz.Continue) {
+	for i := range failureDomains {
+		if failureDomains[i].Zone.ID == "" {
+			failureDomains[i].Zone.ID = c.RandString() + "-id"
+		}
+	}
+}
+
+// spokeCloudStackClusterSpecFuzzer only sets the fields v1beta3 holds, with zone IDs for the same reason as above.
+func spokeCloudStackClusterSpecFuzzer(in *v1beta1.CloudStackClusterSpec, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	in.IdentityRef = nil
+	if len(in.Zones) == 0 {
+		in.Zones = []v1beta1.Zone{{}}
+		c.Fuzz(&in.Zones[0])
+	}
+	for i := range in.Zones {
+		if in.Zones[i].ID == "" {
+			in.Zones[i].ID = c.RandString() + "-id"
+		}
+	}
+}
+
+// spokeCloudStackClusterStatusFuzzer only sets the fields v1beta3 holds.
+func spokeCloudStackClusterStatusFuzzer(in *v1beta1.CloudStackClusterStatus, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	*in = v1beta1.CloudStackClusterStatus{FailureDomains: in.FailureDomains, Ready: in.Ready}
+}
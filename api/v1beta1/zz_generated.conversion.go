@@ -73,6 +73,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CloudStackIdentityReference)(nil), (*v1beta3.CloudStackIdentityReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CloudStackIdentityReference_To_v1beta3_CloudStackIdentityReference(a.(*CloudStackIdentityReference), b.(*v1beta3.CloudStackIdentityReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1beta3.CloudStackIdentityReference)(nil), (*CloudStackIdentityReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_CloudStackIdentityReference_To_v1beta1_CloudStackIdentityReference(a.(*v1beta3.CloudStackIdentityReference), b.(*CloudStackIdentityReference), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CloudStackIsolatedNetwork)(nil), (*v1beta3.CloudStackIsolatedNetwork)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_CloudStackIsolatedNetwork_To_v1beta3_CloudStackIsolatedNetwork(a.(*CloudStackIsolatedNetwork), b.(*v1beta3.CloudStackIsolatedNetwork), scope)
 	}); err != nil {
@@ -103,11 +113,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta3.CloudStackIsolatedNetworkStatus)(nil), (*CloudStackIsolatedNetworkStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIsolatedNetworkStatus(a.(*v1beta3.CloudStackIsolatedNetworkStatus), b.(*CloudStackIsolatedNetworkStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*CloudStackMachine)(nil), (*v1beta3.CloudStackMachine)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_CloudStackMachine_To_v1beta3_CloudStackMachine(a.(*CloudStackMachine), b.(*v1beta3.CloudStackMachine), scope)
 	}); err != nil {
@@ -243,11 +248,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta3.Network)(nil), (*Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta3_Network_To_v1beta1_Network(a.(*v1beta3.Network), b.(*Network), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddConversionFunc((*v1.ObjectMeta)(nil), (*apiv1beta1.ObjectMeta)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_ObjectMeta_To_v1beta1_ObjectMeta(a.(*v1.ObjectMeta), b.(*apiv1beta1.ObjectMeta), scope)
 	}); err != nil {
@@ -283,6 +283,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta3.CloudStackIsolatedNetworkStatus)(nil), (*CloudStackIsolatedNetworkStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIsolatedNetworkStatus(a.(*v1beta3.CloudStackIsolatedNetworkStatus), b.(*CloudStackIsolatedNetworkStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta3.CloudStackMachineSpec)(nil), (*CloudStackMachineSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta3_CloudStackMachineSpec_To_v1beta1_CloudStackMachineSpec(a.(*v1beta3.CloudStackMachineSpec), b.(*CloudStackMachineSpec), scope)
 	}); err != nil {
@@ -298,6 +303,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta3.Network)(nil), (*Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_Network_To_v1beta1_Network(a.(*v1beta3.Network), b.(*Network), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -415,6 +425,28 @@ func Convert_v1beta3_CloudStackAffinityGroupStatus_To_v1beta1_CloudStackAffinity
 	return autoConvert_v1beta3_CloudStackAffinityGroupStatus_To_v1beta1_CloudStackAffinityGroupStatus(in, out, s)
 }
 
+func autoConvert_v1beta1_CloudStackIdentityReference_To_v1beta3_CloudStackIdentityReference(in *CloudStackIdentityReference, out *v1beta3.CloudStackIdentityReference, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1beta1_CloudStackIdentityReference_To_v1beta3_CloudStackIdentityReference is an autogenerated conversion function.
+func Convert_v1beta1_CloudStackIdentityReference_To_v1beta3_CloudStackIdentityReference(in *CloudStackIdentityReference, out *v1beta3.CloudStackIdentityReference, s conversion.Scope) error {
+	return autoConvert_v1beta1_CloudStackIdentityReference_To_v1beta3_CloudStackIdentityReference(in, out, s)
+}
+
+func autoConvert_v1beta3_CloudStackIdentityReference_To_v1beta1_CloudStackIdentityReference(in *v1beta3.CloudStackIdentityReference, out *CloudStackIdentityReference, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1beta3_CloudStackIdentityReference_To_v1beta1_CloudStackIdentityReference is an autogenerated conversion function.
+func Convert_v1beta3_CloudStackIdentityReference_To_v1beta1_CloudStackIdentityReference(in *v1beta3.CloudStackIdentityReference, out *CloudStackIdentityReference, s conversion.Scope) error {
+	return autoConvert_v1beta3_CloudStackIdentityReference_To_v1beta1_CloudStackIdentityReference(in, out, s)
+}
+
 func autoConvert_v1beta1_CloudStackIsolatedNetwork_To_v1beta3_CloudStackIsolatedNetwork(in *CloudStackIsolatedNetwork, out *v1beta3.CloudStackIsolatedNetwork, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1beta1_CloudStackIsolatedNetworkSpec_To_v1beta3_CloudStackIsolatedNetworkSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -504,8 +536,16 @@ func Convert_v1beta1_CloudStackIsolatedNetworkSpec_To_v1beta3_CloudStackIsolated
 func autoConvert_v1beta3_CloudStackIsolatedNetworkSpec_To_v1beta1_CloudStackIsolatedNetworkSpec(in *v1beta3.CloudStackIsolatedNetworkSpec, out *CloudStackIsolatedNetworkSpec, s conversion.Scope) error {
 	out.Name = in.Name
 	out.ID = in.ID
+	// WARNING: in.Tags requires manual conversion: does not exist in peer-type
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
 	// WARNING: in.FailureDomainName requires manual conversion: does not exist in peer-type
+	// WARNING: in.Offering requires manual conversion: does not exist in peer-type
+	// WARNING: in.CIDR requires manual conversion: does not exist in peer-type
+	// WARNING: in.Gateway requires manual conversion: does not exist in peer-type
+	// WARNING: in.Netmask requires manual conversion: does not exist in peer-type
+	// WARNING: in.NetworkDomain requires manual conversion: does not exist in peer-type
+	// WARNING: in.DNS requires manual conversion: does not exist in peer-type
+	// WARNING: in.IPv6DNS requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -524,15 +564,19 @@ func Convert_v1beta1_CloudStackIsolatedNetworkStatus_To_v1beta3_CloudStackIsolat
 func autoConvert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIsolatedNetworkStatus(in *v1beta3.CloudStackIsolatedNetworkStatus, out *CloudStackIsolatedNetworkStatus, s conversion.Scope) error {
 	out.PublicIPID = in.PublicIPID
 	out.LBRuleID = in.LBRuleID
+	// WARNING: in.IPv6CIDR requires manual conversion: does not exist in peer-type
+	// WARNING: in.VPCID requires manual conversion: does not exist in peer-type
+	// WARNING: in.WorkerTierID requires manual conversion: does not exist in peer-type
+	// WARNING: in.ACLListID requires manual conversion: does not exist in peer-type
+	// WARNING: in.WorkloadLBRules requires manual conversion: does not exist in peer-type
+	// WARNING: in.VirtualRouters requires manual conversion: does not exist in peer-type
+	// WARNING: in.LastRestartTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.TagsHash requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
 	out.Ready = in.Ready
 	return nil
 }
 
-// Convert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIsolatedNetworkStatus is an autogenerated conversion function.
-func Convert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIsolatedNetworkStatus(in *v1beta3.CloudStackIsolatedNetworkStatus, out *CloudStackIsolatedNetworkStatus, s conversion.Scope) error {
-	return autoConvert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta1_CloudStackIsolatedNetworkStatus(in, out, s)
-}
-
 func autoConvert_v1beta1_CloudStackMachine_To_v1beta3_CloudStackMachine(in *CloudStackMachine, out *v1beta3.CloudStackMachine, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1beta1_CloudStackMachineSpec_To_v1beta3_CloudStackMachineSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -658,6 +702,10 @@ func autoConvert_v1beta3_CloudStackMachineSpec_To_v1beta1_CloudStackMachineSpec(
 	out.ProviderID = (*string)(unsafe.Pointer(in.ProviderID))
 	// WARNING: in.FailureDomainName requires manual conversion: does not exist in peer-type
 	// WARNING: in.UncompressedUserData requires manual conversion: does not exist in peer-type
+	// WARNING: in.AdditionalTags requires manual conversion: does not exist in peer-type
+	// WARNING: in.Host requires manual conversion: does not exist in peer-type
+	// WARNING: in.ExplicitDedicationAffinityGroup requires manual conversion: does not exist in peer-type
+	// WARNING: in.DeploymentPlanner requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -776,6 +824,11 @@ func autoConvert_v1beta3_CloudStackMachineStatus_To_v1beta1_CloudStackMachineSta
 	out.Ready = in.Ready
 	// WARNING: in.Status requires manual conversion: does not exist in peer-type
 	// WARNING: in.Reason requires manual conversion: does not exist in peer-type
+	// WARNING: in.RemovedFromLoadBalancerAt requires manual conversion: does not exist in peer-type
+	// WARNING: in.HostID requires manual conversion: does not exist in peer-type
+	// WARNING: in.HostName requires manual conversion: does not exist in peer-type
+	// WARNING: in.Hibernated requires manual conversion: does not exist in peer-type
+	// WARNING: in.TagsHash requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -987,10 +1040,14 @@ func autoConvert_v1beta3_Network_To_v1beta1_Network(in *v1beta3.Network, out *Ne
 	out.ID = in.ID
 	out.Type = in.Type
 	out.Name = in.Name
+	// WARNING: in.Tags requires manual conversion: does not exist in peer-type
+	// WARNING: in.Offering requires manual conversion: does not exist in peer-type
+	// WARNING: in.CIDR requires manual conversion: does not exist in peer-type
+	// WARNING: in.Gateway requires manual conversion: does not exist in peer-type
+	// WARNING: in.Netmask requires manual conversion: does not exist in peer-type
+	// WARNING: in.NetworkDomain requires manual conversion: does not exist in peer-type
+	// WARNING: in.DNS requires manual conversion: does not exist in peer-type
+	// WARNING: in.IPv6DNS requires manual conversion: does not exist in peer-type
+	// WARNING: in.VPC requires manual conversion: does not exist in peer-type
 	return nil
 }
-
-// Convert_v1beta3_Network_To_v1beta1_Network is an autogenerated conversion function.
-func Convert_v1beta3_Network_To_v1beta1_Network(in *v1beta3.Network, out *Network, s conversion.Scope) error {
-	return autoConvert_v1beta3_Network_To_v1beta1_Network(in, out, s)
-}
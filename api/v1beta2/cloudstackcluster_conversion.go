@@ -17,16 +17,72 @@ limitations under the License.
 package v1beta2
 
 import (
+	machineryconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *CloudStackCluster) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	dst := dstRaw.(*v1beta3.CloudStackCluster)
-	return Convert_v1beta2_CloudStackCluster_To_v1beta3_CloudStackCluster(src, dst, nil)
+	if err := Convert_v1beta2_CloudStackCluster_To_v1beta3_CloudStackCluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data
+	restored := &v1beta3.CloudStackCluster{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	restoredFailureDomains := map[string]*v1beta3.CloudStackFailureDomainSpec{}
+	for i := range restored.Spec.FailureDomains {
+		restoredFailureDomains[restored.Spec.FailureDomains[i].Name] = &restored.Spec.FailureDomains[i]
+	}
+	for i := range dst.Spec.FailureDomains {
+		if restoredFailureDomain, ok := restoredFailureDomains[dst.Spec.FailureDomains[i].Name]; ok {
+			restoreFailureDomainSpec(&dst.Spec.FailureDomains[i], restoredFailureDomain)
+		}
+	}
+	dst.Spec.FailureDomainDiscovery = restored.Spec.FailureDomainDiscovery
+	dst.Spec.FirewallPolicy = restored.Spec.FirewallPolicy
+	dst.Spec.APIServerLoadBalancer = restored.Spec.APIServerLoadBalancer
+	dst.Spec.LoadBalancerRules = restored.Spec.LoadBalancerRules
+	dst.Spec.LoadBalancerDrainPeriod = restored.Spec.LoadBalancerDrainPeriod
+	dst.Spec.ControlPlanePublicIP = restored.Spec.ControlPlanePublicIP
+	dst.Spec.Bastion = restored.Spec.Bastion
+	dst.Spec.VirtualRouterHealth = restored.Spec.VirtualRouterHealth
+	dst.Spec.GlobalLoadBalancer = restored.Spec.GlobalLoadBalancer
+	dst.Spec.DNSName = restored.Spec.DNSName
+	dst.Spec.DNSProvider = restored.Spec.DNSProvider
+	dst.Spec.AdditionalTags = restored.Spec.AdditionalTags
+	dst.Spec.DeletionProtection = restored.Spec.DeletionProtection
+	dst.Spec.Hibernate = restored.Spec.Hibernate
+	dst.Status.DiscoveredFailureDomains = restored.Status.DiscoveredFailureDomains
+	dst.Status.Bastion = restored.Status.Bastion
+	dst.Status.ControlPlaneVIP = restored.Status.ControlPlaneVIP
+	dst.Status.GlobalLoadBalancer = restored.Status.GlobalLoadBalancer
+	dst.Status.DNSRecordAddresses = restored.Status.DNSRecordAddresses
+	dst.Status.Hibernation = restored.Status.Hibernation
+	return nil
 }
 
 func (dst *CloudStackCluster) ConvertFrom(srcRaw conversion.Hub) error { // nolint
 	src := srcRaw.(*v1beta3.CloudStackCluster)
-	return Convert_v1beta3_CloudStackCluster_To_v1beta2_CloudStackCluster(src, dst, nil)
+	if err := Convert_v1beta3_CloudStackCluster_To_v1beta2_CloudStackCluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+func Convert_v1beta3_CloudStackClusterSpec_To_v1beta2_CloudStackClusterSpec(in *v1beta3.CloudStackClusterSpec, out *CloudStackClusterSpec, s machineryconversion.Scope) error { // nolint
+	return autoConvert_v1beta3_CloudStackClusterSpec_To_v1beta2_CloudStackClusterSpec(in, out, s)
+}
+
+func Convert_v1beta3_CloudStackClusterStatus_To_v1beta2_CloudStackClusterStatus(in *v1beta3.CloudStackClusterStatus, out *CloudStackClusterStatus, s machineryconversion.Scope) error { // nolint
+	return autoConvert_v1beta3_CloudStackClusterStatus_To_v1beta2_CloudStackClusterStatus(in, out, s)
 }
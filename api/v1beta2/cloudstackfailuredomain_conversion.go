@@ -19,19 +19,47 @@ package v1beta2
 import (
 	machineryconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *CloudStackFailureDomain) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	dst := dstRaw.(*v1beta3.CloudStackFailureDomain)
-	return Convert_v1beta2_CloudStackFailureDomain_To_v1beta3_CloudStackFailureDomain(src, dst, nil)
+	if err := Convert_v1beta2_CloudStackFailureDomain_To_v1beta3_CloudStackFailureDomain(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data
+	restored := &v1beta3.CloudStackFailureDomain{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	restoreFailureDomainSpec(&dst.Spec, &restored.Spec)
+	dst.Status.AccountID = restored.Status.AccountID
+	return nil
 }
 
 func (dst *CloudStackFailureDomain) ConvertFrom(srcRaw conversion.Hub) error { // nolint
 	src := srcRaw.(*v1beta3.CloudStackFailureDomain)
-	return Convert_v1beta3_CloudStackFailureDomain_To_v1beta2_CloudStackFailureDomain(src, dst, nil)
+	if err := Convert_v1beta3_CloudStackFailureDomain_To_v1beta2_CloudStackFailureDomain(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+	return nil
 }
 
 func Convert_v1beta3_CloudStackFailureDomainSpec_To_v1beta2_CloudStackFailureDomainSpec(in *v1beta3.CloudStackFailureDomainSpec, out *CloudStackFailureDomainSpec, s machineryconversion.Scope) error { // nolint
 	return autoConvert_v1beta3_CloudStackFailureDomainSpec_To_v1beta2_CloudStackFailureDomainSpec(in, out, s)
 }
+
+func Convert_v1beta3_Network_To_v1beta2_Network(in *v1beta3.Network, out *Network, s machineryconversion.Scope) error { // nolint
+	return autoConvert_v1beta3_Network_To_v1beta2_Network(in, out, s)
+}
+
+func Convert_v1beta3_CloudStackFailureDomainStatus_To_v1beta2_CloudStackFailureDomainStatus(in *v1beta3.CloudStackFailureDomainStatus, out *CloudStackFailureDomainStatus, s machineryconversion.Scope) error { // nolint
+	return autoConvert_v1beta3_CloudStackFailureDomainStatus_To_v1beta2_CloudStackFailureDomainStatus(in, out, s)
+}
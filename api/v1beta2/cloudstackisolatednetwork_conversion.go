@@ -17,16 +17,60 @@ limitations under the License.
 package v1beta2
 
 import (
+	machineryconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 func (src *CloudStackIsolatedNetwork) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	dst := dstRaw.(*v1beta3.CloudStackIsolatedNetwork)
-	return Convert_v1beta2_CloudStackIsolatedNetwork_To_v1beta3_CloudStackIsolatedNetwork(src, dst, nil)
+	if err := Convert_v1beta2_CloudStackIsolatedNetwork_To_v1beta3_CloudStackIsolatedNetwork(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data
+	restored := &v1beta3.CloudStackIsolatedNetwork{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+	dst.Spec.Tags = restored.Spec.Tags
+	dst.Spec.Offering = restored.Spec.Offering
+	dst.Spec.CIDR = restored.Spec.CIDR
+	dst.Spec.Gateway = restored.Spec.Gateway
+	dst.Spec.Netmask = restored.Spec.Netmask
+	dst.Spec.NetworkDomain = restored.Spec.NetworkDomain
+	dst.Spec.DNS = restored.Spec.DNS
+	dst.Spec.IPv6DNS = restored.Spec.IPv6DNS
+	dst.Status.IPv6CIDR = restored.Status.IPv6CIDR
+	dst.Status.VPCID = restored.Status.VPCID
+	dst.Status.WorkerTierID = restored.Status.WorkerTierID
+	dst.Status.ACLListID = restored.Status.ACLListID
+	dst.Status.WorkloadLBRules = restored.Status.WorkloadLBRules
+	dst.Status.VirtualRouters = restored.Status.VirtualRouters
+	dst.Status.LastRestartTime = restored.Status.LastRestartTime
+	dst.Status.TagsHash = restored.Status.TagsHash
+	dst.Status.Conditions = restored.Status.Conditions
+	return nil
 }
 
 func (dst *CloudStackIsolatedNetwork) ConvertFrom(srcRaw conversion.Hub) error { // nolint
 	src := srcRaw.(*v1beta3.CloudStackIsolatedNetwork)
-	return Convert_v1beta3_CloudStackIsolatedNetwork_To_v1beta2_CloudStackIsolatedNetwork(src, dst, nil)
+	if err := Convert_v1beta3_CloudStackIsolatedNetwork_To_v1beta2_CloudStackIsolatedNetwork(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+	return nil
+}
+
+func Convert_v1beta3_CloudStackIsolatedNetworkSpec_To_v1beta2_CloudStackIsolatedNetworkSpec(in *v1beta3.CloudStackIsolatedNetworkSpec, out *CloudStackIsolatedNetworkSpec, s machineryconversion.Scope) error { // nolint
+	return autoConvert_v1beta3_CloudStackIsolatedNetworkSpec_To_v1beta2_CloudStackIsolatedNetworkSpec(in, out, s)
+}
+
+func Convert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta2_CloudStackIsolatedNetworkStatus(in *v1beta3.CloudStackIsolatedNetworkStatus, out *CloudStackIsolatedNetworkStatus, s machineryconversion.Scope) error { // nolint
+	return autoConvert_v1beta3_CloudStackIsolatedNetworkStatus_To_v1beta2_CloudStackIsolatedNetworkStatus(in, out, s)
 }
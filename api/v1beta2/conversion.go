@@ -15,3 +15,28 @@ limitations under the License.
 */
 
 package v1beta2
+
+import (
+	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+// restoreFailureDomainSpec restores the fields of a failure domain spec that v1beta2 can't represent.
+func restoreFailureDomainSpec(dst, restored *v1beta3.CloudStackFailureDomainSpec) {
+	dst.Project = restored.Project
+	dst.IdentityRef = restored.IdentityRef
+	dst.Placement = restored.Placement
+	restoreNetwork(&dst.Zone.Network, &restored.Zone.Network)
+}
+
+// restoreNetwork restores the fields of a network that v1beta2 can't represent.
+func restoreNetwork(dst, restored *v1beta3.Network) {
+	dst.Tags = restored.Tags
+	dst.Offering = restored.Offering
+	dst.CIDR = restored.CIDR
+	dst.Gateway = restored.Gateway
+	dst.Netmask = restored.Netmask
+	dst.NetworkDomain = restored.NetworkDomain
+	dst.DNS = restored.DNS
+	dst.IPv6DNS = restored.IPv6DNS
+	dst.VPC = restored.VPC
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"fmt"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+func TestFuzzyConversion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta3.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("for CloudStackCluster", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme:      scheme,
+		Hub:         &v1beta3.CloudStackCluster{},
+		Spoke:       &CloudStackCluster{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{fuzzFuncs},
+	}))
+	t.Run("for CloudStackFailureDomain", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme: scheme,
+		Hub:    &v1beta3.CloudStackFailureDomain{},
+		Spoke:  &CloudStackFailureDomain{},
+	}))
+	t.Run("for CloudStackIsolatedNetwork", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme: scheme,
+		Hub:    &v1beta3.CloudStackIsolatedNetwork{},
+		Spoke:  &CloudStackIsolatedNetwork{},
+	}))
+	t.Run("for CloudStackMachine", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme: scheme,
+		Hub:    &v1beta3.CloudStackMachine{},
+		Spoke:  &CloudStackMachine{},
+	}))
+	t.Run("for CloudStackMachineTemplate", utilconversion.FuzzTestFunc(utilconversion.FuzzTestFuncInput{
+		Scheme:      scheme,
+		Hub:         &v1beta3.CloudStackMachineTemplate{},
+		Spoke:       &CloudStackMachineTemplate{},
+		FuzzerFuncs: []fuzzer.FuzzerFuncs{fuzzFuncs},
+		SpokeAfterMutation: func(convertible conversion.Convertible) {
+			// The data annotation is removed from an otherwise empty map.
+			if template := convertible.(*CloudStackMachineTemplate); len(template.Annotations) == 0 {
+				template.Annotations = nil
+			}
+		},
+	}))
+}
+
+func fuzzFuncs(_ runtimeserializer.CodecFactory) []interface{} {
+	return []interface{}{
+		hubCloudStackClusterSpecFuzzer,
+		spokeCloudStackMachineTemplateResourceFuzzer,
+	}
+}
+
+// hubCloudStackClusterSpecFuzzer gives the failure domains unique names, as the webhook requires.
+func hubCloudStackClusterSpecFuzzer(in *v1beta3.CloudStackClusterSpec, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	for i := range in.FailureDomains {
+		in.FailureDomains[i].Name = fmt.Sprintf("%s-%d", in.FailureDomains[i].Name, i)
+	}
+}
+
+// spokeCloudStackMachineTemplateResourceFuzzer only sets the template metadata v1beta3 holds.
+func spokeCloudStackMachineTemplateResourceFuzzer(in *CloudStackMachineTemplateResource, c fuzz.Continue) {
+	c.FuzzNoCustom(in)
+
+	in.ObjectMeta = metav1.ObjectMeta{Labels: in.ObjectMeta.Labels, Annotations: in.ObjectMeta.Annotations}
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package v1beta3
 
 import (
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,16 +28,433 @@ import (
 
 const (
 	ClusterFinalizer = "cloudstackcluster.infrastructure.cluster.x-k8s.io"
+
+	DefaultLoadBalancerDrainPeriod = 10 * time.Second
+
+	DefaultVirtualRouterCheckInterval = 5 * time.Minute
+	DefaultMinNetworkRestartInterval  = 30 * time.Minute
+
+	DefaultFailureDomainDiscoveryInterval = 10 * time.Minute
+
+	// AllowFailureDomainRemovalAnnotation allows removing failure domains from a CloudStackCluster's failureDomains
+	// while machines are placed in them, which deletes those machines.
+	AllowFailureDomainRemovalAnnotation = "cloudstackcluster.infrastructure.cluster.x-k8s.io/allow-failure-domain-removal"
 )
 
 var K8sClient client.Client
 
 // CloudStackClusterSpec defines the desired state of CloudStackCluster.
 type CloudStackClusterSpec struct {
-	FailureDomains []CloudStackFailureDomainSpec `json:"failureDomains"`
+	// The failure domains of the cluster. Required unless failure domains are discovered.
+	// +optional
+	FailureDomains []CloudStackFailureDomainSpec `json:"failureDomains,omitempty"`
+
+	// Discovery of failure domains from CloudStack zones, on top of those listed in failureDomains.
+	// +optional
+	FailureDomainDiscovery *FailureDomainDiscovery `json:"failureDomainDiscovery,omitempty"`
 
 	// The kubernetes control plane endpoint.
 	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint"`
+
+	// Firewall policy applied to the cluster's isolated networks.
+	// +optional
+	FirewallPolicy *FirewallPolicy `json:"firewallPolicy,omitempty"`
+
+	// Configuration of the load balancer rule exposing the control plane endpoint on isolated networks.
+	// +optional
+	APIServerLoadBalancer *APIServerLoadBalancer `json:"apiServerLoadBalancer,omitempty"`
+
+	// Additional load balancer rules exposing workloads, e.g. an ingress controller, on the worker machines of
+	// isolated networks.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	LoadBalancerRules []WorkloadLoadBalancerRule `json:"loadBalancerRules,omitempty"`
+
+	// Time a machine being deleted is kept out of the isolated network's load balancer rules before its VM is
+	// destroyed, letting in-flight connections drain. Defaults to 10s.
+	// +optional
+	LoadBalancerDrainPeriod *metav1.Duration `json:"loadBalancerDrainPeriod,omitempty"`
+
+	// Selection and retention of the control plane endpoint's public IP on isolated networks.
+	// +optional
+	ControlPlanePublicIP *PublicIPPolicy `json:"controlPlanePublicIP,omitempty"`
+
+	// Bastion host giving SSH access to the nodes of an isolated network.
+	// +optional
+	Bastion *Bastion `json:"bastion,omitempty"`
+
+	// Health monitoring and recovery of the isolated networks' virtual routers.
+	// +optional
+	VirtualRouterHealth *VirtualRouterHealth `json:"virtualRouterHealth,omitempty"`
+
+	// Global server load balancing of the control plane endpoint across the isolated networks of all failure domains.
+	// The GSLB FQDN becomes the control plane endpoint host, and each isolated network gets a public IP of its own.
+	// +optional
+	GlobalLoadBalancer *GlobalLoadBalancer `json:"globalLoadBalancer,omitempty"`
+
+	// DNS name of the control plane endpoint. CAPC sets it as the endpoint host and points A and AAAA records of the
+	// name at the endpoint's addresses through the DNS provider, keeping the API server certificate's SANs stable when
+	// the addresses change. Each isolated network gets a public IP of its own. Requires DNSProvider.
+	// +optional
+	DNSName string `json:"dnsName,omitempty"`
+
+	// DNS server CAPC manages the control plane endpoint's records on.
+	// +optional
+	DNSProvider *DNSProvider `json:"dnsProvider,omitempty"`
+
+	// Tags CAPC adds to the CloudStack resources it creates for the cluster, on top of those recording the cluster
+	// and machine owning them. Changes are applied to existing resources. Keys may not start with CAPC_ nor be
+	// created_by_CAPC.
+	// +optional
+	AdditionalTags map[string]string `json:"additionalTags,omitempty"`
+
+	// Refuse deleting the CloudStackCluster, and with it the CAPI cluster, its machines and networks, while set.
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// Stop the VMs of all the cluster's machines, workers first and the control plane last, while set. Clearing it
+	// starts them again, the control plane first. Machine health check remediation is paused in between.
+	// +optional
+	Hibernate bool `json:"hibernate,omitempty"`
+}
+
+// HibernationPhase describes how far a CloudStackCluster got stopping or starting its machines.
+type HibernationPhase string
+
+const (
+	// HibernationPhaseHibernating means machines are being stopped.
+	HibernationPhaseHibernating HibernationPhase = "Hibernating"
+	// HibernationPhaseHibernated means all machines are stopped.
+	HibernationPhaseHibernated HibernationPhase = "Hibernated"
+	// HibernationPhaseResuming means machines are being started again.
+	HibernationPhaseResuming HibernationPhase = "Resuming"
+)
+
+// DNSProvider specifies the DNS server managing the control plane endpoint's records. Exactly one provider must be set.
+type DNSProvider struct {
+	// Dynamic DNS updates (RFC 2136) of an authoritative server, e.g. BIND.
+	// +optional
+	RFC2136 *RFC2136Provider `json:"rfc2136,omitempty"`
+}
+
+// RFC2136Provider specifies a DNS server accepting dynamic updates over TCP, optionally authenticated with a TSIG key.
+type RFC2136Provider struct {
+	// Address of the DNS server as host or host:port. The port defaults to 53.
+	// +kubebuilder:validation:MinLength=1
+	Server string `json:"server"`
+
+	// Zone the records are updated in.
+	// +kubebuilder:validation:MinLength=1
+	Zone string `json:"zone"`
+
+	// TTL of the records in seconds. Defaults to 60.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TTL int `json:"ttl,omitempty"`
+
+	// Name of the TSIG key signing the updates. Updates are unsigned when not set.
+	// +optional
+	TSIGKeyName string `json:"tsigKeyName,omitempty"`
+
+	// Algorithm of the TSIG key. Defaults to hmac-sha256.
+	// +kubebuilder:validation:Enum=hmac-sha1;hmac-sha256;hmac-sha512
+	// +optional
+	TSIGAlgorithm string `json:"tsigAlgorithm,omitempty"`
+
+	// Secret holding the base64 encoded TSIG key under the "secret" key. It must be in the cluster's namespace.
+	// +optional
+	TSIGSecretRef *corev1.SecretReference `json:"tsigSecretRef,omitempty"`
+}
+
+// GlobalLoadBalancer configures the CloudStack global load balancer rule spanning the API server load balancer rules of
+// the cluster's isolated networks. The region needs a GSLB service provider, and all failure domains must use the same
+// account.
+type GlobalLoadBalancer struct {
+	// Name registered with the GSLB service. Must be unique within the region.
+	// +kubebuilder:validation:MinLength=1
+	DomainName string `json:"domainName"`
+
+	// DNS domain the region's GSLB service providers are authoritative for. The control plane endpoint host is
+	// <domainName>.<serviceDomain>.
+	// +kubebuilder:validation:MinLength=1
+	ServiceDomain string `json:"serviceDomain"`
+
+	// ID of the CloudStack region the rule is created in. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RegionID int `json:"regionID,omitempty"`
+
+	// Method distributing clients among the failure domains. Defaults to roundrobin.
+	// +kubebuilder:validation:Enum=roundrobin;leastconn;proximity
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// VirtualRouterHealth configures how CAPC monitors, and optionally recovers, the virtual routers of isolated networks.
+type VirtualRouterHealth struct {
+	// Interval between two checks of the virtual routers. Defaults to 5m.
+	// +optional
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+
+	// Restart a network, cleaning up its virtual routers, when none of them is healthy.
+	// +optional
+	AutoRestart bool `json:"autoRestart,omitempty"`
+
+	// Minimum time between two restarts of the same network. Defaults to 30m.
+	// +optional
+	MinRestartInterval *metav1.Duration `json:"minRestartInterval,omitempty"`
+}
+
+// ZoneNamePlaceholder is replaced by the name of a zone in the names of the network and VPC of the failure domain
+// discovered from it.
+const ZoneNamePlaceholder = "{zone}"
+
+// FailureDomainDiscovery generates a failure domain for each CloudStack zone selected, named after the zone. Failure
+// domains are added and removed as zones are. Zones already discovered are kept when disabled, but disabled zones aren't
+// discovered.
+type FailureDomainDiscovery struct {
+	// CloudStack account the zones are listed as and failure domains use.
+	// +optional
+	Account string `json:"account,omitempty"`
+
+	// CloudStack domain of the account.
+	// +optional
+	Domain string `json:"domain,omitempty"`
+
+	// CloudStack project of the failure domains.
+	// +optional
+	Project string `json:"project,omitempty"`
+
+	// Apache CloudStack Endpoint secret reference. Required unless an identity is used.
+	// +optional
+	ACSEndpoint corev1.SecretReference `json:"acsEndpoint,omitempty"`
+
+	// IdentityRef references the identity whose credentials are used instead of an ACS endpoint secret, if the
+	// cluster's namespace is allowed to use it.
+	// +optional
+	IdentityRef *CloudStackIdentityReference `json:"identityRef,omitempty"`
+
+	// Selects the zones failure domains are discovered from. All enabled zones are selected if unset.
+	// +optional
+	Zones *ZoneSelector `json:"zones,omitempty"`
+
+	// The network used in each zone. "{zone}" in its name, and its VPC's name, is replaced by the zone's name.
+	Network Network `json:"network"`
+
+	// How often zones are listed. Defaults to 10 minutes.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ZoneSelector selects CloudStack zones by name and tags.
+type ZoneSelector struct {
+	// Regular expression the names of the zones must match.
+	// +optional
+	NamePattern string `json:"namePattern,omitempty"`
+
+	// CloudStack tags the zones must have.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// Bastion specifies a VM deployed into a failure domain's isolated network, reachable over SSH through a port
+// forwarding rule on a public IP of its own.
+type Bastion struct {
+	// Failure domain whose isolated network the bastion is deployed into. Defaults to the first failure domain.
+	// +optional
+	FailureDomainName string `json:"failureDomainName,omitempty"`
+
+	// Service offering of the bastion VM.
+	Offering CloudStackResourceIdentifier `json:"offering"`
+
+	// Template of the bastion VM.
+	Template CloudStackResourceIdentifier `json:"template"`
+
+	// Name of the CloudStack SSH key pair installed on the bastion.
+	// +optional
+	SSHKey string `json:"sshKey,omitempty"`
+
+	// Public IP address forwarded to the bastion. CloudStack picks a free address when not set.
+	// +optional
+	PublicIPAddress string `json:"publicIPAddress,omitempty"`
+
+	// Public port forwarded to the bastion's SSH port. Defaults to 22.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	PublicPort int `json:"publicPort,omitempty"`
+
+	// CIDRs allowed to reach the bastion. Ignored on VPC tiers, whose traffic is governed by the network ACL.
+	// +kubebuilder:validation:MinItems=1
+	AllowedCIDRs []string `json:"allowedCIDRs"`
+}
+
+// PublicIPPolicy specifies how CAPC picks the control plane endpoint's public IP and what happens to it when the
+// cluster is deleted.
+type PublicIPPolicy struct {
+	// Keep the address allocated when the cluster is deleted, along with the isolated network or VPC it is associated
+	// with. A cluster later created with the same name in the same namespace gets the address back.
+	// +optional
+	Retain bool `json:"retain,omitempty"`
+
+	// Limits the addresses picked when the control plane endpoint host isn't set.
+	// +optional
+	Selector *PublicIPSelector `json:"selector,omitempty"`
+}
+
+// PublicIPSelector limits the public IPs CAPC picks from.
+type PublicIPSelector struct {
+	// ID of the public IP range (VLAN) to pick from.
+	// +optional
+	VLANID string `json:"vlanID,omitempty"`
+
+	// CIDRs the address must be within.
+	// +optional
+	CIDRs []string `json:"cidrs,omitempty"`
+}
+
+// APIServerLoadBalancer configures the CloudStack load balancer rule in front of the API servers.
+// The health check and stickiness policies are left untouched when not configured.
+type APIServerLoadBalancer struct {
+	// Load balancing algorithm. Defaults to roundrobin.
+	// +kubebuilder:validation:Enum=roundrobin;leastconn;source
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Port the API servers listen on. Defaults to 6443. Can't be changed once the cluster exists.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	BackendPort int `json:"backendPort,omitempty"`
+
+	// Health check policy taking dead API servers out of rotation.
+	// Requires a network offering whose load balancer provider supports health checks. Not supported on VPC networks.
+	// +optional
+	HealthCheck *LoadBalancerHealthCheck `json:"healthCheck,omitempty"`
+
+	// Stickiness policy of the load balancer rule. Not supported on VPC networks.
+	// +optional
+	Stickiness *LoadBalancerStickiness `json:"stickiness,omitempty"`
+}
+
+// LoadBalancerHealthCheck specifies a CloudStack load balancer health check policy.
+// CloudStack defaults apply to unset fields.
+type LoadBalancerHealthCheck struct {
+	// HTTP path pinged on the backends.
+	// +optional
+	PingPath string `json:"pingPath,omitempty"`
+
+	// Seconds between two health checks.
+	// +optional
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+
+	// Seconds to wait for a backend to respond.
+	// +optional
+	ResponseTimeoutSeconds int `json:"responseTimeoutSeconds,omitempty"`
+
+	// Consecutive successful checks before a backend is considered healthy.
+	// +optional
+	HealthyThreshold int `json:"healthyThreshold,omitempty"`
+
+	// Consecutive failed checks before a backend is considered unhealthy.
+	// +optional
+	UnhealthyThreshold int `json:"unhealthyThreshold,omitempty"`
+}
+
+// LoadBalancerStickiness specifies a CloudStack load balancer stickiness policy.
+type LoadBalancerStickiness struct {
+	// Stickiness method.
+	// +kubebuilder:validation:Enum=LbCookie;AppCookie;SourceBased
+	Method string `json:"method"`
+
+	// Method specific parameters, e.g. cookie-name for LbCookie.
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// FirewallPolicy specifies the ingress and egress firewall rules CAPC manages on isolated networks. Only rules CAPC
+// created are replaced; others, including allow-all rules opened by earlier CAPC versions, are left in place.
+type FirewallPolicy struct {
+	// Ingress rules on the control plane endpoint's public IP. The endpoint port is open to 0.0.0.0/0 when empty.
+	// On dual-stack isolated networks the IPv6 source CIDRs of the rules are opened on the network's IPv6 firewall,
+	// and the endpoint port to ::/0 when empty.
+	// +optional
+	Ingress []IngressFirewallRule `json:"ingress,omitempty"`
+
+	// Egress rules of the isolated network, replacing the default of allowing all TCP, UDP and ICMP traffic.
+	// +optional
+	Egress []EgressFirewallRule `json:"egress,omitempty"`
+}
+
+// IngressFirewallRule allows traffic from source CIDRs to a port of the control plane endpoint's public IP.
+type IngressFirewallRule struct {
+	// Public port the rule opens. Defaults to the control plane endpoint port.
+	// +optional
+	Port int `json:"port,omitempty"`
+
+	// CIDRs allowed to reach the port.
+	// +kubebuilder:validation:MinItems=1
+	SourceCIDRs []string `json:"sourceCIDRs"`
+}
+
+// EgressFirewallRule allows traffic leaving the isolated network.
+type EgressFirewallRule struct {
+	// Protocol the rule applies to: tcp, udp or icmp.
+	// +kubebuilder:validation:Enum=tcp;udp;icmp
+	Protocol string `json:"protocol"`
+
+	// First port of the range the rule applies to. All ports when not set. Ignored for icmp.
+	// +optional
+	StartPort int `json:"startPort,omitempty"`
+
+	// Last port of the range the rule applies to. Defaults to StartPort.
+	// +optional
+	EndPort int `json:"endPort,omitempty"`
+
+	// Destination CIDRs the rule applies to. All destinations when empty.
+	// +optional
+	DestinationCIDRs []string `json:"destinationCIDRs,omitempty"`
+}
+
+// WorkloadLoadBalancerRule forwards a public port to the worker machines of the selected MachineDeployments.
+type WorkloadLoadBalancerRule struct {
+	// Name of the rule, unique within the cluster.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Public port the rule receives traffic on.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	PublicPort int `json:"publicPort"`
+
+	// Port traffic is forwarded to on the machines, e.g. a NodePort. Defaults to PublicPort.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	PrivatePort int `json:"privatePort,omitempty"`
+
+	// Protocol of the rule. Defaults to tcp.
+	// +kubebuilder:validation:Enum=tcp;udp
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+
+	// Public IP address dedicated to the rule. The control plane endpoint's public IP is used when not set.
+	// +optional
+	PublicIPAddress string `json:"publicIPAddress,omitempty"`
+
+	// Load balancing algorithm. Defaults to roundrobin.
+	// +kubebuilder:validation:Enum=roundrobin;leastconn;source
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// CIDRs the public port is opened to on the firewall of isolated networks. Defaults to 0.0.0.0/0. Ignored on VPC
+	// networks, whose traffic is governed by the network ACL.
+	// +optional
+	SourceCIDRs []string `json:"sourceCIDRs,omitempty"`
+
+	// Selects the MachineDeployments whose machines receive the rule's traffic.
+	MachineDeploymentSelector metav1.LabelSelector `json:"machineDeploymentSelector"`
 }
 
 // The status of the CloudStackCluster object.
@@ -43,10 +464,161 @@ type CloudStackClusterStatus struct {
 	// +optional
 	FailureDomains clusterv1.FailureDomains `json:"failureDomains,omitempty"`
 
+	// The failure domains discovered from CloudStack zones, in the order they were discovered in.
+	// +optional
+	DiscoveredFailureDomains []CloudStackFailureDomainSpec `json:"discoveredFailureDomains,omitempty"`
+
+	// The bastion host CAPC deployed for the cluster.
+	// +optional
+	Bastion *BastionStatus `json:"bastion,omitempty"`
+
+	// The control plane endpoint address CAPC allocated on a shared network.
+	// +optional
+	ControlPlaneVIP *ControlPlaneVIPStatus `json:"controlPlaneVIP,omitempty"`
+
+	// The global load balancer rule CAPC created for the control plane endpoint.
+	// +optional
+	GlobalLoadBalancer *GlobalLoadBalancerStatus `json:"globalLoadBalancer,omitempty"`
+
+	// Addresses the control plane endpoint's DNS records point at.
+	// +optional
+	DNSRecordAddresses []string `json:"dnsRecordAddresses,omitempty"`
+
+	// How far stopping or starting the cluster's machines for hibernation got. Empty while the cluster is awake.
+	// +optional
+	Hibernation HibernationPhase `json:"hibernation,omitempty"`
+
 	// Reflects the readiness of the CS cluster.
 	Ready bool `json:"ready"`
 }
 
+// BastionStatus describes a deployed bastion host.
+type BastionStatus struct {
+	// Failure domain the bastion is deployed in.
+	FailureDomainName string `json:"failureDomainName"`
+
+	// The ID of the bastion VM.
+	// +optional
+	InstanceID string `json:"instanceID,omitempty"`
+
+	// Address of the bastion in the isolated network.
+	// +optional
+	PrivateIP string `json:"privateIP,omitempty"`
+
+	// The ID of the public IP forwarded to the bastion.
+	// +optional
+	PublicIPID string `json:"publicIPID,omitempty"`
+
+	// Public IP address to SSH to.
+	// +optional
+	PublicIP string `json:"publicIP,omitempty"`
+
+	// Public port to SSH to.
+	// +optional
+	PublicPort int `json:"publicPort,omitempty"`
+}
+
+// ControlPlaneVIPStatus describes a control plane endpoint address allocated on a shared network. The address is reserved
+// as a secondary IP of a control plane VM once one runs, and moved to another one before that VM is destroyed.
+type ControlPlaneVIPStatus struct {
+	// The allocated address.
+	Address string `json:"address"`
+
+	// The ID of the shared network the address belongs to.
+	NetworkID string `json:"networkID"`
+}
+
+// GlobalLoadBalancerStatus describes a global load balancer rule.
+type GlobalLoadBalancerStatus struct {
+	// The ID of the global load balancer rule.
+	RuleID string `json:"ruleID"`
+
+	// The IDs of the isolated networks' load balancer rules assigned to the rule.
+	// +optional
+	LoadBalancerRuleIDs []string `json:"loadBalancerRuleIDs,omitempty"`
+}
+
+// GlobalLoadBalancerFQDN returns the FQDN of the cluster's global load balancer rule, or an empty string when the
+// cluster doesn't use one.
+func (c *CloudStackCluster) GlobalLoadBalancerFQDN() string {
+	if c.Spec.GlobalLoadBalancer == nil {
+		return ""
+	}
+	return c.Spec.GlobalLoadBalancer.DomainName + "." + strings.TrimSuffix(c.Spec.GlobalLoadBalancer.ServiceDomain, ".")
+}
+
+// ControlPlaneEndpointName returns the DNS name CAPC sets as the control plane endpoint host, the cluster's DNS name or
+// its global load balancer's FQDN, or an empty string when the endpoint host is an address.
+func (c *CloudStackCluster) ControlPlaneEndpointName() string {
+	if c.Spec.DNSName != "" {
+		return c.Spec.DNSName
+	}
+	return c.GlobalLoadBalancerFQDN()
+}
+
+// BastionFailureDomainName returns the name of the failure domain the cluster's bastion belongs in, or an empty
+// string when the cluster has no bastion.
+func (c *CloudStackCluster) BastionFailureDomainName() string {
+	if c.Spec.Bastion == nil {
+		return ""
+	}
+	fds := c.FailureDomainSpecs()
+	if c.Spec.Bastion.FailureDomainName != "" || len(fds) == 0 {
+		return c.Spec.Bastion.FailureDomainName
+	}
+	return fds[0].Name
+}
+
+// FailureDomainSpecs returns the cluster's failure domains: those listed in its spec, followed by those discovered from
+// CloudStack zones that aren't listed.
+func (c *CloudStackCluster) FailureDomainSpecs() []CloudStackFailureDomainSpec {
+	fds := append([]CloudStackFailureDomainSpec{}, c.Spec.FailureDomains...)
+	listed := map[string]bool{}
+	for _, fd := range c.Spec.FailureDomains {
+		listed[fd.Name] = true
+	}
+	for _, fd := range c.Status.DiscoveredFailureDomains {
+		if !listed[fd.Name] {
+			fds = append(fds, fd)
+		}
+	}
+	return fds
+}
+
+// FailureDomainSpec returns the spec of the failure domain discovered from a zone, or, given no zone, a spec
+// with just the discovery's credentials.
+func (d *FailureDomainDiscovery) FailureDomainSpec(zoneName string, zoneID string) CloudStackFailureDomainSpec {
+	fd := CloudStackFailureDomainSpec{
+		Account:     d.Account,
+		Domain:      d.Domain,
+		Project:     d.Project,
+		ACSEndpoint: d.ACSEndpoint,
+		IdentityRef: d.IdentityRef,
+	}
+	if zoneName == "" {
+		return fd
+	}
+	fd.Name = DiscoveredFailureDomainName(zoneName)
+	fd.Zone = CloudStackZoneSpec{Name: zoneName, ID: zoneID, Network: *d.Network.DeepCopy()}
+	fd.Zone.Network.Name = strings.ReplaceAll(fd.Zone.Network.Name, ZoneNamePlaceholder, zoneName)
+	if fd.Zone.Network.VPC != nil {
+		fd.Zone.Network.VPC.Name = strings.ReplaceAll(fd.Zone.Network.VPC.Name, ZoneNamePlaceholder, zoneName)
+	}
+	return fd
+}
+
+// DiscoveredFailureDomainName names the failure domain discovered from a zone after the zone, lower-cased and with
+// characters not allowed in DNS subdomains replaced by dashes.
+func DiscoveredFailureDomainName(zoneName string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(zoneName))
+	return strings.Trim(name, "-.")
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:storageversion
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+var _ = Describe("CloudStackCluster", func() {
+	Context("When discovering failure domains", func() {
+		discovery := &infrav1.FailureDomainDiscovery{
+			Account:     "account",
+			Domain:      "domain",
+			ACSEndpoint: corev1.SecretReference{Name: "secret", Namespace: "default"},
+			Network:     infrav1.Network{Name: "k8s-{zone}", Type: infrav1.NetworkTypeIsolated},
+		}
+
+		It("Should name failure domains and networks after their zones", func() {
+			fd := discovery.FailureDomainSpec("Site_A Zone1", "zone-id")
+			Ω(fd.Name).Should(Equal("site-a-zone1"))
+			Ω(fd.Zone.ID).Should(Equal("zone-id"))
+			Ω(fd.Zone.Network.Name).Should(Equal("k8s-Site_A Zone1"))
+			Ω(fd.Account).Should(Equal("account"))
+			Ω(fd.ACSEndpoint.Name).Should(Equal("secret"))
+			Ω(discovery.Network.Name).Should(Equal("k8s-{zone}"))
+		})
+
+		It("Should list discovered failure domains after those of the spec, which take precedence", func() {
+			csCluster := &infrav1.CloudStackCluster{
+				Spec: infrav1.CloudStackClusterSpec{FailureDomains: []infrav1.CloudStackFailureDomainSpec{
+					{Name: "zone2", Zone: infrav1.CloudStackZoneSpec{Name: "listed"}}}},
+				Status: infrav1.CloudStackClusterStatus{DiscoveredFailureDomains: []infrav1.CloudStackFailureDomainSpec{
+					discovery.FailureDomainSpec("zone2", "zone2-id"), discovery.FailureDomainSpec("zone1", "zone1-id")}},
+			}
+			fds := csCluster.FailureDomainSpecs()
+			Ω(fds).Should(HaveLen(2))
+			Ω(fds[0].Zone.Name).Should(Equal("listed"))
+			Ω(fds[1].Name).Should(Equal("zone1"))
+		})
+	})
+})
@@ -17,24 +17,64 @@ limitations under the License.
 package v1beta3
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/webhookutil"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/topology"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // log is for logging in this package.
 var cloudstackclusterlog = logf.Log.WithName("cloudstackcluster-resource")
 
+// apiReader reads the identities and namespaces for validations of the credentials the failure domains of a
+// CloudStackCluster use, and the objects depending on a CloudStackCluster for validations of its deletion.
+var apiReader client.Reader
+
+// ACSEndpointSecretRefResolver resolves the reference of the ACS endpoint secret a failure domain of a cluster in the
+// namespace uses, failing if the namespace isn't allowed to use it. The manager sets it, as the resolution reads the
+// identities and namespaces. The credentials of failure domains aren't validated without it.
+var ACSEndpointSecretRefResolver func(
+	ctx context.Context, namespace string, fdSpec CloudStackFailureDomainSpec) (corev1.SecretReference, error)
+
+// credentialVerificationTimeout bounds the verification of the new credentials of a failure domain, so the webhook
+// answers before the API server gives up on it.
+const credentialVerificationTimeout = 5 * time.Second
+
+// FailureDomainAccountResolver resolves the ID of the CloudStack account a failure domain of a cluster in the namespace
+// acts as with its credentials. The manager sets it, as the CloudStack client depends on this package. Credential
+// changes of failure domains are forbidden without it.
+var FailureDomainAccountResolver func(ctx context.Context, namespace string, fdSpec CloudStackFailureDomainSpec) (string, error)
+
+// EventRecorder records events on CloudStackClusters explaining what refused deletions and updates would have
+// destroyed. The manager sets it.
+var EventRecorder record.EventRecorder
+
 func (r *CloudStackCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	apiReader = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
+		WithValidator(&cloudStackClusterValidator{}).
 		Complete()
 }
 
@@ -48,7 +88,7 @@ func (r *CloudStackCluster) Default() {
 	// No defaulted values supported yet.
 }
 
-// +kubebuilder:webhook:name=vcloudstackcluster.kb.io,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackclusters,versions=v1beta3,verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackcluster,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:name=vcloudstackcluster.kb.io,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackclusters,versions=v1beta3,verbs=create;update;delete,path=/validate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackcluster,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
 var _ webhook.Validator = &CloudStackCluster{}
 
@@ -57,28 +97,19 @@ func (r *CloudStackCluster) ValidateCreate() error {
 	cloudstackclusterlog.V(1).Info("entered validate create webhook", "api resource name", r.Name)
 
 	var errorList field.ErrorList
-
-	// Require FailureDomains and their respective sub-fields.
-	if len(r.Spec.FailureDomains) == 0 {
-		errorList = append(errorList, field.Required(field.NewPath("spec", "FailureDomains"), "FailureDomains"))
-	} else {
-		for _, fdSpec := range r.Spec.FailureDomains { // Require failureDomain names meet k8s qualified name spec.
-			for _, errMsg := range validation.IsDNS1123Subdomain(fdSpec.Name) {
-				errorList = append(errorList, field.Invalid(
-					field.NewPath("spec", "failureDomains", "name"), fdSpec.Name, errMsg))
-			}
-			if fdSpec.Zone.Network.Name == "" && fdSpec.Zone.Network.ID == "" {
-				errorList = append(errorList, field.Required(
-					field.NewPath("spec", "failureDomains", "Zone", "Network"),
-					"each Zone requires a Network specification"))
-			}
-			if fdSpec.ACSEndpoint.Name == "" || fdSpec.ACSEndpoint.Namespace == "" {
-				errorList = append(errorList, field.Required(
-					field.NewPath("spec", "failureDomains", "ACSEndpoint"),
-					"Name and Namespace are required"))
-			}
+	if len(r.Spec.FailureDomains) > 0 || r.Spec.FailureDomainDiscovery == nil {
+		errorList = ValidateFailureDomains(r.Spec.FailureDomains, field.NewPath("spec"))
+	}
+	errorList = append(errorList, ValidateFailureDomainCredentials(
+		context.TODO(), r.Namespace, r.Spec.FailureDomains, field.NewPath("spec"))...)
+	errorList = append(errorList, ValidateFailureDomainDiscovery(
+		r.Spec.FailureDomainDiscovery, field.NewPath("spec", "failureDomainDiscovery"))...)
+	if discovery := r.Spec.FailureDomainDiscovery; discovery != nil && ACSEndpointSecretRefResolver != nil {
+		if _, err := ACSEndpointSecretRefResolver(context.TODO(), r.Namespace, discovery.FailureDomainSpec("", "")); err != nil {
+			errorList = append(errorList, field.Forbidden(field.NewPath("spec", "failureDomainDiscovery"), err.Error()))
 		}
 	}
+	errorList = append(errorList, ValidateClusterSpec(r.Spec, r.Namespace, field.NewPath("spec"))...)
 
 	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
 }
@@ -86,7 +117,11 @@ func (r *CloudStackCluster) ValidateCreate() error {
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *CloudStackCluster) ValidateUpdate(old runtime.Object) error {
 	cloudstackclusterlog.V(1).Info("entered validate update webhook", "api resource name", r.Name)
+	return r.validateUpdate(context.TODO(), old, false)
+}
 
+// validateUpdate validates an update, skipping the checks of immutable fields when asked to.
+func (r *CloudStackCluster) validateUpdate(ctx context.Context, old runtime.Object, skipImmutabilityChecks bool) error {
 	spec := r.Spec
 
 	oldCluster, ok := old.(*CloudStackCluster)
@@ -95,11 +130,19 @@ func (r *CloudStackCluster) ValidateUpdate(old runtime.Object) error {
 	}
 	oldSpec := oldCluster.Spec
 
-	errorList := field.ErrorList(nil)
+	errorList := ValidateClusterSpec(spec, r.Namespace, field.NewPath("spec"))
+	if skipImmutabilityChecks {
+		return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
+	}
 
-	if err := ValidateFailureDomainUpdates(oldSpec.FailureDomains, spec.FailureDomains); err != nil {
+	if err := ValidateFailureDomainUpdates(ctx, r.Namespace, r.capiClusterName(), oldSpec.FailureDomains, spec.FailureDomains); err != nil {
+		errorList = append(errorList, err)
+	}
+	if err := r.validateFailureDomainRemoval(ctx, oldCluster); err != nil {
 		errorList = append(errorList, err)
 	}
+	errorList = append(errorList, ValidateFailureDomainCredentials(
+		ctx, r.Namespace, changedFailureDomains(oldSpec.FailureDomains, spec.FailureDomains), field.NewPath("spec"))...)
 
 	if oldSpec.ControlPlaneEndpoint.Host != "" { // Need to allow one time endpoint setting via CAPC cluster controller.
 		errorList = webhookutil.EnsureEqualStrings(
@@ -109,12 +152,662 @@ func (r *CloudStackCluster) ValidateUpdate(old runtime.Object) error {
 			"controlplaneendpoint.port", errorList)
 	}
 
+	// CloudStack can't change the backend port of a load balancer rule, and replacing the rule interrupts the API server.
+	if apiServerBackendPort(spec) != apiServerBackendPort(oldSpec) {
+		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "apiServerLoadBalancer", "backendPort"),
+			"Cannot change the API server backend port"))
+	}
+	errorList = append(errorList, ValidateFailureDomainDiscovery(
+		spec.FailureDomainDiscovery, field.NewPath("spec", "failureDomainDiscovery"))...)
+	if !failureDomainDiscoveriesEqual(spec.FailureDomainDiscovery, oldSpec.FailureDomainDiscovery) {
+		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "failureDomainDiscovery"),
+			"Cannot add or remove failure domain discovery, nor change anything but its zone selector and interval"))
+	}
+	if oldSpec.DNSName != "" && spec.DNSName != oldSpec.DNSName {
+		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "dnsName"), "Cannot change the DNS name"))
+	}
+	if oldGSLB := oldSpec.GlobalLoadBalancer; oldGSLB != nil && (spec.GlobalLoadBalancer == nil ||
+		oldCluster.GlobalLoadBalancerFQDN() != r.GlobalLoadBalancerFQDN() || oldGSLB.RegionID != spec.GlobalLoadBalancer.RegionID) {
+		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "globalLoadBalancer"),
+			"Cannot remove the global load balancer or change its domain names and region"))
+	}
+	if spec.Bastion != nil && oldSpec.Bastion != nil && !bastionVMsEqual(*spec.Bastion, *oldSpec.Bastion) {
+		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "bastion"),
+			"Cannot change the bastion VM, remove the bastion and add it again instead"))
+	}
+
 	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
 }
 
-// ValidateFailureDomainUpdates verifies that at least one failure domain has not been deleted, and
-// failure domains that are held over have not been modified.
-func ValidateFailureDomainUpdates(oldFDs, newFDs []CloudStackFailureDomainSpec) *field.Error {
+// cloudStackClusterValidator validates CloudStackClusters with access to the admission request, so that dry-run
+// updates of the ClusterClass topology controller, e.g. when rebasing a cluster onto another ClusterClass, aren't
+// rejected for changing immutable fields.
+type cloudStackClusterValidator struct{}
+
+var _ webhook.CustomValidator = &cloudStackClusterValidator{}
+
+// ValidateCreate implements webhook.CustomValidator.
+func (v *cloudStackClusterValidator) ValidateCreate(_ context.Context, obj runtime.Object) error {
+	r, ok := obj.(*CloudStackCluster)
+	if !ok {
+		return errors.NewBadRequest(fmt.Sprintf("expected a CloudStackCluster but got a %T", obj))
+	}
+	return r.ValidateCreate()
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+func (v *cloudStackClusterValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	r, ok := newObj.(*CloudStackCluster)
+	if !ok {
+		return errors.NewBadRequest(fmt.Sprintf("expected a CloudStackCluster but got a %T", newObj))
+	}
+	cloudstackclusterlog.V(1).Info("entered validate update webhook", "api resource name", r.Name)
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return errors.NewBadRequest(err.Error())
+	}
+	return r.validateUpdate(ctx, oldObj, topology.ShouldSkipImmutabilityChecks(req, r))
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (v *cloudStackClusterValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	r, ok := obj.(*CloudStackCluster)
+	if !ok {
+		return errors.NewBadRequest(fmt.Sprintf("expected a CloudStackCluster but got a %T", obj))
+	}
+	cloudstackclusterlog.V(1).Info("entered validate delete webhook", "api resource name", r.Name)
+	return r.validateDelete(ctx)
+}
+
+// ValidateFailureDomains requires failure domains and their respective sub-fields.
+func ValidateFailureDomains(fds []CloudStackFailureDomainSpec, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if len(fds) == 0 {
+		return append(errorList, field.Required(path.Child("FailureDomains"), "FailureDomains"))
+	}
+	for _, fdSpec := range fds { // Require failureDomain names meet k8s qualified name spec.
+		for _, errMsg := range validation.IsDNS1123Subdomain(fdSpec.Name) {
+			errorList = append(errorList, field.Invalid(
+				path.Child("failureDomains", "name"), fdSpec.Name, errMsg))
+		}
+		if fdSpec.Zone.Network.Name == "" && fdSpec.Zone.Network.ID == "" {
+			errorList = append(errorList, field.Required(
+				path.Child("failureDomains", "Zone", "Network"),
+				"each Zone requires a Network specification"))
+		}
+		errorList = append(errorList, ValidateNetworkAddressing(fdSpec.Zone.Network,
+			path.Child("failureDomains", "Zone", "Network"))...)
+		if vpc := fdSpec.Zone.Network.VPC; vpc != nil && vpc.Name == "" && vpc.ID == "" {
+			errorList = append(errorList, field.Required(
+				path.Child("failureDomains", "Zone", "Network", "VPC"),
+				"a VPC requires a Name or ID"))
+		}
+		errorList = append(errorList, ValidateFailureDomainPlacement(fdSpec.Placement,
+			path.Child("failureDomains", "Placement"))...)
+		if fdSpec.IdentityRef != nil {
+			if fdSpec.ACSEndpoint != (corev1.SecretReference{}) {
+				errorList = append(errorList, field.Forbidden(
+					path.Child("failureDomains", "ACSEndpoint"),
+					"an ACSEndpoint can't be specified along with an IdentityRef"))
+			}
+		} else if fdSpec.ACSEndpoint.Name == "" || fdSpec.ACSEndpoint.Namespace == "" {
+			errorList = append(errorList, field.Required(
+				path.Child("failureDomains", "ACSEndpoint"),
+				"Name and Namespace are required"))
+		}
+	}
+	return errorList
+}
+
+// ValidateFailureDomainPlacement requires the pod and cluster of a placement to have a name or ID, and its host tags to
+// be single, non-empty tags.
+func ValidateFailureDomainPlacement(placement *FailureDomainPlacement, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if placement == nil {
+		return errorList
+	}
+	if placement.Pod != nil && placement.Pod.ID == "" && placement.Pod.Name == "" {
+		errorList = append(errorList, field.Required(path.Child("Pod"), "a Pod requires a Name or ID"))
+	}
+	if placement.Cluster != nil && placement.Cluster.ID == "" && placement.Cluster.Name == "" {
+		errorList = append(errorList, field.Required(path.Child("Cluster"), "a Cluster requires a Name or ID"))
+	}
+	for i, tag := range placement.HostTags {
+		if strings.TrimSpace(tag) == "" || strings.Contains(tag, ",") {
+			errorList = append(errorList, field.Invalid(path.Child("HostTags").Index(i), tag,
+				"host tags must be non-empty and must not contain commas"))
+		}
+	}
+	return errorList
+}
+
+// ValidateFailureDomainDiscovery verifies the credentials, zone selector and network of a failure domain discovery. The
+// network must be given by name, as network IDs are specific to a zone.
+func ValidateFailureDomainDiscovery(
+	discovery *FailureDomainDiscovery, path *field.Path,
+) field.ErrorList {
+	var errorList field.ErrorList
+	if discovery == nil {
+		return errorList
+	}
+	if discovery.IdentityRef != nil {
+		if discovery.ACSEndpoint != (corev1.SecretReference{}) {
+			errorList = append(errorList, field.Forbidden(path.Child("acsEndpoint"),
+				"an ACSEndpoint can't be specified along with an IdentityRef"))
+		}
+	} else if discovery.ACSEndpoint.Name == "" || discovery.ACSEndpoint.Namespace == "" {
+		errorList = append(errorList, field.Required(path.Child("acsEndpoint"), "Name and Namespace are required"))
+	}
+	if zones := discovery.Zones; zones != nil && zones.NamePattern != "" {
+		if _, err := regexp.Compile(zones.NamePattern); err != nil {
+			errorList = append(errorList, field.Invalid(path.Child("zones", "namePattern"), zones.NamePattern, err.Error()))
+		}
+	}
+	network := discovery.Network
+	if network.Name == "" {
+		errorList = append(errorList, field.Required(path.Child("network", "name"), "the network of each zone requires a name"))
+	}
+	if network.ID != "" {
+		errorList = append(errorList, field.Forbidden(path.Child("network", "id"), "network IDs are specific to a zone"))
+	}
+	if network.VPC != nil && (network.VPC.ID != "" || network.VPC.Name == "") {
+		errorList = append(errorList, field.Required(path.Child("network", "vpc", "name"),
+			"a VPC requires a name, as VPC IDs are specific to a zone"))
+	}
+	errorList = append(errorList, ValidateNetworkAddressing(network, path.Child("network"))...)
+	if interval := discovery.Interval; interval != nil && interval.Duration <= 0 {
+		errorList = append(errorList, field.Invalid(path.Child("interval"), interval.Duration.String(), "must be positive"))
+	}
+	return errorList
+}
+
+// failureDomainDiscoveriesEqual compares two failure domain discoveries, ignoring their zone selectors and intervals.
+func failureDomainDiscoveriesEqual(d1, d2 *FailureDomainDiscovery) bool {
+	if d1 == nil || d2 == nil {
+		return d1 == d2
+	}
+	d1, d2 = d1.DeepCopy(), d2.DeepCopy()
+	d1.Zones, d2.Zones = nil, nil
+	d1.Interval, d2.Interval = nil, nil
+	return reflect.DeepEqual(d1, d2)
+}
+
+// ValidateFailureDomainCredentials verifies that clusters in the namespace are allowed to use the identities and ACS
+// endpoint secrets of the failure domains.
+func ValidateFailureDomainCredentials(
+	ctx context.Context, namespace string, fds []CloudStackFailureDomainSpec, path *field.Path,
+) field.ErrorList {
+	var errorList field.ErrorList
+	if ACSEndpointSecretRefResolver == nil {
+		return errorList
+	}
+	for _, fdSpec := range fds {
+		if _, err := ACSEndpointSecretRefResolver(ctx, namespace, fdSpec); err != nil {
+			fdPath := path.Child("failureDomains", "ACSEndpoint")
+			if fdSpec.IdentityRef != nil {
+				fdPath = path.Child("failureDomains", "identityRef")
+			}
+			errorList = append(errorList, field.Forbidden(fdPath, err.Error()))
+		}
+	}
+	return errorList
+}
+
+// changedFailureDomains returns the failure domains that are new or differ from the old ones of the same name.
+func changedFailureDomains(oldFDs, newFDs []CloudStackFailureDomainSpec) []CloudStackFailureDomainSpec {
+	oldFDsByName := map[string]CloudStackFailureDomainSpec{}
+	for _, oldFD := range oldFDs {
+		oldFDsByName[oldFD.Name] = oldFD
+	}
+	var changed []CloudStackFailureDomainSpec
+	for _, newFD := range newFDs {
+		if oldFD, present := oldFDsByName[newFD.Name]; !present || !FailureDomainsEqual(newFD, oldFD) {
+			changed = append(changed, newFD)
+		}
+	}
+	return changed
+}
+
+// ValidateClusterSpec verifies the parts of a cluster spec in the namespace that may change after creation.
+func ValidateClusterSpec(spec CloudStackClusterSpec, namespace string, path *field.Path) field.ErrorList {
+	errorList := ValidateFirewallPolicy(spec.FirewallPolicy, path.Child("firewallPolicy"))
+	errorList = append(errorList, ValidateAPIServerLoadBalancer(
+		spec.APIServerLoadBalancer, usesVPC(spec), path.Child("apiServerLoadBalancer"))...)
+	errorList = append(errorList, ValidateLoadBalancerRules(
+		spec.LoadBalancerRules, spec.ControlPlaneEndpoint.Port, path.Child("loadBalancerRules"))...)
+	if ip := spec.ControlPlanePublicIP; ip != nil && ip.Selector != nil {
+		errorList = append(errorList, validateCIDRs(
+			ip.Selector.CIDRs, path.Child("controlPlanePublicIP", "selector", "cidrs"))...)
+	}
+	if period := spec.LoadBalancerDrainPeriod; period != nil && period.Duration < 0 {
+		errorList = append(errorList, field.Invalid(
+			path.Child("loadBalancerDrainPeriod"), period.Duration.String(), "must not be negative"))
+	}
+	bastionFDs := spec.FailureDomains
+	if spec.FailureDomainDiscovery != nil { // The names of discovered failure domains aren't known.
+		bastionFDs = nil
+	}
+	errorList = append(errorList, ValidateBastion(spec.Bastion, bastionFDs, path.Child("bastion"))...)
+	errorList = append(errorList, ValidateVirtualRouterHealth(
+		spec.VirtualRouterHealth, path.Child("virtualRouterHealth"))...)
+	gslbFQDN := (&CloudStackCluster{Spec: spec}).GlobalLoadBalancerFQDN()
+	errorList = append(errorList, ValidateGlobalLoadBalancer(
+		spec.GlobalLoadBalancer, gslbFQDN, spec.ControlPlaneEndpoint.Host, path.Child("globalLoadBalancer"))...)
+	errorList = append(errorList, ValidateDNSName(spec, namespace, path)...)
+	errorList = append(errorList, ValidateAdditionalTags(spec.AdditionalTags, path.Child("additionalTags"))...)
+	return errorList
+}
+
+// capiClusterName returns the name of the CAPI Cluster owning the CloudStackCluster by its cluster name label, owner
+// reference or, as a last resort, its own name.
+func (r *CloudStackCluster) capiClusterName() string {
+	name := r.Name
+	for _, ref := range r.OwnerReferences {
+		if ref.Kind == "Cluster" {
+			name = ref.Name
+		}
+	}
+	if labelName := r.Labels[clusterv1.ClusterNameLabel]; labelName != "" {
+		name = labelName
+	}
+	return name
+}
+
+// ValidateNetworkAddressing verifies the addressing CAPC creates a network with. Overlaps with the cluster's pod and
+// service CIDRs are checked by the isolated network controller, as the CAPI cluster usually doesn't exist yet when
+// the CloudStackCluster is created.
+func ValidateNetworkAddressing(network Network, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+
+	var ipNet *net.IPNet
+	if network.CIDR != "" {
+		_, parsed, err := net.ParseCIDR(network.CIDR)
+		if err != nil || parsed.IP.To4() == nil {
+			errorList = append(errorList, field.Invalid(path.Child("cidr"), network.CIDR, "must be an IPv4 CIDR"))
+		} else {
+			ipNet = parsed
+		}
+	}
+	var mask net.IPMask
+	if network.Netmask != "" {
+		if ip := net.ParseIP(network.Netmask).To4(); ip == nil {
+			errorList = append(errorList, field.Invalid(path.Child("netmask"), network.Netmask, "must be an IPv4 netmask"))
+		} else if mask = net.IPMask(ip); !isContiguousMask(mask) {
+			errorList = append(errorList, field.Invalid(path.Child("netmask"), network.Netmask, "must be an IPv4 netmask"))
+		}
+	}
+	if network.Gateway != "" {
+		gateway := net.ParseIP(network.Gateway).To4()
+		if gateway == nil {
+			errorList = append(errorList, field.Invalid(path.Child("gateway"), network.Gateway, "must be an IPv4 address"))
+		} else if ipNet != nil && !ipNet.Contains(gateway) {
+			errorList = append(errorList, field.Invalid(path.Child("gateway"), network.Gateway, "must be within the network CIDR"))
+		} else if ipNet == nil && isContiguousMask(mask) {
+			ipNet = &net.IPNet{IP: gateway.Mask(mask), Mask: mask}
+		}
+	}
+	if network.CIDR == "" && (network.Gateway == "") != (network.Netmask == "") {
+		errorList = append(errorList, field.Required(path, "gateway and netmask must be specified together"))
+	}
+	for _, dns := range network.DNS {
+		if net.ParseIP(dns) == nil {
+			errorList = append(errorList, field.Invalid(path.Child("dns"), dns, "must be an IP address"))
+		}
+	}
+	for _, dns := range network.IPv6DNS {
+		if ip := net.ParseIP(dns); ip == nil || ip.To4() != nil {
+			errorList = append(errorList, field.Invalid(path.Child("ipv6DNS"), dns, "must be an IPv6 address"))
+		}
+	}
+	if network.NetworkDomain != "" {
+		for _, errMsg := range validation.IsDNS1123Subdomain(network.NetworkDomain) {
+			errorList = append(errorList, field.Invalid(path.Child("networkDomain"), network.NetworkDomain, errMsg))
+		}
+	}
+
+	return errorList
+}
+
+// ClusterNetworkOverlaps describes the pod and service CIDRs of the cluster network the addressing of a network
+// overlaps with. Networks without addressing of their own don't overlap.
+func ClusterNetworkOverlaps(network Network, clusterNetwork *clusterv1.ClusterNetwork) []string {
+	var overlaps []string
+	var ipNet *net.IPNet
+	if network.CIDR != "" {
+		_, ipNet, _ = net.ParseCIDR(network.CIDR)
+	} else if gateway, mask := net.ParseIP(network.Gateway).To4(), net.ParseIP(network.Netmask).To4(); gateway != nil &&
+		mask != nil && isContiguousMask(net.IPMask(mask)) {
+		ipNet = &net.IPNet{IP: gateway.Mask(net.IPMask(mask)), Mask: net.IPMask(mask)}
+	}
+	if ipNet == nil || clusterNetwork == nil {
+		return overlaps
+	}
+	clusterCIDRs := []struct {
+		kind   string
+		ranges *clusterv1.NetworkRanges
+	}{
+		{"pod", clusterNetwork.Pods},
+		{"service", clusterNetwork.Services},
+	}
+	for _, clusterCIDR := range clusterCIDRs {
+		if clusterCIDR.ranges == nil {
+			continue
+		}
+		for _, block := range clusterCIDR.ranges.CIDRBlocks {
+			if _, clusterNet, err := net.ParseCIDR(block); err == nil &&
+				(clusterNet.Contains(ipNet.IP) || ipNet.Contains(clusterNet.IP)) {
+				overlaps = append(overlaps, fmt.Sprintf("%s overlaps with the cluster's %s CIDR %s", ipNet, clusterCIDR.kind, block))
+			}
+		}
+	}
+	return overlaps
+}
+
+// ValidateFirewallPolicy verifies the CIDRs and port ranges of a firewall policy.
+func ValidateFirewallPolicy(policy *FirewallPolicy, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if policy == nil {
+		return errorList
+	}
+	for i, rule := range policy.Ingress {
+		rulePath := path.Child("ingress").Index(i)
+		if rule.Port < 0 || rule.Port > 65535 {
+			errorList = append(errorList, field.Invalid(rulePath.Child("port"), rule.Port, "must be a valid port"))
+		}
+		errorList = append(errorList, validateCIDRs(rule.SourceCIDRs, rulePath.Child("sourceCIDRs"))...)
+	}
+	for i, rule := range policy.Egress {
+		rulePath := path.Child("egress").Index(i)
+		if rule.StartPort < 0 || rule.StartPort > 65535 {
+			errorList = append(errorList, field.Invalid(rulePath.Child("startPort"), rule.StartPort, "must be a valid port"))
+		}
+		if rule.EndPort != 0 && (rule.EndPort < rule.StartPort || rule.EndPort > 65535) {
+			errorList = append(errorList, field.Invalid(rulePath.Child("endPort"), rule.EndPort,
+				"must be a valid port not lower than startPort"))
+		}
+		errorList = append(errorList, validateCIDRs(rule.DestinationCIDRs, rulePath.Child("destinationCIDRs"))...)
+	}
+	return errorList
+}
+
+// usesVPC checks whether any failure domain of a cluster spec places its network in a VPC.
+func usesVPC(spec CloudStackClusterSpec) bool {
+	if discovery := spec.FailureDomainDiscovery; discovery != nil && discovery.Network.VPC != nil {
+		return true
+	}
+	for _, fd := range spec.FailureDomains {
+		if fd.Zone.Network.VPC != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// apiServerBackendPort returns the port the API servers of a cluster spec listen on.
+func apiServerBackendPort(spec CloudStackClusterSpec) int {
+	if lb := spec.APIServerLoadBalancer; lb != nil && lb.BackendPort != 0 {
+		return lb.BackendPort
+	}
+	return 6443
+}
+
+// ValidateAPIServerLoadBalancer verifies the backend port and the health check policy of the API server load balancer.
+// The internal load balancers of VPC clusters support neither health check nor stickiness policies.
+func ValidateAPIServerLoadBalancer(lb *APIServerLoadBalancer, vpc bool, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if lb == nil {
+		return errorList
+	}
+	if lb.BackendPort < 0 || lb.BackendPort > 65535 {
+		errorList = append(errorList, field.Invalid(path.Child("backendPort"), lb.BackendPort, "must be a valid port"))
+	}
+	if vpc && lb.HealthCheck != nil {
+		errorList = append(errorList, field.Forbidden(path.Child("healthCheck"),
+			"not supported by the internal load balancers of VPC networks"))
+	}
+	if vpc && lb.Stickiness != nil {
+		errorList = append(errorList, field.Forbidden(path.Child("stickiness"),
+			"not supported by the internal load balancers of VPC networks"))
+	}
+	if hc := lb.HealthCheck; hc != nil {
+		hcPath := path.Child("healthCheck")
+		if hc.PingPath != "" && !strings.HasPrefix(hc.PingPath, "/") {
+			errorList = append(errorList, field.Invalid(hcPath.Child("pingPath"), hc.PingPath, "must start with /"))
+		}
+		for _, setting := range []struct {
+			name  string
+			value int
+		}{
+			{"intervalSeconds", hc.IntervalSeconds},
+			{"responseTimeoutSeconds", hc.ResponseTimeoutSeconds},
+			{"healthyThreshold", hc.HealthyThreshold},
+			{"unhealthyThreshold", hc.UnhealthyThreshold},
+		} {
+			if setting.value < 0 {
+				errorList = append(errorList, field.Invalid(hcPath.Child(setting.name), setting.value, "must not be negative"))
+			}
+		}
+		if hc.IntervalSeconds != 0 && hc.ResponseTimeoutSeconds >= hc.IntervalSeconds {
+			errorList = append(errorList, field.Invalid(hcPath.Child("responseTimeoutSeconds"), hc.ResponseTimeoutSeconds,
+				"must be lower than intervalSeconds"))
+		}
+	}
+	return errorList
+}
+
+// ValidateLoadBalancerRules verifies workload load balancer rules don't clash with each other or with the control
+// plane endpoint.
+func ValidateLoadBalancerRules(rules []WorkloadLoadBalancerRule, endpointPort int32, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	names := map[string]bool{}
+	listeners := map[string]bool{}
+	for i, rule := range rules {
+		rulePath := path.Index(i)
+		if names[rule.Name] {
+			errorList = append(errorList, field.Duplicate(rulePath.Child("name"), rule.Name))
+		}
+		names[rule.Name] = true
+
+		if rule.PublicPort < 1 || rule.PublicPort > 65535 {
+			errorList = append(errorList, field.Invalid(rulePath.Child("publicPort"), rule.PublicPort, "must be a valid port"))
+		}
+		if rule.PrivatePort < 0 || rule.PrivatePort > 65535 {
+			errorList = append(errorList, field.Invalid(rulePath.Child("privatePort"), rule.PrivatePort, "must be a valid port"))
+		}
+		if rule.PublicIPAddress != "" && net.ParseIP(rule.PublicIPAddress) == nil {
+			errorList = append(errorList, field.Invalid(rulePath.Child("publicIPAddress"), rule.PublicIPAddress,
+				"must be an IP address"))
+		}
+		errorList = append(errorList, validateCIDRs(rule.SourceCIDRs, rulePath.Child("sourceCIDRs"))...)
+		protocol := rule.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		if rule.PublicIPAddress == "" && protocol == "tcp" && endpointPort != 0 && int32(rule.PublicPort) == endpointPort {
+			errorList = append(errorList, field.Invalid(rulePath.Child("publicPort"), rule.PublicPort,
+				"is used by the control plane endpoint"))
+		}
+		listener := fmt.Sprintf("%s/%d/%s", rule.PublicIPAddress, rule.PublicPort, protocol)
+		if listeners[listener] {
+			errorList = append(errorList, field.Duplicate(rulePath.Child("publicPort"), rule.PublicPort))
+		}
+		listeners[listener] = true
+
+		if _, err := metav1.LabelSelectorAsSelector(&rule.MachineDeploymentSelector); err != nil {
+			errorList = append(errorList, field.Invalid(rulePath.Child("machineDeploymentSelector"),
+				rule.MachineDeploymentSelector, err.Error()))
+		}
+	}
+	return errorList
+}
+
+// ValidateBastion verifies the bastion's VM is fully specified and placed in one of the cluster's failure domains, unless
+// they aren't known (nil).
+func ValidateBastion(bastion *Bastion, fds []CloudStackFailureDomainSpec, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if bastion == nil {
+		return errorList
+	}
+	if bastion.Offering.ID == "" && bastion.Offering.Name == "" {
+		errorList = append(errorList, field.Required(path.Child("offering"), "an offering requires a Name or ID"))
+	}
+	if bastion.Template.ID == "" && bastion.Template.Name == "" {
+		errorList = append(errorList, field.Required(path.Child("template"), "a template requires a Name or ID"))
+	}
+	if bastion.FailureDomainName != "" && fds != nil {
+		found := false
+		for _, fd := range fds {
+			found = found || fd.Name == bastion.FailureDomainName
+		}
+		if !found {
+			errorList = append(errorList, field.NotFound(path.Child("failureDomainName"), bastion.FailureDomainName))
+		}
+	}
+	if bastion.PublicIPAddress != "" && net.ParseIP(bastion.PublicIPAddress) == nil {
+		errorList = append(errorList, field.Invalid(path.Child("publicIPAddress"), bastion.PublicIPAddress,
+			"must be an IP address"))
+	}
+	if bastion.PublicPort < 0 || bastion.PublicPort > 65535 {
+		errorList = append(errorList, field.Invalid(path.Child("publicPort"), bastion.PublicPort, "must be a valid port"))
+	}
+	if len(bastion.AllowedCIDRs) == 0 {
+		errorList = append(errorList, field.Required(path.Child("allowedCIDRs"), "at least one CIDR is required"))
+	}
+	errorList = append(errorList, validateCIDRs(bastion.AllowedCIDRs, path.Child("allowedCIDRs"))...)
+	return errorList
+}
+
+// ValidateVirtualRouterHealth verifies the intervals of the virtual router health monitoring.
+func ValidateVirtualRouterHealth(health *VirtualRouterHealth, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if health == nil {
+		return errorList
+	}
+	if health.CheckInterval != nil && health.CheckInterval.Duration <= 0 {
+		errorList = append(errorList, field.Invalid(
+			path.Child("checkInterval"), health.CheckInterval.Duration.String(), "must be positive"))
+	}
+	if health.MinRestartInterval != nil && health.MinRestartInterval.Duration < 0 {
+		errorList = append(errorList, field.Invalid(
+			path.Child("minRestartInterval"), health.MinRestartInterval.Duration.String(), "must not be negative"))
+	}
+	return errorList
+}
+
+// ValidateGlobalLoadBalancer verifies the global load balancer's domain names, and that the control plane endpoint host,
+// if set, is the global load balancer's FQDN.
+func ValidateGlobalLoadBalancer(gslb *GlobalLoadBalancer, fqdn string, endpointHost string, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if gslb == nil {
+		return errorList
+	}
+	for _, errMsg := range validation.IsDNS1123Label(gslb.DomainName) {
+		errorList = append(errorList, field.Invalid(path.Child("domainName"), gslb.DomainName, errMsg))
+	}
+	for _, errMsg := range validation.IsDNS1123Subdomain(strings.TrimSuffix(gslb.ServiceDomain, ".")) {
+		errorList = append(errorList, field.Invalid(path.Child("serviceDomain"), gslb.ServiceDomain, errMsg))
+	}
+	if endpointHost != "" && endpointHost != fqdn {
+		errorList = append(errorList, field.Invalid(field.NewPath("spec", "controlPlaneEndpoint", "host"), endpointHost,
+			fmt.Sprintf("must be empty or the global load balancer's FQDN %s", fqdn)))
+	}
+	return errorList
+}
+
+// ValidateDNSName verifies the cluster's DNS name and DNS provider, and that the control plane endpoint host, if set, is
+// the DNS name. The TSIG secret must be in the cluster's namespace.
+func ValidateDNSName(spec CloudStackClusterSpec, namespace string, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if spec.DNSName == "" {
+		return errorList
+	}
+	for _, errMsg := range validation.IsDNS1123Subdomain(strings.TrimSuffix(spec.DNSName, ".")) {
+		errorList = append(errorList, field.Invalid(path.Child("dnsName"), spec.DNSName, errMsg))
+	}
+	if spec.GlobalLoadBalancer != nil {
+		errorList = append(errorList, field.Forbidden(path.Child("dnsName"),
+			"the control plane endpoint host is the global load balancer's FQDN"))
+	}
+	if host := spec.ControlPlaneEndpoint.Host; host != "" && host != spec.DNSName {
+		errorList = append(errorList, field.Invalid(path.Child("controlPlaneEndpoint", "host"), host,
+			fmt.Sprintf("must be empty or the DNS name %s", spec.DNSName)))
+	}
+	if spec.DNSProvider == nil || spec.DNSProvider.RFC2136 == nil {
+		return append(errorList, field.Required(path.Child("dnsProvider"), "a DNS name requires a DNS provider"))
+	}
+	rfc2136 := spec.DNSProvider.RFC2136
+	if zone := strings.TrimSuffix(rfc2136.Zone, "."); spec.DNSName != zone &&
+		!strings.HasSuffix(strings.TrimSuffix(spec.DNSName, "."), "."+zone) {
+		errorList = append(errorList, field.Invalid(path.Child("dnsProvider", "rfc2136", "zone"), rfc2136.Zone,
+			fmt.Sprintf("must contain the DNS name %s", spec.DNSName)))
+	}
+	if rfc2136.TSIGKeyName != "" && (rfc2136.TSIGSecretRef == nil || rfc2136.TSIGSecretRef.Name == "") {
+		errorList = append(errorList, field.Required(path.Child("dnsProvider", "rfc2136", "tsigSecretRef"),
+			"a TSIG key requires a secret"))
+	}
+	if ref := rfc2136.TSIGSecretRef; ref != nil && ref.Namespace != "" && ref.Namespace != namespace {
+		errorList = append(errorList, field.Forbidden(path.Child("dnsProvider", "rfc2136", "tsigSecretRef", "namespace"),
+			fmt.Sprintf("the TSIG secret must be in the cluster's namespace %s", namespace)))
+	}
+	return errorList
+}
+
+// ValidateAdditionalTags verifies that additional tags don't use the keys CAPC tags its resources with. Keys can't
+// contain commas either, as CAPC records the keys it added in a comma-separated tag.
+func ValidateAdditionalTags(tags map[string]string, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch {
+		case strings.TrimSpace(key) == "":
+			errorList = append(errorList, field.Invalid(path, key, "tag keys must not be empty"))
+		case strings.HasPrefix(key, "CAPC_") || key == "created_by_CAPC":
+			errorList = append(errorList, field.Invalid(path.Key(key), key,
+				"tag keys starting with CAPC_ and created_by_CAPC are reserved for CAPC"))
+		case strings.Contains(key, ","):
+			errorList = append(errorList, field.Invalid(path.Key(key), key, "tag keys must not contain commas"))
+		}
+	}
+	return errorList
+}
+
+// bastionVMsEqual compares the parts of two bastions CAPC only applies when deploying the bastion VM.
+func bastionVMsEqual(b1, b2 Bastion) bool {
+	return b1.FailureDomainName == b2.FailureDomainName &&
+		b1.Offering == b2.Offering &&
+		b1.Template == b2.Template &&
+		b1.SSHKey == b2.SSHKey &&
+		b1.PublicIPAddress == b2.PublicIPAddress
+}
+
+func validateCIDRs(cidrs []string, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errorList = append(errorList, field.Invalid(path, cidr, "must be a CIDR"))
+		}
+	}
+	return errorList
+}
+
+// isContiguousMask checks that a netmask is a valid prefix mask.
+func isContiguousMask(mask net.IPMask) bool {
+	if len(mask) == 0 {
+		return false
+	}
+	ones, bits := mask.Size()
+	return bits != 0 || ones != 0
+}
+
+// ValidateFailureDomainUpdates verifies that at least one failure domain of the cluster has not been deleted, and
+// failure domains that are held over have not been modified, except for their credentials.
+func ValidateFailureDomainUpdates(
+	ctx context.Context, namespace, clusterName string, oldFDs, newFDs []CloudStackFailureDomainSpec,
+) *field.Error {
 	newFDsByName := map[string]CloudStackFailureDomainSpec{}
 	for _, newFD := range newFDs {
 		newFDsByName[newFD.Name] = newFD
@@ -124,34 +817,196 @@ func ValidateFailureDomainUpdates(oldFDs, newFDs []CloudStackFailureDomainSpec)
 	for _, oldFD := range oldFDs {
 		if newFD, present := newFDsByName[oldFD.Name]; present {
 			atLeastOneRemains = true
-			if !FailureDomainsEqual(newFD, oldFD) {
+			if FailureDomainsEqual(newFD, oldFD) {
+				continue
+			} else if !FailureDomainLocationsEqual(newFD, oldFD) {
 				return field.Forbidden(field.NewPath("spec", "FailureDomains"),
 					fmt.Sprintf("Cannot change FailureDomain %s", oldFD.Name))
+			} else if err := validateFailureDomainCredentialsUpdate(ctx, namespace, clusterName, newFD); err != nil {
+				return err
 			}
 		}
 	}
-	if !atLeastOneRemains {
+	if !atLeastOneRemains && len(oldFDs) > 0 { // Clusters may have discovered failure domains only.
 		return field.Forbidden(field.NewPath("spec", "FailureDomains"), "At least one FailureDomain must be unchanged on update.")
 	}
 	return nil
 }
 
-// FailureDomainsEqual is a manual deep equal on failure domains.
+// validateFailureDomainCredentialsUpdate verifies that the new credentials of a failure domain act as the account its
+// resources were created in, as recorded in the status of its CloudStackFailureDomain. Credentials of a failure domain
+// that wasn't reconciled yet only need to resolve to an account.
+func validateFailureDomainCredentialsUpdate(
+	ctx context.Context, namespace, clusterName string, newFD CloudStackFailureDomainSpec,
+) *field.Error {
+	path := field.NewPath("spec", "FailureDomains")
+	if FailureDomainAccountResolver == nil || apiReader == nil {
+		return field.Forbidden(path, fmt.Sprintf(
+			"Cannot change the credentials of FailureDomain %s, they can't be verified", newFD.Name))
+	}
+	ctx, cancel := context.WithTimeout(ctx, credentialVerificationTimeout)
+	defer cancel()
+
+	csFD := &CloudStackFailureDomain{}
+	key := client.ObjectKey{Namespace: namespace, Name: FailureDomainHashedMetaName(newFD.Name, clusterName)}
+	if err := apiReader.Get(ctx, key, csFD); client.IgnoreNotFound(err) != nil {
+		return field.InternalError(path, err)
+	}
+	newAccountID, err := FailureDomainAccountResolver(ctx, namespace, newFD)
+	if err != nil {
+		return field.Invalid(path.Child("ACSEndpoint"), newFD.ACSEndpoint, fmt.Sprintf(
+			"resolving the account of the new credentials of FailureDomain %s: %s", newFD.Name, err))
+	}
+	if recorded := csFD.Status.AccountID; recorded != "" && newAccountID != recorded {
+		return field.Forbidden(path, fmt.Sprintf(
+			"Cannot change the credentials of FailureDomain %s to those of another account", newFD.Name))
+	}
+	return nil
+}
+
+// FailureDomainsEqual is a manual deep equal on failure domains. The whole network is compared, as the network CAPC
+// creates, its VPC and its tags are only set up once.
 func FailureDomainsEqual(fd1, fd2 CloudStackFailureDomainSpec) bool {
 	return fd1.Name == fd2.Name &&
 		fd1.ACSEndpoint == fd2.ACSEndpoint &&
+		identityRefsEqual(fd1.IdentityRef, fd2.IdentityRef) &&
 		fd1.Account == fd2.Account &&
 		fd1.Domain == fd2.Domain &&
+		fd1.Project == fd2.Project &&
 		fd1.Zone.Name == fd2.Zone.Name &&
 		fd1.Zone.ID == fd2.Zone.ID &&
-		fd1.Zone.Network.Name == fd2.Zone.Network.Name &&
-		fd1.Zone.Network.ID == fd2.Zone.Network.ID &&
-		fd1.Zone.Network.Type == fd2.Zone.Network.Type
+		equality.Semantic.DeepEqual(fd1.Zone.Network, fd2.Zone.Network) &&
+		reflect.DeepEqual(fd1.Placement, fd2.Placement)
+}
+
+// FailureDomainLocationsEqual compares failure domains ignoring their credentials, i.e. their ACS endpoint or identity,
+// account and domain.
+func FailureDomainLocationsEqual(fd1, fd2 CloudStackFailureDomainSpec) bool {
+	fd1.ACSEndpoint, fd1.IdentityRef, fd1.Account, fd1.Domain = fd2.ACSEndpoint, fd2.IdentityRef, fd2.Account, fd2.Domain
+	return FailureDomainsEqual(fd1, fd2)
+}
+
+func identityRefsEqual(ref1, ref2 *CloudStackIdentityReference) bool {
+	if ref1 == nil || ref2 == nil {
+		return ref1 == ref2
+	}
+	return *ref1 == *ref2
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *CloudStackCluster) ValidateDelete() error {
 	cloudstackclusterlog.V(1).Info("entered validate delete webhook", "api resource name", r.Name)
-	// No deletion validations.  Deletion webhook not enabled.
-	return nil
+	return r.validateDelete(context.TODO())
+}
+
+// validateDelete refuses deleting a CloudStackCluster with deletion protection.
+func (r *CloudStackCluster) validateDelete(ctx context.Context) error {
+	if !r.Spec.DeletionProtection {
+		return nil
+	}
+	msg := fmt.Sprintf("deletion protection is enabled, deleting the cluster would destroy %s. "+
+		"Set spec.deletionProtection to false to delete it", r.describeDestroyedResources(ctx))
+	recordRefusal(ctx, r, "DeletionRefused", msg)
+	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, field.ErrorList{
+		field.Forbidden(field.NewPath("spec", "deletionProtection"), msg)})
+}
+
+// validateFailureDomainRemoval refuses removing failure domains machines are placed in, as the machines are deleted
+// along with them, unless the CloudStackCluster is annotated to allow it. Failure domains that are also discovered
+// aren't removed. Removals are refused if the machines can't be listed.
+func (r *CloudStackCluster) validateFailureDomainRemoval(ctx context.Context, oldCluster *CloudStackCluster) *field.Error {
+	path := field.NewPath("spec", "FailureDomains")
+	if _, allowed := r.Annotations[AllowFailureDomainRemovalAnnotation]; allowed {
+		return nil
+	}
+	remaining := map[string]bool{}
+	for _, fd := range append(r.Spec.FailureDomains, oldCluster.Status.DiscoveredFailureDomains...) {
+		remaining[fd.Name] = true
+	}
+	removed := map[string]bool{}
+	var removedNames []string
+	for _, fd := range oldCluster.Spec.FailureDomains {
+		if !remaining[fd.Name] {
+			removed[fd.Name] = true
+			removedNames = append(removedNames, fd.Name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	machines, err := r.listMachines(ctx)
+	if err != nil {
+		return field.InternalError(path, err)
+	}
+	var machineNames []string
+	for _, machine := range machines {
+		if removed[machine.Spec.FailureDomainName] {
+			machineNames = append(machineNames, machine.Name)
+		}
+	}
+	if len(machineNames) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("removing failure domains %s would delete machines %s. Annotate the CloudStackCluster with %s "+
+		"to remove them anyway", strings.Join(removedNames, ", "), strings.Join(machineNames, ", "),
+		AllowFailureDomainRemovalAnnotation)
+	recordRefusal(ctx, oldCluster, "FailureDomainRemovalRefused", msg)
+	return field.Forbidden(path, msg)
+}
+
+// describeDestroyedResources describes the machines, isolated networks and control plane endpoint deleting the cluster
+// destroys.
+func (r *CloudStackCluster) describeDestroyedResources(ctx context.Context) string {
+	var resources []string
+	if machines, err := r.listMachines(ctx); err != nil {
+		resources = append(resources, "its machines")
+	} else if len(machines) > 0 {
+		var names []string
+		for _, machine := range machines {
+			names = append(names, machine.Name)
+		}
+		resources = append(resources, fmt.Sprintf("machines %s", strings.Join(names, ", ")))
+	}
+	isoNets := &CloudStackIsolatedNetworkList{}
+	if apiReader == nil || apiReader.List(ctx, isoNets, client.InNamespace(r.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: r.capiClusterName()}) != nil {
+		resources = append(resources, "its isolated networks")
+	} else if len(isoNets.Items) > 0 {
+		var names []string
+		for _, isoNet := range isoNets.Items {
+			names = append(names, isoNet.Spec.Name)
+		}
+		resources = append(resources, fmt.Sprintf("isolated networks %s", strings.Join(names, ", ")))
+	}
+	if host := r.Spec.ControlPlaneEndpoint.Host; host != "" {
+		resources = append(resources, fmt.Sprintf("control plane endpoint %s", host))
+	}
+	if len(resources) == 0 {
+		return "the cluster"
+	}
+	return strings.Join(resources, "; ")
+}
+
+// listMachines lists the CloudStackMachines of the cluster.
+func (r *CloudStackCluster) listMachines(ctx context.Context) ([]CloudStackMachine, error) {
+	if apiReader == nil {
+		return nil, fmt.Errorf("listing the machines of CloudStackCluster %s isn't possible", r.Name)
+	}
+	machines := &CloudStackMachineList{}
+	if err := apiReader.List(ctx, machines, client.InNamespace(r.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: r.capiClusterName()}); err != nil {
+		return nil, err
+	}
+	return machines.Items, nil
+}
+
+// recordRefusal records a Warning event on the CloudStackCluster explaining a refused request, unless it's a dry run.
+func recordRefusal(ctx context.Context, r *CloudStackCluster, reason string, msg string) {
+	if EventRecorder == nil {
+		return
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.DryRun != nil && *req.DryRun {
+		return
+	}
+	EventRecorder.Event(r, corev1.EventTypeWarning, reason, msg)
 }
@@ -18,11 +18,17 @@ package v1beta3_test
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 var _ = Describe("CloudStackCluster webhooks", func() {
@@ -49,6 +55,12 @@ var _ = Describe("CloudStackCluster webhooks", func() {
 				MatchError(MatchRegexp(requiredRegex, "each Zone requires a Network specification")))
 		})
 
+		It("Should reject a CloudStackCluster with a VPC missing both Name and ID", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].Zone.Network.VPC = &infrav1.VPC{CIDR: "10.0.0.0/16"}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(
+				MatchError(MatchRegexp(requiredRegex, "a VPC requires a Name or ID")))
+		})
+
 		It("Should reject a CloudStackCluster with missing Zone attribute", func() {
 			dummies.CSCluster.Spec.FailureDomains[0].Zone = infrav1.CloudStackZoneSpec{}
 			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(MatchError(MatchRegexp(requiredRegex,
@@ -56,6 +68,408 @@ var _ = Describe("CloudStackCluster webhooks", func() {
 		})
 	})
 
+	Context("When validating network addressing", func() {
+		path := field.NewPath("spec", "failureDomains", "Zone", "Network")
+		clusterNetwork := &clusterv1.ClusterNetwork{
+			Pods:     &clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+			Services: &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.96.0.0/12"}},
+		}
+
+		It("Should accept a CIDR outside the cluster's pod and service CIDRs", func() {
+			network := infrav1.Network{Name: "net", CIDR: "10.1.0.0/24", Gateway: "10.1.0.1", DNS: []string{"8.8.8.8"}}
+			Ω(infrav1.ValidateNetworkAddressing(network, path)).Should(BeEmpty())
+			Ω(infrav1.ClusterNetworkOverlaps(network, clusterNetwork)).Should(BeEmpty())
+		})
+
+		It("Should report a CIDR overlapping with the cluster's pod CIDR", func() {
+			network := infrav1.Network{Name: "net", CIDR: "192.168.10.0/24"}
+			Ω(infrav1.ClusterNetworkOverlaps(network, clusterNetwork)).
+				Should(ConsistOf(ContainSubstring("overlaps with the cluster's pod CIDR")))
+		})
+
+		It("Should report a gateway and netmask overlapping with the cluster's service CIDR", func() {
+			network := infrav1.Network{Name: "net", Gateway: "10.100.0.1", Netmask: "255.255.255.0"}
+			Ω(infrav1.ClusterNetworkOverlaps(network, clusterNetwork)).
+				Should(ConsistOf(ContainSubstring("overlaps with the cluster's service CIDR")))
+		})
+
+		It("Should reject an IPv4 address as IPv6 DNS server", func() {
+			network := infrav1.Network{Name: "net", IPv6DNS: []string{"2001:db8::53", "8.8.8.8"}}
+			Ω(infrav1.ValidateNetworkAddressing(network, path).ToAggregate()).
+				Should(MatchError(ContainSubstring("must be an IPv6 address")))
+		})
+
+		It("Should reject a gateway outside the CIDR", func() {
+			network := infrav1.Network{Name: "net", CIDR: "10.1.0.0/24", Gateway: "10.2.0.1"}
+			Ω(infrav1.ValidateNetworkAddressing(network, path).ToAggregate()).
+				Should(MatchError(ContainSubstring("must be within the network CIDR")))
+		})
+	})
+
+	Context("When validating a firewall policy", func() {
+		path := field.NewPath("spec", "firewallPolicy")
+
+		It("Should accept valid ingress and egress rules", func() {
+			policy := &infrav1.FirewallPolicy{
+				Ingress: []infrav1.IngressFirewallRule{{SourceCIDRs: []string{"192.0.2.0/24"}}},
+				Egress:  []infrav1.EgressFirewallRule{{Protocol: "tcp", StartPort: 80, EndPort: 443}},
+			}
+			Ω(infrav1.ValidateFirewallPolicy(policy, path)).Should(BeEmpty())
+		})
+
+		It("Should reject invalid CIDRs and port ranges", func() {
+			policy := &infrav1.FirewallPolicy{
+				Ingress: []infrav1.IngressFirewallRule{{SourceCIDRs: []string{"192.0.2.0"}}},
+				Egress:  []infrav1.EgressFirewallRule{{Protocol: "tcp", StartPort: 443, EndPort: 80}},
+			}
+			Ω(infrav1.ValidateFirewallPolicy(policy, path)).Should(HaveLen(2))
+		})
+	})
+
+	Context("When validating the API server load balancer", func() {
+		path := field.NewPath("spec", "apiServerLoadBalancer")
+
+		It("Should accept a health check on a custom backend port", func() {
+			lb := &infrav1.APIServerLoadBalancer{BackendPort: 8443, HealthCheck: &infrav1.LoadBalancerHealthCheck{
+				PingPath: "/healthz", IntervalSeconds: 10, ResponseTimeoutSeconds: 2}}
+			Ω(infrav1.ValidateAPIServerLoadBalancer(lb, false, path)).Should(BeEmpty())
+		})
+
+		It("Should reject a relative ping path and a timeout longer than the interval", func() {
+			lb := &infrav1.APIServerLoadBalancer{HealthCheck: &infrav1.LoadBalancerHealthCheck{
+				PingPath: "healthz", IntervalSeconds: 5, ResponseTimeoutSeconds: 10}}
+			Ω(infrav1.ValidateAPIServerLoadBalancer(lb, false, path)).Should(HaveLen(2))
+		})
+
+		It("Should reject health check and stickiness policies on VPC networks", func() {
+			lb := &infrav1.APIServerLoadBalancer{HealthCheck: &infrav1.LoadBalancerHealthCheck{PingPath: "/healthz"},
+				Stickiness: &infrav1.LoadBalancerStickiness{Method: "SourceBased"}}
+			Ω(infrav1.ValidateAPIServerLoadBalancer(lb, true, path)).Should(HaveLen(2))
+		})
+	})
+
+	Context("When validating workload load balancer rules", func() {
+		path := field.NewPath("spec", "loadBalancerRules")
+
+		It("Should accept rules on different ports or public IPs", func() {
+			rules := []infrav1.WorkloadLoadBalancerRule{
+				{Name: "http", PublicPort: 80, PrivatePort: 30080},
+				{Name: "https", PublicPort: 443, PrivatePort: 30443},
+				{Name: "https-dedicated", PublicPort: 443, PublicIPAddress: "192.0.2.10"},
+			}
+			Ω(infrav1.ValidateLoadBalancerRules(rules, 6443, path)).Should(BeEmpty())
+		})
+
+		It("Should reject duplicate names, listeners and the control plane endpoint port", func() {
+			rules := []infrav1.WorkloadLoadBalancerRule{
+				{Name: "http", PublicPort: 80},
+				{Name: "http", PublicPort: 80},
+				{Name: "api", PublicPort: 6443},
+			}
+			Ω(infrav1.ValidateLoadBalancerRules(rules, 6443, path)).Should(HaveLen(3))
+		})
+	})
+
+	Context("When validating a bastion", func() {
+		path := field.NewPath("spec", "bastion")
+		fds := []infrav1.CloudStackFailureDomainSpec{{Name: "fd1"}}
+
+		It("Should accept a bastion in one of the cluster's failure domains", func() {
+			bastion := &infrav1.Bastion{FailureDomainName: "fd1", Offering: infrav1.CloudStackResourceIdentifier{Name: "small"},
+				Template: infrav1.CloudStackResourceIdentifier{ID: "template-id"}, AllowedCIDRs: []string{"192.0.2.0/24"}}
+			Ω(infrav1.ValidateBastion(bastion, fds, path)).Should(BeEmpty())
+		})
+
+		It("Should reject an unknown failure domain, a missing template and invalid CIDRs", func() {
+			bastion := &infrav1.Bastion{FailureDomainName: "fd2", Offering: infrav1.CloudStackResourceIdentifier{Name: "small"},
+				AllowedCIDRs: []string{"192.0.2.1"}}
+			Ω(infrav1.ValidateBastion(bastion, fds, path)).Should(HaveLen(3))
+		})
+	})
+
+	Context("When validating virtual router health monitoring", func() {
+		path := field.NewPath("spec", "virtualRouterHealth")
+
+		It("Should reject a zero check interval and a negative restart interval", func() {
+			health := &infrav1.VirtualRouterHealth{
+				CheckInterval:      &metav1.Duration{},
+				MinRestartInterval: &metav1.Duration{Duration: -time.Minute},
+			}
+			Ω(infrav1.ValidateVirtualRouterHealth(health, path)).Should(HaveLen(2))
+		})
+	})
+
+	Context("When validating a global load balancer", func() {
+		path := field.NewPath("spec", "globalLoadBalancer")
+		gslb := &infrav1.GlobalLoadBalancer{DomainName: "cluster1", ServiceDomain: "gslb.example.com"}
+
+		It("Should accept an endpoint host that is empty or the global load balancer's FQDN", func() {
+			Ω(infrav1.ValidateGlobalLoadBalancer(gslb, "cluster1.gslb.example.com", "", path)).Should(BeEmpty())
+			Ω(infrav1.ValidateGlobalLoadBalancer(gslb, "cluster1.gslb.example.com", "cluster1.gslb.example.com", path)).
+				Should(BeEmpty())
+		})
+
+		It("Should reject invalid domain names and another endpoint host", func() {
+			invalid := &infrav1.GlobalLoadBalancer{DomainName: "cluster.1", ServiceDomain: "gslb_example"}
+			Ω(infrav1.ValidateGlobalLoadBalancer(invalid, "cluster.1.gslb_example", "192.0.2.10", path)).Should(HaveLen(3))
+		})
+	})
+
+	Context("When validating a DNS name", func() {
+		path := field.NewPath("spec")
+
+		It("Should accept a DNS name within the RFC2136 provider's zone", func() {
+			spec := infrav1.CloudStackClusterSpec{DNSName: "api.cluster1.example.com",
+				DNSProvider: &infrav1.DNSProvider{RFC2136: &infrav1.RFC2136Provider{
+					Server: "192.0.2.53", Zone: "example.com.", TSIGKeyName: "capc",
+					TSIGSecretRef: &corev1.SecretReference{Name: "capc-tsig"}}}}
+			Ω(infrav1.ValidateDNSName(spec, "default", path)).Should(BeEmpty())
+		})
+
+		It("Should reject a name outside the zone, a TSIG key without secret and another endpoint host", func() {
+			spec := infrav1.CloudStackClusterSpec{DNSName: "api.cluster1.example.org",
+				ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "192.0.2.10"},
+				DNSProvider: &infrav1.DNSProvider{RFC2136: &infrav1.RFC2136Provider{
+					Server: "192.0.2.53", Zone: "example.com", TSIGKeyName: "capc"}}}
+			Ω(infrav1.ValidateDNSName(spec, "default", path)).Should(HaveLen(3))
+		})
+
+		It("Should reject a TSIG secret in another namespace", func() {
+			spec := infrav1.CloudStackClusterSpec{DNSName: "api.cluster1.example.com",
+				DNSProvider: &infrav1.DNSProvider{RFC2136: &infrav1.RFC2136Provider{
+					Server: "192.0.2.53", Zone: "example.com.", TSIGKeyName: "capc",
+					TSIGSecretRef: &corev1.SecretReference{Name: "capc-tsig", Namespace: "other"}}}}
+			Ω(infrav1.ValidateDNSName(spec, "default", path)).Should(ConsistOf(
+				HaveField("Field", "spec.dnsProvider.rfc2136.tsigSecretRef.namespace")))
+			spec.DNSProvider.RFC2136.TSIGSecretRef.Namespace = "default"
+			Ω(infrav1.ValidateDNSName(spec, "default", path)).Should(BeEmpty())
+		})
+
+		It("Should require a DNS provider", func() {
+			Ω(infrav1.ValidateDNSName(infrav1.CloudStackClusterSpec{DNSName: "api.example.com"}, "default", path)).
+				Should(HaveLen(1))
+		})
+	})
+
+	Context("When validating failure domain placement", func() {
+		path := field.NewPath("spec", "failureDomains", "Placement")
+
+		It("Should accept a pod, a cluster and host tags", func() {
+			placement := &infrav1.FailureDomainPlacement{
+				Pod:      &infrav1.CloudStackResourceIdentifier{Name: "pod1"},
+				Cluster:  &infrav1.CloudStackResourceIdentifier{ID: "cluster-id"},
+				HostTags: []string{"k8s"},
+			}
+			Ω(infrav1.ValidateFailureDomainPlacement(placement, path)).Should(BeEmpty())
+		})
+
+		It("Should reject an empty pod and cluster, and empty or combined host tags", func() {
+			placement := &infrav1.FailureDomainPlacement{
+				Pod:      &infrav1.CloudStackResourceIdentifier{},
+				Cluster:  &infrav1.CloudStackResourceIdentifier{},
+				HostTags: []string{"", "k8s,gpu"},
+			}
+			Ω(infrav1.ValidateFailureDomainPlacement(placement, path)).Should(HaveLen(4))
+		})
+	})
+
+	Context("When validating failure domain discovery", func() {
+		path := field.NewPath("spec", "failureDomainDiscovery")
+
+		It("Should accept a discovery with credentials, a zone selector and a network name", func() {
+			discovery := &infrav1.FailureDomainDiscovery{
+				ACSEndpoint: corev1.SecretReference{Name: "secret", Namespace: "default"},
+				Zones:       &infrav1.ZoneSelector{NamePattern: "^site-a-"},
+				Network:     infrav1.Network{Name: "k8s-{zone}"},
+			}
+			Ω(infrav1.ValidateFailureDomainDiscovery(discovery, path)).Should(BeEmpty())
+		})
+
+		It("Should reject missing credentials, an invalid name pattern, a network ID and a zero interval", func() {
+			discovery := &infrav1.FailureDomainDiscovery{
+				Zones:    &infrav1.ZoneSelector{NamePattern: "("},
+				Network:  infrav1.Network{Name: "k8s", ID: "network-id"},
+				Interval: &metav1.Duration{},
+			}
+			Ω(infrav1.ValidateFailureDomainDiscovery(discovery, path)).Should(HaveLen(4))
+		})
+
+		It("Should accept a bastion in a failure domain yet to be discovered", func() {
+			spec := infrav1.CloudStackClusterSpec{
+				FailureDomainDiscovery: &infrav1.FailureDomainDiscovery{
+					ACSEndpoint: corev1.SecretReference{Name: "secret", Namespace: "default"},
+					Network:     infrav1.Network{Name: "k8s"},
+				},
+				Bastion: &infrav1.Bastion{FailureDomainName: "zone1", Offering: infrav1.CloudStackResourceIdentifier{Name: "small"},
+					Template: infrav1.CloudStackResourceIdentifier{ID: "template-id"}, AllowedCIDRs: []string{"192.0.2.0/24"}},
+			}
+			Ω(infrav1.ValidateClusterSpec(spec, "default", field.NewPath("spec"))).Should(BeEmpty())
+		})
+	})
+
+	Context("When validating additional tags", func() {
+		path := field.NewPath("spec", "additionalTags")
+
+		It("Should accept tags of the user's own", func() {
+			Ω(infrav1.ValidateAdditionalTags(map[string]string{"team": "infra", "cost-center": ""}, path)).Should(BeEmpty())
+		})
+
+		It("Should reject empty keys, keys reserved for CAPC and keys with commas", func() {
+			tags := map[string]string{"": "x", "CAPC_cluster_name": "x", "created_by_CAPC": "x", "a,b": "x"}
+			Ω(infrav1.ValidateAdditionalTags(tags, path)).Should(HaveLen(4))
+		})
+	})
+
+	Context("When validating failure domain credentials", func() {
+		path := field.NewPath("spec")
+
+		It("Should accept an identity instead of an ACS endpoint", func() {
+			fd := dummies.CSFailureDomain1.Spec
+			fd.ACSEndpoint = corev1.SecretReference{}
+			fd.IdentityRef = &infrav1.CloudStackIdentityReference{Kind: infrav1.CloudStackClusterIdentityKind, Name: "tenant-a"}
+			Ω(infrav1.ValidateFailureDomains([]infrav1.CloudStackFailureDomainSpec{fd}, path)).Should(BeEmpty())
+		})
+
+		It("Should reject an identity along with an ACS endpoint", func() {
+			fd := dummies.CSFailureDomain1.Spec
+			fd.IdentityRef = &infrav1.CloudStackIdentityReference{Kind: infrav1.CloudStackClusterIdentityKind, Name: "tenant-a"}
+			Ω(infrav1.ValidateFailureDomains([]infrav1.CloudStackFailureDomainSpec{fd}, path)).Should(HaveLen(1))
+		})
+
+		It("Should reject a CloudStackCluster using an identity its namespace isn't allowed to use", func() {
+			identity := &infrav1.CloudStackClusterIdentity{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"},
+				Spec: infrav1.CloudStackClusterIdentitySpec{
+					SecretRef:         dummies.CSFailureDomain1.Spec.ACSEndpoint,
+					AllowedNamespaces: &infrav1.AllowedNamespaces{NamespaceList: []string{"tenant-a"}},
+				},
+			}
+			Ω(k8sClient.Create(ctx, identity)).Should(Succeed())
+			defer func() { Ω(k8sClient.Delete(ctx, identity)).Should(Succeed()) }()
+			dummies.CSCluster.Spec.FailureDomains[0].ACSEndpoint = corev1.SecretReference{}
+			dummies.CSCluster.Spec.FailureDomains[0].IdentityRef = &infrav1.CloudStackIdentityReference{
+				Kind: infrav1.CloudStackClusterIdentityKind, Name: identity.Name}
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "namespace default is not allowed")))
+		})
+
+		It("Should reject a CloudStackCluster referencing the ACS endpoint secret of another namespace", func() {
+			dummies.CSCluster.Spec.FailureDomains[0].ACSEndpoint.Namespace = "capc-system"
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "outside of namespace default")))
+		})
+	})
+
+	Context("When changing the credentials of failure domains", func() {
+		const clusterName = "credentials-cluster"
+		var (
+			oldFDs, newFDs []infrav1.CloudStackFailureDomainSpec
+			csFD           *infrav1.CloudStackFailureDomain
+		)
+		accountIDs := map[string]string{"new-credentials": "account-1", "other-credentials": "account-2"}
+
+		BeforeEach(func() {
+			oldFDs = []infrav1.CloudStackFailureDomainSpec{dummies.CSFailureDomain1.Spec}
+			oldFDs[0].ACSEndpoint.Name = "old-credentials"
+			newFDs = []infrav1.CloudStackFailureDomainSpec{oldFDs[0]}
+			infrav1.FailureDomainAccountResolver = func(_ context.Context, _ string, fd infrav1.CloudStackFailureDomainSpec) (string, error) {
+				if id, ok := accountIDs[fd.ACSEndpoint.Name]; ok {
+					return id, nil
+				}
+				return "", errors.New("secret not found")
+			}
+
+			// The failure domain was reconciled with the old credentials, which are gone since.
+			csFD = &infrav1.CloudStackFailureDomain{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      infrav1.FailureDomainHashedMetaName(oldFDs[0].Name, clusterName),
+					Namespace: "default",
+				},
+				Spec: oldFDs[0],
+			}
+			Ω(k8sClient.Create(ctx, csFD)).Should(Succeed())
+			csFD.Status.AccountID = "account-1"
+			Ω(k8sClient.Status().Update(ctx, csFD)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			infrav1.FailureDomainAccountResolver = nil
+			Ω(k8sClient.Delete(ctx, csFD)).Should(Succeed())
+		})
+
+		It("Should accept credentials of the recorded account", func() {
+			newFDs[0].ACSEndpoint.Name = "new-credentials"
+			Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", clusterName, oldFDs, newFDs)).Should(BeNil())
+		})
+
+		It("Should reject credentials of another account", func() {
+			newFDs[0].ACSEndpoint.Name = "other-credentials"
+			Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", clusterName, oldFDs, newFDs).Detail).
+				Should(ContainSubstring("another account"))
+		})
+
+		It("Should accept credentials of any account for a failure domain that wasn't reconciled yet", func() {
+			newFDs[0].ACSEndpoint.Name = "other-credentials"
+			Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", "other-cluster", oldFDs, newFDs)).Should(BeNil())
+		})
+
+		It("Should reject credentials that don't resolve", func() {
+			newFDs[0].ACSEndpoint.Name = "missing-credentials"
+			Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", clusterName, oldFDs, newFDs).Detail).
+				Should(ContainSubstring("secret not found"))
+		})
+
+		It("Should reject credential changes that can't be verified", func() {
+			infrav1.FailureDomainAccountResolver = nil
+			newFDs[0].ACSEndpoint.Name = "new-credentials"
+			Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", clusterName, oldFDs, newFDs)).ShouldNot(BeNil())
+		})
+
+		It("Should reject credential changes along with other changes", func() {
+			newFDs[0].ACSEndpoint.Name = "new-credentials"
+			newFDs[0].Zone.Network.Name = "OtherNetwork"
+			Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", clusterName, oldFDs, newFDs).Detail).
+				Should(ContainSubstring("Cannot change FailureDomain"))
+		})
+	})
+
+	Context("When changing the network of failure domains", func() {
+		var oldFDs, newFDs []infrav1.CloudStackFailureDomainSpec
+
+		BeforeEach(func() {
+			oldFDs = []infrav1.CloudStackFailureDomainSpec{*dummies.CSFailureDomain1.Spec.DeepCopy()}
+			oldFDs[0].Zone.Network.Tags = map[string]string{"team": "a"}
+			newFDs = []infrav1.CloudStackFailureDomainSpec{*oldFDs[0].DeepCopy()}
+		})
+
+		It("Should accept an unchanged network", func() {
+			Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", "cluster", oldFDs, newFDs)).Should(BeNil())
+		})
+
+		DescribeTable("Should reject changes to the network CAPC creates",
+			func(change func(*infrav1.Network)) {
+				change(&newFDs[0].Zone.Network)
+				Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", "cluster", oldFDs, newFDs).Detail).
+					Should(ContainSubstring("Cannot change FailureDomain"))
+			},
+			Entry("offering", func(n *infrav1.Network) { n.Offering = "OtherOffering" }),
+			Entry("CIDR", func(n *infrav1.Network) { n.CIDR = "10.1.0.0/24" }),
+			Entry("gateway", func(n *infrav1.Network) { n.Gateway = "10.1.0.1" }),
+			Entry("netmask", func(n *infrav1.Network) { n.Netmask = "255.255.0.0" }),
+			Entry("network domain", func(n *infrav1.Network) { n.NetworkDomain = "other.local" }),
+			Entry("DNS", func(n *infrav1.Network) { n.DNS = []string{"10.1.0.2"} }),
+			Entry("IPv6 DNS", func(n *infrav1.Network) { n.IPv6DNS = []string{"fd00::2"} }),
+			Entry("tags", func(n *infrav1.Network) { n.Tags = map[string]string{"team": "b"} }),
+			Entry("VPC", func(n *infrav1.Network) { n.VPC = &infrav1.VPC{Name: "OtherVPC"} }),
+		)
+
+		It("Should reject changing the project", func() {
+			newFDs[0].Project = "OtherProject"
+			Ω(infrav1.ValidateFailureDomainUpdates(ctx, "default", "cluster", oldFDs, newFDs).Detail).
+				Should(ContainSubstring("Cannot change FailureDomain"))
+		})
+	})
+
 	Context("When updating a CloudStackCluster", func() {
 		BeforeEach(func() {
 			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(Succeed())
@@ -80,5 +494,63 @@ var _ = Describe("CloudStackCluster webhooks", func() {
 			Ω(k8sClient.Update(ctx, dummies.CSCluster)).
 				Should(MatchError(MatchRegexp(forbiddenRegex, "controlplaneendpoint\\.port")))
 		})
+
+		It("Should refuse removing a failure domain machines are placed in unless annotated", func() {
+			Ω(k8sClient.Create(ctx, dummies.CSMachine1)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, dummies.CSMachine1) }()
+
+			dummies.CSCluster.Spec.FailureDomains = []infrav1.CloudStackFailureDomainSpec{dummies.CSFailureDomain2.Spec}
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "removing failure domains .* would delete machines test-machine-1")))
+
+			dummies.CSCluster.Annotations = map[string]string{infrav1.AllowFailureDomainRemovalAnnotation: ""}
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+		})
+
+		It("Should refuse removing a failure domain if the machines can't be listed", func() {
+			oldCluster := dummies.CSCluster.DeepCopy()
+			dummies.CSCluster.Spec.FailureDomains = []infrav1.CloudStackFailureDomainSpec{dummies.CSFailureDomain1.Spec}
+			defer infrav1.SetAPIReader(nil)()
+			Ω(dummies.CSCluster.ValidateUpdate(oldCluster)).
+				Should(MatchError(ContainSubstring("listing the machines of CloudStackCluster")))
+		})
+
+		It("Should accept removing a failure domain no machines are placed in", func() {
+			dummies.CSCluster.Spec.FailureDomains = []infrav1.CloudStackFailureDomainSpec{dummies.CSFailureDomain1.Spec}
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
+	Context("When deleting a CloudStackCluster", func() {
+		It("Should refuse deleting a CloudStackCluster with deletion protection", func() {
+			dummies.CSCluster.Spec.DeletionProtection = true
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(Succeed())
+			Ω(k8sClient.Delete(ctx, dummies.CSCluster)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "deletion protection is enabled")))
+
+			dummies.CSCluster.Spec.DeletionProtection = false
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+			Ω(k8sClient.Delete(ctx, dummies.CSCluster)).Should(Succeed())
+		})
+
+		It("Should refuse deleting a failure domain the cluster uses while it has deletion protection", func() {
+			dummies.CSCluster.Spec.DeletionProtection = true
+			Ω(k8sClient.Create(ctx, dummies.CSCluster)).Should(Succeed())
+			dummies.CSFailureDomain1.Spec.Name = dummies.CSCluster.Spec.FailureDomains[0].Name
+			dummies.CSFailureDomain1.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: infrav1.GroupVersion.String(),
+				Kind:       "CloudStackCluster",
+				Name:       dummies.CSCluster.Name,
+				UID:        dummies.CSCluster.UID,
+			}}
+			Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+			Ω(k8sClient.Delete(ctx, dummies.CSFailureDomain1)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "deletion protection of CloudStackCluster")))
+
+			dummies.CSCluster.Spec.DeletionProtection = false
+			Ω(k8sClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+			Ω(k8sClient.Delete(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+			Ω(k8sClient.Delete(ctx, dummies.CSCluster)).Should(Succeed())
+		})
 	})
 })
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// CloudStackClusterIdentityKind is the kind of identities failure domains reference.
+const CloudStackClusterIdentityKind = "CloudStackClusterIdentity"
+
+// CloudStackClusterIdentitySpec defines the desired state of CloudStackClusterIdentity
+type CloudStackClusterIdentitySpec struct {
+	// Apache CloudStack Endpoint secret reference, holding the credentials of the identity in the format of a
+	// failure domain's ACS endpoint secret.
+	SecretRef corev1.SecretReference `json:"secretRef"`
+
+	// AllowedNamespaces selects the namespaces of the clusters allowed to use the identity.
+	// No namespace is allowed when nil, and all namespaces are when empty.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// AllowedNamespaces selects namespaces by name or by label. A namespace is selected when either selects it.
+type AllowedNamespaces struct {
+	// NamespaceList selects namespaces by name.
+	// +optional
+	NamespaceList []string `json:"list,omitempty"`
+
+	// Selector selects namespaces by label.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// CloudStackIdentityReference references the identity whose credentials a failure domain uses.
+type CloudStackIdentityReference struct {
+	// Kind of the identity.
+	// +kubebuilder:validation:Enum=CloudStackClusterIdentity
+	Kind string `json:"kind"`
+
+	// Name of the identity.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=cloudstackclusteridentities,scope=Cluster,categories=cluster-api
+//+kubebuilder:storageversion
+
+// CloudStackClusterIdentity is the Schema for the cloudstackclusteridentities API
+type CloudStackClusterIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CloudStackClusterIdentitySpec `json:"spec,omitempty"`
+}
+
+// AllowsNamespace reports whether clusters in the namespace may use the identity.
+func (i *CloudStackClusterIdentity) AllowsNamespace(namespace *corev1.Namespace) (bool, error) {
+	allowed := i.Spec.AllowedNamespaces
+	if allowed == nil {
+		return false, nil
+	} else if len(allowed.NamespaceList) == 0 && allowed.Selector == nil {
+		return true, nil
+	}
+	for _, name := range allowed.NamespaceList {
+		if name == namespace.Name {
+			return true, nil
+		}
+	}
+	if allowed.Selector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(allowed.Selector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(namespace.GetLabels())), nil
+}
+
+//+kubebuilder:object:root=true
+
+// CloudStackClusterIdentityList contains a list of CloudStackClusterIdentity
+type CloudStackClusterIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CloudStackClusterIdentity `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CloudStackClusterIdentity{}, &CloudStackClusterIdentityList{})
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+var _ = Describe("CloudStackClusterIdentity", func() {
+	var (
+		identity  *infrav1.CloudStackClusterIdentity
+		namespace *corev1.Namespace
+	)
+
+	BeforeEach(func() {
+		identity = &infrav1.CloudStackClusterIdentity{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"},
+			Spec: infrav1.CloudStackClusterIdentitySpec{
+				SecretRef: corev1.SecretReference{Name: "tenant-a-credentials", Namespace: "capc-system"},
+			},
+		}
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant": "a"}}}
+	})
+
+	Context("When selecting allowed namespaces", func() {
+		It("Should allow no namespace without allowed namespaces", func() {
+			Ω(identity.AllowsNamespace(namespace)).Should(BeFalse())
+		})
+
+		It("Should allow all namespaces with empty allowed namespaces", func() {
+			identity.Spec.AllowedNamespaces = &infrav1.AllowedNamespaces{}
+			Ω(identity.AllowsNamespace(namespace)).Should(BeTrue())
+		})
+
+		It("Should allow listed namespaces", func() {
+			identity.Spec.AllowedNamespaces = &infrav1.AllowedNamespaces{NamespaceList: []string{"tenant-b", "tenant-a"}}
+			Ω(identity.AllowsNamespace(namespace)).Should(BeTrue())
+			identity.Spec.AllowedNamespaces.NamespaceList = []string{"tenant-b"}
+			Ω(identity.AllowsNamespace(namespace)).Should(BeFalse())
+		})
+
+		It("Should allow namespaces matching the selector", func() {
+			identity.Spec.AllowedNamespaces = &infrav1.AllowedNamespaces{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}}}
+			Ω(identity.AllowsNamespace(namespace)).Should(BeTrue())
+			identity.Spec.AllowedNamespaces.Selector.MatchLabels["tenant"] = "b"
+			Ω(identity.AllowsNamespace(namespace)).Should(BeFalse())
+		})
+	})
+})
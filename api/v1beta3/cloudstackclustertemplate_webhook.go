@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/webhookutil"
+	"sigs.k8s.io/cluster-api/util/topology"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var cloudstackclustertemplatelog = logf.Log.WithName("cloudstackclustertemplate-resource")
+
+func (r *CloudStackClusterTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&cloudStackClusterTemplateValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackclustertemplate,mutating=true,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackclustertemplates,verbs=create;update,versions=v1beta3,name=mcloudstackclustertemplate.kb.io,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &CloudStackClusterTemplate{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *CloudStackClusterTemplate) Default() {
+	cloudstackclustertemplatelog.V(1).Info("entered default setting webhook", "api resource name", r.Name)
+	// No defaulted values supported yet.
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackclustertemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackclustertemplates,verbs=create;update,versions=v1beta3,name=vcloudstackclustertemplate.kb.io,admissionReviewVersions=v1;v1beta1
+
+// cloudStackClusterTemplateValidator validates CloudStackClusterTemplates with access to the admission request, so
+// that dry-run updates of the ClusterClass topology controller aren't rejected for changing the immutable template.
+type cloudStackClusterTemplateValidator struct{}
+
+var _ webhook.CustomValidator = &cloudStackClusterTemplateValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *cloudStackClusterTemplateValidator) ValidateCreate(_ context.Context, obj runtime.Object) error {
+	r, ok := obj.(*CloudStackClusterTemplate)
+	if !ok {
+		return errors.NewBadRequest(fmt.Sprintf("expected a CloudStackClusterTemplate but got a %T", obj))
+	}
+	cloudstackclustertemplatelog.V(1).Info("entered validate create webhook", "api resource name", r.Name)
+
+	path := field.NewPath("spec", "template", "spec")
+	var errorList field.ErrorList
+	if spec := r.Spec.Template.Spec; len(spec.FailureDomains) > 0 || spec.FailureDomainDiscovery == nil {
+		errorList = ValidateFailureDomains(spec.FailureDomains, path)
+	}
+	errorList = append(errorList, ValidateFailureDomainDiscovery(
+		r.Spec.Template.Spec.FailureDomainDiscovery, path.Child("failureDomainDiscovery"))...)
+	errorList = append(errorList, ValidateClusterSpec(r.Spec.Template.Spec, r.Namespace, path)...)
+	if r.Spec.Template.Spec.ControlPlaneEndpoint.Host != "" {
+		errorList = append(errorList, field.Forbidden(path.Child("controlPlaneEndpoint", "host"),
+			"the control plane endpoint of a cluster template is set per cluster"))
+	}
+
+	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *cloudStackClusterTemplateValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	r, ok := newObj.(*CloudStackClusterTemplate)
+	if !ok {
+		return errors.NewBadRequest(fmt.Sprintf("expected a CloudStackClusterTemplate but got a %T", newObj))
+	}
+	cloudstackclustertemplatelog.V(1).Info("entered validate update webhook", "api resource name", r.Name)
+	oldTemplate, ok := oldObj.(*CloudStackClusterTemplate)
+	if !ok {
+		return errors.NewBadRequest(fmt.Sprintf("expected a CloudStackClusterTemplate but got a %T", oldObj))
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return errors.NewBadRequest(err.Error())
+	}
+
+	// Templates are immutable: clusters are rebased onto a new template rather than the template changed under them.
+	var errorList field.ErrorList
+	if !topology.ShouldSkipImmutabilityChecks(req, r) &&
+		!reflect.DeepEqual(r.Spec.Template.Spec, oldTemplate.Spec.Template.Spec) {
+		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "template", "spec"),
+			"CloudStackClusterTemplate spec.template.spec is immutable, create a new template instead"))
+	}
+
+	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *cloudStackClusterTemplateValidator) ValidateDelete(_ context.Context, obj runtime.Object) error {
+	cloudstackclustertemplatelog.V(1).Info("entered validate delete webhook")
+	// No deletion validations.  Deletion webhook not enabled.
+	return nil
+}
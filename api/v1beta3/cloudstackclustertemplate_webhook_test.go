@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3_test
+
+import (
+	"context"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CloudStackClusterTemplate webhook", func() {
+	var ctx context.Context
+	forbiddenRegex := "admission webhook.*denied the request.*Forbidden\\: %s"
+	requiredRegex := "admission webhook.*denied the request.*Required value\\: %s"
+
+	BeforeEach(func() { // Reset test vars to initial state.
+		dummies.SetDummyVars()
+		ctx = context.Background()
+		_ = k8sClient.Delete(ctx, dummies.CSClusterTemplate) // Delete any remnants.
+	})
+
+	Context("When creating a CloudStackClusterTemplate", func() {
+		It("Should accept a CloudStackClusterTemplate with all attributes present", func() {
+			Ω(k8sClient.Create(ctx, dummies.CSClusterTemplate)).Should(Succeed())
+		})
+
+		It("Should reject a CloudStackClusterTemplate without failure domains", func() {
+			dummies.CSClusterTemplate.Spec.Template.Spec.FailureDomains = nil
+			Ω(k8sClient.Create(ctx, dummies.CSClusterTemplate)).
+				Should(MatchError(MatchRegexp(requiredRegex, "FailureDomains")))
+		})
+
+		It("Should reject a CloudStackClusterTemplate with a control plane endpoint host", func() {
+			dummies.CSClusterTemplate.Spec.Template.Spec.ControlPlaneEndpoint.Host = "10.0.0.10"
+			Ω(k8sClient.Create(ctx, dummies.CSClusterTemplate)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "the control plane endpoint")))
+		})
+
+		It("Should reject a CloudStackClusterTemplate with an invalid firewall policy", func() {
+			dummies.CSClusterTemplate.Spec.Template.Spec.FirewallPolicy = &infrav1.FirewallPolicy{
+				Ingress: []infrav1.IngressFirewallRule{{SourceCIDRs: []string{"192.0.2.0"}}}}
+			Ω(k8sClient.Create(ctx, dummies.CSClusterTemplate)).ShouldNot(Succeed())
+		})
+	})
+
+	Context("When updating a CloudStackClusterTemplate", func() {
+		BeforeEach(func() {
+			Ω(k8sClient.Create(ctx, dummies.CSClusterTemplate)).Should(Succeed())
+		})
+
+		It("Should reject updates to the template spec", func() {
+			dummies.CSClusterTemplate.Spec.Template.Spec.FailureDomains[0].Zone.Network.Name = "OtherNetwork"
+			Ω(k8sClient.Update(ctx, dummies.CSClusterTemplate)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "CloudStackClusterTemplate spec.template.spec is immutable")))
+		})
+
+		It("Should accept updates to the template spec in topology dry runs", func() {
+			dummies.CSClusterTemplate.Annotations = map[string]string{clusterv1.TopologyDryRunAnnotation: ""}
+			dummies.CSClusterTemplate.Spec.Template.Spec.FailureDomains[0].Zone.Network.Name = "OtherNetwork"
+			Ω(k8sClient.Update(ctx, dummies.CSClusterTemplate, client.DryRunAll)).Should(Succeed())
+		})
+	})
+})
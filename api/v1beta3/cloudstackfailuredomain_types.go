@@ -53,6 +53,132 @@ type Network struct {
 
 	// Cloudstack Network Name the cluster is built in.
 	Name string `json:"name"`
+
+	// CloudStack tags the network must have, in addition to the name, to be used. Networks are looked up in the
+	// failure domain's zone, so identically named networks in other zones don't conflict. CAPC tags networks it
+	// creates with these tags.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Name of the network offering used when CAPC creates the network.
+	// +optional
+	Offering string `json:"offering,omitempty"`
+
+	// CIDR of the network CAPC creates. Gateway and netmask are derived from it when not set.
+	// +optional
+	CIDR string `json:"cidr,omitempty"`
+
+	// Gateway of the network CAPC creates. Required when CAPC creates the network as a VPC tier without a CIDR.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// Netmask of the network CAPC creates. Required when CAPC creates the network as a VPC tier without a CIDR.
+	// +optional
+	Netmask string `json:"netmask,omitempty"`
+
+	// Network domain of the network CAPC creates.
+	// +optional
+	NetworkDomain string `json:"networkDomain,omitempty"`
+
+	// DNS servers of the network CAPC creates.
+	// +kubebuilder:validation:MaxItems=2
+	// +optional
+	DNS []string `json:"dns,omitempty"`
+
+	// IPv6 DNS servers of the network CAPC creates. Only used with an IPv6 enabled network offering.
+	// +kubebuilder:validation:MaxItems=2
+	// +optional
+	IPv6DNS []string `json:"ipv6DNS,omitempty"`
+
+	// The VPC the network is a tier of. When set, CAPC gets or creates the VPC and the network as one of its tiers.
+	// +optional
+	VPC *VPC `json:"vpc,omitempty"`
+}
+
+// VPC specifies a CloudStack VPC and the cluster resources placed in it.
+type VPC struct {
+	// CloudStack VPC ID.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// CloudStack VPC name. An existing VPC with this name is adopted, otherwise one is created.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// CIDR of the VPC's super network. Required when CAPC creates the VPC.
+	// +optional
+	CIDR string `json:"cidr,omitempty"`
+
+	// Name of the VPC offering used when CAPC creates the VPC.
+	// +optional
+	Offering string `json:"offering,omitempty"`
+
+	// The tier worker machines are placed in. Workers share the control plane's tier when not set.
+	// +optional
+	WorkerTier *VPCTier `json:"workerTier,omitempty"`
+
+	// Network ACL rules applied to the tiers CAPC creates. The tiers use the VPC's default_allow ACL list when empty.
+	// +optional
+	ACLRules []NetworkACLRule `json:"aclRules,omitempty"`
+
+	// Expose the control plane endpoint with a VPC internal load balancer instead of a public load balancer.
+	// +optional
+	InternalLoadBalancer bool `json:"internalLoadBalancer,omitempty"`
+}
+
+// VPCTier specifies a tier network of a VPC.
+type VPCTier struct {
+	// CloudStack network ID of the tier.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// CloudStack network name of the tier.
+	Name string `json:"name"`
+
+	// Gateway of the tier. Required when CAPC creates the tier.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// Netmask of the tier. Required when CAPC creates the tier.
+	// +optional
+	Netmask string `json:"netmask,omitempty"`
+
+	// Name of the network offering used when CAPC creates the tier.
+	// +optional
+	Offering string `json:"offering,omitempty"`
+}
+
+// NetworkACLRule specifies a rule of a VPC network ACL list.
+type NetworkACLRule struct {
+	// Position of the rule in the ACL list. Rules are evaluated in ascending order.
+	// +kubebuilder:validation:Minimum=1
+	Number int `json:"number"`
+
+	// Protocol the rule applies to: tcp, udp, icmp or all.
+	// +kubebuilder:validation:Enum=tcp;udp;icmp;all
+	Protocol string `json:"protocol"`
+
+	// First port of the range the rule applies to. Ignored for icmp and all.
+	// +optional
+	StartPort int `json:"startPort,omitempty"`
+
+	// Last port of the range the rule applies to. Defaults to StartPort.
+	// +optional
+	EndPort int `json:"endPort,omitempty"`
+
+	// CIDRs the rule applies to. Defaults to 0.0.0.0/0.
+	// +optional
+	CIDRList []string `json:"cidrList,omitempty"`
+
+	// Whether matching traffic is allowed or denied.
+	// +kubebuilder:validation:Enum=Allow;Deny
+	// +optional
+	Action string `json:"action,omitempty"`
+
+	// Direction of the traffic the rule applies to.
+	// +kubebuilder:validation:Enum=Ingress;Egress
+	// +optional
+	TrafficType string `json:"trafficType,omitempty"`
 }
 
 // CloudStackZoneSpec specifies a Zone's details.
@@ -69,6 +195,23 @@ type CloudStackZoneSpec struct {
 	Network Network `json:"network"`
 }
 
+// FailureDomainPlacement pins the machines of a failure domain to a pod, a cluster or a set of hosts within its zone,
+// so failure domains can map onto hardware boundaries smaller than a zone.
+type FailureDomainPlacement struct {
+	// The CloudStack pod machines are deployed in.
+	// +optional
+	Pod *CloudStackResourceIdentifier `json:"pod,omitempty"`
+
+	// The CloudStack cluster of hypervisor hosts machines are deployed in. It must be in the pod, if one is given.
+	// +optional
+	Cluster *CloudStackResourceIdentifier `json:"cluster,omitempty"`
+
+	// Host tags the host a machine is deployed on must have. Machines are deployed on the enabled host with these tags,
+	// within the pod and cluster if given, with the most unallocated memory.
+	// +optional
+	HostTags []string `json:"hostTags,omitempty"`
+}
+
 // CloudStackFailureDomainSpec defines the desired state of CloudStackFailureDomain
 type CloudStackFailureDomainSpec struct {
 	// The failure domain unique name.
@@ -89,14 +232,30 @@ type CloudStackFailureDomainSpec struct {
 	// +optional
 	Project string `json:"project,omitempty"`
 
-	// Apache CloudStack Endpoint secret reference.
-	ACSEndpoint corev1.SecretReference `json:"acsEndpoint"`
+	// Apache CloudStack Endpoint secret reference. Required unless the failure domain uses an identity.
+	// +optional
+	ACSEndpoint corev1.SecretReference `json:"acsEndpoint,omitempty"`
+
+	// IdentityRef references the identity whose credentials the failure domain uses instead of an ACS endpoint
+	// secret, if the cluster's namespace is allowed to use it.
+	// +optional
+	IdentityRef *CloudStackIdentityReference `json:"identityRef,omitempty"`
+
+	// Placement pins the failure domain's machines to part of its zone. Machines may be deployed anywhere in the zone
+	// when not set. Requires the failure domain's credentials to be those of a root admin.
+	// +optional
+	Placement *FailureDomainPlacement `json:"placement,omitempty"`
 }
 
 // CloudStackFailureDomainStatus defines the observed state of CloudStackFailureDomain
 type CloudStackFailureDomainStatus struct {
 	// Reflects the readiness of the CloudStack Failure Domain.
 	Ready bool `json:"ready"`
+
+	// The ID of the CloudStack account the failure domain's resources are created in, recorded when its credentials
+	// are first used. New credentials of the failure domain must act as this account.
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/webhookutil"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var cloudstackfailuredomainlog = logf.Log.WithName("cloudstackfailuredomain-resource")
+
+func (r *CloudStackFailureDomain) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	apiReader = mgr.GetAPIReader()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&cloudStackFailureDomainValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackfailuredomain,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackfailuredomains,verbs=delete,versions=v1beta3,name=vcloudstackfailuredomain.kb.io,admissionReviewVersions=v1;v1beta1
+
+// cloudStackFailureDomainValidator refuses deleting CloudStackFailureDomains their cluster still uses while it has
+// deletion protection.
+type cloudStackFailureDomainValidator struct{}
+
+var _ webhook.CustomValidator = &cloudStackFailureDomainValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *cloudStackFailureDomainValidator) ValidateCreate(_ context.Context, _ runtime.Object) error {
+	// No create validations. Create webhook not enabled.
+	return nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *cloudStackFailureDomainValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) error {
+	// No update validations. Update webhook not enabled.
+	return nil
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
+func (v *cloudStackFailureDomainValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	r, ok := obj.(*CloudStackFailureDomain)
+	if !ok {
+		return errors.NewBadRequest(fmt.Sprintf("expected a CloudStackFailureDomain but got a %T", obj))
+	}
+	cloudstackfailuredomainlog.V(1).Info("entered validate delete webhook", "api resource name", r.Name)
+
+	csCluster := r.getCloudStackCluster(ctx)
+	if csCluster == nil || !csCluster.Spec.DeletionProtection || !csCluster.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	// Failure domains removed from the cluster are deleted by CAPC, guarded by the cluster's own validations.
+	inUse := false
+	for _, fdSpec := range csCluster.FailureDomainSpecs() {
+		inUse = inUse || fdSpec.Name == r.Spec.Name
+	}
+	if !inUse {
+		return nil
+	}
+
+	destroyed := "its machines"
+	if machines, err := csCluster.listMachines(ctx); err == nil {
+		var names []string
+		for _, machine := range machines {
+			if machine.Spec.FailureDomainName == r.Spec.Name {
+				names = append(names, machine.Name)
+			}
+		}
+		destroyed = "no machines"
+		if len(names) > 0 {
+			destroyed = fmt.Sprintf("machines %s", strings.Join(names, ", "))
+		}
+	}
+	msg := fmt.Sprintf("deletion protection of CloudStackCluster %s is enabled, deleting failure domain %s would "+
+		"delete %s. Remove it from the cluster's failureDomains instead", csCluster.Name, r.Spec.Name, destroyed)
+	recordRefusal(ctx, csCluster, "FailureDomainDeletionRefused", msg)
+	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, field.ErrorList{
+		field.Forbidden(field.NewPath("metadata", "name"), msg)})
+}
+
+// getCloudStackCluster fetches the CloudStackCluster owning the failure domain. Returns nil if it can't be read.
+func (r *CloudStackFailureDomain) getCloudStackCluster(ctx context.Context) *CloudStackCluster {
+	if apiReader == nil {
+		return nil
+	}
+	for _, ref := range r.OwnerReferences {
+		if ref.Kind != "CloudStackCluster" {
+			continue
+		}
+		csCluster := &CloudStackCluster{}
+		if err := apiReader.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: ref.Name}, csCluster); err != nil {
+			cloudstackfailuredomainlog.V(1).Info("skipping deletion protection", "reason", err.Error())
+			return nil
+		}
+		return csCluster
+	}
+	return nil
+}
@@ -24,6 +24,30 @@ import (
 // The presence of a finalizer prevents CAPI from deleting the corresponding CAPI data.
 const IsolatedNetworkFinalizer = "cloudstackisolatednetwork.infrastructure.cluster.x-k8s.io"
 
+const (
+	// VirtualRouterReadyCondition reports whether the virtual routers of the network, or of its VPC, are up.
+	VirtualRouterReadyCondition clusterv1.ConditionType = "VirtualRouterReady"
+
+	// NoVirtualRouterReason is used while CloudStack hasn't deployed a virtual router yet, e.g. before the first VM
+	// is placed in the network.
+	NoVirtualRouterReason = "NoVirtualRouter"
+	// VirtualRouterNotRunningReason is used when none of the virtual routers is running.
+	VirtualRouterNotRunningReason = "VirtualRouterNotRunning"
+	// NoPrimaryVirtualRouterReason is used when none of the running redundant virtual routers is primary.
+	NoPrimaryVirtualRouterReason = "NoPrimaryVirtualRouter"
+	// VirtualRouterHealthChecksFailedReason is used when a running virtual router fails its CloudStack health checks.
+	VirtualRouterHealthChecksFailedReason = "VirtualRouterHealthChecksFailed"
+	// VirtualRouterCheckFailedReason is used when the virtual routers can't be listed.
+	VirtualRouterCheckFailedReason = "VirtualRouterCheckFailed"
+
+	// NetworkAddressingValidCondition reports whether the addressing of the network is free of overlaps with the pod
+	// and service CIDRs of the cluster.
+	NetworkAddressingValidCondition clusterv1.ConditionType = "NetworkAddressingValid"
+
+	// ClusterNetworkOverlapReason is used when the network overlaps with the pod or service CIDRs of the cluster.
+	ClusterNetworkOverlapReason = "ClusterNetworkOverlap"
+)
+
 // CloudStackIsolatedNetworkSpec defines the desired state of CloudStackIsolatedNetwork
 type CloudStackIsolatedNetworkSpec struct {
 	// Name.
@@ -34,11 +58,46 @@ type CloudStackIsolatedNetworkSpec struct {
 	//+optional
 	ID string `json:"id,omitempty"`
 
+	// CloudStack tags the network must have, in addition to the name, to be used.
+	//+optional
+	Tags map[string]string `json:"tags,omitempty"`
+
 	// The kubernetes control plane endpoint.
 	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint"`
 
 	// FailureDomainName -- the FailureDomain the network is placed in.
 	FailureDomainName string `json:"failureDomainName"`
+
+	// Name of the network offering used when creating the network.
+	// Defaults to DefaultIsolatedNetworkOfferingWithSourceNatService.
+	//+optional
+	Offering string `json:"offering,omitempty"`
+
+	// CIDR of the network. Gateway and netmask are derived from it when not set.
+	//+optional
+	CIDR string `json:"cidr,omitempty"`
+
+	// Gateway of the network.
+	//+optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// Netmask of the network.
+	//+optional
+	Netmask string `json:"netmask,omitempty"`
+
+	// Network domain of the network.
+	//+optional
+	NetworkDomain string `json:"networkDomain,omitempty"`
+
+	// DNS servers of the network.
+	//+kubebuilder:validation:MaxItems=2
+	//+optional
+	DNS []string `json:"dns,omitempty"`
+
+	// IPv6 DNS servers of the network.
+	//+kubebuilder:validation:MaxItems=2
+	//+optional
+	IPv6DNS []string `json:"ipv6DNS,omitempty"`
 }
 
 // CloudStackIsolatedNetworkStatus defines the observed state of CloudStackIsolatedNetwork
@@ -49,15 +108,103 @@ type CloudStackIsolatedNetworkStatus struct {
 	// The ID of the lb rule used to assign VMs to the lb.
 	LBRuleID string `json:"loadBalancerRuleID,omitempty"`
 
+	// The IPv6 CIDR of the network. Only set for dual-stack networks.
+	// +optional
+	IPv6CIDR string `json:"ipv6CIDR,omitempty"`
+
+	// The ID of the VPC the network is a tier of.
+	// +optional
+	VPCID string `json:"vpcID,omitempty"`
+
+	// The ID of the VPC tier worker machines are placed in.
+	// +optional
+	WorkerTierID string `json:"workerTierID,omitempty"`
+
+	// The ID of the network ACL list CAPC manages for the VPC tiers.
+	// +optional
+	ACLListID string `json:"aclListID,omitempty"`
+
+	// The workload load balancer rules CAPC created for the cluster's LoadBalancerRules.
+	// +optional
+	WorkloadLBRules []WorkloadLBRuleStatus `json:"workloadLoadBalancerRules,omitempty"`
+
+	// The virtual routers of the network, or of its VPC, as of the last check.
+	// +optional
+	VirtualRouters []VirtualRouterStatus `json:"virtualRouters,omitempty"`
+
+	// Time CAPC last restarted the network to recover its virtual routers.
+	// +optional
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
+
+	// The hash of the tags CAPC last brought the network, VPC, public IPs and load balancer rules in line with. Their
+	// tags are only reconciled again once the desired tags or the resources change.
+	// +optional
+	TagsHash string `json:"tagsHash,omitempty"`
+
+	// Conditions of the network.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
 	// Ready indicates the readiness of this provider resource.
 	Ready bool `json:"ready"`
 }
 
+// VirtualRouterStatus describes a virtual router of the network.
+type VirtualRouterStatus struct {
+	// The ID of the router.
+	ID string `json:"id"`
+
+	// Name of the router.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// State of the router VM, e.g. Running or Stopped.
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// Whether the router is one of a redundant pair.
+	// +optional
+	Redundant bool `json:"redundant,omitempty"`
+
+	// Redundancy state of a redundant router, e.g. PRIMARY or BACKUP.
+	// +optional
+	RedundantState string `json:"redundantState,omitempty"`
+
+	// Whether the router failed CloudStack's health checks.
+	// +optional
+	HealthChecksFailed bool `json:"healthChecksFailed,omitempty"`
+}
+
+// WorkloadLBRuleStatus records a workload load balancer rule CAPC created.
+type WorkloadLBRuleStatus struct {
+	// Name of the rule in the CloudStackCluster's LoadBalancerRules.
+	Name string `json:"name"`
+
+	// The ID of the CloudStack load balancer rule.
+	ID string `json:"id"`
+
+	// The ID of the public IP the rule is on.
+	PublicIPID string `json:"publicIPID"`
+
+	// Whether the public IP is dedicated to the rule and was associated by CAPC.
+	// +optional
+	DedicatedPublicIP bool `json:"dedicatedPublicIP,omitempty"`
+}
+
+func (n *CloudStackIsolatedNetwork) GetConditions() clusterv1.Conditions {
+	return n.Status.Conditions
+}
+
+func (n *CloudStackIsolatedNetwork) SetConditions(conditions clusterv1.Conditions) {
+	n.Status.Conditions = conditions
+}
+
 func (n *CloudStackIsolatedNetwork) Network() *Network {
 	return &Network{
 		Name: n.Spec.Name,
 		Type: "IsolatedNetwork",
-		ID:   n.Spec.ID}
+		ID:   n.Spec.ID,
+		Tags: n.Spec.Tags}
 }
 
 //+kubebuilder:object:root=true
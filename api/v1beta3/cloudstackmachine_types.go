@@ -89,6 +89,38 @@ type CloudStackMachineSpec struct {
 	//
 	// +optional
 	UncompressedUserData *bool `json:"uncompressedUserData,omitempty"`
+
+	// Tags CAPC adds to the machine's VM and volumes, on top of the cluster's additional tags, which they override.
+	// Changes are applied to the existing VM and volumes. Keys may not start with CAPC_ nor be created_by_CAPC.
+	// +optional
+	AdditionalTags map[string]string `json:"additionalTags,omitempty"`
+
+	// Host selects the host the machine is deployed on. Requires the failure domain's credentials to be those of a
+	// root admin.
+	// +optional
+	Host *HostSelector `json:"host,omitempty"`
+
+	// The ExplicitDedication affinity group of resources dedicated to the account or its domain. The machine is
+	// deployed on those resources only, and a host it selects must be one of them.
+	// +optional
+	ExplicitDedicationAffinityGroup *CloudStackResourceIdentifier `json:"explicitDedicationAffinityGroup,omitempty"`
+
+	// The deployment planner CloudStack places the machine with, e.g. ImplicitDedicationPlanner. Requires the failure
+	// domain's credentials to be those of a root admin.
+	// +optional
+	DeploymentPlanner string `json:"deploymentPlanner,omitempty"`
+}
+
+// HostSelector selects a host by ID, or by host tags. Of the enabled hosts with the host tags, the one with the most
+// unallocated memory is selected.
+type HostSelector struct {
+	// CloudStack host ID.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// Host tags the host must have, in addition to those of the failure domain's placement.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
 }
 
 func (c *CloudStackMachine) CompressUserdata() bool {
@@ -143,6 +175,28 @@ type CloudStackMachineStatus struct {
 	// Reason indicates the reason of status failure
 	// +optional
 	Reason *string `json:"reason,omitempty"`
+
+	// RemovedFromLoadBalancerAt is the time the instance was taken out of the load balancer rules while deleting.
+	// +optional
+	RemovedFromLoadBalancerAt *metav1.Time `json:"removedFromLoadBalancerAt,omitempty"`
+
+	// HostID is the ID of the host the CloudStack instance runs on. Only known to root admins.
+	// +optional
+	HostID string `json:"hostID,omitempty"`
+
+	// HostName is the name of the host the CloudStack instance runs on. Only known to root admins.
+	// +optional
+	HostName string `json:"hostName,omitempty"`
+
+	// Hibernated is set while CAPC keeps the CloudStack instance stopped for the cluster's hibernation, from asking
+	// CloudStack to stop it until it runs again and its node is healthy.
+	// +optional
+	Hibernated bool `json:"hibernated,omitempty"`
+
+	// TagsHash is the hash of the tags CAPC last brought the instance and its volumes in line with. Their tags are
+	// only reconciled again once the desired tags change.
+	// +optional
+	TagsHash string `json:"tagsHash,omitempty"`
 }
 
 // TimeSinceLastStateChange returns the amount of time that's elapsed since the state was last updated.  If the state
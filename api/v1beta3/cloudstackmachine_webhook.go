@@ -17,14 +17,18 @@ limitations under the License.
 package v1beta3
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/webhookutil"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -33,6 +37,7 @@ import (
 var cloudstackmachinelog = logf.Log.WithName("cloudstackmachine-resource")
 
 func (r *CloudStackMachine) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	apiReader = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -48,7 +53,7 @@ func (r *CloudStackMachine) Default() {
 	// No defaulted values supported yet.
 }
 
-//+kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackmachine,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackmachines,verbs=create;update,versions=v1beta3,name=vcloudstackmachine.kb.io,admissionReviewVersions=v1;v1beta1
+//+kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1beta3-cloudstackmachine,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=cloudstackmachines,verbs=create;update;delete,versions=v1beta3,name=vcloudstackmachine.kb.io,admissionReviewVersions=v1;v1beta1
 
 var _ webhook.Validator = &CloudStackMachine{}
 
@@ -60,6 +65,8 @@ func (r *CloudStackMachine) ValidateCreate() error {
 
 	errorList = webhookutil.EnsureAtLeastOneFieldExists(r.Spec.Offering.ID, r.Spec.Offering.Name, "Offering", errorList)
 	errorList = webhookutil.EnsureAtLeastOneFieldExists(r.Spec.Template.ID, r.Spec.Template.Name, "Template", errorList)
+	errorList = append(errorList, ValidateAdditionalTags(r.Spec.AdditionalTags, field.NewPath("spec", "additionalTags"))...)
+	errorList = append(errorList, ValidateMachinePlacement(r.Spec, field.NewPath("spec"))...)
 	if len(r.Spec.DiskOffering.ID) > 0 || len(r.Spec.DiskOffering.Name) > 0 {
 		errorList = webhookutil.EnsureIntFieldsAreNotNegative(r.Spec.DiskOffering.CustomSize, "customSizeInGB", errorList)
 	}
@@ -93,17 +100,101 @@ func (r *CloudStackMachine) ValidateUpdate(old runtime.Object) error {
 	errorList = webhookutil.EnsureEqualStrings(r.Spec.Template.Name, oldSpec.Template.Name, "template", errorList)
 	errorList = webhookutil.EnsureEqualMapStringString(&r.Spec.Details, &oldSpec.Details, "details", errorList)
 	errorList = webhookutil.EnsureEqualStrings(r.Spec.Affinity, oldSpec.Affinity, "affinity", errorList)
+	errorList = append(errorList, ValidateAdditionalTags(r.Spec.AdditionalTags, field.NewPath("spec", "additionalTags"))...)
 
 	if !reflect.DeepEqual(r.Spec.AffinityGroupIDs, oldSpec.AffinityGroupIDs) { // Equivalent to other Ensure funcs.
 		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "AffinityGroupIDs"), "AffinityGroupIDs"))
 	}
+	errorList = append(errorList, ValidateMachinePlacementUpdate(r.Spec, oldSpec, field.NewPath("spec"))...)
 
 	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
 }
 
+// ValidateMachinePlacement requires a host selector to select a host by ID or host tags, host tags to be single,
+// non-empty tags and an ExplicitDedication affinity group to have a name or ID.
+func ValidateMachinePlacement(spec CloudStackMachineSpec, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if host := spec.Host; host != nil {
+		if host.ID == "" && len(host.Tags) == 0 {
+			errorList = append(errorList, field.Required(path.Child("host"), "a host requires an ID or tags"))
+		}
+		for i, tag := range host.Tags {
+			if strings.TrimSpace(tag) == "" || strings.Contains(tag, ",") {
+				errorList = append(errorList, field.Invalid(path.Child("host", "tags").Index(i), tag,
+					"host tags must be non-empty and must not contain commas"))
+			}
+		}
+	}
+	if group := spec.ExplicitDedicationAffinityGroup; group != nil && group.ID == "" && group.Name == "" {
+		errorList = append(errorList, field.Required(path.Child("explicitDedicationAffinityGroup"),
+			"an ExplicitDedication affinity group requires a name or ID"))
+	}
+	return errorList
+}
+
+// ValidateMachinePlacementUpdate forbids changing where a machine is deployed, as its VM isn't moved.
+func ValidateMachinePlacementUpdate(spec, oldSpec CloudStackMachineSpec, path *field.Path) field.ErrorList {
+	var errorList field.ErrorList
+	if !reflect.DeepEqual(spec.Host, oldSpec.Host) {
+		errorList = append(errorList, field.Forbidden(path.Child("host"), "host"))
+	}
+	if !reflect.DeepEqual(spec.ExplicitDedicationAffinityGroup, oldSpec.ExplicitDedicationAffinityGroup) {
+		errorList = append(errorList, field.Forbidden(path.Child("explicitDedicationAffinityGroup"),
+			"explicitDedicationAffinityGroup"))
+	}
+	if spec.DeploymentPlanner != oldSpec.DeploymentPlanner {
+		errorList = append(errorList, field.Forbidden(path.Child("deploymentPlanner"), "deploymentPlanner"))
+	}
+	return errorList
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *CloudStackMachine) ValidateDelete() error {
 	cloudstackmachinelog.V(1).Info("entered validate delete webhook", "api resource name", r.Name)
-	// No deletion validations.  Deletion webhook not enabled.
-	return nil
+	return r.validateDelete(context.TODO())
+}
+
+// validateDelete refuses deleting a CloudStackMachine while its CAPI cluster is being deleted and its CloudStackCluster
+// has deletion protection, as deleting the CAPI cluster deletes its machines before the CloudStackCluster. Machines of
+// clusters that aren't being deleted are replaced and scaled down as usual.
+func (r *CloudStackMachine) validateDelete(ctx context.Context) error {
+	path := field.NewPath("metadata", "name")
+	clusterName := r.Labels[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		return nil
+	}
+	refuse := func(err *field.Error) error {
+		return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, field.ErrorList{err})
+	}
+	if apiReader == nil {
+		return refuse(field.InternalError(path,
+			fmt.Errorf("the deletion protection of cluster %s can't be checked", clusterName)))
+	}
+
+	capiCluster := &clusterv1.Cluster{}
+	err := apiReader.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: clusterName}, capiCluster)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return refuse(field.InternalError(path, err))
+	}
+	infraRef := capiCluster.Spec.InfrastructureRef
+	if capiCluster.DeletionTimestamp.IsZero() || infraRef == nil || infraRef.Kind != "CloudStackCluster" {
+		return nil
+	}
+	csCluster := &CloudStackCluster{}
+	err = apiReader.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: infraRef.Name}, csCluster)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return refuse(field.InternalError(path, err))
+	}
+	if !csCluster.Spec.DeletionProtection {
+		return nil
+	}
+	msg := fmt.Sprintf("deletion protection of CloudStackCluster %s is enabled, deleting cluster %s would destroy "+
+		"machine %s. Set spec.deletionProtection of the CloudStackCluster to false to delete it", csCluster.Name,
+		clusterName, r.Name)
+	recordRefusal(ctx, csCluster, "MachineDeletionRefused", msg)
+	return refuse(field.Forbidden(path, msg))
 }
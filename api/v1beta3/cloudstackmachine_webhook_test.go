@@ -19,6 +19,12 @@ package v1beta3_test
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
 
@@ -65,6 +71,18 @@ var _ = Describe("CloudStackMachine webhook", func() {
 				Should(MatchError(MatchRegexp(requiredRegex, "Offering")))
 		})
 
+		It("should reject a CloudStackMachine with additional tags reserved for CAPC", func() {
+			dummies.CSMachine1.Spec.AdditionalTags = map[string]string{"CAPC_owner_machine_name": "other"}
+			Expect(k8sClient.Create(ctx, dummies.CSMachine1)).
+				Should(MatchError(MatchRegexp("admission webhook.*denied the request.*reserved for CAPC")))
+		})
+
+		It("should reject a CloudStackMachine with an empty host selector", func() {
+			dummies.CSMachine1.Spec.Host = &infrav1.HostSelector{}
+			Expect(k8sClient.Create(ctx, dummies.CSMachine1)).
+				Should(MatchError(MatchRegexp(requiredRegex, "a host requires an ID or tags")))
+		})
+
 		It("should reject a CloudStackMachine with missing Template attribute", func() {
 			dummies.CSMachine1.Spec.Template = infrav1.CloudStackResourceIdentifier{ID: "", Name: ""}
 			Expect(k8sClient.Create(ctx, dummies.CSMachine1)).
@@ -101,10 +119,71 @@ var _ = Describe("CloudStackMachine webhook", func() {
 				Should(MatchError(MatchRegexp(forbiddenRegex, "details")))
 		})
 
+		It("should accept updates to the additional tags of the CloudStackMachine", func() {
+			dummies.CSMachine1.Spec.AdditionalTags = map[string]string{"team": "infra"}
+			Ω(k8sClient.Update(ctx, dummies.CSMachine1)).Should(Succeed())
+		})
+
+		It("should reject updates to the host of the CloudStackMachine", func() {
+			dummies.CSMachine1.Spec.Host = &infrav1.HostSelector{Tags: []string{"k8s"}}
+			Ω(k8sClient.Update(ctx, dummies.CSMachine1)).
+				Should(MatchError(MatchRegexp(forbiddenRegex, "host")))
+		})
+
 		It("should reject updates to the list of affinty groups of the CloudStackMachine", func() {
 			dummies.CSMachine1.Spec.AffinityGroupIDs = []string{"28b907b8-75a7-4214-bd3d-6c61961fc2af"}
 			Ω(k8sClient.Update(ctx, dummies.CSMachine1)).
 				Should(MatchError(MatchRegexp(forbiddenRegex, "AffinityGroupIDs")))
 		})
 	})
+
+	Context("When deleting a CloudStackMachine", func() {
+		var (
+			capiCluster *clusterv1.Cluster
+			csCluster   *infrav1.CloudStackCluster
+		)
+
+		BeforeEach(func() {
+			dummies.CSMachine1.Labels = map[string]string{clusterv1.ClusterNameLabel: "protected"}
+			csCluster = &infrav1.CloudStackCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "protected-infra", Namespace: dummies.CSMachine1.Namespace},
+				Spec:       infrav1.CloudStackClusterSpec{DeletionProtection: true},
+			}
+			now := metav1.Now()
+			capiCluster = &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "protected", Namespace: dummies.CSMachine1.Namespace,
+					DeletionTimestamp: &now, Finalizers: []string{clusterv1.ClusterFinalizer}},
+				Spec: clusterv1.ClusterSpec{InfrastructureRef: &corev1.ObjectReference{
+					Kind: "CloudStackCluster", Name: csCluster.Name}},
+			}
+		})
+
+		// validateDelete validates deleting the machine, reading the clusters as the test left them.
+		validateDelete := func() error {
+			scheme := runtime.NewScheme()
+			Ω(infrav1.AddToScheme(scheme)).Should(Succeed())
+			Ω(clusterv1.AddToScheme(scheme)).Should(Succeed())
+			defer infrav1.SetAPIReader(fake.NewClientBuilder().WithScheme(scheme).WithObjects(capiCluster, csCluster).Build())()
+			return dummies.CSMachine1.ValidateDelete()
+		}
+
+		It("should refuse deleting a machine of a cluster being deleted while its CloudStackCluster is protected", func() {
+			Ω(validateDelete()).Should(MatchError(ContainSubstring("deletion protection of CloudStackCluster protected-infra")))
+		})
+
+		It("should accept deleting a machine of a cluster that isn't being deleted", func() {
+			capiCluster.DeletionTimestamp = nil
+			Ω(validateDelete()).Should(Succeed())
+		})
+
+		It("should accept deleting a machine of a cluster without deletion protection", func() {
+			csCluster.Spec.DeletionProtection = false
+			Ω(validateDelete()).Should(Succeed())
+		})
+
+		It("should refuse deleting a machine if the deletion protection can't be checked", func() {
+			defer infrav1.SetAPIReader(nil)()
+			Ω(dummies.CSMachine1.ValidateDelete()).Should(MatchError(ContainSubstring("can't be checked")))
+		})
+	})
 })
@@ -74,6 +74,9 @@ func (r *CloudStackMachineTemplate) ValidateCreate() error {
 
 	errorList = webhookutil.EnsureAtLeastOneFieldExists(spec.Offering.ID, spec.Offering.Name, "Offering", errorList)
 	errorList = webhookutil.EnsureAtLeastOneFieldExists(spec.Template.ID, spec.Template.Name, "Template", errorList)
+	errorList = append(errorList, ValidateAdditionalTags(
+		spec.AdditionalTags, field.NewPath("spec", "template", "spec", "additionalTags"))...)
+	errorList = append(errorList, ValidateMachinePlacement(spec, field.NewPath("spec", "template", "spec"))...)
 
 	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
 }
@@ -101,10 +104,13 @@ func (r *CloudStackMachineTemplate) ValidateUpdate(old runtime.Object) error {
 	errorList = webhookutil.EnsureEqualStrings(spec.Template.Name, oldSpec.Template.Name, "template", errorList)
 	errorList = webhookutil.EnsureEqualMapStringString(&spec.Details, &oldSpec.Details, "details", errorList)
 	errorList = webhookutil.EnsureEqualStrings(spec.Affinity, oldSpec.Affinity, "affinity", errorList)
+	errorList = append(errorList, ValidateAdditionalTags(
+		spec.AdditionalTags, field.NewPath("spec", "template", "spec", "additionalTags"))...)
 
 	if !reflect.DeepEqual(spec.AffinityGroupIDs, oldSpec.AffinityGroupIDs) { // Equivalent to other Ensure funcs.
 		errorList = append(errorList, field.Forbidden(field.NewPath("spec", "AffinityGroupIDs"), "AffinityGroupIDs"))
 	}
+	errorList = append(errorList, ValidateMachinePlacementUpdate(spec, oldSpec, field.NewPath("spec", "template", "spec"))...)
 
 	return webhookutil.AggregateObjErrors(r.GroupVersionKind().GroupKind(), r.Name, errorList)
 }
@@ -0,0 +1,26 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+// SetAPIReader makes the webhooks read other objects with the reader, returning a function restoring the previous one.
+func SetAPIReader(reader client.Reader) (restore func()) {
+	previous := apiReader
+	apiReader = reader
+	return func() { apiReader = previous }
+}
@@ -31,9 +31,11 @@ import (
 	. "github.com/onsi/gomega"
 
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	//+kubebuilder:scaffold:imports
 	"k8s.io/apimachinery/pkg/runtime"
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -105,6 +107,13 @@ var _ = BeforeSuite(func() {
 	Ω((&infrav1.CloudStackCluster{}).SetupWebhookWithManager(mgr)).Should(Succeed())
 	Ω((&infrav1.CloudStackMachine{}).SetupWebhookWithManager(mgr)).Should(Succeed())
 	Ω((&infrav1.CloudStackMachineTemplate{}).SetupWebhookWithManager(mgr)).Should(Succeed())
+	Ω((&infrav1.CloudStackClusterTemplate{}).SetupWebhookWithManager(mgr)).Should(Succeed())
+	Ω((&infrav1.CloudStackFailureDomain{}).SetupWebhookWithManager(mgr)).Should(Succeed())
+	infrav1.ACSEndpointSecretRefResolver = func(
+		ctx context.Context, namespace string, fdSpec infrav1.CloudStackFailureDomainSpec,
+	) (corev1.SecretReference, error) {
+		return utils.ResolveACSEndpointSecretRef(ctx, mgr.GetAPIReader(), namespace, fdSpec)
+	}
 
 	//+kubebuilder:scaffold:webhook
 
@@ -22,11 +22,99 @@ limitations under the License.
 package v1beta3
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIServerLoadBalancer) DeepCopyInto(out *APIServerLoadBalancer) {
+	*out = *in
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(LoadBalancerHealthCheck)
+		**out = **in
+	}
+	if in.Stickiness != nil {
+		in, out := &in.Stickiness, &out.Stickiness
+		*out = new(LoadBalancerStickiness)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServerLoadBalancer.
+func (in *APIServerLoadBalancer) DeepCopy() *APIServerLoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(APIServerLoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedNamespaces) DeepCopyInto(out *AllowedNamespaces) {
+	*out = *in
+	if in.NamespaceList != nil {
+		in, out := &in.NamespaceList, &out.NamespaceList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedNamespaces.
+func (in *AllowedNamespaces) DeepCopy() *AllowedNamespaces {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedNamespaces)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Bastion) DeepCopyInto(out *Bastion) {
+	*out = *in
+	out.Offering = in.Offering
+	out.Template = in.Template
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bastion.
+func (in *Bastion) DeepCopy() *Bastion {
+	if in == nil {
+		return nil
+	}
+	out := new(Bastion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BastionStatus) DeepCopyInto(out *BastionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BastionStatus.
+func (in *BastionStatus) DeepCopy() *BastionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BastionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackAffinityGroup) DeepCopyInto(out *CloudStackAffinityGroup) {
 	*out = *in
@@ -143,6 +231,85 @@ func (in *CloudStackCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackClusterIdentity) DeepCopyInto(out *CloudStackClusterIdentity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterIdentity.
+func (in *CloudStackClusterIdentity) DeepCopy() *CloudStackClusterIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackClusterIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackClusterIdentity) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackClusterIdentityList) DeepCopyInto(out *CloudStackClusterIdentityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudStackClusterIdentity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterIdentityList.
+func (in *CloudStackClusterIdentityList) DeepCopy() *CloudStackClusterIdentityList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackClusterIdentityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackClusterIdentityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackClusterIdentitySpec) DeepCopyInto(out *CloudStackClusterIdentitySpec) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = new(AllowedNamespaces)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterIdentitySpec.
+func (in *CloudStackClusterIdentitySpec) DeepCopy() *CloudStackClusterIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackClusterIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackClusterList) DeepCopyInto(out *CloudStackClusterList) {
 	*out = *in
@@ -181,9 +348,70 @@ func (in *CloudStackClusterSpec) DeepCopyInto(out *CloudStackClusterSpec) {
 	if in.FailureDomains != nil {
 		in, out := &in.FailureDomains, &out.FailureDomains
 		*out = make([]CloudStackFailureDomainSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FailureDomainDiscovery != nil {
+		in, out := &in.FailureDomainDiscovery, &out.FailureDomainDiscovery
+		*out = new(FailureDomainDiscovery)
+		(*in).DeepCopyInto(*out)
 	}
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.FirewallPolicy != nil {
+		in, out := &in.FirewallPolicy, &out.FirewallPolicy
+		*out = new(FirewallPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APIServerLoadBalancer != nil {
+		in, out := &in.APIServerLoadBalancer, &out.APIServerLoadBalancer
+		*out = new(APIServerLoadBalancer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoadBalancerRules != nil {
+		in, out := &in.LoadBalancerRules, &out.LoadBalancerRules
+		*out = make([]WorkloadLoadBalancerRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LoadBalancerDrainPeriod != nil {
+		in, out := &in.LoadBalancerDrainPeriod, &out.LoadBalancerDrainPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ControlPlanePublicIP != nil {
+		in, out := &in.ControlPlanePublicIP, &out.ControlPlanePublicIP
+		*out = new(PublicIPPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bastion != nil {
+		in, out := &in.Bastion, &out.Bastion
+		*out = new(Bastion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VirtualRouterHealth != nil {
+		in, out := &in.VirtualRouterHealth, &out.VirtualRouterHealth
+		*out = new(VirtualRouterHealth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GlobalLoadBalancer != nil {
+		in, out := &in.GlobalLoadBalancer, &out.GlobalLoadBalancer
+		*out = new(GlobalLoadBalancer)
+		**out = **in
+	}
+	if in.DNSProvider != nil {
+		in, out := &in.DNSProvider, &out.DNSProvider
+		*out = new(DNSProvider)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalTags != nil {
+		in, out := &in.AdditionalTags, &out.AdditionalTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterSpec.
@@ -206,6 +434,33 @@ func (in *CloudStackClusterStatus) DeepCopyInto(out *CloudStackClusterStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.DiscoveredFailureDomains != nil {
+		in, out := &in.DiscoveredFailureDomains, &out.DiscoveredFailureDomains
+		*out = make([]CloudStackFailureDomainSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Bastion != nil {
+		in, out := &in.Bastion, &out.Bastion
+		*out = new(BastionStatus)
+		**out = **in
+	}
+	if in.ControlPlaneVIP != nil {
+		in, out := &in.ControlPlaneVIP, &out.ControlPlaneVIP
+		*out = new(ControlPlaneVIPStatus)
+		**out = **in
+	}
+	if in.GlobalLoadBalancer != nil {
+		in, out := &in.GlobalLoadBalancer, &out.GlobalLoadBalancer
+		*out = new(GlobalLoadBalancerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSRecordAddresses != nil {
+		in, out := &in.DNSRecordAddresses, &out.DNSRecordAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterStatus.
@@ -219,26 +474,25 @@ func (in *CloudStackClusterStatus) DeepCopy() *CloudStackClusterStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackFailureDomain) DeepCopyInto(out *CloudStackFailureDomain) {
+func (in *CloudStackClusterTemplate) DeepCopyInto(out *CloudStackClusterTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomain.
-func (in *CloudStackFailureDomain) DeepCopy() *CloudStackFailureDomain {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterTemplate.
+func (in *CloudStackClusterTemplate) DeepCopy() *CloudStackClusterTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackFailureDomain)
+	out := new(CloudStackClusterTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStackFailureDomain) DeepCopyObject() runtime.Object {
+func (in *CloudStackClusterTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -246,31 +500,31 @@ func (in *CloudStackFailureDomain) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackFailureDomainList) DeepCopyInto(out *CloudStackFailureDomainList) {
+func (in *CloudStackClusterTemplateList) DeepCopyInto(out *CloudStackClusterTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]CloudStackFailureDomain, len(*in))
+		*out = make([]CloudStackClusterTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainList.
-func (in *CloudStackFailureDomainList) DeepCopy() *CloudStackFailureDomainList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterTemplateList.
+func (in *CloudStackClusterTemplateList) DeepCopy() *CloudStackClusterTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackFailureDomainList)
+	out := new(CloudStackClusterTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStackFailureDomainList) DeepCopyObject() runtime.Object {
+func (in *CloudStackClusterTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -278,58 +532,59 @@ func (in *CloudStackFailureDomainList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackFailureDomainSpec) DeepCopyInto(out *CloudStackFailureDomainSpec) {
+func (in *CloudStackClusterTemplateResource) DeepCopyInto(out *CloudStackClusterTemplateResource) {
 	*out = *in
-	out.Zone = in.Zone
-	out.ACSEndpoint = in.ACSEndpoint
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainSpec.
-func (in *CloudStackFailureDomainSpec) DeepCopy() *CloudStackFailureDomainSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterTemplateResource.
+func (in *CloudStackClusterTemplateResource) DeepCopy() *CloudStackClusterTemplateResource {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackFailureDomainSpec)
+	out := new(CloudStackClusterTemplateResource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackFailureDomainStatus) DeepCopyInto(out *CloudStackFailureDomainStatus) {
+func (in *CloudStackClusterTemplateSpec) DeepCopyInto(out *CloudStackClusterTemplateSpec) {
 	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainStatus.
-func (in *CloudStackFailureDomainStatus) DeepCopy() *CloudStackFailureDomainStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackClusterTemplateSpec.
+func (in *CloudStackClusterTemplateSpec) DeepCopy() *CloudStackClusterTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackFailureDomainStatus)
+	out := new(CloudStackClusterTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackIsolatedNetwork) DeepCopyInto(out *CloudStackIsolatedNetwork) {
+func (in *CloudStackFailureDomain) DeepCopyInto(out *CloudStackFailureDomain) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetwork.
-func (in *CloudStackIsolatedNetwork) DeepCopy() *CloudStackIsolatedNetwork {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomain.
+func (in *CloudStackFailureDomain) DeepCopy() *CloudStackFailureDomain {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackIsolatedNetwork)
+	out := new(CloudStackFailureDomain)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStackIsolatedNetwork) DeepCopyObject() runtime.Object {
+func (in *CloudStackFailureDomain) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -337,31 +592,31 @@ func (in *CloudStackIsolatedNetwork) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackIsolatedNetworkList) DeepCopyInto(out *CloudStackIsolatedNetworkList) {
+func (in *CloudStackFailureDomainList) DeepCopyInto(out *CloudStackFailureDomainList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]CloudStackIsolatedNetwork, len(*in))
+		*out = make([]CloudStackFailureDomain, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetworkList.
-func (in *CloudStackIsolatedNetworkList) DeepCopy() *CloudStackIsolatedNetworkList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainList.
+func (in *CloudStackFailureDomainList) DeepCopy() *CloudStackFailureDomainList {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackIsolatedNetworkList)
+	out := new(CloudStackFailureDomainList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStackIsolatedNetworkList) DeepCopyObject() runtime.Object {
+func (in *CloudStackFailureDomainList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -369,38 +624,64 @@ func (in *CloudStackIsolatedNetworkList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackIsolatedNetworkSpec) DeepCopyInto(out *CloudStackIsolatedNetworkSpec) {
+func (in *CloudStackFailureDomainSpec) DeepCopyInto(out *CloudStackFailureDomainSpec) {
 	*out = *in
-	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	in.Zone.DeepCopyInto(&out.Zone)
+	out.ACSEndpoint = in.ACSEndpoint
+	if in.IdentityRef != nil {
+		in, out := &in.IdentityRef, &out.IdentityRef
+		*out = new(CloudStackIdentityReference)
+		**out = **in
+	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(FailureDomainPlacement)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetworkSpec.
-func (in *CloudStackIsolatedNetworkSpec) DeepCopy() *CloudStackIsolatedNetworkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainSpec.
+func (in *CloudStackFailureDomainSpec) DeepCopy() *CloudStackFailureDomainSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackIsolatedNetworkSpec)
+	out := new(CloudStackFailureDomainSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackIsolatedNetworkStatus) DeepCopyInto(out *CloudStackIsolatedNetworkStatus) {
+func (in *CloudStackFailureDomainStatus) DeepCopyInto(out *CloudStackFailureDomainStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetworkStatus.
-func (in *CloudStackIsolatedNetworkStatus) DeepCopy() *CloudStackIsolatedNetworkStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackFailureDomainStatus.
+func (in *CloudStackFailureDomainStatus) DeepCopy() *CloudStackFailureDomainStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackIsolatedNetworkStatus)
+	out := new(CloudStackFailureDomainStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackMachine) DeepCopyInto(out *CloudStackMachine) {
+func (in *CloudStackIdentityReference) DeepCopyInto(out *CloudStackIdentityReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIdentityReference.
+func (in *CloudStackIdentityReference) DeepCopy() *CloudStackIdentityReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackIdentityReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackIsolatedNetwork) DeepCopyInto(out *CloudStackIsolatedNetwork) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -408,18 +689,18 @@ func (in *CloudStackMachine) DeepCopyInto(out *CloudStackMachine) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachine.
-func (in *CloudStackMachine) DeepCopy() *CloudStackMachine {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetwork.
+func (in *CloudStackIsolatedNetwork) DeepCopy() *CloudStackIsolatedNetwork {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackMachine)
+	out := new(CloudStackIsolatedNetwork)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStackMachine) DeepCopyObject() runtime.Object {
+func (in *CloudStackIsolatedNetwork) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -427,31 +708,31 @@ func (in *CloudStackMachine) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackMachineList) DeepCopyInto(out *CloudStackMachineList) {
+func (in *CloudStackIsolatedNetworkList) DeepCopyInto(out *CloudStackIsolatedNetworkList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]CloudStackMachine, len(*in))
+		*out = make([]CloudStackIsolatedNetwork, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachineList.
-func (in *CloudStackMachineList) DeepCopy() *CloudStackMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetworkList.
+func (in *CloudStackIsolatedNetworkList) DeepCopy() *CloudStackIsolatedNetworkList {
 	if in == nil {
 		return nil
 	}
-	out := new(CloudStackMachineList)
+	out := new(CloudStackIsolatedNetworkList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *CloudStackMachineList) DeepCopyObject() runtime.Object {
+func (in *CloudStackIsolatedNetworkList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -459,7 +740,135 @@ func (in *CloudStackMachineList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudStackMachineSpec) DeepCopyInto(out *CloudStackMachineSpec) {
+func (in *CloudStackIsolatedNetworkSpec) DeepCopyInto(out *CloudStackIsolatedNetworkSpec) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv6DNS != nil {
+		in, out := &in.IPv6DNS, &out.IPv6DNS
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetworkSpec.
+func (in *CloudStackIsolatedNetworkSpec) DeepCopy() *CloudStackIsolatedNetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackIsolatedNetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackIsolatedNetworkStatus) DeepCopyInto(out *CloudStackIsolatedNetworkStatus) {
+	*out = *in
+	if in.WorkloadLBRules != nil {
+		in, out := &in.WorkloadLBRules, &out.WorkloadLBRules
+		*out = make([]WorkloadLBRuleStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.VirtualRouters != nil {
+		in, out := &in.VirtualRouters, &out.VirtualRouters
+		*out = make([]VirtualRouterStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRestartTime != nil {
+		in, out := &in.LastRestartTime, &out.LastRestartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackIsolatedNetworkStatus.
+func (in *CloudStackIsolatedNetworkStatus) DeepCopy() *CloudStackIsolatedNetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackIsolatedNetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackMachine) DeepCopyInto(out *CloudStackMachine) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachine.
+func (in *CloudStackMachine) DeepCopy() *CloudStackMachine {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackMachine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackMachine) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackMachineList) DeepCopyInto(out *CloudStackMachineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudStackMachine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachineList.
+func (in *CloudStackMachineList) DeepCopy() *CloudStackMachineList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudStackMachineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudStackMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudStackMachineSpec) DeepCopyInto(out *CloudStackMachineSpec) {
 	*out = *in
 	if in.InstanceID != nil {
 		in, out := &in.InstanceID, &out.InstanceID
@@ -483,7 +892,7 @@ func (in *CloudStackMachineSpec) DeepCopyInto(out *CloudStackMachineSpec) {
 	}
 	if in.AffinityGroupRef != nil {
 		in, out := &in.AffinityGroupRef, &out.AffinityGroupRef
-		*out = new(v1.ObjectReference)
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 	if in.ProviderID != nil {
@@ -496,6 +905,23 @@ func (in *CloudStackMachineSpec) DeepCopyInto(out *CloudStackMachineSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.AdditionalTags != nil {
+		in, out := &in.AdditionalTags, &out.AdditionalTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Host != nil {
+		in, out := &in.Host, &out.Host
+		*out = new(HostSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExplicitDedicationAffinityGroup != nil {
+		in, out := &in.ExplicitDedicationAffinityGroup, &out.ExplicitDedicationAffinityGroup
+		*out = new(CloudStackResourceIdentifier)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachineSpec.
@@ -602,7 +1028,7 @@ func (in *CloudStackMachineStatus) DeepCopyInto(out *CloudStackMachineStatus) {
 	*out = *in
 	if in.Addresses != nil {
 		in, out := &in.Addresses, &out.Addresses
-		*out = make([]v1.NodeAddress, len(*in))
+		*out = make([]corev1.NodeAddress, len(*in))
 		copy(*out, *in)
 	}
 	in.InstanceStateLastUpdated.DeepCopyInto(&out.InstanceStateLastUpdated)
@@ -616,6 +1042,10 @@ func (in *CloudStackMachineStatus) DeepCopyInto(out *CloudStackMachineStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.RemovedFromLoadBalancerAt != nil {
+		in, out := &in.RemovedFromLoadBalancerAt, &out.RemovedFromLoadBalancerAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackMachineStatus.
@@ -753,7 +1183,7 @@ func (in *CloudStackResourceIdentifier) DeepCopy() *CloudStackResourceIdentifier
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudStackZoneSpec) DeepCopyInto(out *CloudStackZoneSpec) {
 	*out = *in
-	out.Network = in.Network
+	in.Network.DeepCopyInto(&out.Network)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudStackZoneSpec.
@@ -766,9 +1196,289 @@ func (in *CloudStackZoneSpec) DeepCopy() *CloudStackZoneSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneVIPStatus) DeepCopyInto(out *ControlPlaneVIPStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneVIPStatus.
+func (in *ControlPlaneVIPStatus) DeepCopy() *ControlPlaneVIPStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneVIPStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSProvider) DeepCopyInto(out *DNSProvider) {
+	*out = *in
+	if in.RFC2136 != nil {
+		in, out := &in.RFC2136, &out.RFC2136
+		*out = new(RFC2136Provider)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSProvider.
+func (in *DNSProvider) DeepCopy() *DNSProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EgressFirewallRule) DeepCopyInto(out *EgressFirewallRule) {
+	*out = *in
+	if in.DestinationCIDRs != nil {
+		in, out := &in.DestinationCIDRs, &out.DestinationCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EgressFirewallRule.
+func (in *EgressFirewallRule) DeepCopy() *EgressFirewallRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EgressFirewallRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureDomainDiscovery) DeepCopyInto(out *FailureDomainDiscovery) {
+	*out = *in
+	out.ACSEndpoint = in.ACSEndpoint
+	if in.IdentityRef != nil {
+		in, out := &in.IdentityRef, &out.IdentityRef
+		*out = new(CloudStackIdentityReference)
+		**out = **in
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = new(ZoneSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Network.DeepCopyInto(&out.Network)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureDomainDiscovery.
+func (in *FailureDomainDiscovery) DeepCopy() *FailureDomainDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureDomainDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureDomainPlacement) DeepCopyInto(out *FailureDomainPlacement) {
+	*out = *in
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(CloudStackResourceIdentifier)
+		**out = **in
+	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(CloudStackResourceIdentifier)
+		**out = **in
+	}
+	if in.HostTags != nil {
+		in, out := &in.HostTags, &out.HostTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureDomainPlacement.
+func (in *FailureDomainPlacement) DeepCopy() *FailureDomainPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureDomainPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirewallPolicy) DeepCopyInto(out *FirewallPolicy) {
+	*out = *in
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = make([]IngressFirewallRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]EgressFirewallRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirewallPolicy.
+func (in *FirewallPolicy) DeepCopy() *FirewallPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FirewallPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalLoadBalancer) DeepCopyInto(out *GlobalLoadBalancer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalLoadBalancer.
+func (in *GlobalLoadBalancer) DeepCopy() *GlobalLoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalLoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalLoadBalancerStatus) DeepCopyInto(out *GlobalLoadBalancerStatus) {
+	*out = *in
+	if in.LoadBalancerRuleIDs != nil {
+		in, out := &in.LoadBalancerRuleIDs, &out.LoadBalancerRuleIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalLoadBalancerStatus.
+func (in *GlobalLoadBalancerStatus) DeepCopy() *GlobalLoadBalancerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalLoadBalancerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostSelector) DeepCopyInto(out *HostSelector) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostSelector.
+func (in *HostSelector) DeepCopy() *HostSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(HostSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressFirewallRule) DeepCopyInto(out *IngressFirewallRule) {
+	*out = *in
+	if in.SourceCIDRs != nil {
+		in, out := &in.SourceCIDRs, &out.SourceCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressFirewallRule.
+func (in *IngressFirewallRule) DeepCopy() *IngressFirewallRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressFirewallRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerHealthCheck) DeepCopyInto(out *LoadBalancerHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerHealthCheck.
+func (in *LoadBalancerHealthCheck) DeepCopy() *LoadBalancerHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerStickiness) DeepCopyInto(out *LoadBalancerStickiness) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStickiness.
+func (in *LoadBalancerStickiness) DeepCopy() *LoadBalancerStickiness {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerStickiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv6DNS != nil {
+		in, out := &in.IPv6DNS, &out.IPv6DNS
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VPC != nil {
+		in, out := &in.VPC, &out.VPC
+		*out = new(VPC)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Network.
@@ -780,3 +1490,223 @@ func (in *Network) DeepCopy() *Network {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkACLRule) DeepCopyInto(out *NetworkACLRule) {
+	*out = *in
+	if in.CIDRList != nil {
+		in, out := &in.CIDRList, &out.CIDRList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkACLRule.
+func (in *NetworkACLRule) DeepCopy() *NetworkACLRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkACLRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicIPPolicy) DeepCopyInto(out *PublicIPPolicy) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(PublicIPSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicIPPolicy.
+func (in *PublicIPPolicy) DeepCopy() *PublicIPPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicIPPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicIPSelector) DeepCopyInto(out *PublicIPSelector) {
+	*out = *in
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicIPSelector.
+func (in *PublicIPSelector) DeepCopy() *PublicIPSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicIPSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RFC2136Provider) DeepCopyInto(out *RFC2136Provider) {
+	*out = *in
+	if in.TSIGSecretRef != nil {
+		in, out := &in.TSIGSecretRef, &out.TSIGSecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RFC2136Provider.
+func (in *RFC2136Provider) DeepCopy() *RFC2136Provider {
+	if in == nil {
+		return nil
+	}
+	out := new(RFC2136Provider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPC) DeepCopyInto(out *VPC) {
+	*out = *in
+	if in.WorkerTier != nil {
+		in, out := &in.WorkerTier, &out.WorkerTier
+		*out = new(VPCTier)
+		**out = **in
+	}
+	if in.ACLRules != nil {
+		in, out := &in.ACLRules, &out.ACLRules
+		*out = make([]NetworkACLRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPC.
+func (in *VPC) DeepCopy() *VPC {
+	if in == nil {
+		return nil
+	}
+	out := new(VPC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPCTier) DeepCopyInto(out *VPCTier) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPCTier.
+func (in *VPCTier) DeepCopy() *VPCTier {
+	if in == nil {
+		return nil
+	}
+	out := new(VPCTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualRouterHealth) DeepCopyInto(out *VirtualRouterHealth) {
+	*out = *in
+	if in.CheckInterval != nil {
+		in, out := &in.CheckInterval, &out.CheckInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MinRestartInterval != nil {
+		in, out := &in.MinRestartInterval, &out.MinRestartInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualRouterHealth.
+func (in *VirtualRouterHealth) DeepCopy() *VirtualRouterHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualRouterHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualRouterStatus) DeepCopyInto(out *VirtualRouterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualRouterStatus.
+func (in *VirtualRouterStatus) DeepCopy() *VirtualRouterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualRouterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadLBRuleStatus) DeepCopyInto(out *WorkloadLBRuleStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadLBRuleStatus.
+func (in *WorkloadLBRuleStatus) DeepCopy() *WorkloadLBRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadLBRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadLoadBalancerRule) DeepCopyInto(out *WorkloadLoadBalancerRule) {
+	*out = *in
+	if in.SourceCIDRs != nil {
+		in, out := &in.SourceCIDRs, &out.SourceCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.MachineDeploymentSelector.DeepCopyInto(&out.MachineDeploymentSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadLoadBalancerRule.
+func (in *WorkloadLoadBalancerRule) DeepCopy() *WorkloadLoadBalancerRule {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadLoadBalancerRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneSelector) DeepCopyInto(out *ZoneSelector) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneSelector.
+func (in *ZoneSelector) DeepCopy() *ZoneSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ZoneSelector)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -67,7 +67,12 @@ func (reconciler *CloudStackAffinityGroupReconciler) Reconcile(ctx context.Conte
 
 func (r *CloudStackAGReconciliationRunner) Reconcile() (ctrl.Result, error) {
 	controllerutil.AddFinalizer(r.ReconciliationSubject, infrav1.AffinityGroupFinalizer)
-	affinityGroup := &cloud.AffinityGroup{Name: r.ReconciliationSubject.Spec.Name, Type: r.ReconciliationSubject.Spec.Type}
+	affinityGroup := &cloud.AffinityGroup{
+		Name: r.ReconciliationSubject.Spec.Name,
+		Type: r.ReconciliationSubject.Spec.Type,
+		Description: cloud.AffinityGroupDescription(
+			r.CAPICluster.Namespace, r.CAPICluster.Name, string(r.CAPICluster.UID)),
+	}
 	if err := r.CSUser.GetOrCreateAffinityGroup(affinityGroup); err != nil {
 		return ctrl.Result{}, err
 	}
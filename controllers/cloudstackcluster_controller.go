@@ -19,9 +19,13 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
+	"sort"
+	"strings"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -29,9 +33,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/dns"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/predicates"
@@ -89,12 +96,177 @@ func (reconciler *CloudStackClusterReconciler) Reconcile(ctx context.Context, re
 // Reconcile actually reconciles the CloudStackCluster.
 func (r *CloudStackClusterReconciliationRunner) Reconcile() (res ctrl.Result, reterr error) {
 	return r.RunReconciliationStages(
+		r.DiscoverFailureDomains,
 		r.SetFailureDomainsStatusMap,
-		r.CreateFailureDomains(r.ReconciliationSubject.Spec.FailureDomains),
+		r.SetControlPlaneEndpointName,
+		r.CreateClusterFailureDomains,
 		r.GetFailureDomains(r.FailureDomains),
 		r.RemoveExtraneousFailureDomains(r.FailureDomains),
 		r.VerifyFailureDomainCRDs,
-		r.SetReady)
+		r.ReconcileGlobalLoadBalancer,
+		r.ReconcileDNSRecords,
+		r.SetReady,
+		r.SetHibernationStatus,
+		r.RequeueForFailureDomainDiscovery)
+}
+
+// DiscoverFailureDomains lists the zones the cluster's failure domain discovery selects, and records the failure domains
+// generated for them in the cluster's status. Failure domains already discovered keep their place, so the cluster's
+// first failure domain doesn't change, and are kept when their zone is disabled. The failure domains discovered before
+// are kept when no zone is selected anymore, which is more likely a transient issue than all zones being gone.
+func (r *CloudStackClusterReconciliationRunner) DiscoverFailureDomains() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	discovery := csCluster.Spec.FailureDomainDiscovery
+	if discovery == nil {
+		csCluster.Status.DiscoveredFailureDomains = nil
+		return ctrl.Result{}, nil
+	}
+	credentials := discovery.FailureDomainSpec("", "")
+	if res, err := r.AsFailureDomainUser(&credentials)(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	zones, err := r.CSUser.ListZones(discovery.Zones)
+	if err != nil {
+		return r.ReturnWrappedError(err, "discovering failure domains")
+	}
+
+	zonesByName := map[string]*cloudstack.Zone{}
+	for _, zone := range zones {
+		zonesByName[zone.Name] = zone
+	}
+	discovered := []infrav1.CloudStackFailureDomainSpec{}
+	for _, fd := range csCluster.Status.DiscoveredFailureDomains {
+		if zone, found := zonesByName[fd.Zone.Name]; found {
+			discovered = append(discovered, discovery.FailureDomainSpec(zone.Name, zone.Id))
+			delete(zonesByName, fd.Zone.Name)
+		}
+	}
+	for _, zone := range zones {
+		if _, undiscovered := zonesByName[zone.Name]; undiscovered && (zone.Allocationstate == "" || zone.Allocationstate == "Enabled") {
+			discovered = append(discovered, discovery.FailureDomainSpec(zone.Name, zone.Id))
+		}
+	}
+
+	if len(discovered) == 0 {
+		if len(csCluster.FailureDomainSpecs()) == 0 {
+			return r.RequeueWithMessage("No zone selected to discover failure domains from, requeueing.")
+		}
+		r.Log.Info("No zone selected to discover failure domains from, keeping those discovered before.")
+		return ctrl.Result{}, nil
+	}
+	csCluster.Status.DiscoveredFailureDomains = discovered
+	return ctrl.Result{}, nil
+}
+
+// CreateClusterFailureDomains creates the CloudStackFailureDomains of the failure domains listed in the cluster's spec
+// and discovered from zones.
+func (r *CloudStackClusterReconciliationRunner) CreateClusterFailureDomains() (ctrl.Result, error) {
+	return r.CreateFailureDomains(r.ReconciliationSubject.FailureDomainSpecs())()
+}
+
+// RequeueForFailureDomainDiscovery requeues the cluster for the next discovery of its failure domains, if it has any.
+func (r *CloudStackClusterReconciliationRunner) RequeueForFailureDomainDiscovery() (ctrl.Result, error) {
+	discovery := r.ReconciliationSubject.Spec.FailureDomainDiscovery
+	if discovery == nil {
+		return ctrl.Result{}, nil
+	} else if discovery.Interval != nil {
+		return ctrl.Result{RequeueAfter: discovery.Interval.Duration}, nil
+	}
+	return ctrl.Result{RequeueAfter: infrav1.DefaultFailureDomainDiscoveryInterval}, nil
+}
+
+// SetHibernationStatus records how far the cluster's machines got stopping or starting for hibernation, and requeues
+// until they all got there.
+func (r *CloudStackClusterReconciliationRunner) SetHibernationStatus() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	if !csCluster.Spec.Hibernate && csCluster.Status.Hibernation == "" {
+		return ctrl.Result{}, nil
+	}
+	csMachines := &infrav1.CloudStackMachineList{}
+	if err := r.K8sClient.List(r.RequestCtx, csMachines, client.InNamespace(csCluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: r.CAPICluster.Name}); err != nil {
+		return r.ReturnWrappedError(err, "listing machines")
+	}
+	// Machines being deleted or in Error state are neither stopped nor started, and don't hold the cluster's
+	// hibernation up.
+	machines, hibernated, stopped := 0, 0, 0
+	for _, csMachine := range csMachines.Items {
+		if !csMachine.DeletionTimestamp.IsZero() || csMachine.Status.InstanceState == "Error" {
+			continue
+		}
+		machines++
+		if csMachine.Status.Hibernated {
+			hibernated++
+			if csMachine.Status.InstanceState == "Stopped" {
+				stopped++
+			}
+		}
+	}
+
+	switch {
+	case csCluster.Spec.Hibernate && stopped == machines:
+		csCluster.Status.Hibernation = infrav1.HibernationPhaseHibernated
+		return ctrl.Result{}, nil
+	case csCluster.Spec.Hibernate:
+		csCluster.Status.Hibernation = infrav1.HibernationPhaseHibernating
+	case hibernated == 0:
+		csCluster.Status.Hibernation = ""
+		return ctrl.Result{}, nil
+	default:
+		csCluster.Status.Hibernation = infrav1.HibernationPhaseResuming
+	}
+	return r.RequeueWithMessage("Waiting for machines to change state for hibernation.", "phase", csCluster.Status.Hibernation,
+		"hibernated", hibernated, "stopped", stopped, "machines", machines)
+}
+
+// SetControlPlaneEndpointName sets the control plane endpoint host to the cluster's DNS name or its global load
+// balancer's FQDN, if it has one.
+func (r *CloudStackClusterReconciliationRunner) SetControlPlaneEndpointName() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	if csCluster.ControlPlaneEndpointName() == "" || csCluster.Spec.ControlPlaneEndpoint.Host != "" {
+		return ctrl.Result{}, nil
+	}
+	csCluster.Spec.ControlPlaneEndpoint.Host = csCluster.ControlPlaneEndpointName()
+	if csCluster.Spec.ControlPlaneEndpoint.Port == 0 {
+		csCluster.Spec.ControlPlaneEndpoint.Port = cloud.K8sDefaultAPIPort
+	}
+	return ctrl.Result{}, nil
+}
+
+// ReconcileGlobalLoadBalancer assigns the API server load balancer rules of the cluster's isolated networks to the
+// cluster's global load balancer rule, using the credentials of the first failure domain.
+func (r *CloudStackClusterReconciliationRunner) ReconcileGlobalLoadBalancer() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	if csCluster.Spec.GlobalLoadBalancer == nil {
+		return ctrl.Result{}, nil
+	}
+	isoNets := &infrav1.CloudStackIsolatedNetworkList{}
+	if err := r.K8sClient.List(r.RequestCtx, isoNets, client.InNamespace(csCluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: r.CAPICluster.Name}); err != nil {
+		return r.ReturnWrappedError(err, "listing isolated networks")
+	}
+	var lbRuleIDs []string
+	for _, isoNet := range isoNets.Items {
+		if isoNet.Status.PublicIPID == "" { // VPC internal load balancers can't be globally load balanced.
+			continue
+		} else if isoNet.Status.LBRuleID == "" {
+			return r.RequeueWithMessage(fmt.Sprintf(
+				"Load balancer rule of isolated network %s not created yet, requeueing.", isoNet.Name))
+		}
+		lbRuleIDs = append(lbRuleIDs, isoNet.Status.LBRuleID)
+	}
+	if len(lbRuleIDs) == 0 {
+		return r.RequeueWithMessage("No isolated network load balancer rule to assign to the global load balancer, requeueing.")
+	}
+	sort.Strings(lbRuleIDs)
+
+	if res, err := r.AsFailureDomainUser(&csCluster.FailureDomainSpecs()[0])(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	if err := r.CSUser.ReconcileGlobalLoadBalancerRule(csCluster, lbRuleIDs); err != nil {
+		return r.ReturnWrappedError(err, "reconciling global load balancer rule")
+	}
+	return ctrl.Result{}, nil
 }
 
 // SetReady adds a finalizer and sets the cluster status to ready.
@@ -107,7 +279,7 @@ func (r *CloudStackClusterReconciliationRunner) SetReady() (ctrl.Result, error)
 // VerifyFailureDomainCRDs verifies the FailureDomains found match against those requested.
 func (r *CloudStackClusterReconciliationRunner) VerifyFailureDomainCRDs() (ctrl.Result, error) {
 	// Check that all required failure domains are present and ready.
-	for _, requiredFdSpec := range r.ReconciliationSubject.Spec.FailureDomains {
+	for _, requiredFdSpec := range r.ReconciliationSubject.FailureDomainSpecs() {
 		found := false
 		for _, fd := range r.FailureDomains.Items {
 			if requiredFdSpec.Name == fd.Spec.Name {
@@ -128,7 +300,7 @@ func (r *CloudStackClusterReconciliationRunner) VerifyFailureDomainCRDs() (ctrl.
 // SetFailureDomainsStatusMap sets failure domains in CloudStackCluster status to be used for CAPI machine placement.
 func (r *CloudStackClusterReconciliationRunner) SetFailureDomainsStatusMap() (ctrl.Result, error) {
 	r.ReconciliationSubject.Status.FailureDomains = clusterv1.FailureDomains{}
-	for _, fdSpec := range r.ReconciliationSubject.Spec.FailureDomains {
+	for _, fdSpec := range r.ReconciliationSubject.FailureDomainSpecs() {
 		metaHashName := infrav1.FailureDomainHashedMetaName(fdSpec.Name, r.CAPICluster.Name)
 		r.ReconciliationSubject.Status.FailureDomains[fdSpec.Name] = clusterv1.FailureDomainSpec{
 			ControlPlane: true, Attributes: map[string]string{"MetaHashName": metaHashName},
@@ -137,12 +309,73 @@ func (r *CloudStackClusterReconciliationRunner) SetFailureDomainsStatusMap() (ct
 	return ctrl.Result{}, nil
 }
 
+// ReconcileDNSRecords points the records of the cluster's DNS name at the control plane endpoint's addresses: the
+// public IPs of its isolated networks, or the address CAPC allocated on a shared network.
+func (r *CloudStackClusterReconciliationRunner) ReconcileDNSRecords() (ctrl.Result, error) {
+	csCluster := r.ReconciliationSubject
+	if csCluster.Spec.DNSName == "" {
+		return ctrl.Result{}, nil
+	}
+	isoNets := &infrav1.CloudStackIsolatedNetworkList{}
+	if err := r.K8sClient.List(r.RequestCtx, isoNets, client.InNamespace(csCluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: r.CAPICluster.Name}); err != nil {
+		return r.ReturnWrappedError(err, "listing isolated networks")
+	}
+	var addresses []string
+	for _, isoNet := range isoNets.Items {
+		if host := isoNet.Spec.ControlPlaneEndpoint.Host; net.ParseIP(host) != nil {
+			addresses = append(addresses, host)
+		}
+	}
+	if vip := csCluster.Status.ControlPlaneVIP; vip != nil {
+		addresses = append(addresses, vip.Address)
+	}
+	if len(addresses) == 0 {
+		return r.RequeueWithMessage("No control plane endpoint address to point the DNS records at, requeueing.")
+	}
+	sort.Strings(addresses)
+	if reflect.DeepEqual(addresses, csCluster.Status.DNSRecordAddresses) {
+		return ctrl.Result{}, nil
+	}
+
+	provider, err := dns.NewProvider(r.RequestCtx, r.K8sClient, csCluster.Namespace, csCluster.Spec.DNSProvider)
+	if err != nil {
+		return r.ReturnWrappedError(err, "setting up DNS provider")
+	}
+	if err := provider.EnsureRecords(r.RequestCtx, csCluster.Spec.DNSName, addresses); err != nil {
+		return r.ReturnWrappedError(err, "updating DNS records")
+	}
+	csCluster.Status.DNSRecordAddresses = addresses
+	r.Recorder.Eventf(csCluster, "Normal", "UpdatedDNSRecords",
+		"Pointed %s at %s", csCluster.Spec.DNSName, strings.Join(addresses, ", "))
+	return ctrl.Result{}, nil
+}
+
 // ReconcileDelete cleans up resources used by the cluster and finally removes the CloudStackCluster's finalizers.
 func (r *CloudStackClusterReconciliationRunner) ReconcileDelete() (ctrl.Result, error) {
 	r.Log.Info("Deleting CloudStackCluster.")
 	if res, err := r.GetFailureDomains(r.FailureDomains)(); r.ShouldReturn(res, err) {
 		return res, err
 	}
+	if fds := r.ReconciliationSubject.FailureDomainSpecs(); r.ReconciliationSubject.Status.GlobalLoadBalancer != nil && len(fds) > 0 {
+		if res, err := r.AsFailureDomainUser(&fds[0])(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+		if err := r.CSUser.DeleteGlobalLoadBalancerRule(r.ReconciliationSubject); err != nil {
+			return r.ReturnWrappedError(err, "deleting global load balancer rule")
+		}
+	}
+	if r.ReconciliationSubject.Spec.DNSName != "" && len(r.ReconciliationSubject.Status.DNSRecordAddresses) > 0 {
+		provider, err := dns.NewProvider(
+			r.RequestCtx, r.K8sClient, r.ReconciliationSubject.Namespace, r.ReconciliationSubject.Spec.DNSProvider)
+		if err != nil {
+			return r.ReturnWrappedError(err, "setting up DNS provider")
+		}
+		if err := provider.DeleteRecords(r.RequestCtx, r.ReconciliationSubject.Spec.DNSName); err != nil {
+			return r.ReturnWrappedError(err, "deleting DNS records")
+		}
+		r.ReconciliationSubject.Status.DNSRecordAddresses = nil
+	}
 	if len(r.FailureDomains.Items) > 0 {
 		for idx := range r.FailureDomains.Items {
 			if err := r.K8sClient.Delete(r.RequestCtx, &r.FailureDomains.Items[idx]); err != nil {
@@ -38,6 +38,7 @@ var _ = Describe("CloudStackClusterReconciler", func() {
 		It("Should create a CloudStackFailureDomain.", func() {
 			tempfd := &infrav1.CloudStackFailureDomain{}
 			mockCloudClient.EXPECT().ResolveZone(gomock.Any()).AnyTimes()
+			mockCloudClient.EXPECT().ResolvePlacement(gomock.Any(), gomock.Any()).AnyTimes()
 			Eventually(func() bool {
 				key := client.ObjectKeyFromObject(dummies.CSFailureDomain1)
 				key.Name = key.Name + "-" + dummies.CSCluster.Name
@@ -54,5 +55,26 @@ var _ = Describe("CloudStackClusterReconciler", func() {
 			reconRunenr := controllers.NewCSClusterReconciliationRunner()
 			Ω(reconRunenr.ReconciliationSubject).ShouldNot(BeNil())
 		})
+
+		It("Should report a cluster hibernated while one of its machines is in Error state.", func() {
+			setupFakeTestClient()
+			stopped := dummies.CSMachine1.DeepCopy()
+			stopped.Status.InstanceState = "Stopped"
+			stopped.Status.Hibernated = true
+			failed := dummies.CSMachine1.DeepCopy()
+			failed.Name = "test-machine-2"
+			failed.Status.InstanceState = "Error"
+			Ω(fakeCtrlClient.Create(ctx, stopped)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, failed)).Should(Succeed())
+
+			reconRunner := controllers.NewCSClusterReconciliationRunner()
+			reconRunner.UsingBaseReconciler(ClusterReconciler.ReconcilerBase).WithRequestCtx(ctx)
+			reconRunner.CAPICluster = dummies.CAPICluster
+			reconRunner.ReconciliationSubject.Spec.Hibernate = true
+			res, err := reconRunner.SetHibernationStatus()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(res.RequeueAfter).Should(BeZero())
+			Ω(reconRunner.ReconciliationSubject.Status.Hibernation).Should(Equal(infrav1.HibernationPhaseHibernated))
+		})
 	})
 })
@@ -19,13 +19,18 @@ package controllers
 import (
 	"context"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sort"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
@@ -46,6 +51,8 @@ type CloudStackFailureDomainReconciler struct {
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackfailuredomains,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackfailuredomains/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackfailuredomains/finalizers,verbs=update
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackclusteridentities,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinesets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=etcdcluster.cluster.x-k8s.io,resources=etcdadmclusters,verbs=get;list;watch
 //+kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,verbs=get;list;watch
@@ -90,15 +97,26 @@ func (r *CloudStackFailureDomainReconciliationRunner) Reconcile() (retRes ctrl.R
 	}
 	// Prevent premature deletion.
 	controllerutil.AddFinalizer(r.ReconciliationSubject, infrav1.FailureDomainFinalizer)
+	// Record the account the credentials act as, the webhook verifies new credentials against it.
+	if r.ReconciliationSubject.Status.AccountID == "" {
+		r.ReconciliationSubject.Status.AccountID = r.CSUser.GetUser().Account.ID
+	}
 
 	// Start by purely data fetching information about the zone and specified network.
 	if err := r.CSUser.ResolveZone(&r.ReconciliationSubject.Spec.Zone); err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "resolving CloudStack zone information")
 	}
+	// Listing pods, clusters and hosts requires an admin account, so use CSClient rather than CSUser.
+	if err := r.CSClient.ResolvePlacement(r.ReconciliationSubject.Spec.Placement, r.ReconciliationSubject.Spec.Zone.ID); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "resolving CloudStack placement information")
+	}
 	if err := r.CSUser.ResolveNetworkForZone(&r.ReconciliationSubject.Spec.Zone); err != nil &&
 		!csCtrlrUtils.ContainsNoMatchSubstring(err) {
 		return ctrl.Result{}, errors.Wrap(err, "resolving Cloudstack network information")
 	}
+	if res, err := r.AllocateControlPlaneVIPIfNeeded(); r.ShouldReturn(res, err) {
+		return res, err
+	}
 
 	// Check if the passed network was an isolated network or the network was missing. In either case, create a
 	// CloudStackIsolatedNetwork to manage the many intricacies and wait until CloudStackIsolatedNetwork is ready.
@@ -106,7 +124,7 @@ func (r *CloudStackFailureDomainReconciliationRunner) Reconcile() (retRes ctrl.R
 		r.ReconciliationSubject.Spec.Zone.Network.Type == infrav1.NetworkTypeIsolated {
 		netName := r.ReconciliationSubject.Spec.Zone.Network.Name
 		if res, err := r.GenerateIsolatedNetwork(
-			netName, func() string { return r.ReconciliationSubject.Spec.Name })(); r.ShouldReturn(res, err) {
+			r.ReconciliationSubject.Spec.Zone.Network, func() string { return r.ReconciliationSubject.Spec.Name })(); r.ShouldReturn(res, err) {
 			return res, err
 		} else if res, err := r.GetObjectByName(r.IsoNetMetaName(netName), r.IsoNet)(); r.ShouldReturn(res, err) {
 			return res, err
@@ -117,6 +135,13 @@ func (r *CloudStackFailureDomainReconciliationRunner) Reconcile() (retRes ctrl.R
 		if !r.IsoNet.Status.Ready {
 			return r.RequeueWithMessage("Isolated network dependency not ready.")
 		}
+		// Record the VPC and worker tier the isolated network resolved so machines can be placed in them.
+		if vpc := r.ReconciliationSubject.Spec.Zone.Network.VPC; vpc != nil {
+			vpc.ID = r.IsoNet.Status.VPCID
+			if vpc.WorkerTier != nil {
+				vpc.WorkerTier.ID = r.IsoNet.Status.WorkerTierID
+			}
+		}
 	}
 	r.ReconciliationSubject.Status.Ready = true
 	return ctrl.Result{}, nil
@@ -137,10 +162,59 @@ func (r *CloudStackFailureDomainReconciliationRunner) ReconcileDelete() (ctrl.Re
 		r.CheckOwnedObjectsDeleted(
 			infrav1.GroupVersion.WithKind("CloudStackAffinityGroup"),
 			infrav1.GroupVersion.WithKind("CloudStackIsolatedNetwork")),
+		r.ReleaseControlPlaneVIPIfNeeded,
 		r.RemoveFinalizer,
 	)
 }
 
+// allocatesControlPlaneVIP checks whether the control plane endpoint address is allocated on the failure domain's
+// network. CAPC allocates it on the shared network of the cluster's first failure domain.
+func (r *CloudStackFailureDomainReconciliationRunner) allocatesControlPlaneVIP() bool {
+	fds := r.CSCluster.FailureDomainSpecs()
+	return r.ReconciliationSubject.Spec.Zone.Network.Type == infrav1.NetworkTypeShared &&
+		len(fds) > 0 && fds[0].Name == r.ReconciliationSubject.Spec.Name
+}
+
+// AllocateControlPlaneVIPIfNeeded allocates the control plane endpoint address on the failure domain's shared network
+// when the cluster's endpoint host is empty or a DNS name, and writes it to the CloudStackCluster.
+func (r *CloudStackFailureDomainReconciliationRunner) AllocateControlPlaneVIPIfNeeded() (ctrl.Result, error) {
+	host := r.CSCluster.Spec.ControlPlaneEndpoint.Host
+	if !r.allocatesControlPlaneVIP() || r.CSCluster.Status.ControlPlaneVIP != nil ||
+		(host != "" && host != r.CSCluster.ControlPlaneEndpointName()) {
+		return ctrl.Result{}, nil
+	}
+	csClusterPatcher, err := patch.NewHelper(r.CSCluster, r.K8sClient)
+	if err != nil {
+		return r.ReturnWrappedError(err, "setting up CloudStackCluster patcher")
+	}
+	// Listing the network's IP ranges requires an admin account, so use CSClient rather than CSUser.
+	if err := r.CSClient.AllocateControlPlaneVIP(r.ReconciliationSubject, r.CSCluster); err != nil {
+		return r.ReturnWrappedError(err, "allocating control plane endpoint address")
+	}
+	if err := csClusterPatcher.Patch(r.RequestCtx, r.CSCluster); err != nil {
+		return r.ReturnWrappedError(err, "patching endpoint update to CloudStackCluster")
+	}
+	r.Recorder.Eventf(r.CSCluster, "Normal", "AllocatedControlPlaneVIP",
+		"Allocated control plane endpoint address %s", r.CSCluster.Status.ControlPlaneVIP.Address)
+	return ctrl.Result{}, nil
+}
+
+// ReleaseControlPlaneVIPIfNeeded releases the control plane endpoint address CAPC allocated on the failure domain's
+// network once the cluster is being deleted.
+func (r *CloudStackFailureDomainReconciliationRunner) ReleaseControlPlaneVIPIfNeeded() (ctrl.Result, error) {
+	vip := r.CSCluster.Status.ControlPlaneVIP
+	if vip == nil || r.CSCluster.DeletionTimestamp.IsZero() || vip.NetworkID != r.ReconciliationSubject.Spec.Zone.Network.ID {
+		return ctrl.Result{}, nil
+	}
+	if res, err := r.AsFailureDomainUser(&r.ReconciliationSubject.Spec)(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	if err := r.CSUser.ReleaseControlPlaneVIP(r.CSCluster); err != nil {
+		return r.ReturnWrappedError(err, "releasing control plane endpoint address")
+	}
+	return ctrl.Result{}, nil
+}
+
 // GetAllMachinesInFailureDomain returns all cloudstackmachines deployed in this failure domain sorted by name.
 func (r *CloudStackFailureDomainReconciliationRunner) GetAllMachinesInFailureDomain() (ctrl.Result, error) {
 	machines := &infrav1.CloudStackMachineList{}
@@ -268,5 +342,15 @@ func (reconciler *CloudStackFailureDomainReconciler) SetupWithManager(mgr ctrl.M
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(opts).
 		For(&infrav1.CloudStackFailureDomain{}).
+		// Reconcile failure domains with fresh clients when the credentials of their ACS endpoint change.
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(csCtrlrUtils.ACSEndpointSecretToFailureDomains(reconciler.K8sClient)),
+			builder.WithPredicates(csCtrlrUtils.ACSEndpointSecretChanged()),
+		).
+		Watches(
+			&source.Kind{Type: &infrav1.CloudStackClusterIdentity{}},
+			handler.EnqueueRequestsFromMapFunc(csCtrlrUtils.IdentityToFailureDomains(reconciler.K8sClient)),
+		).
 		Complete(reconciler)
 }
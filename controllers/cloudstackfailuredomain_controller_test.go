@@ -44,6 +44,7 @@ var _ = Describe("CloudStackFailureDomainReconciler", func() {
 			Ω(k8sClient.Create(ctx, dummies.CSFailureDomain1))
 
 			mockCloudClient.EXPECT().ResolveZone(gomock.Any()).MinTimes(1)
+			mockCloudClient.EXPECT().ResolvePlacement(gomock.Any(), gomock.Any()).AnyTimes()
 
 			mockCloudClient.EXPECT().ResolveNetworkForZone(gomock.Any()).AnyTimes().Do(
 				func(arg1 interface{}) {
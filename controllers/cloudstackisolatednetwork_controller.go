@@ -18,13 +18,25 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"time"
 
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	csCtrlrUtils "sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
@@ -33,6 +45,8 @@ import (
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackisolatednetworks,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackisolatednetworks/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackisolatednetworks/finalizers,verbs=update
+//+kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackmachines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch
 
 // CloudStackIsoNetReconciler reconciles a CloudStackZone object
 type CloudStackIsoNetReconciler struct {
@@ -78,6 +92,9 @@ func (r *CloudStackIsoNetReconciliationRunner) Reconcile() (retRes ctrl.Result,
 	if r.FailureDomain.Spec.Zone.ID == "" {
 		return r.RequeueWithMessage("Zone ID not resolved yet.")
 	}
+	if res, err := r.CheckNetworkAddressing(); r.ShouldReturn(res, err) {
+		return res, err
+	}
 	if err := r.CSUser.GetOrCreateIsolatedNetwork(r.FailureDomain, r.ReconciliationSubject, r.CSCluster); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -85,16 +102,229 @@ func (r *CloudStackIsoNetReconciliationRunner) Reconcile() (retRes ctrl.Result,
 	if err := r.CSUser.AddClusterTag(cloud.ResourceTypeNetwork, r.ReconciliationSubject.Spec.ID, r.CSCluster); err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "tagging network with id %s", r.ReconciliationSubject.Spec.ID)
 	}
+	if res, err := r.ReconcileWorkloadLoadBalancerRules(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	if res, err := r.ReconcileBastion(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	if err := r.CSUser.ReconcileIsolatedNetworkTags(r.ReconciliationSubject, r.CSCluster); err != nil {
+		return r.ReturnWrappedError(err, "reconciling tags of isolated network resources")
+	}
 	if err := csClusterPatcher.Patch(r.RequestCtx, r.CSCluster); err != nil {
 		return r.ReturnWrappedError(err, "patching endpoint update to CloudStackCluster")
 	}
 
 	r.ReconciliationSubject.Status.Ready = true
+	return r.CheckVirtualRouterHealth()
+}
+
+// CheckNetworkAddressing surfaces overlaps of the network's addressing with the pod and service CIDRs of the CAPI
+// cluster in its NetworkAddressingValid condition. The CloudStackCluster webhook can't check this, as the CAPI cluster
+// usually doesn't exist yet when the CloudStackCluster is created. A network that doesn't exist yet isn't created
+// while it overlaps; an existing one is only reported.
+func (r *CloudStackIsoNetReconciliationRunner) CheckNetworkAddressing() (ctrl.Result, error) {
+	isoNet := r.ReconciliationSubject
+	network := infrav1.Network{CIDR: isoNet.Spec.CIDR, Gateway: isoNet.Spec.Gateway, Netmask: isoNet.Spec.Netmask}
+	overlaps := infrav1.ClusterNetworkOverlaps(network, r.CAPICluster.Spec.ClusterNetwork)
+	if len(overlaps) == 0 {
+		conditions.MarkTrue(isoNet, infrav1.NetworkAddressingValidCondition)
+		return ctrl.Result{}, nil
+	}
+	message := strings.Join(overlaps, "; ")
+	if !conditions.IsFalse(isoNet, infrav1.NetworkAddressingValidCondition) {
+		r.Recorder.Event(isoNet, "Warning", infrav1.ClusterNetworkOverlapReason, message)
+	}
+	conditions.MarkFalse(isoNet, infrav1.NetworkAddressingValidCondition, infrav1.ClusterNetworkOverlapReason,
+		clusterv1.ConditionSeverityError, message)
+	if isoNet.Spec.ID == "" {
+		return r.RequeueWithMessage("Network overlaps with the cluster network.", "overlaps", message)
+	}
+	return ctrl.Result{}, nil
+}
+
+// CheckVirtualRouterHealth surfaces the state of the network's virtual routers in its VirtualRouterReady condition and
+// requeues the network for the next check. If the cluster opted in, the network is restarted when none of its routers
+// is healthy, at most once per minimum restart interval.
+func (r *CloudStackIsoNetReconciliationRunner) CheckVirtualRouterHealth() (ctrl.Result, error) {
+	isoNet := r.ReconciliationSubject
+	health := r.CSCluster.Spec.VirtualRouterHealth
+	if health == nil {
+		health = &infrav1.VirtualRouterHealth{}
+	}
+	res := ctrl.Result{RequeueAfter: infrav1.DefaultVirtualRouterCheckInterval}
+	if health.CheckInterval != nil {
+		res.RequeueAfter = health.CheckInterval.Duration
+	}
+
+	// Listing routers requires an admin account, so use CSClient rather than CSUser.
+	if err := r.CSClient.ResolveVirtualRouters(isoNet); err != nil {
+		r.Log.Error(err, "checking virtual routers")
+		conditions.MarkUnknown(isoNet, infrav1.VirtualRouterReadyCondition, infrav1.VirtualRouterCheckFailedReason, err.Error())
+		return res, nil
+	}
+	reason, message := virtualRouterHealth(isoNet.Status.VirtualRouters)
+	switch reason {
+	case "":
+		conditions.MarkTrue(isoNet, infrav1.VirtualRouterReadyCondition)
+		return res, nil
+	case infrav1.NoVirtualRouterReason:
+		conditions.MarkFalse(isoNet, infrav1.VirtualRouterReadyCondition, reason, clusterv1.ConditionSeverityInfo, message)
+		return res, nil
+	}
+	conditions.MarkFalse(isoNet, infrav1.VirtualRouterReadyCondition, reason, clusterv1.ConditionSeverityWarning, message)
+
+	if !health.AutoRestart {
+		return res, nil
+	}
+	minRestartInterval := infrav1.DefaultMinNetworkRestartInterval
+	if health.MinRestartInterval != nil {
+		minRestartInterval = health.MinRestartInterval.Duration
+	}
+	if last := isoNet.Status.LastRestartTime; last != nil && time.Since(last.Time) < minRestartInterval {
+		r.Log.Info("Network restart rate limited.", "lastRestartTime", last.Time, "reason", reason)
+		return res, nil
+	}
+	r.Recorder.Eventf(isoNet, "Warning", "RestartingNetwork", "Restarting network to recover virtual routers: %s", message)
+	if err := r.CSUser.RestartIsolatedNetwork(isoNet); err != nil {
+		return r.ReturnWrappedError(err, "restarting network")
+	}
+	now := metav1.Now()
+	isoNet.Status.LastRestartTime = &now
+	return res, nil
+}
+
+// virtualRouterHealth evaluates the virtual routers of a network. Returns the reason and a message if none of them is
+// healthy, i.e. running, passing its health checks and, for redundant routers, primary.
+func virtualRouterHealth(routers []infrav1.VirtualRouterStatus) (string, string) {
+	if len(routers) == 0 {
+		return infrav1.NoVirtualRouterReason, "no virtual router deployed yet"
+	}
+	running, passing := 0, 0
+	for _, router := range routers {
+		if router.State != cloud.VirtualRouterStateRunning {
+			continue
+		}
+		running++
+		if router.HealthChecksFailed {
+			continue
+		}
+		passing++
+		if !router.Redundant || router.RedundantState == cloud.VirtualRouterRedundantStatePrimary {
+			return "", ""
+		}
+	}
+	switch {
+	case running == 0:
+		return infrav1.VirtualRouterNotRunningReason, fmt.Sprintf("none of %d virtual routers is running", len(routers))
+	case passing == 0:
+		return infrav1.VirtualRouterHealthChecksFailedReason, "all running virtual routers failed their health checks"
+	default:
+		return infrav1.NoPrimaryVirtualRouterReason, "none of the redundant virtual routers is primary"
+	}
+}
+
+// ReconcileWorkloadLoadBalancerRules reconciles the cluster's workload load balancer rules on the isolated network.
+// Their members are the running machines in the network's failure domain of the MachineDeployments each rule selects.
+func (r *CloudStackIsoNetReconciliationRunner) ReconcileWorkloadLoadBalancerRules() (ctrl.Result, error) {
+	members := map[string][]string{}
+	for _, rule := range r.CSCluster.Spec.LoadBalancerRules {
+		selector, err := metav1.LabelSelectorAsSelector(&rule.MachineDeploymentSelector)
+		if err != nil {
+			return r.ReturnWrappedError(err, "parsing MachineDeployment selector of load balancer rule "+rule.Name)
+		}
+		mds := &clusterv1.MachineDeploymentList{}
+		if err := r.K8sClient.List(r.RequestCtx, mds, client.InNamespace(r.CSCluster.Namespace),
+			client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return r.ReturnWrappedError(err, "listing MachineDeployments")
+		}
+		members[rule.Name] = []string{}
+		for _, md := range mds.Items {
+			if md.Spec.ClusterName != r.CAPICluster.Name {
+				continue
+			}
+			csMachines := &infrav1.CloudStackMachineList{}
+			if err := r.K8sClient.List(r.RequestCtx, csMachines, client.InNamespace(md.Namespace), client.MatchingLabels{
+				clusterv1.ClusterNameLabel:           md.Spec.ClusterName,
+				clusterv1.MachineDeploymentNameLabel: md.Name,
+			}); err != nil {
+				return r.ReturnWrappedError(err, "listing CloudStackMachines")
+			}
+			for _, csMachine := range csMachines.Items {
+				// Take machines out of rotation as soon as they are being deleted.
+				if csMachine.Spec.FailureDomainName != r.ReconciliationSubject.Spec.FailureDomainName ||
+					csMachine.Spec.InstanceID == nil || *csMachine.Spec.InstanceID == "" ||
+					!csMachine.DeletionTimestamp.IsZero() {
+					continue
+				}
+				members[rule.Name] = append(members[rule.Name], *csMachine.Spec.InstanceID)
+			}
+		}
+	}
+	isoNet := r.ReconciliationSubject
+	recorded := isoNet.Status.WorkloadLBRules
+	if err := r.CSUser.ReconcileWorkloadLoadBalancerRules(r.FailureDomain, isoNet, r.CSCluster, members); err != nil {
+		return r.ReturnWrappedError(err, "reconciling workload load balancer rules")
+	}
+	// The rules don't open the firewall themselves. Open the public ports of new rules right away rather than on the
+	// next reconciliation of the network. VPC networks are governed by their ACL instead.
+	if isoNet.Status.VPCID == "" && !equality.Semantic.DeepEqual(recorded, isoNet.Status.WorkloadLBRules) {
+		if err := r.CSUser.ReconcileFirewallPolicy(isoNet, r.CSCluster); err != nil {
+			return r.ReturnWrappedError(err, "reconciling firewall rules of workload load balancer rules")
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// ReconcileBastion deploys the cluster's bastion into the isolated network of its failure domain, or disposes of the
+// bastion once it is removed from the cluster's spec.
+func (r *CloudStackIsoNetReconciliationRunner) ReconcileBastion() (ctrl.Result, error) {
+	if r.CSCluster.BastionFailureDomainName() == r.ReconciliationSubject.Spec.FailureDomainName {
+		if err := r.CSUser.ReconcileBastion(r.FailureDomain, r.ReconciliationSubject, r.CSCluster); err != nil {
+			return r.ReturnWrappedError(err, "reconciling bastion")
+		}
+		return ctrl.Result{}, nil
+	} else if r.hostsBastion() {
+		return r.DisposeBastion()
+	}
+	return ctrl.Result{}, nil
+}
+
+// hostsBastion checks whether the cluster's bastion is, or is to be, deployed into the isolated network.
+func (r *CloudStackIsoNetReconciliationRunner) hostsBastion() bool {
+	fdName := r.ReconciliationSubject.Spec.FailureDomainName
+	status := r.CSCluster.Status.Bastion
+	return r.CSCluster.BastionFailureDomainName() == fdName || (status != nil && status.FailureDomainName == fdName)
+}
+
+// DisposeBastion releases the bastion's public IP and destroys its VM.
+func (r *CloudStackIsoNetReconciliationRunner) DisposeBastion() (ctrl.Result, error) {
+	if err := r.CSUser.ReleaseBastionPublicIP(r.FailureDomain, r.CSCluster); err != nil {
+		return r.ReturnWrappedError(err, "releasing bastion public IP")
+	}
+	bastion := cloud.BastionMachine(r.CSCluster)
+	if err := r.CSUser.ResolveVMInstanceDetails(bastion); err != nil {
+		if !strings.Contains(strings.ToLower(err.Error()), "no match") {
+			return r.ReturnWrappedError(err, "resolving bastion VM")
+		}
+	} else if err := r.CSClient.DestroyVMInstance(bastion); err != nil { // Expunge as admin, as for CloudStackMachines.
+		if err.Error() == "VM deletion in progress" {
+			r.Log.Info(err.Error())
+			return ctrl.Result{RequeueAfter: csCtrlrUtils.DestoryVMRequeueInterval}, nil
+		}
+		return r.ReturnWrappedError(err, "destroying bastion VM")
+	}
+	r.CSCluster.Status.Bastion = nil
 	return ctrl.Result{}, nil
 }
 
 func (r *CloudStackIsoNetReconciliationRunner) ReconcileDelete() (retRes ctrl.Result, retErr error) {
 	r.Log.Info("Deleting IsolatedNetwork.")
+	if r.hostsBastion() {
+		if res, err := r.DisposeBastion(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+	}
 	if err := r.CSUser.DisposeIsoNetResources(r.FailureDomain, r.ReconciliationSubject, r.CSCluster); err != nil {
 		if !strings.Contains(strings.ToLower(err.Error()), "no match found") {
 			return ctrl.Result{}, err
@@ -108,5 +338,43 @@ func (r *CloudStackIsoNetReconciliationRunner) ReconcileDelete() (retRes ctrl.Re
 func (reconciler *CloudStackIsoNetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1.CloudStackIsolatedNetwork{}).
+		// Reconcile the cluster's isolated networks when the CloudStackCluster spec, e.g. its firewall policy, changes.
+		Watches(
+			&source.Kind{Type: &infrav1.CloudStackCluster{}},
+			handler.EnqueueRequestsFromMapFunc(reconciler.csClusterToIsoNets),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		// Keep workload load balancer rule membership up to date as worker machines come and go.
+		Watches(
+			&source.Kind{Type: &infrav1.CloudStackMachine{}},
+			handler.EnqueueRequestsFromMapFunc(reconciler.csClusterToIsoNets),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetLabels()[clusterv1.MachineDeploymentNameLabel] != ""
+			})),
+		).
+		Watches(
+			&source.Kind{Type: &clusterv1.MachineDeployment{}},
+			handler.EnqueueRequestsFromMapFunc(reconciler.csClusterToIsoNets),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
 		Complete(reconciler)
 }
+
+// csClusterToIsoNets maps a CloudStackCluster, or any other object labeled with a cluster name, to reconcile requests
+// for the cluster's isolated networks.
+func (reconciler *CloudStackIsoNetReconciler) csClusterToIsoNets(o client.Object) []reconcile.Request {
+	clusterName := o.GetLabels()[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		return nil
+	}
+	isoNets := &infrav1.CloudStackIsolatedNetworkList{}
+	if err := reconciler.K8sClient.List(context.Background(), isoNets, client.InNamespace(o.GetNamespace()),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(isoNets.Items))
+	for _, isoNet := range isoNets.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&isoNet)})
+	}
+	return requests
+}
@@ -22,6 +22,7 @@ import (
 	"math/rand"
 	"reflect"
 	"regexp"
+	"time"
 
 	"k8s.io/utils/pointer"
 
@@ -30,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,6 +40,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
@@ -62,12 +65,20 @@ const (
 	CSMachineStateCheckerCreationSuccess       = "CloudStackMachineStateChecker created"
 	CSMachineDeletionMessage                   = "Deleting CloudStack Machine %s"
 	CSMachineDeletionInstanceIDNotFoundMessage = "Deleting CloudStack Machine %s instanceID not found"
+	CSMachineRemovedFromLBMessage              = "Removed CloudStack Machine %s from load balancer rules, draining for %s"
+	MachineHibernatingMessage                  = "Stopping CloudStack instance for the cluster's hibernation"
+	MachineResumingMessage                     = "Starting CloudStack instance as the cluster resumes from hibernation"
+	MachineResumedMessage                      = "CloudStack instance resumed from hibernation"
+
+	// hibernationSkipRemediation is the value of the skip remediation annotation CAPC puts on CAPI machines while
+	// their cluster hibernates, telling it apart from annotations set by users.
+	hibernationSkipRemediation = "cloudstackcluster-hibernation"
 )
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackmachines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackmachines/finalizers,verbs=update
-// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinesets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
@@ -125,8 +136,11 @@ func (r *CloudStackMachineReconciliationRunner) Reconcile() (retRes ctrl.Result,
 			r.CheckPresent(map[string]client.Object{"CloudStackIsolatedNetwork": r.IsoNet})),
 		r.ConsiderAffinity,
 		r.GetOrCreateVMInstance,
+		r.ReconcileHibernation,
 		r.RequeueIfInstanceNotRunning,
+		r.ReconcileVMTags,
 		r.AddToLBIfNeeded,
+		r.ReserveControlPlaneVIPIfNeeded,
 		r.GetOrCreateMachineStateChecker,
 	)
 }
@@ -182,8 +196,12 @@ func (r *CloudStackMachineReconciliationRunner) SetFailureDomainOnCSMachine() (r
 			name = *r.CAPIMachine.Spec.FailureDomain
 			r.ReconciliationSubject.Spec.FailureDomainName = *r.CAPIMachine.Spec.FailureDomain
 		} else { // Not a control plane machine. Place randomly.
-			randNum := (rand.Int() % len(r.CSCluster.Spec.FailureDomains)) // #nosec G404 -- weak crypt rand doesn't matter here.
-			name = r.CSCluster.Spec.FailureDomains[randNum].Name
+			fds := r.CSCluster.FailureDomainSpecs()
+			if len(fds) == 0 {
+				return r.RequeueWithMessage("No failure domains discovered yet, requeueing.")
+			}
+			randNum := (rand.Int() % len(fds)) // #nosec G404 -- weak crypt rand doesn't matter here.
+			name = fds[randNum].Name
 		}
 		r.ReconciliationSubject.Spec.FailureDomainName = name
 		r.ReconciliationSubject.Labels[infrav1.FailureDomainLabelName] = infrav1.FailureDomainHashedMetaName(name, r.CAPICluster.Name)
@@ -197,8 +215,12 @@ func (r *CloudStackMachineReconciliationRunner) DeleteMachineIfFailuredomainNotE
 		return ctrl.Result{}, nil
 	}
 	capiAssignedFailuredomainName := *r.CAPIMachine.Spec.FailureDomain
+	fds := r.CSCluster.FailureDomainSpecs()
+	if len(fds) == 0 { // Failure domains are yet to be discovered.
+		return ctrl.Result{}, nil
+	}
 	exist := false
-	for _, fd := range r.CSCluster.Spec.FailureDomains {
+	for _, fd := range fds {
 		if capiAssignedFailuredomainName == fd.Name {
 			exist = true
 			break
@@ -272,6 +294,8 @@ func (r *CloudStackMachineReconciliationRunner) RequeueIfInstanceNotRunning() (r
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{RequeueAfter: utils.RequeueTimeout}, nil
+	} else if r.ReconciliationSubject.Status.Hibernated {
+		r.Log.Info("Instance is stopped while the cluster hibernates.", "instanceState", r.ReconciliationSubject.Status.InstanceState)
 	} else {
 		r.Recorder.Eventf(r.ReconciliationSubject, "Warning", r.ReconciliationSubject.Status.InstanceState, MachineNotReadyMessage, r.ReconciliationSubject.Status.InstanceState)
 		r.Log.Info(fmt.Sprintf(MachineNotReadyMessage, r.ReconciliationSubject.Status.InstanceState))
@@ -280,6 +304,147 @@ func (r *CloudStackMachineReconciliationRunner) RequeueIfInstanceNotRunning() (r
 	return ctrl.Result{}, nil
 }
 
+// ReconcileHibernation stops the machine's VM while its cluster hibernates and starts it again once the cluster
+// resumes. Workers are stopped before the control plane, which is started before the workers again. Machine health check
+// remediation is paused until the machine's node is healthy again.
+func (r *CloudStackMachineReconciliationRunner) ReconcileHibernation() (retRes ctrl.Result, reterr error) {
+	if r.CSCluster.Spec.Hibernate {
+		return r.hibernate()
+	} else if r.ReconciliationSubject.Status.Hibernated {
+		return r.resume()
+	}
+	return ctrl.Result{}, nil
+}
+
+// hibernate stops the machine's VM once no worker VM of the cluster runs anymore, if it is a control plane machine.
+// Remediation is only paused for machines being stopped, so that machines in Error state can still be replaced.
+func (r *CloudStackMachineReconciliationRunner) hibernate() (ctrl.Result, error) {
+	csMachine := r.ReconciliationSubject
+	switch csMachine.Status.InstanceState {
+	case "Stopped":
+		if err := r.setSkipRemediation(true); err != nil {
+			return r.ReturnWrappedError(err, "pausing remediation")
+		}
+		csMachine.Status.Hibernated = true
+		return ctrl.Result{}, nil
+	case "Error": // Left to RequeueIfInstanceNotRunning and remediation.
+		return ctrl.Result{}, nil
+	case "Running":
+	default:
+		return r.RequeueWithMessage("Waiting for the instance to settle before stopping it.", "instanceState", csMachine.Status.InstanceState)
+	}
+
+	if util.IsControlPlaneMachine(r.CAPIMachine) {
+		workers, err := r.listClusterMachines(false)
+		if err != nil {
+			return r.ReturnWrappedError(err, "listing worker machines")
+		}
+		for _, worker := range workers {
+			if worker.Status.InstanceState != "Stopped" && worker.Status.InstanceState != "Error" {
+				return r.RequeueWithMessage("Waiting for workers to stop before stopping the control plane.", "worker", worker.Name)
+			}
+		}
+	}
+
+	if err := r.setSkipRemediation(true); err != nil {
+		return r.ReturnWrappedError(err, "pausing remediation")
+	}
+	if err := r.CSUser.StopVMInstance(csMachine); err != nil {
+		return r.ReturnWrappedError(err, "stopping VM for hibernation")
+	}
+	csMachine.Status.Hibernated = true
+	r.Recorder.Event(csMachine, "Normal", "Hibernating", MachineHibernatingMessage)
+	return r.RequeueWithMessage(MachineHibernatingMessage + ".")
+}
+
+// resume starts the machine's VM once the cluster's control plane machines resumed, if it is a worker machine, and
+// resumes remediation once its node is healthy.
+func (r *CloudStackMachineReconciliationRunner) resume() (ctrl.Result, error) {
+	csMachine := r.ReconciliationSubject
+	switch csMachine.Status.InstanceState {
+	case "Running":
+		if !conditions.IsTrue(r.CAPIMachine, clusterv1.MachineNodeHealthyCondition) {
+			return r.RequeueWithMessage("Waiting for the node to become healthy after resuming.")
+		}
+		if err := r.setSkipRemediation(false); err != nil {
+			return r.ReturnWrappedError(err, "resuming remediation")
+		}
+		csMachine.Status.Hibernated = false
+		r.Recorder.Event(csMachine, "Normal", "Resumed", MachineResumedMessage)
+		return ctrl.Result{}, nil
+	case "Error": // Left to RequeueIfInstanceNotRunning.
+		return ctrl.Result{}, nil
+	case "Stopped":
+	default:
+		return r.RequeueWithMessage("Waiting for the instance to settle before starting it.", "instanceState", csMachine.Status.InstanceState)
+	}
+
+	if !util.IsControlPlaneMachine(r.CAPIMachine) {
+		controlPlane, err := r.listClusterMachines(true)
+		if err != nil {
+			return r.ReturnWrappedError(err, "listing control plane machines")
+		}
+		for _, machine := range controlPlane {
+			if machine.Status.Hibernated {
+				return r.RequeueWithMessage("Waiting for the control plane to resume before starting workers.", "controlPlaneMachine", machine.Name)
+			}
+		}
+	}
+
+	if err := r.CSUser.StartVMInstance(csMachine); err != nil {
+		return r.ReturnWrappedError(err, "starting VM after hibernation")
+	}
+	r.Recorder.Event(csMachine, "Normal", "Resuming", MachineResumingMessage)
+	return r.RequeueWithMessage(MachineResumingMessage + ".")
+}
+
+// listClusterMachines lists the cluster's other CloudStackMachines that aren't being deleted, either those of the
+// control plane or the workers.
+func (r *CloudStackMachineReconciliationRunner) listClusterMachines(controlPlane bool) ([]infrav1.CloudStackMachine, error) {
+	csMachines := &infrav1.CloudStackMachineList{}
+	if err := r.K8sClient.List(r.RequestCtx, csMachines, client.InNamespace(r.ReconciliationSubject.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: r.CAPICluster.Name}); err != nil {
+		return nil, err
+	}
+	machines := []infrav1.CloudStackMachine{}
+	for _, csMachine := range csMachines.Items {
+		// Control plane providers label the infrastructure machines of the control plane like its machines.
+		_, isControlPlane := csMachine.Labels[clusterv1.MachineControlPlaneLabel]
+		if csMachine.Name == r.ReconciliationSubject.Name || !csMachine.DeletionTimestamp.IsZero() || isControlPlane != controlPlane {
+			continue
+		}
+		machines = append(machines, csMachine)
+	}
+	return machines, nil
+}
+
+// setSkipRemediation adds or removes the annotation pausing machine health check remediation of the CAPI machine.
+// Annotations set by users are left alone.
+func (r *CloudStackMachineReconciliationRunner) setSkipRemediation(skip bool) error {
+	value, found := r.CAPIMachine.Annotations[clusterv1.MachineSkipRemediationAnnotation]
+	if skip == found || (found && value != hibernationSkipRemediation) {
+		return nil
+	}
+	patch := client.MergeFrom(r.CAPIMachine.DeepCopy())
+	if skip {
+		if r.CAPIMachine.Annotations == nil {
+			r.CAPIMachine.Annotations = map[string]string{}
+		}
+		r.CAPIMachine.Annotations[clusterv1.MachineSkipRemediationAnnotation] = hibernationSkipRemediation
+	} else {
+		delete(r.CAPIMachine.Annotations, clusterv1.MachineSkipRemediationAnnotation)
+	}
+	return r.K8sClient.Patch(r.RequestCtx, r.CAPIMachine, patch)
+}
+
+// ReconcileVMTags keeps the tags of the VM and its volumes in line with the cluster's and machine's additional tags.
+func (r *CloudStackMachineReconciliationRunner) ReconcileVMTags() (retRes ctrl.Result, reterr error) {
+	if err := r.CSUser.ReconcileVMTags(r.ReconciliationSubject, r.CSCluster); err != nil {
+		return r.ReturnWrappedError(err, "reconciling VM tags")
+	}
+	return ctrl.Result{}, nil
+}
+
 // AddToLBIfNeeded adds instance to load balancer if it is a control plane in an isolated network.
 func (r *CloudStackMachineReconciliationRunner) AddToLBIfNeeded() (retRes ctrl.Result, reterr error) {
 	if util.IsControlPlaneMachine(r.CAPIMachine) && r.FailureDomain.Spec.Zone.Network.Type == cloud.NetworkTypeIsolated {
@@ -295,6 +460,45 @@ func (r *CloudStackMachineReconciliationRunner) AddToLBIfNeeded() (retRes ctrl.R
 	return ctrl.Result{}, nil
 }
 
+// ReserveControlPlaneVIPIfNeeded reserves the control plane endpoint address CAPC allocated on a shared network on the
+// control plane machine's NIC, unless another control plane machine already holds it.
+func (r *CloudStackMachineReconciliationRunner) ReserveControlPlaneVIPIfNeeded() (retRes ctrl.Result, reterr error) {
+	vip := r.CSCluster.Status.ControlPlaneVIP
+	if !util.IsControlPlaneMachine(r.CAPIMachine) || vip == nil || vip.NetworkID != r.FailureDomain.Spec.Zone.Network.ID {
+		return ctrl.Result{}, nil
+	}
+	// Checking whether the address was taken requires an admin account, so use CSClient rather than CSUser.
+	err := r.CSClient.ReserveControlPlaneVIP(r.ReconciliationSubject, r.CSCluster)
+	if errors.Is(err, cloud.ErrControlPlaneVIPTaken) {
+		r.Recorder.Event(r.CSCluster, "Warning", "ControlPlaneVIPTaken", err.Error())
+	}
+	return ctrl.Result{}, err
+}
+
+// HandOverControlPlaneVIPIfNeeded moves the reservation of the control plane endpoint address CAPC allocated on a
+// shared network from the control plane machine being deleted to another control plane machine, so the address
+// isn't free while the control plane is rolled out.
+func (r *CloudStackMachineReconciliationRunner) HandOverControlPlaneVIPIfNeeded() (ctrl.Result, error) {
+	_, isControlPlane := r.ReconciliationSubject.Labels[clusterv1.MachineControlPlaneLabel]
+	if !isControlPlane || r.CSCluster.Status.ControlPlaneVIP == nil {
+		return ctrl.Result{}, nil
+	}
+	csMachines, err := r.listClusterMachines(true)
+	if err != nil {
+		return r.ReturnWrappedError(err, "listing control plane machines")
+	}
+	successorIDs := []string{}
+	for _, csMachine := range csMachines {
+		if csMachine.Spec.InstanceID != nil && *csMachine.Spec.InstanceID != "" {
+			successorIDs = append(successorIDs, *csMachine.Spec.InstanceID)
+		}
+	}
+	if err := r.CSUser.HandOverControlPlaneVIP(r.ReconciliationSubject, successorIDs, r.CSCluster); err != nil {
+		return r.ReturnWrappedError(err, "handing over the control plane endpoint address")
+	}
+	return ctrl.Result{}, nil
+}
+
 // GetOrCreateMachineStateChecker creates or gets CloudStackMachineStateChecker object.
 func (r *CloudStackMachineReconciliationRunner) GetOrCreateMachineStateChecker() (retRes ctrl.Result, reterr error) {
 	checkerName := r.ReconciliationSubject.Spec.InstanceID
@@ -312,6 +516,47 @@ func (r *CloudStackMachineReconciliationRunner) GetOrCreateMachineStateChecker()
 	return r.GetObjectByName(*checkerName, r.StateChecker)()
 }
 
+// RemoveFromLBIfNeeded takes the instance out of the isolated network's load balancer rules and requeues until the
+// cluster's drain period has passed, so clients aren't sent to a VM being destroyed. It doesn't depend on the CAPI
+// Machine, which may already be gone.
+func (r *CloudStackMachineReconciliationRunner) RemoveFromLBIfNeeded() (retRes ctrl.Result, reterr error) {
+	if r.FailureDomain.Spec.Zone.Network.Type != cloud.NetworkTypeIsolated {
+		return ctrl.Result{}, nil
+	}
+	if r.ReconciliationSubject.Status.RemovedFromLoadBalancerAt == nil {
+		if res, err := r.GetObjectByName("placeholder", r.IsoNet,
+			func() string { return r.IsoNetMetaName(r.FailureDomain.Spec.Zone.Network.Name) })(); r.ShouldReturn(res, err) {
+			return res, err
+		} else if r.IsoNet.Name == "" { // Nothing to remove the instance from.
+			return ctrl.Result{}, nil
+		}
+		removed, err := r.CSUser.RemoveVMFromLoadBalancerRules(r.IsoNet, *r.ReconciliationSubject.Spec.InstanceID)
+		if err != nil {
+			return r.ReturnWrappedError(err, "removing instance from load balancer rules")
+		} else if !removed {
+			return ctrl.Result{}, nil
+		}
+		now := metav1.Now()
+		r.ReconciliationSubject.Status.RemovedFromLoadBalancerAt = &now
+		r.Recorder.Eventf(r.ReconciliationSubject, "Normal", "Deleting", CSMachineRemovedFromLBMessage,
+			r.ReconciliationSubject.Name, r.lbDrainPeriod())
+	}
+
+	if remaining := time.Until(r.ReconciliationSubject.Status.RemovedFromLoadBalancerAt.Add(r.lbDrainPeriod())); remaining > 0 {
+		r.Log.Info("Draining instance before deletion.", "remaining", remaining)
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// lbDrainPeriod returns the cluster's load balancer drain period or the default.
+func (r *CloudStackMachineReconciliationRunner) lbDrainPeriod() time.Duration {
+	if period := r.CSCluster.Spec.LoadBalancerDrainPeriod; period != nil {
+		return period.Duration
+	}
+	return infrav1.DefaultLoadBalancerDrainPeriod
+}
+
 func (r *CloudStackMachineReconciliationRunner) ReconcileDelete() (retRes ctrl.Result, reterr error) {
 	if r.ReconciliationSubject.Spec.InstanceID == nil {
 		// InstanceID is not set until deploying VM finishes which can take minutes, and CloudStack Machine can be deleted before VM deployment complete.
@@ -328,6 +573,12 @@ func (r *CloudStackMachineReconciliationRunner) ReconcileDelete() (retRes ctrl.R
 			return ctrl.Result{}, err
 		}
 	}
+	if res, err := r.RemoveFromLBIfNeeded(); r.ShouldReturn(res, err) {
+		return res, err
+	}
+	if res, err := r.HandOverControlPlaneVIPIfNeeded(); r.ShouldReturn(res, err) {
+		return res, err
+	}
 	r.Recorder.Eventf(r.ReconciliationSubject, "Normal", "Deleting", CSMachineDeletionMessage, r.ReconciliationSubject.Name)
 	r.Log.Info("Deleting instance", "instance-id", r.ReconciliationSubject.Spec.InstanceID)
 	// Use CSClient instead of CSUser here to expunge as admin.
@@ -407,6 +658,37 @@ func (reconciler *CloudStackMachineReconciler) SetupWithManager(ctx context.Cont
 		return err
 	}
 
+	// Watch ACS endpoint secrets for credential rotations.
+	// Queues a reconcile request for the CloudStackMachines of the failure domains using a changed secret.
+	if err = controller.Watch(
+		&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(utils.ACSEndpointSecretToMachines(reconciler.K8sClient)),
+		utils.ACSEndpointSecretChanged(),
+	); err != nil {
+		return err
+	}
+
+	// Watch CloudStackClusters for changes to their additional tags and hibernation.
+	// Queues a reconcile request for the cluster's CloudStackMachines, so the tags of their VMs are updated.
+	if err = controller.Watch(
+		&source.Kind{Type: &infrav1.CloudStackCluster{}},
+		handler.EnqueueRequestsFromMapFunc(reconciler.csClusterToCSMachines),
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldCluster := e.ObjectOld.(*infrav1.CloudStackCluster)
+				newCluster := e.ObjectNew.(*infrav1.CloudStackCluster)
+
+				return !reflect.DeepEqual(oldCluster.Spec.AdditionalTags, newCluster.Spec.AdditionalTags) ||
+					oldCluster.Spec.Hibernate != newCluster.Spec.Hibernate
+			},
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		},
+	); err != nil {
+		return err
+	}
+
 	// Used below, this maps CAPI clusters to CAPC machines
 	csMachineMapper, err := util.ClusterToObjectsMapper(reconciler.K8sClient, &infrav1.CloudStackMachineList{}, mgr.GetScheme())
 	if err != nil {
@@ -421,3 +703,21 @@ func (reconciler *CloudStackMachineReconciler) SetupWithManager(ctx context.Cont
 		predicates.ClusterUnpausedAndInfrastructureReady(log),
 	)
 }
+
+// csClusterToCSMachines maps a CloudStackCluster to reconcile requests for the cluster's CloudStackMachines.
+func (reconciler *CloudStackMachineReconciler) csClusterToCSMachines(o client.Object) []reconcile.Request {
+	clusterName := o.GetLabels()[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		return nil
+	}
+	csMachines := &infrav1.CloudStackMachineList{}
+	if err := reconciler.K8sClient.List(context.Background(), csMachines, client.InNamespace(o.GetNamespace()),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(csMachines.Items))
+	for _, csMachine := range csMachines.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&csMachine)})
+	}
+	return requests
+}
@@ -64,6 +64,7 @@ var _ = Describe("CloudStackMachineReconciler", func() {
 				func(arg1, _, _, _, _, _ interface{}) {
 					arg1.(*infrav1.CloudStackMachine).Status.InstanceState = "Running"
 				}).AnyTimes()
+			mockCloudClient.EXPECT().ReconcileVMTags(gomock.Any(), gomock.Any()).AnyTimes()
 
 			// Have to do this here or the reconcile call to GetOrCreateVMInstance may happen too early.
 			setupMachineCRDs()
@@ -90,6 +91,7 @@ var _ = Describe("CloudStackMachineReconciler", func() {
 					arg1.(*infrav1.CloudStackMachine).Status.InstanceState = "Running"
 					controllerutil.AddFinalizer(arg1.(*infrav1.CloudStackMachine), infrav1.MachineFinalizer)
 				}).AnyTimes()
+			mockCloudClient.EXPECT().ReconcileVMTags(gomock.Any(), gomock.Any()).AnyTimes()
 
 			mockCloudClient.EXPECT().DestroyVMInstance(gomock.Any()).Times(1).Return(nil)
 			// Have to do this here or the reconcile call to GetOrCreateVMInstance may happen too early.
@@ -130,6 +132,7 @@ var _ = Describe("CloudStackMachineReconciler", func() {
 					arg1.(*infrav1.CloudStackMachine).Status.InstanceState = "Running"
 					controllerutil.AddFinalizer(arg1.(*infrav1.CloudStackMachine), infrav1.MachineFinalizer)
 				}).AnyTimes()
+			mockCloudClient.EXPECT().ReconcileVMTags(gomock.Any(), gomock.Any()).AnyTimes()
 
 			mockCloudClient.EXPECT().ResolveVMInstanceDetails(gomock.Any()).Do(
 				func(arg1 interface{}) {
@@ -180,6 +183,7 @@ var _ = Describe("CloudStackMachineReconciler", func() {
 					Ω(userdata == expectedUserdata).Should(BeTrue())
 					arg1.(*infrav1.CloudStackMachine).Status.InstanceState = "Running"
 				}).AnyTimes()
+			mockCloudClient.EXPECT().ReconcileVMTags(gomock.Any(), gomock.Any()).AnyTimes()
 
 			// Have to do this here or the reconcile call to GetOrCreateVMInstance may happen too early.
 			setupMachineCRDs()
@@ -224,6 +228,89 @@ var _ = Describe("CloudStackMachineReconciler", func() {
 			Ω(res.RequeueAfter).ShouldNot(BeZero())
 		})
 
+		It("Should stop the VM of a worker of a hibernating cluster and pause its remediation", func() {
+			key := client.ObjectKeyFromObject(dummies.CSCluster)
+			dummies.CAPIMachine.Name = "someMachine"
+			dummies.CAPIMachine.Spec.Bootstrap.DataSecretName = &dummies.BootstrapSecret.Name
+			dummies.CSMachine1.OwnerReferences = append(dummies.CSMachine1.OwnerReferences, metav1.OwnerReference{
+				Kind:       "Machine",
+				APIVersion: clusterv1.GroupVersion.String(),
+				Name:       dummies.CAPIMachine.Name,
+				UID:        "uniqueness",
+			})
+			mockCloudClient.EXPECT().GetOrCreateVMInstance(
+				gomock.Any(), gomock.Any(), gomock.Any(),
+				gomock.Any(), gomock.Any(), gomock.Any()).Do(
+				func(arg1, _, _, _, _, _ interface{}) {
+					arg1.(*infrav1.CloudStackMachine).Status.InstanceState = "Running"
+				}).AnyTimes()
+			mockCloudClient.EXPECT().StopVMInstance(gomock.Any()).Times(1)
+			Ω(fakeCtrlClient.Get(ctx, key, dummies.CSCluster)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.CAPIMachine)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.CSMachine1)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.ACSEndpointSecret1)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.BootstrapSecret)).Should(Succeed())
+
+			dummies.CSCluster.Spec.Hibernate = true
+			Ω(fakeCtrlClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+			setClusterReady(fakeCtrlClient)
+
+			requestNamespacedName := types.NamespacedName{Namespace: dummies.ClusterNameSpace, Name: dummies.CSMachine1.Name}
+			MachineReconciler.AsFailureDomainUser(&dummies.CSFailureDomain1.Spec)
+			res, err := MachineReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: requestNamespacedName})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(res.RequeueAfter).ShouldNot(BeZero())
+
+			csMachine := &infrav1.CloudStackMachine{}
+			Ω(fakeCtrlClient.Get(ctx, requestNamespacedName, csMachine)).Should(Succeed())
+			Ω(csMachine.Status.Hibernated).Should(BeTrue())
+			capiMachine := &clusterv1.Machine{}
+			Ω(fakeCtrlClient.Get(ctx, client.ObjectKeyFromObject(dummies.CAPIMachine), capiMachine)).Should(Succeed())
+			Ω(capiMachine.Annotations).Should(HaveKey(clusterv1.MachineSkipRemediationAnnotation))
+		})
+
+		It("Should neither stop nor pause the remediation of a machine in Error state of a hibernating cluster", func() {
+			key := client.ObjectKeyFromObject(dummies.CSCluster)
+			dummies.CAPIMachine.Name = "someMachine"
+			dummies.CAPIMachine.Spec.Bootstrap.DataSecretName = &dummies.BootstrapSecret.Name
+			dummies.CSMachine1.OwnerReferences = append(dummies.CSMachine1.OwnerReferences, metav1.OwnerReference{
+				Kind:       "Machine",
+				APIVersion: clusterv1.GroupVersion.String(),
+				Name:       dummies.CAPIMachine.Name,
+				UID:        "uniqueness",
+			})
+			mockCloudClient.EXPECT().GetOrCreateVMInstance(
+				gomock.Any(), gomock.Any(), gomock.Any(),
+				gomock.Any(), gomock.Any(), gomock.Any()).Do(
+				func(arg1, _, _, _, _, _ interface{}) {
+					arg1.(*infrav1.CloudStackMachine).Status.InstanceState = "Error"
+				}).AnyTimes()
+			mockCloudClient.EXPECT().StopVMInstance(gomock.Any()).Times(0)
+			Ω(fakeCtrlClient.Get(ctx, key, dummies.CSCluster)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.CAPIMachine)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.CSMachine1)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.ACSEndpointSecret1)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.BootstrapSecret)).Should(Succeed())
+
+			dummies.CSCluster.Spec.Hibernate = true
+			Ω(fakeCtrlClient.Update(ctx, dummies.CSCluster)).Should(Succeed())
+			setClusterReady(fakeCtrlClient)
+
+			requestNamespacedName := types.NamespacedName{Namespace: dummies.ClusterNameSpace, Name: dummies.CSMachine1.Name}
+			MachineReconciler.AsFailureDomainUser(&dummies.CSFailureDomain1.Spec)
+			_, err := MachineReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: requestNamespacedName})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			csMachine := &infrav1.CloudStackMachine{}
+			Ω(fakeCtrlClient.Get(ctx, requestNamespacedName, csMachine)).Should(Succeed())
+			Ω(csMachine.Status.Hibernated).Should(BeFalse())
+			capiMachine := &clusterv1.Machine{}
+			err = fakeCtrlClient.Get(ctx, client.ObjectKeyFromObject(dummies.CAPIMachine), capiMachine)
+			Ω(errors.IsNotFound(err)).Should(BeTrue())
+		})
+
 		It("Should create event Machine instance is Running", func() {
 			key := client.ObjectKeyFromObject(dummies.CSCluster)
 			dummies.CAPIMachine.Name = "someMachine"
@@ -240,6 +327,7 @@ var _ = Describe("CloudStackMachineReconciler", func() {
 				func(arg1, _, _, _, _, _ interface{}) {
 					arg1.(*infrav1.CloudStackMachine).Status.InstanceState = "Running"
 				}).AnyTimes()
+			mockCloudClient.EXPECT().ReconcileVMTags(gomock.Any(), gomock.Any()).AnyTimes()
 			Ω(fakeCtrlClient.Get(ctx, key, dummies.CSCluster)).Should(Succeed())
 			Ω(fakeCtrlClient.Create(ctx, dummies.CAPIMachine)).Should(Succeed())
 			Ω(fakeCtrlClient.Create(ctx, dummies.CSMachine1)).Should(Succeed())
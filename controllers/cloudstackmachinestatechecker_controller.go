@@ -90,7 +90,10 @@ func (r *CloudStackMachineStateCheckerReconciliationRunner) Reconcile() (ctrl.Re
 			capiRunning := r.CAPIMachine.Status.Phase == "Running"
 			capiTimeout := csRunning && !capiRunning && csTimeInState > 5*time.Minute
 
-			if csRunning && capiRunning {
+			// Machines of hibernating clusters are stopped on purpose.
+			hibernated := r.CSCluster.Spec.Hibernate || r.CSMachine.Status.Hibernated
+
+			if (csRunning && capiRunning) || hibernated {
 				r.ReconciliationSubject.Status.Ready = true
 			} else if !csRunning || capiTimeout {
 				r.Log.Info("CloudStack instance in bad state",
@@ -129,6 +129,7 @@ var (
 	FailureDomainReconciler *csReconcilers.CloudStackFailureDomainReconciler
 	IsoNetReconciler        *csReconcilers.CloudStackIsoNetReconciler
 	AffinityGReconciler     *csReconcilers.CloudStackAffinityGroupReconciler
+	MDQuotaReconciler       *csReconcilers.MachineDeploymentQuotaReconciler
 )
 
 var _ = BeforeSuite(func() {
@@ -207,6 +208,7 @@ func SetupTestEnvironment() {
 	Ω(k8sClient).ShouldNot(BeNil())
 	k8sManager, _ = ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme.Scheme})
 	Ω(err).ShouldNot(HaveOccurred())
+	Ω(csCtrlrUtils.SetupACSEndpointSecretIndexes(context.Background(), k8sManager)).Should(Succeed())
 
 	// Base reconciler shared across reconcilers.
 	base := csCtrlrUtils.ReconcilerBase{
@@ -223,6 +225,7 @@ func SetupTestEnvironment() {
 	FailureDomainReconciler = &csReconcilers.CloudStackFailureDomainReconciler{ReconcilerBase: base}
 	IsoNetReconciler = &csReconcilers.CloudStackIsoNetReconciler{ReconcilerBase: base}
 	AffinityGReconciler = &csReconcilers.CloudStackAffinityGroupReconciler{ReconcilerBase: base}
+	MDQuotaReconciler = &csReconcilers.MachineDeploymentQuotaReconciler{ReconcilerBase: base}
 
 	ctx, cancel = context.WithCancel(context.TODO())
 
@@ -235,6 +238,7 @@ func SetupTestEnvironment() {
 	IsoNetReconciler.CSClient = mockCloudClient
 	MachineReconciler.CSClient = mockCloudClient
 	AffinityGReconciler.CSClient = mockCloudClient
+	MDQuotaReconciler.CSClient = mockCloudClient
 	FailureDomainReconciler.CSClient = mockCloudClient
 
 	setupClusterCRDs()
@@ -280,6 +284,7 @@ func setupFakeTestClient() {
 	FailureDomainReconciler = &csReconcilers.CloudStackFailureDomainReconciler{ReconcilerBase: base}
 	IsoNetReconciler = &csReconcilers.CloudStackIsoNetReconciler{ReconcilerBase: base}
 	AffinityGReconciler = &csReconcilers.CloudStackAffinityGroupReconciler{ReconcilerBase: base}
+	MDQuotaReconciler = &csReconcilers.MachineDeploymentQuotaReconciler{ReconcilerBase: base}
 
 	// Set on reconcilers. The mock client wasn't available at suite startup, so set it now.
 	ClusterReconciler.CSClient = mockCloudClient
@@ -287,6 +292,7 @@ func setupFakeTestClient() {
 	MachineReconciler.CSClient = mockCloudClient
 	FailureDomainReconciler.CSClient = mockCloudClient
 	AffinityGReconciler.CSClient = mockCloudClient
+	MDQuotaReconciler.CSClient = mockCloudClient
 
 	DeferCleanup(func() {
 		cancel()
@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	MachineDeploymentQuotaExceeded = "Scaling up by %d machines exceeds CloudStack limits in failure domain %s: %s"
+)
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=cloudstackmachinetemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// MachineDeploymentQuotaReconciliationRunner is a ReconciliationRunner with extensions specific to checking
+// MachineDeployment scale-ups against CloudStack resource limits.
+type MachineDeploymentQuotaReconciliationRunner struct {
+	*utils.ReconciliationRunner
+	ReconciliationSubject *clusterv1.MachineDeployment
+	CSMachineTemplate     *infrav1.CloudStackMachineTemplate
+}
+
+// MachineDeploymentQuotaReconciler warns when a MachineDeployment scale-up exceeds CloudStack resource limits.
+// It only warns: the replica change has already been accepted by the time it runs, and CAPI goes on to create the
+// Machines, which fail to deploy once the limits are reached.
+type MachineDeploymentQuotaReconciler struct {
+	utils.ReconcilerBase
+}
+
+// Initialize a new MachineDeploymentQuota reconciliation runner with concrete types and initialized member fields.
+func NewMachineDeploymentQuotaReconciliationRunner() *MachineDeploymentQuotaReconciliationRunner {
+	// Set concrete type and init pointers.
+	r := &MachineDeploymentQuotaReconciliationRunner{ReconciliationSubject: &clusterv1.MachineDeployment{}}
+	r.CSMachineTemplate = &infrav1.CloudStackMachineTemplate{}
+	// Setup the base runner. Initializes pointers and links reconciliation methods.
+	r.ReconciliationRunner = utils.NewRunner(r, r.ReconciliationSubject, "MachineDeploymentQuota")
+	return r
+}
+
+func (reconciler *MachineDeploymentQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return NewMachineDeploymentQuotaReconciliationRunner().
+		UsingBaseReconciler(reconciler.ReconcilerBase).
+		ForRequest(req).
+		WithRequestCtx(ctx).
+		ReadOnly(). // MachineDeployments belong to CAPI.
+		RunBaseReconciliationStages()
+}
+
+func (r *MachineDeploymentQuotaReconciliationRunner) Reconcile() (ctrl.Result, error) {
+	return r.RunReconciliationStages(
+		r.GetObjectByName("placeholder", r.CSMachineTemplate,
+			func() string { return r.ReconciliationSubject.Spec.Template.Spec.InfrastructureRef.Name }),
+		r.CheckPresent(map[string]client.Object{"CloudStackMachineTemplate": r.CSMachineTemplate}),
+		r.CheckScaleUpLimits,
+	)
+}
+
+// CheckScaleUpLimits compares the resources needed by the machines being added with the limits left in each failure
+// domain they may be placed in, and records a warning event on the MachineDeployment if they can't be fulfilled.
+// Machines not pinned to a failure domain are assumed to spread evenly across the cluster's failure domains.
+// Machines that already have an instance are left out of the demand as CloudStack already counts them against the
+// limits. Those still being created aren't, so they are counted as part of the scale-up.
+func (r *MachineDeploymentQuotaReconciliationRunner) CheckScaleUpLimits() (ctrl.Result, error) {
+	if r.ReconciliationSubject.Spec.Replicas == nil {
+		return ctrl.Result{}, nil
+	}
+	machines := &clusterv1.MachineList{}
+	if err := r.K8sClient.List(r.RequestCtx, machines,
+		client.InNamespace(r.ReconciliationSubject.Namespace),
+		client.MatchingLabels{clusterv1.MachineDeploymentNameLabel: r.ReconciliationSubject.Name},
+	); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "listing machines of MachineDeployment")
+	}
+	deployed := int64(0)
+	for _, machine := range machines.Items {
+		if machine.DeletionTimestamp.IsZero() && machine.Spec.ProviderID != nil && *machine.Spec.ProviderID != "" {
+			deployed++
+		}
+	}
+	scaleUp := int64(*r.ReconciliationSubject.Spec.Replicas) - deployed
+	if scaleUp < 1 {
+		return ctrl.Result{}, nil
+	}
+
+	fdNames := []string{}
+	if fdName := r.ReconciliationSubject.Spec.Template.Spec.FailureDomain; fdName != nil && *fdName != "" {
+		fdNames = append(fdNames, *fdName)
+	} else {
+		for _, fdSpec := range r.CSCluster.FailureDomainSpecs() {
+			fdNames = append(fdNames, fdSpec.Name)
+		}
+	}
+	if len(fdNames) == 0 {
+		return ctrl.Result{}, nil
+	}
+	perFailureDomain := (scaleUp + int64(len(fdNames)) - 1) / int64(len(fdNames))
+
+	csMachine := &infrav1.CloudStackMachine{Spec: r.CSMachineTemplate.Spec.Template.Spec}
+	for _, fdName := range fdNames {
+		name := fdName
+		fd := &infrav1.CloudStackFailureDomain{}
+		if res, err := r.GetFailureDomainByName(func() string { return name }, fd)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+		if res, err := r.AsFailureDomainUser(&fd.Spec)(); r.ShouldReturn(res, err) {
+			return res, err
+		}
+		if err := r.CSUser.CheckScaleUpLimits(fd, csMachine, perFailureDomain); err != nil {
+			r.Recorder.Eventf(r.ReconciliationSubject, "Warning", "InsufficientQuota", MachineDeploymentQuotaExceeded, scaleUp, name, err.Error())
+			r.Log.Info(fmt.Sprintf(MachineDeploymentQuotaExceeded, scaleUp, name, err.Error()))
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *MachineDeploymentQuotaReconciliationRunner) ReconcileDelete() (ctrl.Result, error) {
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (reconciler *MachineDeploymentQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clusterv1.MachineDeployment{}).
+		WithEventFilter(predicate.And(
+			// Replica changes bump the generation, status updates don't.
+			predicate.GenerationChangedPredicate{},
+			// Only consider MachineDeployments whose machines are CloudStackMachines.
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				md, ok := obj.(*clusterv1.MachineDeployment)
+				return ok && md.Spec.Template.Spec.InfrastructureRef.Kind == "CloudStackMachineTemplate" &&
+					md.Spec.Template.Spec.InfrastructureRef.GroupVersionKind().Group == infrav1.GroupVersion.Group
+			}),
+		)).
+		Complete(reconciler)
+}
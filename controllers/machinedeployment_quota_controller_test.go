@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	csReconcilers "sigs.k8s.io/cluster-api-provider-cloudstack/controllers"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("MachineDeploymentQuotaReconciler", func() {
+	Context("With a fake ctrlRuntimeClient and no test Env at all.", func() {
+		var md *clusterv1.MachineDeployment
+
+		BeforeEach(func() {
+			setupFakeTestClient()
+			md = &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-md",
+					Namespace: dummies.ClusterNameSpace,
+					Labels:    dummies.ClusterLabel,
+				},
+				Spec: clusterv1.MachineDeploymentSpec{
+					ClusterName: dummies.ClusterName,
+					Replicas:    pointer.Int32(5),
+					Template: clusterv1.MachineTemplateSpec{
+						Spec: clusterv1.MachineSpec{
+							ClusterName:   dummies.ClusterName,
+							FailureDomain: pointer.String(dummies.CSFailureDomain1.Spec.Name),
+							InfrastructureRef: corev1.ObjectReference{
+								APIVersion: dummies.CSMachineTemplate1.APIVersion,
+								Kind:       dummies.CSMachineTemplate1.Kind,
+								Name:       dummies.CSMachineTemplate1.Name,
+							},
+						},
+					},
+				},
+			}
+			Ω(fakeCtrlClient.Create(ctx, dummies.CSFailureDomain1)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, dummies.CSMachineTemplate1)).Should(Succeed())
+			Ω(fakeCtrlClient.Create(ctx, md)).Should(Succeed())
+		})
+
+		// createMachine creates a Machine of the MachineDeployment, deployed if it has a provider ID.
+		createMachine := func(name string, providerID *string) {
+			Ω(fakeCtrlClient.Create(ctx, &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: dummies.ClusterNameSpace,
+					Labels: map[string]string{
+						clusterv1.ClusterNameLabel:           dummies.ClusterName,
+						clusterv1.MachineDeploymentNameLabel: md.Name,
+					},
+				},
+				Spec: clusterv1.MachineSpec{ClusterName: dummies.ClusterName, ProviderID: providerID},
+			})).Should(Succeed())
+		}
+
+		reconcile := func() {
+			requestNamespacedName := types.NamespacedName{Namespace: md.Namespace, Name: md.Name}
+			_, err := MDQuotaReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: requestNamespacedName})
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+
+		It("Should warn when the machines yet to be deployed exceed the limits.", func() {
+			createMachine("deployed", pointer.String("cloudstack:///deployed"))
+			createMachine("deploying", nil)
+
+			// Only the deployed machine already counts against the limits.
+			mockCloudClient.EXPECT().CheckScaleUpLimits(gomock.Any(), gomock.Any(), int64(4)).
+				Return(fmt.Errorf("VM available (3) in account can't fulfil the scale-up requirement of 4 machines: 4"))
+
+			reconcile()
+			Ω(fakeRecorder.Events).Should(Receive(ContainSubstring(
+				fmt.Sprintf(csReconcilers.MachineDeploymentQuotaExceeded, 4, dummies.CSFailureDomain1.Spec.Name, ""))))
+		})
+
+		It("Should not check the limits when all machines are deployed.", func() {
+			md.Spec.Replicas = pointer.Int32(1)
+			Ω(fakeCtrlClient.Update(ctx, md)).Should(Succeed())
+			createMachine("deployed", pointer.String("cloudstack:///deployed"))
+
+			reconcile()
+			Ω(fakeRecorder.Events).ShouldNot(Receive())
+		})
+	})
+})
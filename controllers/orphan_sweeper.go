@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/metrics"
+)
+
+const (
+	OrphanedResourceFound   = "Found %s left behind by deleted clusters"
+	OrphanedResourceDeleted = "Deleted %s left behind by deleted clusters"
+	OrphanDeletionFailed    = "Failed to delete %s left behind by deleted clusters: %s"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// OrphanSweeper periodically looks for resources CAPC created for clusters that no longer exist at each ACS endpoint
+// failure domains use. Orphans are reported through the acs_orphaned_resources metric and events on the endpoint's
+// Secret, and deleted once they've been orphaned for the grace period if deletion is enabled and this management
+// cluster created them. The grace period is tracked in memory, so it starts over when the manager restarts.
+type OrphanSweeper struct {
+	utils.ReconcilerBase
+	Interval      time.Duration
+	GracePeriod   time.Duration
+	DeleteOrphans bool
+
+	metrics metrics.ACSCustomMetrics
+	// orphanedSince records when each orphan of an endpoint was first found. Endpoints are kept across sweeps, so
+	// they're still swept once the failure domains using them are gone.
+	orphanedSince map[acsEndpoint]map[string]time.Time
+}
+
+// acsEndpoint is an ACS endpoint Secret and the project failure domains use it in.
+type acsEndpoint struct {
+	Secret  types.NamespacedName
+	Project string
+}
+
+func (e acsEndpoint) String() string {
+	if e.Project == "" {
+		return e.Secret.String()
+	}
+	return e.Secret.String() + "/" + e.Project
+}
+
+// NeedLeaderElection makes only the leading manager sweep.
+func (s *OrphanSweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Start sweeps every interval until the context is done.
+func (s *OrphanSweeper) Start(ctx context.Context) error {
+	s.metrics = metrics.NewCustomMetrics()
+	s.orphanedSince = map[acsEndpoint]map[string]time.Time{}
+	wait.UntilWithContext(ctx, s.Sweep, s.Interval)
+	return nil
+}
+
+// Sweep looks for orphans at every known ACS endpoint.
+func (s *OrphanSweeper) Sweep(ctx context.Context) {
+	log := s.BaseLogger.WithName("OrphanSweeper")
+	live, err := s.liveClusters(ctx)
+	if err != nil {
+		log.Error(err, "listing clusters")
+		return
+	}
+	if err := s.discoverEndpoints(ctx); err != nil {
+		log.Error(err, "listing ACS endpoints")
+		return
+	}
+	for endpoint := range s.orphanedSince {
+		if err := s.sweepEndpoint(ctx, endpoint, live); err != nil {
+			log.Error(err, "sweeping orphaned resources", "endpoint", endpoint.String())
+		}
+	}
+}
+
+// liveClusters returns all CloudStackClusters and Clusters. CAPC tags resources with the UID of the former and the
+// name of the latter, and names affinity groups after the latter.
+func (s *OrphanSweeper) liveClusters(ctx context.Context) (*cloud.LiveClusters, error) {
+	live := cloud.NewLiveClusters()
+	csClusters := &infrav1.CloudStackClusterList{}
+	if err := s.K8sClient.List(ctx, csClusters); err != nil {
+		return nil, errors.Wrap(err, "listing CloudStackClusters")
+	}
+	for _, csCluster := range csClusters.Items {
+		name := csCluster.Labels[clusterv1.ClusterNameLabel]
+		if name == "" {
+			name = csCluster.Name
+		}
+		live.Add(string(csCluster.UID), csCluster.Namespace, name)
+	}
+	capiClusters := &clusterv1.ClusterList{}
+	if err := s.K8sClient.List(ctx, capiClusters); err != nil {
+		return nil, errors.Wrap(err, "listing Clusters")
+	}
+	for _, capiCluster := range capiClusters.Items {
+		live.Add(string(capiCluster.UID), capiCluster.Namespace, capiCluster.Name)
+	}
+	return live, nil
+}
+
+// discoverEndpoints adds the ACS endpoints failure domains currently use to those swept.
+func (s *OrphanSweeper) discoverEndpoints(ctx context.Context) error {
+	fds := &infrav1.CloudStackFailureDomainList{}
+	if err := s.K8sClient.List(ctx, fds); err != nil {
+		return errors.Wrap(err, "listing CloudStackFailureDomains")
+	}
+	for _, fd := range fds.Items {
+		secretRef, err := utils.ResolveACSEndpointSecretRef(ctx, s.K8sClient, fd.Namespace, fd.Spec)
+		if err != nil {
+			continue
+		}
+		endpoint := acsEndpoint{
+			Secret:  types.NamespacedName{Namespace: secretRef.Namespace, Name: secretRef.Name},
+			Project: fd.Spec.Project,
+		}
+		if _, found := s.orphanedSince[endpoint]; !found {
+			s.orphanedSince[endpoint] = map[string]time.Time{}
+		}
+	}
+	return nil
+}
+
+// sweepEndpoint reports the orphans found at an ACS endpoint, and deletes those this management cluster created that
+// have been orphaned for the grace period if deletion is enabled. Endpoints whose Secret is gone are no longer swept.
+func (s *OrphanSweeper) sweepEndpoint(ctx context.Context, endpoint acsEndpoint, live *cloud.LiveClusters) error {
+	secret := &corev1.Secret{}
+	if err := s.K8sClient.Get(ctx, endpoint.Secret, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			delete(s.orphanedSince, endpoint)
+			s.metrics.ResetOrphanedResources(endpoint.String())
+			return nil
+		}
+		return errors.Wrapf(err, "getting ACS endpoint secret %s", endpoint.Secret)
+	}
+	csClient, err := utils.NewACSEndpointClient(ctx, s.K8sClient, secret, endpoint.Project)
+	if err != nil {
+		return errors.Wrapf(err, "parsing ACS endpoint secret %s", endpoint.Secret)
+	}
+	resources, err := csClient.ListCAPCResources()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	since := map[string]time.Time{}
+	orphans := map[cloud.ResourceType][]cloud.CAPCResource{}
+	for _, resource := range resources {
+		if !resource.Orphaned(live) {
+			continue
+		}
+		key := string(resource.Type) + "/" + resource.ID
+		if firstFound, found := s.orphanedSince[endpoint][key]; found {
+			since[key] = firstFound
+		} else {
+			since[key] = now
+			s.Recorder.Eventf(secret, corev1.EventTypeWarning, "OrphanedResource", OrphanedResourceFound, describe(resource))
+		}
+		orphans[resource.Type] = append(orphans[resource.Type], resource)
+	}
+	s.orphanedSince[endpoint] = since
+
+	s.metrics.ResetOrphanedResources(endpoint.String())
+	for _, rType := range cloud.CAPCResourceDeletionOrder {
+		s.metrics.SetOrphanedResources(endpoint.String(), string(rType), len(orphans[rType]))
+	}
+	if !s.DeleteOrphans {
+		return nil
+	}
+
+	for _, rType := range cloud.CAPCResourceDeletionOrder {
+		for _, resource := range orphans[rType] {
+			if !resource.Deletable() || now.Sub(since[string(rType)+"/"+resource.ID]) < s.GracePeriod {
+				continue
+			}
+			err := csClient.DeleteCAPCResource(resource)
+			s.metrics.IncrementOrphanDeletionCounter(endpoint.String(), string(rType), err)
+			if err != nil {
+				s.Recorder.Eventf(secret, corev1.EventTypeWarning, "OrphanDeletionFailed", OrphanDeletionFailed,
+					describe(resource), err.Error())
+				continue
+			}
+			s.Recorder.Eventf(secret, corev1.EventTypeNormal, "OrphanDeleted", OrphanedResourceDeleted, describe(resource))
+		}
+	}
+	return nil
+}
+
+// describe names a resource in events.
+func describe(resource cloud.CAPCResource) string {
+	if resource.Name == "" {
+		return fmt.Sprintf("%s with ID %s", resource.Type, resource.ID)
+	}
+	return fmt.Sprintf("%s %s with ID %s", resource.Type, resource.Name, resource.ID)
+}
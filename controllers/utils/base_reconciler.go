@@ -71,6 +71,7 @@ type ReconciliationRunner struct {
 	ReconciliationSubject  client.Object // Underlying crd interface.
 	ConditionalResult      bool          // Stores a conidtinal result for stringing if else type methods.
 	returnEarly            bool          // A signal that the reconcile should return early.
+	readOnly               bool          // A signal that the reconciliation subject must not be patched.
 	additionalCommonStages []CloudStackReconcilerMethod
 	ReconcileDelete        CloudStackReconcilerMethod
 	Reconcile              CloudStackReconcilerMethod
@@ -120,6 +121,12 @@ func (r *ReconciliationRunner) WithRequestCtx(ctx context.Context) *Reconciliati
 	return r
 }
 
+// ReadOnly keeps the runner from patching the reconciliation subject, for subjects owned by other controllers.
+func (r *ReconciliationRunner) ReadOnly() *ReconciliationRunner {
+	r.readOnly = true
+	return r
+}
+
 // WithAdditionalCommonStages adds reconciliation stages to the base set of reconciliation stages ran before both
 // Reconcile() and ReconcileDelete().
 func (r *ReconciliationRunner) WithAdditionalCommonStages(fns ...CloudStackReconcilerMethod) *ReconciliationRunner {
@@ -400,7 +407,7 @@ func (r *ReconciliationRunner) RunBaseReconciliationStages() (res ctrl.Result, r
 	baseStages := []CloudStackReconcilerMethod{
 		r.SetupLogger,
 		r.GetReconciliationSubject,
-		r.SetupPatcher,
+		r.RunIf(func() bool { return !r.readOnly }, r.SetupPatcher),
 		r.GetCAPICluster,
 		r.GetCSCluster,
 		r.RunIf(func() bool { return r.ReconciliationSubject.GetDeletionTimestamp().IsZero() }, r.RequeueIfMissingBaseCRs),
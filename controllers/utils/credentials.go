@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResolveACSEndpointSecretRef returns the reference of the ACS endpoint secret holding the credentials of a failure
+// domain of a cluster in the namespace, failing if the namespace isn't allowed to use them. Failure domains can only
+// reference the secrets of their cluster's namespace directly, leaving identities as the only way to share credentials
+// across namespaces.
+func ResolveACSEndpointSecretRef(
+	ctx context.Context, reader client.Reader, namespace string, fdSpec infrav1.CloudStackFailureDomainSpec,
+) (corev1.SecretReference, error) {
+	if fdSpec.IdentityRef == nil {
+		secretRef := fdSpec.ACSEndpoint
+		if secretRef.Namespace != namespace {
+			return corev1.SecretReference{}, errors.Errorf(
+				"ACS endpoint secret %s/%s is outside of namespace %s, use a %s instead",
+				secretRef.Namespace, secretRef.Name, namespace, infrav1.CloudStackClusterIdentityKind)
+		}
+		return secretRef, nil
+	}
+
+	identity := &infrav1.CloudStackClusterIdentity{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: fdSpec.IdentityRef.Name}, identity); err != nil {
+		return corev1.SecretReference{}, errors.Wrapf(err, "getting %s %s",
+			infrav1.CloudStackClusterIdentityKind, fdSpec.IdentityRef.Name)
+	}
+	ns := &corev1.Namespace{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return corev1.SecretReference{}, errors.Wrapf(err, "getting namespace %s", namespace)
+	}
+	if allowed, err := identity.AllowsNamespace(ns); err != nil {
+		return corev1.SecretReference{}, errors.Wrapf(err, "selecting the allowed namespaces of %s %s",
+			infrav1.CloudStackClusterIdentityKind, identity.Name)
+	} else if !allowed {
+		return corev1.SecretReference{}, errors.Errorf("namespace %s is not allowed to use %s %s",
+			namespace, infrav1.CloudStackClusterIdentityKind, identity.Name)
+	}
+	return identity.Spec.SecretRef, nil
+}
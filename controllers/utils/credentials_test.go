@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ResolveACSEndpointSecretRef", func() {
+	var (
+		identity  *infrav1.CloudStackClusterIdentity
+		namespace *corev1.Namespace
+		fdSpec    infrav1.CloudStackFailureDomainSpec
+	)
+
+	BeforeEach(func() {
+		identity = &infrav1.CloudStackClusterIdentity{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"},
+			Spec: infrav1.CloudStackClusterIdentitySpec{
+				SecretRef: corev1.SecretReference{Name: "tenant-a-credentials", Namespace: "capc-system"},
+			},
+		}
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a", Labels: map[string]string{"tenant": "a"}}}
+		fdSpec = infrav1.CloudStackFailureDomainSpec{Name: "fd1",
+			IdentityRef: &infrav1.CloudStackIdentityReference{Kind: infrav1.CloudStackClusterIdentityKind, Name: "tenant-a"}}
+	})
+
+	// resolve resolves the secret reference of the failure domain with a client reading the identity and namespace as
+	// the test left them.
+	resolve := func(clusterNamespace string) (corev1.SecretReference, error) {
+		scheme := runtime.NewScheme()
+		Ω(clientgoscheme.AddToScheme(scheme)).Should(Succeed())
+		Ω(infrav1.AddToScheme(scheme)).Should(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(identity, namespace).Build()
+		return utils.ResolveACSEndpointSecretRef(context.Background(), reader, clusterNamespace, fdSpec)
+	}
+
+	It("Should resolve the secret of an identity the namespace is allowed to use", func() {
+		identity.Spec.AllowedNamespaces = &infrav1.AllowedNamespaces{NamespaceList: []string{"tenant-a"}}
+		ref, err := resolve("tenant-a")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ref).Should(Equal(identity.Spec.SecretRef))
+	})
+
+	It("Should reject an identity the namespace isn't allowed to use", func() {
+		_, err := resolve("tenant-a")
+		Ω(err).Should(MatchError(ContainSubstring("not allowed")))
+	})
+
+	It("Should reject a missing identity", func() {
+		fdSpec.IdentityRef.Name = "tenant-b"
+		_, err := resolve("tenant-a")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("Should reject references to secrets of other namespaces", func() {
+		fdSpec.IdentityRef = nil
+		fdSpec.ACSEndpoint = corev1.SecretReference{Name: "credentials", Namespace: "default"}
+		_, err := resolve("tenant-a")
+		Ω(err).Should(MatchError(ContainSubstring("outside of namespace tenant-a")))
+		Ω(resolve("default")).
+			Should(Equal(fdSpec.ACSEndpoint))
+	})
+
+	It("Should reject references to secrets held by an identity from other namespaces", func() {
+		fdSpec.IdentityRef = nil
+		fdSpec.ACSEndpoint = identity.Spec.SecretRef
+		_, err := resolve("tenant-b")
+		Ω(err).Should(MatchError(ContainSubstring("outside of namespace tenant-b")))
+		Ω(resolve("capc-system")).
+			Should(Equal(fdSpec.ACSEndpoint))
+	})
+})
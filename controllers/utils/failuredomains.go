@@ -17,7 +17,9 @@ limitations under the License.
 package utils
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +30,9 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -48,6 +53,8 @@ func (r *ReconciliationRunner) CreateFailureDomains(fdSpecs []infrav1.CloudStack
 			if err := r.CreateFailureDomain(fdSpec); err != nil {
 				if !strings.Contains(strings.ToLower(err.Error()), "already exists") {
 					return reconcile.Result{}, errors.Wrap(err, "creating CloudStackFailureDomains")
+				} else if err := r.UpdateFailureDomainCredentials(fdSpec); err != nil {
+					return reconcile.Result{}, err
 				}
 			}
 		}
@@ -55,6 +62,27 @@ func (r *ReconciliationRunner) CreateFailureDomains(fdSpecs []infrav1.CloudStack
 	}
 }
 
+// UpdateFailureDomainCredentials updates the credentials of an existing CloudStackFailureDomain CRD to those of the
+// spec, the only part of a failure domain that may change.
+func (r *ReconciliationRunner) UpdateFailureDomainCredentials(fdSpec infrav1.CloudStackFailureDomainSpec) error {
+	csFD := &infrav1.CloudStackFailureDomain{}
+	key := client.ObjectKey{
+		Namespace: r.Request.Namespace, Name: infrav1.FailureDomainHashedMetaName(fdSpec.Name, r.CAPICluster.Name)}
+	if err := r.K8sClient.Get(r.RequestCtx, key, csFD); err != nil {
+		return errors.Wrapf(err, "getting CloudStackFailureDomain %s", fdSpec.Name)
+	}
+	if csFD.Spec.ACSEndpoint == fdSpec.ACSEndpoint && reflect.DeepEqual(csFD.Spec.IdentityRef, fdSpec.IdentityRef) &&
+		csFD.Spec.Account == fdSpec.Account && csFD.Spec.Domain == fdSpec.Domain {
+		return nil
+	}
+	csFD.Spec.ACSEndpoint = fdSpec.ACSEndpoint
+	csFD.Spec.IdentityRef = fdSpec.IdentityRef
+	csFD.Spec.Account = fdSpec.Account
+	csFD.Spec.Domain = fdSpec.Domain
+	r.Log.Info("Updating credentials of failure domain.", "failureDomain", fdSpec.Name)
+	return errors.Wrapf(r.K8sClient.Update(r.RequestCtx, csFD), "updating credentials of CloudStackFailureDomain %s", fdSpec.Name)
+}
+
 // GetFailureDomains gets CloudStackFailureDomains owned by a CloudStackCluster.
 func (r *ReconciliationRunner) GetFailureDomains(fds *infrav1.CloudStackFailureDomainList) CloudStackReconcilerMethod {
 	return func() (ctrl.Result, error) {
@@ -87,7 +115,7 @@ func (r *ReconciliationRunner) RemoveExtraneousFailureDomains(fds *infrav1.Cloud
 	return func() (ctrl.Result, error) {
 		// Toss together a precense map.
 		fdPresenceByName := map[string]bool{}
-		for _, fdSpec := range r.CSCluster.Spec.FailureDomains {
+		for _, fdSpec := range r.CSCluster.FailureDomainSpecs() {
 			name := fdSpec.Name
 			fdPresenceByName[name] = true
 		}
@@ -136,31 +164,220 @@ func (c *CloudClientImplementation) RegisterExtension(r *ReconciliationRunner) C
 // AsFailureDomainUser uses the credentials specified in the failure domain to set the ReconciliationSubject's CSUser client.
 func (c *CloudClientImplementation) AsFailureDomainUser(fdSpec *infrav1.CloudStackFailureDomainSpec) CloudStackReconcilerMethod {
 	return func() (ctrl.Result, error) {
-		endpointCredentials := &corev1.Secret{}
-		key := client.ObjectKey{Name: fdSpec.ACSEndpoint.Name, Namespace: fdSpec.ACSEndpoint.Namespace}
-		if err := c.K8sClient.Get(c.RequestCtx, key, endpointCredentials); err != nil {
-			return ctrl.Result{}, errors.Wrapf(err, "getting ACSEndpoint secret with ref: %v", fdSpec.ACSEndpoint)
+		var err error
+		if c.CSClient, c.CSUser, err = NewFailureDomainClients(c.RequestCtx, c.K8sClient, c.Request.Namespace, fdSpec); err != nil {
+			return ctrl.Result{}, err
 		}
+		return ctrl.Result{}, nil
+	}
+}
 
-		clientConfig := &corev1.ConfigMap{}
-		key = client.ObjectKey{Name: cloud.ClientConfigMapName, Namespace: cloud.ClientConfigMapNamespace}
-		_ = c.K8sClient.Get(c.RequestCtx, key, clientConfig)
+// NewFailureDomainClients creates a client with the credentials of the ACS endpoint Secret of a failure domain of a
+// cluster in the namespace, and a client acting as the failure domain's account. The latter is the former when the
+// failure domain has no account. The namespace must be allowed to use the credentials.
+func NewFailureDomainClients(
+	ctx context.Context, k8sClient client.Client, namespace string, fdSpec *infrav1.CloudStackFailureDomainSpec,
+) (csClient cloud.Client, csUser cloud.Client, retErr error) {
+	secretRef, err := ResolveACSEndpointSecretRef(ctx, k8sClient, namespace, *fdSpec)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "resolving credentials of failure domain %s", fdSpec.Name)
+	}
+	endpointCredentials := &corev1.Secret{}
+	key := client.ObjectKey{Name: secretRef.Name, Namespace: secretRef.Namespace}
+	if err := k8sClient.Get(ctx, key, endpointCredentials); err != nil {
+		return nil, nil, errors.Wrapf(err, "getting ACSEndpoint secret with ref: %v", secretRef)
+	}
 
-		var err error
-		if c.CSClient, err = cloud.NewClientFromK8sSecret(endpointCredentials, clientConfig, fdSpec.Project); err != nil {
-			return ctrl.Result{}, errors.Wrapf(err, "parsing ACSEndpoint secret with ref: %v", fdSpec.ACSEndpoint)
+	if csClient, err = NewACSEndpointClient(ctx, k8sClient, endpointCredentials, fdSpec.Project); err != nil {
+		return nil, nil, errors.Wrapf(err, "parsing ACSEndpoint secret with ref: %v", secretRef)
+	}
+
+	if fdSpec.Account == "" { // Use the endpoint's client since Account & Domain weren't provided.
+		return csClient, csClient, nil
+	}
+	// Create a client per Account and Domain.
+	if csUser, err = csClient.NewClientInDomainAndAccount(fdSpec.Domain, fdSpec.Account, fdSpec.Project); err != nil {
+		return nil, nil, err
+	}
+	return csClient, csUser, nil
+}
+
+// NewACSEndpointClient creates a client with the credentials of an ACS endpoint Secret, scoped to the project if any.
+func NewACSEndpointClient(
+	ctx context.Context, k8sClient client.Client, endpointCredentials *corev1.Secret, project string,
+) (cloud.Client, error) {
+	clientConfig := &corev1.ConfigMap{}
+	key := client.ObjectKey{Name: cloud.ClientConfigMapName, Namespace: cloud.ClientConfigMapNamespace}
+	_ = k8sClient.Get(ctx, key, clientConfig)
+
+	return cloud.NewClientFromK8sSecret(endpointCredentials, clientConfig, project)
+}
+
+// ResolveFailureDomainAccount returns the ID of the CloudStack account a failure domain of a cluster in the namespace
+// acts as with its credentials. The CloudStack calls don't take a context, so the ID is given up on once the context
+// is done.
+func ResolveFailureDomainAccount(
+	ctx context.Context, k8sClient client.Client, namespace string, fdSpec infrav1.CloudStackFailureDomainSpec,
+) (string, error) {
+	type result struct {
+		accountID string
+		err       error
+	}
+	resolved := make(chan result, 1)
+	go func() {
+		_, csUser, err := NewFailureDomainClients(ctx, k8sClient, namespace, &fdSpec)
+		if err != nil {
+			resolved <- result{err: err}
+			return
+		}
+		resolved <- result{accountID: csUser.GetUser().Account.ID}
+	}()
+	select {
+	case <-ctx.Done():
+		return "", errors.Wrap(ctx.Err(), "resolving the account of the failure domain credentials")
+	case r := <-resolved:
+		return r.accountID, r.err
+	}
+}
+
+const (
+	// ACSEndpointSecretField indexes failure domains by the ACS endpoint Secret they reference directly.
+	ACSEndpointSecretField = "spec.acsEndpoint"
+	// IdentitySecretField indexes CloudStackClusterIdentities by their Secret.
+	IdentitySecretField = "spec.secretRef"
+	// IdentityRefField indexes failure domains by the CloudStackClusterIdentity they reference.
+	IdentityRefField = "spec.identityRef"
+)
+
+// SetupACSEndpointSecretIndexes indexes failure domains and CloudStackClusterIdentities by the ACS endpoint Secrets
+// they use, so the changes of a Secret are mapped to the failure domains using it without listing them all.
+func SetupACSEndpointSecretIndexes(ctx context.Context, mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+	if err := indexer.IndexField(ctx, &infrav1.CloudStackFailureDomain{}, ACSEndpointSecretField,
+		func(o client.Object) []string {
+			fd := o.(*infrav1.CloudStackFailureDomain)
+			if fd.Spec.IdentityRef != nil {
+				return nil
+			}
+			return []string{secretIndexKey(fd.Spec.ACSEndpoint.Namespace, fd.Spec.ACSEndpoint.Name)}
+		}); err != nil {
+		return errors.Wrap(err, "indexing CloudStackFailureDomains by ACS endpoint secret")
+	}
+	if err := indexer.IndexField(ctx, &infrav1.CloudStackFailureDomain{}, IdentityRefField,
+		func(o client.Object) []string {
+			if ref := o.(*infrav1.CloudStackFailureDomain).Spec.IdentityRef; ref != nil {
+				return []string{ref.Name}
+			}
+			return nil
+		}); err != nil {
+		return errors.Wrap(err, "indexing CloudStackFailureDomains by identity")
+	}
+	if err := indexer.IndexField(ctx, &infrav1.CloudStackClusterIdentity{}, IdentitySecretField,
+		func(o client.Object) []string {
+			ref := o.(*infrav1.CloudStackClusterIdentity).Spec.SecretRef
+			return []string{secretIndexKey(ref.Namespace, ref.Name)}
+		}); err != nil {
+		return errors.Wrap(err, "indexing CloudStackClusterIdentities by secret")
+	}
+	return nil
+}
+
+// secretIndexKey is the index key of a Secret.
+func secretIndexKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// ACSEndpointSecretToFailureDomains maps an ACS endpoint Secret to reconcile requests for the failure domains using it.
+// The stale clients created from the Secret are evicted when the failure domains are reconciled.
+func ACSEndpointSecretToFailureDomains(k8sClient client.Client) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		fds, err := failureDomainsUsingSecret(k8sClient, o)
+		if err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(fds))
+		for _, fd := range fds {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&fd)})
 		}
+		return requests
+	}
+}
 
-		if fdSpec.Account != "" { // Set r.CSUser CloudStack Client per Account and Domain.
-			client, err := c.CSClient.NewClientInDomainAndAccount(fdSpec.Domain, fdSpec.Account, fdSpec.Project)
-			if err != nil {
-				return ctrl.Result{}, err
+// ACSEndpointSecretToMachines maps an ACS endpoint Secret to reconcile requests for the CloudStackMachines placed in
+// the failure domains using it.
+func ACSEndpointSecretToMachines(k8sClient client.Client) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		fds, err := failureDomainsUsingSecret(k8sClient, o)
+		if err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, fd := range fds {
+			machines := &infrav1.CloudStackMachineList{}
+			if err := k8sClient.List(context.Background(), machines, client.InNamespace(fd.Namespace),
+				client.MatchingLabels{clusterv1.ClusterNameLabel: fd.Labels[clusterv1.ClusterNameLabel]}); err != nil {
+				return nil
+			}
+			for _, machine := range machines.Items {
+				if machine.Spec.FailureDomainName == fd.Spec.Name {
+					requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&machine)})
+				}
 			}
-			c.CSUser = client
-		} else { // Set r.CSUser CloudStack Client to r.CSClient since Account & Domain weren't provided.
-			c.CSUser = c.CSClient
 		}
+		return requests
+	}
+}
 
-		return ctrl.Result{}, nil
+// ACSEndpointSecretChanged filters the Secret events to the changes of their data, leaving out resyncs and metadata
+// updates.
+func ACSEndpointSecretChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSecret, okOld := e.ObjectOld.(*corev1.Secret)
+			newSecret, okNew := e.ObjectNew.(*corev1.Secret)
+			return okOld && okNew && !reflect.DeepEqual(oldSecret.Data, newSecret.Data)
+		},
+		CreateFunc:  func(event.CreateEvent) bool { return true },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// failureDomainsUsingSecret lists the failure domains using the ACS endpoint Secret, directly or through a
+// CloudStackClusterIdentity, from the indexes set up by SetupACSEndpointSecretIndexes.
+func failureDomainsUsingSecret(k8sClient client.Client, secret client.Object) ([]infrav1.CloudStackFailureDomain, error) {
+	key := secretIndexKey(secret.GetNamespace(), secret.GetName())
+	fds := &infrav1.CloudStackFailureDomainList{}
+	if err := k8sClient.List(context.Background(), fds, client.MatchingFields{ACSEndpointSecretField: key}); err != nil {
+		return nil, err
+	}
+	using := fds.Items
+
+	identities := &infrav1.CloudStackClusterIdentityList{}
+	if err := k8sClient.List(context.Background(), identities, client.MatchingFields{IdentitySecretField: key}); err != nil {
+		return nil, err
+	}
+	for _, identity := range identities.Items {
+		fds := &infrav1.CloudStackFailureDomainList{}
+		if err := k8sClient.List(context.Background(), fds, client.MatchingFields{IdentityRefField: identity.Name}); err != nil {
+			return nil, err
+		}
+		using = append(using, fds.Items...)
+	}
+	return using, nil
+}
+
+// IdentityToFailureDomains maps a CloudStackClusterIdentity to reconcile requests for the failure domains using it.
+func IdentityToFailureDomains(k8sClient client.Client) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		fds := &infrav1.CloudStackFailureDomainList{}
+		if err := k8sClient.List(context.Background(), fds, client.MatchingFields{IdentityRefField: o.GetName()}); err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(fds.Items))
+		for _, fd := range fds.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&fd)})
+		}
+		return requests
 	}
 }
@@ -28,17 +28,27 @@ func (r *ReconciliationRunner) IsoNetMetaName(name string) string {
 	return fmt.Sprintf("%s-%s", r.CSCluster.Name, strings.ToLower(name))
 }
 
-// GenerateIsolatedNetwork of the passed name that's owned by the ReconciliationSubject.
-func (r *ReconciliationRunner) GenerateIsolatedNetwork(name string, fdNameFunc func() string) CloudStackReconcilerMethod {
+// GenerateIsolatedNetwork of the passed network specification that's owned by the ReconciliationSubject.
+func (r *ReconciliationRunner) GenerateIsolatedNetwork(net infrav1.Network, fdNameFunc func() string) CloudStackReconcilerMethod {
 	return func() (ctrl.Result, error) {
-		lowerName := strings.ToLower(name)
+		lowerName := strings.ToLower(net.Name)
 		metaName := fmt.Sprintf("%s-%s", r.CSCluster.Name, lowerName)
 		csIsoNet := &infrav1.CloudStackIsolatedNetwork{}
 		csIsoNet.ObjectMeta = r.NewChildObjectMeta(metaName)
 		csIsoNet.Spec.Name = lowerName
+		csIsoNet.Spec.Tags = net.Tags
 		csIsoNet.Spec.FailureDomainName = fdNameFunc()
-		csIsoNet.Spec.ControlPlaneEndpoint.Host = r.CSCluster.Spec.ControlPlaneEndpoint.Host
+		if r.CSCluster.ControlPlaneEndpointName() == "" { // Each network gets a public IP of its own otherwise.
+			csIsoNet.Spec.ControlPlaneEndpoint.Host = r.CSCluster.Spec.ControlPlaneEndpoint.Host
+		}
 		csIsoNet.Spec.ControlPlaneEndpoint.Port = r.CSCluster.Spec.ControlPlaneEndpoint.Port
+		csIsoNet.Spec.Offering = net.Offering
+		csIsoNet.Spec.CIDR = net.CIDR
+		csIsoNet.Spec.Gateway = net.Gateway
+		csIsoNet.Spec.Netmask = net.Netmask
+		csIsoNet.Spec.NetworkDomain = net.NetworkDomain
+		csIsoNet.Spec.DNS = net.DNS
+		csIsoNet.Spec.IPv6DNS = net.IPv6DNS
 
 		if err := r.K8sClient.Create(r.RequestCtx, csIsoNet); err != nil && !ContainsAlreadyExistsSubstring(err) {
 			return r.ReturnWrappedError(err, "creating isolated network CRD")
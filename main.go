@@ -37,10 +37,13 @@ import (
 	logsv1 "k8s.io/component-base/logs/api/v1"
 	"sigs.k8s.io/cluster-api/util/flags"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -52,6 +55,7 @@ import (
 	infrav1b3 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/controllers"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/controllers/utils"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -84,6 +88,11 @@ type managerOpts struct {
 	WatchFilterValue     string
 	CertDir              string
 
+	ManagementClusterID       string
+	OrphanSweepInterval       time.Duration
+	OrphanDeletionGracePeriod time.Duration
+	DeleteOrphans             bool
+
 	CloudStackClusterConcurrency       int
 	CloudStackMachineConcurrency       int
 	CloudStackAffinityGroupConcurrency int
@@ -132,6 +141,28 @@ func setFlags() *managerOpts {
 		"webhook-cert-dir",
 		"/tmp/k8s-webhook-server/serving-certs/",
 		"Specify the directory where webhooks will get tls certificates.")
+	flag.StringVar(
+		&opts.ManagementClusterID,
+		"management-cluster-id",
+		"",
+		"Identifies this management cluster on the CloudStack resources CAPC creates, so orphans are only deleted by "+
+			"the management cluster that created them. Defaults to the UID of the kube-system namespace.")
+	flag.DurationVar(
+		&opts.OrphanSweepInterval,
+		"orphan-sweep-interval",
+		time.Hour,
+		"How often to look for CloudStack resources CAPC created for clusters that no longer exist. 0 disables the sweeps.")
+	flag.BoolVar(
+		&opts.DeleteOrphans,
+		"delete-orphans",
+		false,
+		"Delete the CloudStack resources left behind by deleted clusters that sweeps find. "+
+			"Only resources tagged with this management cluster's ID are deleted.")
+	flag.DurationVar(
+		&opts.OrphanDeletionGracePeriod,
+		"orphan-deletion-grace-period",
+		24*time.Hour,
+		"How long a resource has to be left behind for before it's deleted.")
 	flag.IntVar(
 		&opts.CloudStackClusterConcurrency,
 		"cloudstackcluster-concurrency",
@@ -210,8 +241,31 @@ func main() {
 	}
 
 	ctx := ctrl.SetupSignalHandler()
+	if err := utils.SetupACSEndpointSecretIndexes(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to set up field indexes")
+		os.Exit(1)
+	}
+	if opts.ManagementClusterID == "" {
+		opts.ManagementClusterID, err = kubeSystemNamespaceUID(ctx, mgr.GetAPIReader())
+		if err != nil {
+			setupLog.Error(err, "unable to identify the management cluster")
+			os.Exit(1)
+		}
+	}
+	cloud.ManagementClusterID = opts.ManagementClusterID
 	setupReconcilers(ctx, base, *opts, mgr)
 	infrav1b3.K8sClient = base.K8sClient
+	infrav1b3.EventRecorder = base.Recorder
+	infrav1b3.ACSEndpointSecretRefResolver = func(
+		ctx context.Context, namespace string, fdSpec infrav1b3.CloudStackFailureDomainSpec,
+	) (corev1.SecretReference, error) {
+		return utils.ResolveACSEndpointSecretRef(ctx, mgr.GetAPIReader(), namespace, fdSpec)
+	}
+	infrav1b3.FailureDomainAccountResolver = func(
+		ctx context.Context, namespace string, fdSpec infrav1b3.CloudStackFailureDomainSpec,
+	) (string, error) {
+		return utils.ResolveFailureDomainAccount(ctx, base.K8sClient, namespace, fdSpec)
+	}
 
 	// +kubebuilder:scaffold:builder
 
@@ -238,6 +292,14 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "CloudStackMachineTemplate")
 		os.Exit(1)
 	}
+	if err = (&infrav1b3.CloudStackClusterTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "CloudStackClusterTemplate")
+		os.Exit(1)
+	}
+	if err = (&infrav1b3.CloudStackFailureDomain{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "CloudStackFailureDomain")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
@@ -267,4 +329,29 @@ func setupReconcilers(ctx context.Context, base utils.ReconcilerBase, opts manag
 		setupLog.Error(err, "unable to create controller", "controller", "CloudStackFailureDomain")
 		os.Exit(1)
 	}
+	if err := (&controllers.MachineDeploymentQuotaReconciler{ReconcilerBase: base}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MachineDeploymentQuota")
+		os.Exit(1)
+	}
+	if opts.OrphanSweepInterval > 0 {
+		if err := mgr.Add(&controllers.OrphanSweeper{
+			ReconcilerBase: base,
+			Interval:       opts.OrphanSweepInterval,
+			GracePeriod:    opts.OrphanDeletionGracePeriod,
+			DeleteOrphans:  opts.DeleteOrphans,
+		}); err != nil {
+			setupLog.Error(err, "unable to create runnable", "runnable", "OrphanSweeper")
+			os.Exit(1)
+		}
+	}
+}
+
+// kubeSystemNamespaceUID returns the UID of the kube-system namespace, which identifies the management cluster for as
+// long as it exists.
+func kubeSystemNamespaceUID(ctx context.Context, reader client.Reader) (string, error) {
+	ns := &corev1.Namespace{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: metav1.NamespaceSystem}, ns); err != nil {
+		return "", fmt.Errorf("getting namespace %s: %w", metav1.NamespaceSystem, err)
+	}
+	return string(ns.UID), nil
 }
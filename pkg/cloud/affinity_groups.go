@@ -17,6 +17,8 @@ limitations under the License.
 package cloud
 
 import (
+	"fmt"
+
 	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
@@ -25,12 +27,27 @@ import (
 const (
 	AntiAffinityGroupType = "host anti-affinity"
 	AffinityGroupType     = "host affinity"
+	// ExplicitDedicationAffinityGroupType is the type of the affinity groups CloudStack creates when dedicating
+	// resources to an account or domain.
+	ExplicitDedicationAffinityGroupType = "ExplicitDedication"
 )
 
 type AffinityGroup struct {
 	Type string
 	Name string
 	ID   string
+	// Description is set on groups CAPC creates. Affinity groups can't be tagged, so it records the cluster owning them.
+	Description string
+}
+
+// AffinityGroupDescription describes an affinity group CAPC creates for a cluster, recording the cluster and the
+// management cluster owning it.
+func AffinityGroupDescription(clusterNamespace, clusterName, clusterUID string) string {
+	description := fmt.Sprintf("Created by CAPC for cluster %s/%s (%s)", clusterNamespace, clusterName, clusterUID)
+	if ManagementClusterID != "" {
+		description += " of management cluster " + ManagementClusterID
+	}
+	return description
 }
 
 type AffinityGroupIface interface {
@@ -79,6 +96,7 @@ func (c *client) GetOrCreateAffinityGroup(group *AffinityGroup) (retErr error) {
 	if err := c.FetchAffinityGroup(group); err != nil { // Group not found?
 		p := c.cs.AffinityGroup.NewCreateAffinityGroupParams(group.Name, group.Type)
 		p.SetName(group.Name)
+		setIfNotEmpty(group.Description, p.SetDescription)
 		setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
 		resp, err := c.cs.AffinityGroup.CreateAffinityGroup(p)
 		if err != nil {
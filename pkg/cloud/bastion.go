@@ -0,0 +1,258 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+type BastionIface interface {
+	ReconcileBastion(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster) error
+	ReleaseBastionPublicIP(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackCluster) error
+}
+
+// BastionSSHPort is the port the bastion's SSH daemon listens on.
+const BastionSSHPort = 22
+
+// BastionMachine returns a CloudStackMachine standing in for the cluster's bastion VM, so the bastion can be deployed,
+// resolved and destroyed like any other machine.
+func BastionMachine(csCluster *infrav1.CloudStackCluster) *infrav1.CloudStackMachine {
+	csMachine := &infrav1.CloudStackMachine{ObjectMeta: metav1.ObjectMeta{
+		Name:      csCluster.Name + "-bastion",
+		Namespace: csCluster.Namespace,
+	}}
+	if bastion := csCluster.Spec.Bastion; bastion != nil {
+		csMachine.Spec.Offering = bastion.Offering
+		csMachine.Spec.Template = bastion.Template
+		csMachine.Spec.SSHKey = bastion.SSHKey
+	}
+	if status := csCluster.Status.Bastion; status != nil && status.InstanceID != "" {
+		csMachine.Spec.InstanceID = pointer.String(status.InstanceID)
+	}
+	return csMachine
+}
+
+func bastionPublicPort(bastion *infrav1.Bastion) int {
+	if bastion.PublicPort != 0 {
+		return bastion.PublicPort
+	}
+	return BastionSSHPort
+}
+
+// ReconcileBastion deploys the cluster's bastion into the isolated network and forwards a public IP of its own to it.
+// The bastion's addresses are published in the CloudStackCluster's status.
+func (c *client) ReconcileBastion(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) error {
+	status := csCluster.Status.Bastion
+	if status == nil || status.FailureDomainName != fd.Spec.Name {
+		status = &infrav1.BastionStatus{FailureDomainName: fd.Spec.Name}
+		csCluster.Status.Bastion = status
+	}
+
+	csMachine := BastionMachine(csCluster)
+	if err := c.ResolveVMInstanceDetails(csMachine); err != nil {
+		if !strings.Contains(strings.ToLower(err.Error()), "no match") {
+			return errors.Wrap(err, "resolving bastion VM")
+		}
+		if err := c.deployBastion(fd, isoNet, csCluster, csMachine); err != nil {
+			return err
+		}
+		if err := c.ResolveVMInstanceDetails(csMachine); err != nil {
+			return errors.Wrap(err, "resolving bastion VM")
+		}
+	}
+	status.InstanceID = *csMachine.Spec.InstanceID
+	status.PrivateIP = csMachine.Status.Addresses[0].Address
+
+	publicAddress, err := c.getOrAssociateBastionPublicIP(fd, isoNet, csCluster)
+	if err != nil {
+		return err
+	}
+	status.PublicIPID = publicAddress.Id
+	status.PublicIP = publicAddress.Ipaddress
+
+	if err := c.reconcileBastionPortForwardingRule(isoNet, csCluster, status.PublicIPID, status.InstanceID); err != nil {
+		return err
+	}
+	status.PublicPort = bastionPublicPort(csCluster.Spec.Bastion)
+	return nil
+}
+
+// deployBastion deploys the bastion VM into the isolated network.
+func (c *client) deployBastion(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+	csMachine *infrav1.CloudStackMachine,
+) error {
+	offering, err := c.ResolveServiceOffering(csMachine, fd.Spec.Zone.ID)
+	if err != nil {
+		return errors.Wrap(err, "resolving bastion offering")
+	}
+	templateID, err := c.ResolveTemplate(csCluster, csMachine, fd.Spec.Zone.ID)
+	if err != nil {
+		return errors.Wrap(err, "resolving bastion template")
+	}
+
+	p := c.cs.VirtualMachine.NewDeployVirtualMachineParams(offering.Id, templateID, fd.Spec.Zone.ID)
+	p.SetNetworkids([]string{isoNet.Spec.ID})
+	p.SetName(csMachine.Name)
+	setIfNotEmpty(csMachine.Spec.SSHKey, p.SetKeypair)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.VirtualMachine.DeployVirtualMachine(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "deploying bastion VM %s", csMachine.Name)
+	}
+	return c.tagVMResources(resp.Id, machineResourceTags(csCluster, csMachine, MachineRoleBastion))
+}
+
+// findBastionPublicIP finds the public IP CAPC tagged as the cluster's bastion IP. Returns nil if there is none.
+func (c *client) findBastionPublicIP(
+	fd *infrav1.CloudStackFailureDomain,
+	csCluster *infrav1.CloudStackCluster,
+) (*cloudstack.PublicIpAddress, error) {
+	p := c.cs.Address.NewListPublicIpAddressesParams()
+	p.SetZoneid(fd.Spec.Zone.ID)
+	p.SetTags(map[string]string{generateBastionTagName(csCluster): "1"})
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Address.ListPublicIpAddresses(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing bastion public IP addresses")
+	} else if resp.Count == 0 {
+		return nil, nil
+	}
+	return resp.PublicIpAddresses[0], nil
+}
+
+// getOrAssociateBastionPublicIP gets the bastion's public IP, associating the configured address, or one CloudStack
+// picks, to the isolated network or its VPC if the bastion has none yet.
+func (c *client) getOrAssociateBastionPublicIP(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) (*cloudstack.PublicIpAddress, error) {
+	if publicAddress, err := c.findBastionPublicIP(fd, csCluster); err != nil || publicAddress != nil {
+		return publicAddress, err
+	}
+
+	bastionTag := map[string]string{generateBastionTagName(csCluster): "1"}
+	if ipAddress := csCluster.Spec.Bastion.PublicIPAddress; ipAddress != "" {
+		publicIPID, err := c.getOrAssociateDedicatedPublicIP(fd, isoNet, csCluster, ipAddress)
+		if err != nil {
+			return nil, err
+		}
+		return &cloudstack.PublicIpAddress{Id: publicIPID, Ipaddress: ipAddress}, errors.Wrapf(
+			c.AddTags(ResourceTypeIPAddress, publicIPID, bastionTag), "adding tag to public IP address with ID %s", publicIPID)
+	}
+
+	p := c.cs.Address.NewAssociateIpAddressParams()
+	if isoNet.Status.VPCID != "" {
+		p.SetVpcid(isoNet.Status.VPCID)
+	} else {
+		p.SetNetworkid(isoNet.Spec.ID)
+	}
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Address.AssociateIpAddress(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrapf(err, "associating a public IP address to network with ID %s", isoNet.Spec.ID)
+	}
+	if err := c.AddTags(ResourceTypeIPAddress, resp.Id, bastionTag); err != nil {
+		return nil, errors.Wrapf(err, "adding tag to public IP address with ID %s", resp.Id)
+	} else if err := c.AddCreatedByCAPCTag(ResourceTypeIPAddress, resp.Id); err != nil {
+		return nil, errors.Wrapf(err, "adding tag to public IP address with ID %s", resp.Id)
+	} else if err := c.AddClusterTag(ResourceTypeIPAddress, resp.Id, csCluster); err != nil {
+		return nil, errors.Wrapf(err, "adding tag to public IP address with ID %s", resp.Id)
+	}
+	return &cloudstack.PublicIpAddress{Id: resp.Id, Ipaddress: resp.Ipaddress}, nil
+}
+
+// reconcileBastionPortForwardingRule forwards the bastion's public port to its SSH port. Any other port forwarding
+// rule on the bastion's public IP is deleted.
+func (c *client) reconcileBastionPortForwardingRule(
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+	publicIPID string,
+	instanceID string,
+) error {
+	bastion := csCluster.Spec.Bastion
+	publicPort := bastionPublicPort(bastion)
+	// CloudStack only opens the firewall of isolated networks. VPC tiers are governed by their network ACL.
+	isVPC := isoNet.Status.VPCID != ""
+
+	p := c.cs.Firewall.NewListPortForwardingRulesParams()
+	p.SetIpaddressid(publicIPID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Firewall.ListPortForwardingRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing port forwarding rules of public IP with ID %s", publicIPID)
+	}
+	found := false
+	for _, rule := range resp.PortForwardingRules {
+		if !found && rule.Publicport == strconv.Itoa(publicPort) && rule.Privateport == strconv.Itoa(BastionSSHPort) &&
+			rule.Virtualmachineid == instanceID && (isVPC ||
+			newFirewallRule("", 0, 0, splitCIDRList(rule.Cidrlist)) == newFirewallRule("", 0, 0, bastion.AllowedCIDRs)) {
+			found = true
+			continue
+		}
+		if _, err := c.cs.Firewall.DeletePortForwardingRule(c.cs.Firewall.NewDeletePortForwardingRuleParams(rule.Id)); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "deleting port forwarding rule with ID %s", rule.Id)
+		}
+	}
+	if found {
+		return nil
+	}
+
+	cp := c.cs.Firewall.NewCreatePortForwardingRuleParams(publicIPID, BastionSSHPort, NetworkProtocolTCP, publicPort, instanceID)
+	cp.SetNetworkid(isoNet.Spec.ID)
+	cp.SetOpenfirewall(!isVPC)
+	if !isVPC {
+		cp.SetCidrlist(bastion.AllowedCIDRs)
+	}
+	if _, err := c.cs.Firewall.CreatePortForwardingRule(cp); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "creating port forwarding rule for bastion VM with ID %s", instanceID)
+	}
+	return nil
+}
+
+// ReleaseBastionPublicIP disassociates the bastion's public IP, which also deletes its port forwarding rule. Addresses
+// CAPC didn't associate are only untagged.
+func (c *client) ReleaseBastionPublicIP(fd *infrav1.CloudStackFailureDomain, csCluster *infrav1.CloudStackCluster) error {
+	publicAddress, err := c.findBastionPublicIP(fd, csCluster)
+	if err != nil || publicAddress == nil {
+		return err
+	}
+	if err := c.DeleteTags(ResourceTypeIPAddress, publicAddress.Id, map[string]string{generateBastionTagName(csCluster): "1"}); err != nil {
+		return errors.Wrapf(err, "deleting tag from public IP address with ID %s", publicAddress.Id)
+	}
+	return c.releaseDedicatedPublicIP(publicAddress.Id, csCluster)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Bastion", func() {
+	var ( // Declare shared vars.
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		vms        *csapi.MockVirtualMachineServiceIface
+		sos        *csapi.MockServiceOfferingServiceIface
+		ts         *csapi.MockTemplateServiceIface
+		as         *csapi.MockAddressServiceIface
+		fs         *csapi.MockFirewallServiceIface
+		rs         *csapi.MockResourcetagsServiceIface
+		vs         *csapi.MockVolumeServiceIface
+		client     cloud.Client
+	)
+
+	capcTags := &csapi.ListTagsResponse{Tags: []*csapi.Tag{{Key: cloud.CreatedByCAPCTagName, Value: "1"}}}
+
+	BeforeEach(func() {
+		// Setup new mock services.
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		vms = mockClient.VirtualMachine.(*csapi.MockVirtualMachineServiceIface)
+		sos = mockClient.ServiceOffering.(*csapi.MockServiceOfferingServiceIface)
+		ts = mockClient.Template.(*csapi.MockTemplateServiceIface)
+		as = mockClient.Address.(*csapi.MockAddressServiceIface)
+		fs = mockClient.Firewall.(*csapi.MockFirewallServiceIface)
+		rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+		vs = mockClient.Volume.(*csapi.MockVolumeServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		dummies.CSCluster.Spec.Bastion = &infrav1.Bastion{
+			Offering:     infrav1.CloudStackResourceIdentifier{Name: "bastion-offering"},
+			Template:     infrav1.CloudStackResourceIdentifier{Name: "bastion-template"},
+			SSHKey:       "bastion-key",
+			AllowedCIDRs: []string{"192.0.2.0/24"},
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("deploys the bastion and forwards a public IP of its own to it", func() {
+		bastionName := dummies.CSCluster.Name + "-bastion"
+		gomock.InOrder(
+			vms.EXPECT().GetVirtualMachinesMetricByName(bastionName, gomock.Any()).
+				Return(nil, 0, errors.New("No match found for "+bastionName)),
+			vms.EXPECT().GetVirtualMachinesMetricByName(bastionName, gomock.Any()).
+				Return(&csapi.VirtualMachinesMetric{Id: "bastion-id", Ipaddress: "10.1.0.5"}, 1, nil),
+		)
+		sos.EXPECT().GetServiceOfferingByName("bastion-offering", gomock.Any()).
+			Return(&csapi.ServiceOffering{Id: "offering-id", Name: "bastion-offering"}, 1, nil)
+		ts.EXPECT().GetTemplateID("bastion-template", "executable", dummies.CSFailureDomain1.Spec.Zone.ID, gomock.Any()).
+			Return("template-id", 1, nil)
+		vms.EXPECT().NewDeployVirtualMachineParams("offering-id", "template-id", dummies.CSFailureDomain1.Spec.Zone.ID).
+			Return(&csapi.DeployVirtualMachineParams{})
+		vms.EXPECT().DeployVirtualMachine(gomock.Any()).
+			DoAndReturn(func(p *csapi.DeployVirtualMachineParams) (*csapi.DeployVirtualMachineResponse, error) {
+				name, _ := p.GetName()
+				Ω(name).Should(Equal(bastionName))
+				networkIDs, _ := p.GetNetworkids()
+				Ω(networkIDs).Should(Equal([]string{dummies.CSISONet1.Spec.ID}))
+				keyPair, _ := p.GetKeypair()
+				Ω(keyPair).Should(Equal("bastion-key"))
+				return &csapi.DeployVirtualMachineResponse{Id: "bastion-id"}, nil
+			})
+		rs.EXPECT().NewCreateTagsParams([]string{"bastion-id"}, string(cloud.ResourceTypeVM), gomock.Any()).
+			DoAndReturn(func(_ []string, _ string, tags map[string]string) *csapi.CreateTagsParams {
+				Ω(tags).Should(HaveKeyWithValue(cloud.MachineRoleTagName, cloud.MachineRoleBastion))
+				return &csapi.CreateTagsParams{}
+			})
+		vs.EXPECT().NewListVolumesParams().Return(&csapi.ListVolumesParams{})
+		vs.EXPECT().ListVolumes(gomock.Any()).Return(&csapi.ListVolumesResponse{
+			Count: 1, Volumes: []*csapi.Volume{{Id: "bastion-root-id"}}}, nil)
+		rs.EXPECT().NewCreateTagsParams([]string{"bastion-root-id"}, string(cloud.ResourceTypeVolume), gomock.Any()).
+			Return(&csapi.CreateTagsParams{})
+
+		as.EXPECT().NewListPublicIpAddressesParams().Return(&csapi.ListPublicIpAddressesParams{})
+		as.EXPECT().ListPublicIpAddresses(gomock.Any()).Return(&csapi.ListPublicIpAddressesResponse{}, nil)
+		as.EXPECT().NewAssociateIpAddressParams().Return(&csapi.AssociateIpAddressParams{})
+		as.EXPECT().AssociateIpAddress(gomock.Any()).
+			Return(&csapi.AssociateIpAddressResponse{Id: "bastion-ip-id", Ipaddress: "192.0.2.20"}, nil)
+		rs.EXPECT().NewCreateTagsParams([]string{"bastion-ip-id"}, string(cloud.ResourceTypeIPAddress), gomock.Any()).
+			Return(&csapi.CreateTagsParams{}).Times(3)
+		rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(5)
+		rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+		rs.EXPECT().ListTags(gomock.Any()).Return(capcTags, nil)
+
+		// A rule left over from an earlier public port is replaced.
+		fs.EXPECT().NewListPortForwardingRulesParams().Return(&csapi.ListPortForwardingRulesParams{})
+		fs.EXPECT().ListPortForwardingRules(gomock.Any()).Return(&csapi.ListPortForwardingRulesResponse{
+			PortForwardingRules: []*csapi.PortForwardingRule{{Id: "stale", Publicport: "2222", Privateport: "22",
+				Virtualmachineid: "bastion-id", Cidrlist: "192.0.2.0/24"}}}, nil)
+		fs.EXPECT().NewDeletePortForwardingRuleParams("stale").Return(&csapi.DeletePortForwardingRuleParams{})
+		fs.EXPECT().DeletePortForwardingRule(gomock.Any()).Return(&csapi.DeletePortForwardingRuleResponse{}, nil)
+		fs.EXPECT().NewCreatePortForwardingRuleParams("bastion-ip-id", cloud.BastionSSHPort, "tcp", 22, "bastion-id").
+			Return(&csapi.CreatePortForwardingRuleParams{})
+		fs.EXPECT().CreatePortForwardingRule(gomock.Any()).
+			DoAndReturn(func(p *csapi.CreatePortForwardingRuleParams) (*csapi.CreatePortForwardingRuleResponse, error) {
+				cidrs, _ := p.GetCidrlist()
+				Ω(cidrs).Should(Equal([]string{"192.0.2.0/24"}))
+				openFirewall, _ := p.GetOpenfirewall()
+				Ω(openFirewall).Should(BeTrue())
+				return &csapi.CreatePortForwardingRuleResponse{}, nil
+			})
+
+		Ω(client.ReconcileBastion(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		Ω(dummies.CSCluster.Status.Bastion).Should(Equal(&infrav1.BastionStatus{
+			FailureDomainName: dummies.CSFailureDomain1.Spec.Name,
+			InstanceID:        "bastion-id",
+			PrivateIP:         "10.1.0.5",
+			PublicIPID:        "bastion-ip-id",
+			PublicIP:          "192.0.2.20",
+			PublicPort:        22,
+		}))
+	})
+
+	It("disassociates the bastion's public IP once no cluster uses it", func() {
+		as.EXPECT().NewListPublicIpAddressesParams().Return(&csapi.ListPublicIpAddressesParams{})
+		as.EXPECT().ListPublicIpAddresses(gomock.Any()).Return(&csapi.ListPublicIpAddressesResponse{
+			Count: 1, PublicIpAddresses: []*csapi.PublicIpAddress{{Id: "bastion-ip-id"}}}, nil)
+		rs.EXPECT().NewDeleteTagsParams([]string{"bastion-ip-id"}, string(cloud.ResourceTypeIPAddress)).
+			Return(&csapi.DeleteTagsParams{}).Times(3)
+		rs.EXPECT().DeleteTags(gomock.Any()).Return(&csapi.DeleteTagsResponse{}, nil).Times(3)
+		rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{}).Times(2)
+		rs.EXPECT().ListTags(gomock.Any()).Return(capcTags, nil).Times(2)
+		as.EXPECT().NewDisassociateIpAddressParams("bastion-ip-id").Return(&csapi.DisassociateIpAddressParams{})
+		as.EXPECT().DisassociateIpAddress(gomock.Any()).Return(&csapi.DisassociateIpAddressResponse{}, nil)
+
+		Ω(client.ReleaseBastionPublicIP(dummies.CSFailureDomain1, dummies.CSCluster)).Should(Succeed())
+	})
+})
@@ -43,7 +43,15 @@ type Client interface {
 	TagIface
 	ZoneIFace
 	IsoNetworkIface
+	VPCIface
+	FirewallIface
+	LoadBalancerIface
+	BastionIface
+	VirtualRouterIface
+	ControlPlaneVIPIface
+	GlobalLoadBalancerIface
 	UserCredIFace
+	OrphanIface
 	NewClientInDomainAndAccount(string, string, string) (Client, error)
 }
 
@@ -61,6 +69,8 @@ type client struct {
 	config        Config
 	user          *User
 	customMetrics metrics.ACSCustomMetrics
+	// source is the endpoint Secret the client's credentials were read from, if any.
+	source string
 }
 
 type SecretConfig struct {
@@ -74,6 +84,13 @@ type SecretConfig struct {
 var clientCache *ttlcache.Cache[string, *client]
 var cacheMutex sync.Mutex
 
+// sourceCacheKeys holds the cache keys of the clients created from each endpoint Secret, including the clients of
+// other users created from them, so they can be evicted when the Secret changes.
+var sourceCacheKeys = map[string]map[string]struct{}{}
+
+// sourceVersions holds the resource version of each endpoint Secret its cached clients were created from.
+var sourceVersions = map[string]string{}
+
 var NewAsyncClient = cloudstack.NewAsyncClient
 var NewClient = cloudstack.NewClient
 
@@ -106,11 +123,17 @@ func NewClientFromK8sSecret(endpointSecret *corev1.Secret, clientConfig *corev1.
 	for k, v := range endpointSecret.Data {
 		endpointSecretStrings[k] = string(v)
 	}
-	bytes, err := yaml.Marshal(endpointSecretStrings)
+	conf, err := yaml.Marshal(endpointSecretStrings)
 	if err != nil {
 		return nil, err
 	}
-	return NewClientFromBytesConfig(bytes, clientConfig, project)
+	var config Config
+	if err := yaml.NewDecoder(bytes.NewReader(conf)).Decode(&config); err != nil {
+		return nil, err
+	}
+	source := secretSource(endpointSecret.Namespace, endpointSecret.Name)
+	evictStaleClients(source, endpointSecret.ResourceVersion)
+	return newClientFromConf(config, clientConfig, project, source)
 }
 
 // NewClientFromBytesConfig returns a client from a bytes array that unmarshals to a yaml config.
@@ -151,6 +174,11 @@ func NewClientFromYamlPath(confPath string, secretName string) (Client, error) {
 
 // NewClientFromConf creates a new Cloud Client form a map of strings to strings.
 func NewClientFromConf(conf Config, clientConfig *corev1.ConfigMap, project string) (Client, error) {
+	return newClientFromConf(conf, clientConfig, project, "")
+}
+
+// newClientFromConf creates a new Cloud Client, recording the endpoint Secret its config was read from.
+func newClientFromConf(conf Config, clientConfig *corev1.ConfigMap, project string, source string) (Client, error) {
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 
@@ -160,6 +188,7 @@ func NewClientFromConf(conf Config, clientConfig *corev1.ConfigMap, project stri
 
 	clientCacheKey := generateClientCacheKey(conf)
 	if item := clientCache.Get(clientCacheKey); item != nil {
+		recordSource(source, clientCacheKey)
 		return item.Value(), nil
 	}
 
@@ -171,7 +200,7 @@ func NewClientFromConf(conf Config, clientConfig *corev1.ConfigMap, project stri
 	// The client returned from NewAsyncClient works in a synchronous way. On the other hand,
 	// a client returned from NewClient works in an asynchronous way. Dive into the constructor definition
 	// comments for more details
-	c := &client{config: conf}
+	c := &client{config: conf, source: source}
 	c.cs = NewClient(conf.APIUrl, conf.APIKey, conf.SecretKey, verifySSL)
 	c.csAsync = NewAsyncClient(conf.APIUrl, conf.APIKey, conf.SecretKey, verifySSL)
 	c.customMetrics = metrics.NewCustomMetrics()
@@ -201,6 +230,7 @@ func NewClientFromConf(conf Config, clientConfig *corev1.ConfigMap, project stri
 	}
 	c.user = user
 	clientCache.Set(clientCacheKey, c, ttlcache.DefaultTTL)
+	recordSource(source, clientCacheKey)
 
 	return c, nil
 }
@@ -217,11 +247,61 @@ func (c *client) NewClientInDomainAndAccount(domain string, account string, proj
 		return nil, errors.Errorf(
 			"could not find sufficient user (with API keys) in domain/account %s/%s", domain, account)
 	}
-	c.config.APIKey = user.APIKey
-	c.config.SecretKey = user.SecretKey
-	c.user = user
+	// Leave the config of this client, which may be cached, untouched.
+	conf := c.config
+	conf.APIKey = user.APIKey
+	conf.SecretKey = user.SecretKey
+
+	return newClientFromConf(conf, nil, project, c.source)
+}
+
+// EvictClients removes the clients created from an endpoint Secret from the client cache, so the next clients are
+// created with the Secret's current credentials rather than those it held when the cached clients were created.
+func EvictClients(secretNamespace, secretName string) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	evictSource(secretSource(secretNamespace, secretName))
+}
 
-	return NewClientFromConf(c.config, nil, project)
+// evictStaleClients evicts the clients created from an endpoint Secret when it changed since they were created, so
+// credential rotations take effect on the next reconcile of each object using the Secret.
+func evictStaleClients(source string, resourceVersion string) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if version, ok := sourceVersions[source]; ok && version != resourceVersion {
+		evictSource(source)
+	}
+	sourceVersions[source] = resourceVersion
+}
+
+// evictSource removes the clients created from the source from the client cache. Must be called with the cacheMutex
+// held.
+func evictSource(source string) {
+	if clientCache != nil {
+		for key := range sourceCacheKeys[source] {
+			clientCache.Delete(key)
+		}
+	}
+	delete(sourceCacheKeys, source)
+}
+
+// secretSource identifies an endpoint Secret as the source of clients.
+func secretSource(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// recordSource records that the client cached under the key was created from the source. Must be called with the
+// cacheMutex held.
+func recordSource(source string, clientCacheKey string) {
+	if source == "" {
+		return
+	}
+	if sourceCacheKeys[source] == nil {
+		sourceCacheKeys[source] = map[string]struct{}{}
+	}
+	sourceCacheKeys[source][clientCacheKey] = struct{}{}
 }
 
 // NewClientFromCSAPIClient creates a client from a CloudStack-Go API client. Used only for testing.
@@ -230,13 +310,15 @@ func NewClientFromCSAPIClient(cs *cloudstack.CloudStackClient, user *User) Clien
 		user = &User{
 			Account: Account{
 				Domain: Domain{
-					CPUAvailable:    "Unlimited",
-					MemoryAvailable: "Unlimited",
-					VMAvailable:     "Unlimited",
+					CPUAvailable:            "Unlimited",
+					MemoryAvailable:         "Unlimited",
+					VMAvailable:             "Unlimited",
+					PrimaryStorageAvailable: "Unlimited",
 				},
-				CPUAvailable:    "Unlimited",
-				MemoryAvailable: "Unlimited",
-				VMAvailable:     "Unlimited",
+				CPUAvailable:            "Unlimited",
+				MemoryAvailable:         "Unlimited",
+				VMAvailable:             "Unlimited",
+				PrimaryStorageAvailable: "Unlimited",
 			},
 		}
 	}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/golang/mock/gomock"
@@ -190,5 +191,44 @@ var _ = Describe("Client", func() {
 			result2, _ := cloud.NewClientFromConf(config2, clientConfig, "")
 			Ω(result1).Should(Equal(result2))
 		})
+
+		It("Returns a new client for an endpoint secret after its clients are evicted", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "endpoint-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"api-url": []byte("http://5.5.5.5")},
+			}
+			result1, err := cloud.NewClientFromK8sSecret(secret, clientConfig, "")
+			Ω(err).ShouldNot(HaveOccurred())
+			result2, _ := cloud.NewClientFromK8sSecret(secret, clientConfig, "")
+			Ω(result2).Should(BeIdenticalTo(result1))
+
+			cloud.EvictClients("default", "other-credentials")
+			result3, _ := cloud.NewClientFromK8sSecret(secret, clientConfig, "")
+			Ω(result3).Should(BeIdenticalTo(result1))
+
+			cloud.EvictClients("default", "endpoint-credentials")
+			result4, err := cloud.NewClientFromK8sSecret(secret, clientConfig, "")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result4).ShouldNot(BeIdenticalTo(result1))
+			Ω(result4.GetUser().Account.ID).Should(Equal(dummies.AccountID))
+		})
+
+		It("Returns a new client for an endpoint secret after it changed", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "rotated-credentials", Namespace: "default", ResourceVersion: "1"},
+				Data:       map[string][]byte{"api-url": []byte("http://6.6.6.6")},
+			}
+			result1, err := cloud.NewClientFromK8sSecret(secret, clientConfig, "")
+			Ω(err).ShouldNot(HaveOccurred())
+			result2, _ := cloud.NewClientFromK8sSecret(secret, clientConfig, "")
+			Ω(result2).Should(BeIdenticalTo(result1))
+
+			secret.ResourceVersion = "2"
+			result3, err := cloud.NewClientFromK8sSecret(secret, clientConfig, "")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result3).ShouldNot(BeIdenticalTo(result1))
+			result4, _ := cloud.NewClientFromK8sSecret(secret, clientConfig, "")
+			Ω(result4).Should(BeIdenticalTo(result3))
+		})
 	})
 })
@@ -0,0 +1,243 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+type ControlPlaneVIPIface interface {
+	AllocateControlPlaneVIP(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackCluster) error
+	ReserveControlPlaneVIP(*infrav1.CloudStackMachine, *infrav1.CloudStackCluster) error
+	HandOverControlPlaneVIP(*infrav1.CloudStackMachine, []string, *infrav1.CloudStackCluster) error
+	ReleaseControlPlaneVIP(*infrav1.CloudStackCluster) error
+}
+
+// ErrControlPlaneVIPTaken is returned when the control plane endpoint address CAPC allocated was taken by a NIC before
+// a control plane VM could reserve it.
+var ErrControlPlaneVIPTaken = errors.New("control plane endpoint address is used by a NIC CAPC didn't reserve it on")
+
+// AllocateControlPlaneVIP picks an address of the failure domain's shared network that no VM or router uses and sets
+// it as the cluster's control plane endpoint host, unless the host is a DNS name. Addresses are picked from the end of the network's IP ranges, away
+// from the addresses CloudStack hands out first. Listing the IP ranges and routers requires an admin account.
+func (c *client) AllocateControlPlaneVIP(fd *infrav1.CloudStackFailureDomain, csCluster *infrav1.CloudStackCluster) error {
+	networkID := fd.Spec.Zone.Network.ID
+	rangesParams := c.cs.VLAN.NewListVlanIpRangesParams()
+	rangesParams.SetNetworkid(networkID)
+	ranges, err := c.cs.VLAN.ListVlanIpRanges(rangesParams)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing IP ranges of network with ID %s", networkID)
+	}
+
+	inUse, err := c.networkAddressesInUse(networkID)
+	if err != nil {
+		return err
+	}
+	for i := len(ranges.VlanIpRanges) - 1; i >= 0; i-- {
+		ipRange := ranges.VlanIpRanges[i]
+		start, end := net.ParseIP(ipRange.Startip).To4(), net.ParseIP(ipRange.Endip).To4()
+		if start == nil || end == nil {
+			continue
+		}
+		first, last := binary.BigEndian.Uint32(start), binary.BigEndian.Uint32(end)
+		for ip := last; ip >= first && ip != 0; ip-- {
+			address := make(net.IP, net.IPv4len)
+			binary.BigEndian.PutUint32(address, ip)
+			if inUse[address.String()] || address.String() == ipRange.Gateway {
+				continue
+			}
+			if csCluster.ControlPlaneEndpointName() == "" {
+				csCluster.Spec.ControlPlaneEndpoint.Host = address.String()
+			}
+			if csCluster.Spec.ControlPlaneEndpoint.Port == 0 {
+				csCluster.Spec.ControlPlaneEndpoint.Port = K8sDefaultAPIPort
+			}
+			csCluster.Status.ControlPlaneVIP = &infrav1.ControlPlaneVIPStatus{Address: address.String(), NetworkID: networkID}
+			return nil
+		}
+	}
+	return errors.Errorf("no free address left in the IP ranges of network with ID %s", networkID)
+}
+
+// networkAddressesInUse returns the primary and secondary addresses of the VM and router NICs in a network.
+func (c *client) networkAddressesInUse(networkID string) (map[string]bool, error) {
+	inUse := map[string]bool{}
+	addNics := func(nics []cloudstack.Nic) {
+		for _, nic := range nics {
+			if nic.Networkid != networkID {
+				continue
+			}
+			inUse[nic.Ipaddress] = true
+			for _, secondaryIP := range nic.Secondaryip {
+				inUse[secondaryIP.Ipaddress] = true
+			}
+		}
+	}
+
+	vmsParams := c.cs.VirtualMachine.NewListVirtualMachinesParams()
+	vmsParams.SetNetworkid(networkID)
+	vmsParams.SetListall(true)
+	vms, err := c.cs.VirtualMachine.ListVirtualMachines(vmsParams)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrapf(err, "listing VMs of network with ID %s", networkID)
+	}
+	for _, vm := range vms.VirtualMachines {
+		addNics(vm.Nic)
+	}
+
+	routersParams := c.cs.Router.NewListRoutersParams()
+	routersParams.SetNetworkid(networkID)
+	routersParams.SetListall(true)
+	routers, err := c.cs.Router.ListRouters(routersParams)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrapf(err, "listing routers of network with ID %s", networkID)
+	}
+	for _, router := range routers.Routers {
+		addNics(router.Nic)
+	}
+	return inUse, nil
+}
+
+// ReserveControlPlaneVIP reserves the control plane endpoint address CAPC allocated as a secondary IP of the machine's
+// NIC in the shared network, so that CloudStack never hands it out to another VM. Nothing is done while another
+// control plane VM holds the reservation. CloudStack can only reserve addresses on the NICs of VMs, so the address
+// isn't reserved between its allocation and the first control plane VM; ErrControlPlaneVIPTaken is returned if it was
+// taken in the meantime. Checking the addresses in use requires an admin account.
+func (c *client) ReserveControlPlaneVIP(csMachine *infrav1.CloudStackMachine, csCluster *infrav1.CloudStackCluster) error {
+	vip := csCluster.Status.ControlPlaneVIP
+	if vip == nil || csMachine.Spec.InstanceID == nil {
+		return nil
+	}
+	if _, holderVMID, err := c.findControlPlaneVIPHolder(vip); err != nil || holderVMID != "" {
+		return err
+	}
+	if inUse, err := c.networkAddressesInUse(vip.NetworkID); err != nil {
+		return err
+	} else if inUse[vip.Address] {
+		return errors.Wrapf(ErrControlPlaneVIPTaken, "reserving control plane endpoint address %s", vip.Address)
+	}
+
+	if reserved, err := c.addControlPlaneVIPToVM(*csMachine.Spec.InstanceID, vip); err != nil {
+		return err
+	} else if !reserved {
+		return errors.Errorf("VM with ID %s has no NIC in network with ID %s", *csMachine.Spec.InstanceID, vip.NetworkID)
+	}
+	return nil
+}
+
+// HandOverControlPlaneVIP moves the reservation of the control plane endpoint address from the machine's NIC to the
+// NIC of the first successor VM in the shared network, if the machine holds it, so the address stays reserved when
+// the machine's VM is destroyed, e.g. during a control plane rollout. Without a successor the reservation goes with
+// the VM.
+func (c *client) HandOverControlPlaneVIP(
+	csMachine *infrav1.CloudStackMachine,
+	successorIDs []string,
+	csCluster *infrav1.CloudStackCluster,
+) error {
+	vip := csCluster.Status.ControlPlaneVIP
+	if vip == nil || csMachine.Spec.InstanceID == nil || len(successorIDs) == 0 {
+		return nil
+	}
+	holderIPID, holderVMID, err := c.findControlPlaneVIPHolder(vip)
+	if err != nil || holderVMID != *csMachine.Spec.InstanceID {
+		return err
+	}
+	// CloudStack can't move a secondary IP, so it's released and reserved again right away.
+	if _, err := c.csAsync.Nic.RemoveIpFromNic(c.csAsync.Nic.NewRemoveIpFromNicParams(holderIPID)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "releasing control plane endpoint address %s", vip.Address)
+	}
+	for _, successorID := range successorIDs {
+		if reserved, err := c.addControlPlaneVIPToVM(successorID, vip); err != nil || reserved {
+			return err
+		}
+	}
+	return errors.Errorf("no successor VM has a NIC in network with ID %s", vip.NetworkID)
+}
+
+// addControlPlaneVIPToVM adds the control plane endpoint address as a secondary IP to the VM's NIC in the shared
+// network. Returns false if the VM has no NIC in the network.
+func (c *client) addControlPlaneVIPToVM(instanceID string, vip *infrav1.ControlPlaneVIPStatus) (bool, error) {
+	nicsParams := c.cs.Nic.NewListNicsParams(instanceID)
+	nicsParams.SetNetworkid(vip.NetworkID)
+	nics, err := c.cs.Nic.ListNics(nicsParams)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return false, errors.Wrapf(err, "listing NICs of VM with ID %s", instanceID)
+	} else if len(nics.Nics) == 0 {
+		return false, nil
+	}
+	p := c.csAsync.Nic.NewAddIpToNicParams(nics.Nics[0].Id)
+	p.SetIpaddress(vip.Address)
+	if _, err := c.csAsync.Nic.AddIpToNic(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return false, errors.Wrapf(err, "reserving control plane endpoint address %s", vip.Address)
+	}
+	return true, nil
+}
+
+// ReleaseControlPlaneVIP removes the secondary IP reserving the control plane endpoint address CAPC allocated, if a VM
+// still holds it.
+func (c *client) ReleaseControlPlaneVIP(csCluster *infrav1.CloudStackCluster) error {
+	vip := csCluster.Status.ControlPlaneVIP
+	if vip == nil {
+		return nil
+	}
+	holderID, _, err := c.findControlPlaneVIPHolder(vip)
+	if err != nil || holderID == "" {
+		return err
+	}
+	if _, err := c.csAsync.Nic.RemoveIpFromNic(c.csAsync.Nic.NewRemoveIpFromNicParams(holderID)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "releasing control plane endpoint address %s", vip.Address)
+	}
+	return nil
+}
+
+// findControlPlaneVIPHolder returns the IDs of the secondary IP reserving the control plane endpoint address and of
+// the VM holding it, or empty strings if no VM of the network holds it.
+func (c *client) findControlPlaneVIPHolder(vip *infrav1.ControlPlaneVIPStatus) (string, string, error) {
+	p := c.cs.VirtualMachine.NewListVirtualMachinesParams()
+	p.SetNetworkid(vip.NetworkID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.VirtualMachine.ListVirtualMachines(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return "", "", errors.Wrapf(err, "listing VMs of network with ID %s", vip.NetworkID)
+	}
+	for _, vm := range resp.VirtualMachines {
+		for _, nic := range vm.Nic {
+			if nic.Networkid != vip.NetworkID {
+				continue
+			}
+			for _, ip := range nic.Secondaryip {
+				if ip.Ipaddress == vip.Address {
+					return ip.Id, vm.Id, nil
+				}
+			}
+		}
+	}
+	return "", "", nil
+}
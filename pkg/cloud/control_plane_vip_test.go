@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Control Plane VIP", func() {
+	var ( // Declare shared vars.
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		vlans      *csapi.MockVLANServiceIface
+		vms        *csapi.MockVirtualMachineServiceIface
+		routers    *csapi.MockRouterServiceIface
+		nics       *csapi.MockNicServiceIface
+		client     cloud.Client
+	)
+
+	const networkID = "shared-network-id"
+	nicWithSecondaryIP := func(address string) csapi.Nic {
+		nic := csapi.Nic{Id: "nic-id", Networkid: networkID, Ipaddress: "10.0.0.10"}
+		nic.Secondaryip = append(nic.Secondaryip, struct {
+			Id        string `json:"id"`
+			Ipaddress string `json:"ipaddress"`
+		}{Id: "secondary-ip-id", Ipaddress: address})
+		return nic
+	}
+
+	BeforeEach(func() {
+		// Setup new mock services.
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		vlans = mockClient.VLAN.(*csapi.MockVLANServiceIface)
+		vms = mockClient.VirtualMachine.(*csapi.MockVirtualMachineServiceIface)
+		routers = mockClient.Router.(*csapi.MockRouterServiceIface)
+		nics = mockClient.Nic.(*csapi.MockNicServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		dummies.CSFailureDomain1.Spec.Zone.Network.ID = networkID
+		dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = ""
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("allocates the last address of the network's IP ranges no VM or router uses", func() {
+		vlans.EXPECT().NewListVlanIpRangesParams().Return(&csapi.ListVlanIpRangesParams{})
+		vlans.EXPECT().ListVlanIpRanges(gomock.Any()).Return(&csapi.ListVlanIpRangesResponse{
+			VlanIpRanges: []*csapi.VlanIpRange{{Startip: "10.0.0.2", Endip: "10.0.0.254", Gateway: "10.0.0.1"}}}, nil)
+		vms.EXPECT().NewListVirtualMachinesParams().Return(&csapi.ListVirtualMachinesParams{})
+		vms.EXPECT().ListVirtualMachines(gomock.Any()).Return(&csapi.ListVirtualMachinesResponse{
+			VirtualMachines: []*csapi.VirtualMachine{{Nic: []csapi.Nic{nicWithSecondaryIP("10.0.0.254")}}}}, nil)
+		routers.EXPECT().NewListRoutersParams().Return(&csapi.ListRoutersParams{})
+		routers.EXPECT().ListRouters(gomock.Any()).Return(&csapi.ListRoutersResponse{
+			Routers: []*csapi.Router{{Nic: []csapi.Nic{{Networkid: networkID, Ipaddress: "10.0.0.253"}}}}}, nil)
+
+		Ω(client.AllocateControlPlaneVIP(dummies.CSFailureDomain1, dummies.CSCluster)).Should(Succeed())
+		Ω(dummies.CSCluster.Spec.ControlPlaneEndpoint.Host).Should(Equal("10.0.0.252"))
+		Ω(dummies.CSCluster.Status.ControlPlaneVIP).Should(Equal(
+			&infrav1.ControlPlaneVIPStatus{Address: "10.0.0.252", NetworkID: networkID}))
+	})
+
+	It("reserves the address on a control plane VM's NIC when no VM holds it", func() {
+		dummies.CSCluster.Status.ControlPlaneVIP = &infrav1.ControlPlaneVIPStatus{Address: "10.0.0.252", NetworkID: networkID}
+		vms.EXPECT().NewListVirtualMachinesParams().Return(&csapi.ListVirtualMachinesParams{}).Times(2)
+		vms.EXPECT().ListVirtualMachines(gomock.Any()).Return(&csapi.ListVirtualMachinesResponse{}, nil).Times(2)
+		routers.EXPECT().NewListRoutersParams().Return(&csapi.ListRoutersParams{})
+		routers.EXPECT().ListRouters(gomock.Any()).Return(&csapi.ListRoutersResponse{}, nil)
+		nics.EXPECT().NewListNicsParams(*dummies.CSMachine1.Spec.InstanceID).Return(&csapi.ListNicsParams{})
+		nics.EXPECT().ListNics(gomock.Any()).Return(&csapi.ListNicsResponse{Nics: []*csapi.Nic{{Id: "nic-id"}}}, nil)
+		nics.EXPECT().NewAddIpToNicParams("nic-id").Return(&csapi.AddIpToNicParams{})
+		nics.EXPECT().AddIpToNic(gomock.Any()).
+			DoAndReturn(func(p *csapi.AddIpToNicParams) (*csapi.AddIpToNicResponse, error) {
+				address, _ := p.GetIpaddress()
+				Ω(address).Should(Equal("10.0.0.252"))
+				return &csapi.AddIpToNicResponse{}, nil
+			})
+
+		Ω(client.ReserveControlPlaneVIP(dummies.CSMachine1, dummies.CSCluster)).Should(Succeed())
+	})
+
+	It("reports an address taken before a control plane VM could reserve it", func() {
+		dummies.CSCluster.Status.ControlPlaneVIP = &infrav1.ControlPlaneVIPStatus{Address: "10.0.0.252", NetworkID: networkID}
+		vms.EXPECT().NewListVirtualMachinesParams().Return(&csapi.ListVirtualMachinesParams{}).Times(2)
+		gomock.InOrder(
+			vms.EXPECT().ListVirtualMachines(gomock.Any()).Return(&csapi.ListVirtualMachinesResponse{}, nil),
+			vms.EXPECT().ListVirtualMachines(gomock.Any()).Return(&csapi.ListVirtualMachinesResponse{
+				VirtualMachines: []*csapi.VirtualMachine{{Nic: []csapi.Nic{{Networkid: networkID, Ipaddress: "10.0.0.252"}}}}}, nil))
+		routers.EXPECT().NewListRoutersParams().Return(&csapi.ListRoutersParams{})
+		routers.EXPECT().ListRouters(gomock.Any()).Return(&csapi.ListRoutersResponse{}, nil)
+
+		err := client.ReserveControlPlaneVIP(dummies.CSMachine1, dummies.CSCluster)
+		Ω(errors.Is(err, cloud.ErrControlPlaneVIPTaken)).Should(BeTrue())
+	})
+
+	It("hands the reservation over to a successor VM before the holder is destroyed", func() {
+		dummies.CSCluster.Status.ControlPlaneVIP = &infrav1.ControlPlaneVIPStatus{Address: "10.0.0.252", NetworkID: networkID}
+		vms.EXPECT().NewListVirtualMachinesParams().Return(&csapi.ListVirtualMachinesParams{})
+		vms.EXPECT().ListVirtualMachines(gomock.Any()).Return(&csapi.ListVirtualMachinesResponse{
+			VirtualMachines: []*csapi.VirtualMachine{{
+				Id: *dummies.CSMachine1.Spec.InstanceID, Nic: []csapi.Nic{nicWithSecondaryIP("10.0.0.252")}}}}, nil)
+		nics.EXPECT().NewRemoveIpFromNicParams("secondary-ip-id").Return(&csapi.RemoveIpFromNicParams{})
+		nics.EXPECT().RemoveIpFromNic(gomock.Any()).Return(&csapi.RemoveIpFromNicResponse{}, nil)
+		nics.EXPECT().NewListNicsParams("successor-id").Return(&csapi.ListNicsParams{})
+		nics.EXPECT().ListNics(gomock.Any()).Return(&csapi.ListNicsResponse{Nics: []*csapi.Nic{{Id: "successor-nic-id"}}}, nil)
+		nics.EXPECT().NewAddIpToNicParams("successor-nic-id").Return(&csapi.AddIpToNicParams{})
+		nics.EXPECT().AddIpToNic(gomock.Any()).Return(&csapi.AddIpToNicResponse{}, nil)
+
+		Ω(client.HandOverControlPlaneVIP(dummies.CSMachine1, []string{"successor-id"}, dummies.CSCluster)).Should(Succeed())
+	})
+
+	It("releases the address held by a VM", func() {
+		dummies.CSCluster.Status.ControlPlaneVIP = &infrav1.ControlPlaneVIPStatus{Address: "10.0.0.252", NetworkID: networkID}
+		vms.EXPECT().NewListVirtualMachinesParams().Return(&csapi.ListVirtualMachinesParams{})
+		vms.EXPECT().ListVirtualMachines(gomock.Any()).Return(&csapi.ListVirtualMachinesResponse{
+			VirtualMachines: []*csapi.VirtualMachine{{Nic: []csapi.Nic{nicWithSecondaryIP("10.0.0.252")}}}}, nil)
+		nics.EXPECT().NewRemoveIpFromNicParams("secondary-ip-id").Return(&csapi.RemoveIpFromNicParams{})
+		nics.EXPECT().RemoveIpFromNic(gomock.Any()).Return(&csapi.RemoveIpFromNicResponse{}, nil)
+
+		Ω(client.ReleaseControlPlaneVIP(dummies.CSCluster)).Should(Succeed())
+	})
+})
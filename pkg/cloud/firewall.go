@@ -0,0 +1,380 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+type FirewallIface interface {
+	ReconcileFirewallPolicy(*infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster) error
+}
+
+const (
+	AnyCIDR     = "0.0.0.0/0"
+	AnyIPv6CIDR = "::/0"
+
+	FirewallTrafficTypeIngress = "Ingress"
+)
+
+// firewallRule is the part of a CloudStack ingress or egress firewall rule CAPC compares against its policy.
+type firewallRule struct {
+	protocol  string
+	startPort int
+	endPort   int
+	cidrs     string
+}
+
+func newFirewallRule(protocol string, startPort, endPort int, cidrs []string) firewallRule {
+	sorted := append([]string{}, cidrs...)
+	sort.Strings(sorted)
+	return firewallRule{protocol: strings.ToLower(protocol), startPort: startPort, endPort: endPort, cidrs: strings.Join(sorted, ",")}
+}
+
+// isCreatedByCAPC checks a rule's tags for the CAPC creation tag.
+func isCreatedByCAPC(tags []cloudstack.Tags) bool {
+	for _, tag := range tags {
+		if tag.Key == CreatedByCAPCTagName {
+			return true
+		}
+	}
+	return false
+}
+
+// ReconcileFirewallPolicy makes the isolated network's firewall rules match the cluster's firewall policy. Rules CAPC
+// created that are no longer in the policy are deleted, rules created by anyone else are left alone.
+func (c *client) ReconcileFirewallPolicy(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) error {
+	policy := csCluster.Spec.FirewallPolicy
+	if policy == nil {
+		policy = &infrav1.FirewallPolicy{}
+	}
+	if err := c.reconcileEgressFirewallRules(isoNet, policy.Egress); err != nil {
+		return errors.Wrap(err, "reconciling egress firewall rules")
+	}
+	if isoNet.Status.IPv6CIDR != "" {
+		if err := c.reconcileIPv6IngressFirewallRules(isoNet, policy.Ingress); err != nil {
+			return errors.Wrap(err, "reconciling IPv6 ingress firewall rules")
+		}
+	}
+	return errors.Wrap(c.reconcileIngressFirewallRules(isoNet, csCluster, policy.Ingress), "reconciling ingress firewall rules")
+}
+
+// defaultEgressFirewallRules allow all TCP, UDP and ICMP traffic leaving a network whose policy has no egress rules.
+var defaultEgressFirewallRules = []infrav1.EgressFirewallRule{
+	{Protocol: NetworkProtocolTCP}, {Protocol: NetworkProtocolUDP}, {Protocol: NetworkProtocolICMP},
+}
+
+// reconcileEgressFirewallRules opens the network's egress to the rules of the policy, or to all TCP, UDP and ICMP
+// traffic when the policy has no egress rules. Like the policy's rules, the default rules are tagged at creation, so
+// they're deleted when an allowlist replaces them. Untagged rules, e.g. opened by earlier CAPC versions, are left alone.
+func (c *client) reconcileEgressFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork, rules []infrav1.EgressFirewallRule) error {
+	if len(rules) == 0 {
+		rules = defaultEgressFirewallRules
+	}
+
+	desired := map[firewallRule]bool{}
+	for _, rule := range rules {
+		desired[egressFirewallRule(rule)] = true
+	}
+
+	p := c.cs.Firewall.NewListEgressFirewallRulesParams()
+	p.SetNetworkid(isoNet.Spec.ID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Firewall.ListEgressFirewallRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing egress firewall rules of network with ID %s", isoNet.Spec.ID)
+	}
+	for _, existing := range resp.EgressFirewallRules {
+		key := newFirewallRule(existing.Protocol, existing.Startport, existing.Endport, splitCIDRList(existing.Destcidrlist))
+		if desired[key] {
+			delete(desired, key)
+			continue
+		}
+		if !isCreatedByCAPC(existing.Tags) {
+			continue
+		}
+		if _, err := c.cs.Firewall.DeleteEgressFirewallRule(
+			c.cs.Firewall.NewDeleteEgressFirewallRuleParams(existing.Id)); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "deleting egress firewall rule with ID %s", existing.Id)
+		}
+	}
+
+	for _, rule := range rules {
+		if !desired[egressFirewallRule(rule)] {
+			continue
+		}
+		p := c.cs.Firewall.NewCreateEgressFirewallRuleParams(isoNet.Spec.ID, rule.Protocol)
+		if len(rule.DestinationCIDRs) > 0 {
+			p.SetDestcidrlist(rule.DestinationCIDRs)
+		}
+		if rule.Protocol == NetworkProtocolICMP {
+			p.SetIcmptype(-1)
+			p.SetIcmpcode(-1)
+		} else if rule.StartPort != 0 {
+			p.SetStartport(rule.StartPort)
+			p.SetEndport(egressFirewallRule(rule).endPort)
+		}
+		resp, err := c.cs.Firewall.CreateEgressFirewallRule(p)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "creating egress firewall rule for network ID %s protocol %s", isoNet.Spec.ID, rule.Protocol)
+		}
+		if err := c.AddCreatedByCAPCTag(ResourceTypeFirewallRule, resp.Id); err != nil {
+			return errors.Wrapf(err, "tagging egress firewall rule with ID %s", resp.Id)
+		}
+		delete(desired, egressFirewallRule(rule))
+	}
+	return nil
+}
+
+func egressFirewallRule(rule infrav1.EgressFirewallRule) firewallRule {
+	if rule.Protocol == NetworkProtocolICMP {
+		return newFirewallRule(rule.Protocol, 0, 0, rule.DestinationCIDRs)
+	}
+	endPort := rule.EndPort
+	if endPort == 0 {
+		endPort = rule.StartPort
+	}
+	return newFirewallRule(rule.Protocol, rule.StartPort, endPort, rule.DestinationCIDRs)
+}
+
+// reconcileIngressFirewallRules opens ports of the control plane endpoint's public IP to the rules of the policy, or
+// the endpoint port to 0.0.0.0/0 when the policy has no ingress rules, and the public ports of the workload load
+// balancer rules on their public IPs.
+func (c *client) reconcileIngressFirewallRules(
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+	rules []infrav1.IngressFirewallRule,
+) error {
+	desired := workloadFirewallRules(isoNet, csCluster)
+	if isoNet.Status.PublicIPID != "" {
+		endpointPort := int(isoNet.Spec.ControlPlaneEndpoint.Port)
+		if len(rules) == 0 {
+			rules = []infrav1.IngressFirewallRule{{SourceCIDRs: []string{AnyCIDR}}}
+		}
+		for _, rule := range ingressRulesOfFamily(rules, false) {
+			desired[isoNet.Status.PublicIPID] = append(desired[isoNet.Status.PublicIPID], ingressFirewallRule(rule, endpointPort))
+		}
+	}
+	publicIPIDs := []string{}
+	for publicIPID := range desired {
+		publicIPIDs = append(publicIPIDs, publicIPID)
+	}
+	sort.Strings(publicIPIDs)
+	for _, publicIPID := range publicIPIDs {
+		if err := c.reconcilePublicIPFirewallRules(publicIPID, desired[publicIPID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workloadFirewallRules returns the firewall rules opening the public ports of the isolated network's workload load
+// balancer rules, by the ID of the public IP they're on.
+func workloadFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) map[string][]firewallRule {
+	specs := map[string]infrav1.WorkloadLoadBalancerRule{}
+	for _, rule := range csCluster.Spec.LoadBalancerRules {
+		specs[rule.Name] = rule
+	}
+	rules := map[string][]firewallRule{}
+	for _, status := range isoNet.Status.WorkloadLBRules {
+		rule, found := specs[status.Name]
+		if !found {
+			continue
+		}
+		protocol := rule.Protocol
+		if protocol == "" {
+			protocol = NetworkProtocolTCP
+		}
+		cidrs := []string{AnyCIDR}
+		if len(rule.SourceCIDRs) > 0 {
+			if cidrs = cidrsOfFamily(rule.SourceCIDRs, false); len(cidrs) == 0 {
+				continue
+			}
+		}
+		rules[status.PublicIPID] = append(rules[status.PublicIPID],
+			newFirewallRule(protocol, rule.PublicPort, rule.PublicPort, cidrs))
+	}
+	return rules
+}
+
+// reconcilePublicIPFirewallRules makes the firewall rules of a public IP match the given ones. Only rules CAPC tagged
+// at creation, including the default endpoint rule, are deleted.
+func (c *client) reconcilePublicIPFirewallRules(publicIPID string, rules []firewallRule) error {
+	desired := map[firewallRule]bool{}
+	for _, rule := range rules {
+		desired[rule] = true
+	}
+
+	p := c.cs.Firewall.NewListFirewallRulesParams()
+	p.SetIpaddressid(publicIPID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Firewall.ListFirewallRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing firewall rules of public IP with ID %s", publicIPID)
+	}
+	for _, existing := range resp.FirewallRules {
+		key := newFirewallRule(existing.Protocol, existing.Startport, existing.Endport, splitCIDRList(existing.Cidrlist))
+		if desired[key] {
+			delete(desired, key)
+			continue
+		}
+		if !isCreatedByCAPC(existing.Tags) {
+			continue
+		}
+		if _, err := c.cs.Firewall.DeleteFirewallRule(c.cs.Firewall.NewDeleteFirewallRuleParams(existing.Id)); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "deleting firewall rule with ID %s", existing.Id)
+		}
+	}
+
+	for _, key := range rules {
+		if !desired[key] {
+			continue
+		}
+		p := c.cs.Firewall.NewCreateFirewallRuleParams(publicIPID, key.protocol)
+		p.SetStartport(key.startPort)
+		p.SetEndport(key.endPort)
+		p.SetCidrlist(splitCIDRList(key.cidrs))
+		resp, err := c.cs.Firewall.CreateFirewallRule(p)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "creating firewall rule for port %d", key.startPort)
+		}
+		if err := c.AddCreatedByCAPCTag(ResourceTypeFirewallRule, resp.Id); err != nil {
+			return errors.Wrapf(err, "tagging firewall rule with ID %s", resp.Id)
+		}
+		delete(desired, key)
+	}
+	return nil
+}
+
+// reconcileIPv6IngressFirewallRules opens ports on the IPv6 firewall of a dual-stack network to the IPv6 source CIDRs of
+// the policy's rules, or the endpoint port to ::/0 when the policy has no ingress rules. IPv6 traffic isn't translated,
+// so the rules open the ports of the machines themselves.
+func (c *client) reconcileIPv6IngressFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork, rules []infrav1.IngressFirewallRule) error {
+	endpointPort := int(isoNet.Spec.ControlPlaneEndpoint.Port)
+	if len(rules) == 0 {
+		rules = []infrav1.IngressFirewallRule{{SourceCIDRs: []string{AnyIPv6CIDR}}}
+	}
+	rules = ingressRulesOfFamily(rules, true)
+	desired := map[firewallRule]bool{}
+	for _, rule := range rules {
+		desired[ingressFirewallRule(rule, endpointPort)] = true
+	}
+
+	p := c.cs.Firewall.NewListIpv6FirewallRulesParams()
+	p.SetNetworkid(isoNet.Spec.ID)
+	p.SetTraffictype(FirewallTrafficTypeIngress)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Firewall.ListIpv6FirewallRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing IPv6 firewall rules of network with ID %s", isoNet.Spec.ID)
+	}
+	for _, existing := range resp.Ipv6FirewallRules {
+		startPort, _ := strconv.Atoi(existing.Publicport)
+		endPort, _ := strconv.Atoi(existing.Publicendport)
+		key := newFirewallRule(existing.Protocol, startPort, endPort, splitCIDRList(existing.Cidrlist))
+		if desired[key] {
+			delete(desired, key)
+			continue
+		}
+		// IPv6 ingress is denied unless opened, so there's no default rule to replace.
+		if !isCreatedByCAPC(existing.Tags) {
+			continue
+		}
+		if _, err := c.cs.Firewall.DeleteIpv6FirewallRule(c.cs.Firewall.NewDeleteIpv6FirewallRuleParams(existing.Id)); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "deleting IPv6 firewall rule with ID %s", existing.Id)
+		}
+	}
+
+	for _, rule := range rules {
+		key := ingressFirewallRule(rule, endpointPort)
+		if !desired[key] {
+			continue
+		}
+		p := c.cs.Firewall.NewCreateIpv6FirewallRuleParams(isoNet.Spec.ID, NetworkProtocolTCP)
+		p.SetStartport(key.startPort)
+		p.SetEndport(key.endPort)
+		p.SetCidrlist(rule.SourceCIDRs)
+		p.SetTraffictype(FirewallTrafficTypeIngress)
+		resp, err := c.cs.Firewall.CreateIpv6FirewallRule(p)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "creating IPv6 firewall rule for port %d", key.startPort)
+		}
+		if err := c.AddCreatedByCAPCTag(ResourceTypeFirewallRule, resp.Id); err != nil {
+			return errors.Wrapf(err, "tagging IPv6 firewall rule with ID %s", resp.Id)
+		}
+		delete(desired, key)
+	}
+	return nil
+}
+
+// ingressRulesOfFamily returns the rules restricted to their IPv6 or IPv4 source CIDRs. Rules without source CIDRs of
+// the family are dropped.
+func ingressRulesOfFamily(rules []infrav1.IngressFirewallRule, ipv6 bool) []infrav1.IngressFirewallRule {
+	filtered := []infrav1.IngressFirewallRule{}
+	for _, rule := range rules {
+		if cidrs := cidrsOfFamily(rule.SourceCIDRs, ipv6); len(cidrs) > 0 {
+			filtered = append(filtered, infrav1.IngressFirewallRule{Port: rule.Port, SourceCIDRs: cidrs})
+		}
+	}
+	return filtered
+}
+
+// cidrsOfFamily returns the IPv6 or IPv4 CIDRs of a list.
+func cidrsOfFamily(cidrs []string, ipv6 bool) []string {
+	var filtered []string
+	for _, cidr := range cidrs {
+		if ip, _, err := net.ParseCIDR(cidr); err == nil && (ip.To4() == nil) == ipv6 {
+			filtered = append(filtered, cidr)
+		}
+	}
+	return filtered
+}
+
+func ingressFirewallRule(rule infrav1.IngressFirewallRule, endpointPort int) firewallRule {
+	port := rule.Port
+	if port == 0 {
+		port = endpointPort
+	}
+	return newFirewallRule(NetworkProtocolTCP, port, port, rule.SourceCIDRs)
+}
+
+// splitCIDRList splits a comma separated CIDR list as returned by CloudStack.
+func splitCIDRList(cidrList string) []string {
+	cidrs := []string{}
+	for _, cidr := range strings.Split(cidrList, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	return cidrs
+}
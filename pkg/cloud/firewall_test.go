@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Firewall", func() {
+	var ( // Declare shared vars.
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		fs         *csapi.MockFirewallServiceIface
+		rs         *csapi.MockResourcetagsServiceIface
+		client     cloud.Client
+	)
+
+	capcTags := []csapi.Tags{{Key: cloud.CreatedByCAPCTagName, Value: "1"}}
+
+	BeforeEach(func() {
+		// Setup new mock services.
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		fs = mockClient.Firewall.(*csapi.MockFirewallServiceIface)
+		rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		dummies.CSISONet1.Status.PublicIPID = dummies.PublicIPID
+		dummies.CSISONet1.Spec.ControlPlaneEndpoint.Port = 6443
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("with an egress allowlist", func() {
+		It("replaces the default allow-all rules CAPC opened and deletes stale CAPC rules", func() {
+			dummies.CSCluster.Spec.FirewallPolicy = &infrav1.FirewallPolicy{Egress: []infrav1.EgressFirewallRule{
+				{Protocol: "tcp", StartPort: 443, DestinationCIDRs: []string{"10.0.0.0/8"}},
+				{Protocol: "udp", StartPort: 53},
+			}}
+
+			fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+			fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{
+				EgressFirewallRules: []*csapi.EgressFirewallRule{
+					{Id: "default-tcp", Protocol: "tcp", Tags: capcTags},
+					{Id: "untagged-udp", Protocol: "udp"},
+					{Id: "kept-udp", Protocol: "udp", Startport: 53, Endport: 53, Tags: capcTags},
+					{Id: "stale", Protocol: "tcp", Startport: 80, Endport: 80, Tags: capcTags},
+					{Id: "foreign", Protocol: "tcp", Startport: 22, Endport: 22},
+				}}, nil)
+			fs.EXPECT().NewDeleteEgressFirewallRuleParams("default-tcp").Return(&csapi.DeleteEgressFirewallRuleParams{})
+			fs.EXPECT().NewDeleteEgressFirewallRuleParams("stale").Return(&csapi.DeleteEgressFirewallRuleParams{})
+			fs.EXPECT().DeleteEgressFirewallRule(gomock.Any()).Return(&csapi.DeleteEgressFirewallRuleResponse{}, nil).Times(2)
+			fs.EXPECT().NewCreateEgressFirewallRuleParams(dummies.CSISONet1.Spec.ID, "tcp").
+				Return(&csapi.CreateEgressFirewallRuleParams{})
+			fs.EXPECT().CreateEgressFirewallRule(gomock.Any()).Return(&csapi.CreateEgressFirewallRuleResponse{Id: "new-tcp"}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{"new-tcp"}, string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			// Default ingress rule already present.
+			fs.EXPECT().NewListFirewallRulesParams().Return(&csapi.ListFirewallRulesParams{})
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{
+				FirewallRules: []*csapi.FirewallRule{
+					{Id: "endpoint", Protocol: "tcp", Startport: 6443, Endport: 6443, Cidrlist: cloud.AnyCIDR}}}, nil)
+
+			Ω(client.ReconcileFirewallPolicy(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
+	Context("without egress rules", func() {
+		It("opens the default allow-all rules once", func() {
+			dummies.CSCluster.Spec.FirewallPolicy = nil
+			dummies.CSISONet1.Status.PublicIPID = ""
+
+			fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+			fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{
+				EgressFirewallRules: []*csapi.EgressFirewallRule{
+					{Id: "default-tcp", Protocol: "tcp", Tags: capcTags},
+					{Id: "default-udp", Protocol: "udp", Tags: capcTags},
+				}}, nil)
+			fs.EXPECT().NewCreateEgressFirewallRuleParams(dummies.CSISONet1.Spec.ID, "icmp").
+				Return(&csapi.CreateEgressFirewallRuleParams{})
+			fs.EXPECT().CreateEgressFirewallRule(gomock.Any()).Return(&csapi.CreateEgressFirewallRuleResponse{Id: "default-icmp"}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{"default-icmp"}, string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			Ω(client.ReconcileFirewallPolicy(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
+	Context("with ingress source CIDRs", func() {
+		It("restricts the endpoint port and leaves rules CAPC didn't create alone", func() {
+			dummies.CSCluster.Spec.FirewallPolicy = &infrav1.FirewallPolicy{
+				Ingress: []infrav1.IngressFirewallRule{{SourceCIDRs: []string{"192.0.2.0/24", "198.51.100.0/24"}}},
+				Egress:  []infrav1.EgressFirewallRule{{Protocol: "icmp"}},
+			}
+
+			fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+			fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{
+				EgressFirewallRules: []*csapi.EgressFirewallRule{{Id: "default-icmp", Protocol: "icmp"}}}, nil)
+
+			fs.EXPECT().NewListFirewallRulesParams().Return(&csapi.ListFirewallRulesParams{})
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{
+				FirewallRules: []*csapi.FirewallRule{
+					{Id: "endpoint", Protocol: "tcp", Startport: 6443, Endport: 6443, Cidrlist: cloud.AnyCIDR, Tags: capcTags},
+					{Id: "foreign", Protocol: "tcp", Startport: 22, Endport: 22, Cidrlist: cloud.AnyCIDR},
+				}}, nil)
+			fs.EXPECT().NewDeleteFirewallRuleParams("endpoint").Return(&csapi.DeleteFirewallRuleParams{})
+			fs.EXPECT().DeleteFirewallRule(gomock.Any()).Return(&csapi.DeleteFirewallRuleResponse{}, nil)
+			fs.EXPECT().NewCreateFirewallRuleParams(dummies.PublicIPID, "tcp").Return(&csapi.CreateFirewallRuleParams{})
+			fs.EXPECT().CreateFirewallRule(gomock.Any()).Return(&csapi.CreateFirewallRuleResponse{Id: "restricted"}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{"restricted"}, string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			Ω(client.ReconcileFirewallPolicy(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
+	Context("with workload load balancer rules", func() {
+		It("opens the rules' public ports to their source CIDRs", func() {
+			dummies.CSCluster.Spec.FirewallPolicy = &infrav1.FirewallPolicy{Egress: []infrav1.EgressFirewallRule{{Protocol: "icmp"}}}
+			dummies.CSCluster.Spec.LoadBalancerRules = []infrav1.WorkloadLoadBalancerRule{
+				{Name: "https", PublicPort: 443},
+				{Name: "dns", PublicPort: 53, Protocol: "udp", SourceCIDRs: []string{"192.0.2.0/24", "2001:db8::/32"}},
+			}
+			dummies.CSISONet1.Status.WorkloadLBRules = []infrav1.WorkloadLBRuleStatus{
+				{Name: "dns", ID: "DNSRuleID", PublicIPID: "DedicatedIPID", DedicatedPublicIP: true},
+				{Name: "https", ID: "HTTPSRuleID", PublicIPID: dummies.PublicIPID},
+			}
+
+			fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+			fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{
+				EgressFirewallRules: []*csapi.EgressFirewallRule{{Id: "default-icmp", Protocol: "icmp"}}}, nil)
+
+			fs.EXPECT().NewListFirewallRulesParams().Return(&csapi.ListFirewallRulesParams{}).Times(2)
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{}, nil)
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{
+				FirewallRules: []*csapi.FirewallRule{
+					{Id: "endpoint", Protocol: "tcp", Startport: 6443, Endport: 6443, Cidrlist: cloud.AnyCIDR, Tags: capcTags},
+				}}, nil)
+			fs.EXPECT().NewCreateFirewallRuleParams("DedicatedIPID", "udp").Return(&csapi.CreateFirewallRuleParams{})
+			fs.EXPECT().NewCreateFirewallRuleParams(dummies.PublicIPID, "tcp").Return(&csapi.CreateFirewallRuleParams{})
+			fs.EXPECT().CreateFirewallRule(gomock.Any()).
+				DoAndReturn(func(p *csapi.CreateFirewallRuleParams) (*csapi.CreateFirewallRuleResponse, error) {
+					cidrs, _ := p.GetCidrlist()
+					Ω(cidrs).Should(Equal([]string{"192.0.2.0/24"}))
+					return &csapi.CreateFirewallRuleResponse{Id: "dns"}, nil
+				})
+			fs.EXPECT().CreateFirewallRule(gomock.Any()).
+				DoAndReturn(func(p *csapi.CreateFirewallRuleParams) (*csapi.CreateFirewallRuleResponse, error) {
+					port, _ := p.GetStartport()
+					Ω(port).Should(Equal(443))
+					return &csapi.CreateFirewallRuleResponse{Id: "https"}, nil
+				})
+			rs.EXPECT().NewCreateTagsParams(gomock.Any(), string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{}).Times(2)
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(2)
+
+			Ω(client.ReconcileFirewallPolicy(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
+	Context("on a dual-stack network", func() {
+		It("opens the IPv6 source CIDRs on the network's IPv6 firewall", func() {
+			dummies.CSISONet1.Status.IPv6CIDR = "2001:db8:1::/64"
+			dummies.CSISONet1.Status.PublicIPID = ""
+			dummies.CSCluster.Spec.FirewallPolicy = &infrav1.FirewallPolicy{
+				Ingress: []infrav1.IngressFirewallRule{{SourceCIDRs: []string{"192.0.2.0/24", "2001:db8:2::/48"}}},
+				Egress:  []infrav1.EgressFirewallRule{{Protocol: "icmp"}},
+			}
+
+			fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+			fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{
+				EgressFirewallRules: []*csapi.EgressFirewallRule{{Id: "default-icmp", Protocol: "icmp"}}}, nil)
+
+			fs.EXPECT().NewListIpv6FirewallRulesParams().Return(&csapi.ListIpv6FirewallRulesParams{})
+			fs.EXPECT().ListIpv6FirewallRules(gomock.Any()).Return(&csapi.ListIpv6FirewallRulesResponse{
+				Ipv6FirewallRules: []*csapi.Ipv6FirewallRule{{Id: "stale", Protocol: "tcp", Publicport: "6443",
+					Publicendport: "6443", Cidrlist: cloud.AnyIPv6CIDR, Tags: capcTags}}}, nil)
+			fs.EXPECT().NewDeleteIpv6FirewallRuleParams("stale").Return(&csapi.DeleteIpv6FirewallRuleParams{})
+			fs.EXPECT().DeleteIpv6FirewallRule(gomock.Any()).Return(&csapi.DeleteIpv6FirewallRuleResponse{}, nil)
+			fs.EXPECT().NewCreateIpv6FirewallRuleParams(dummies.CSISONet1.Spec.ID, "tcp").
+				Return(&csapi.CreateIpv6FirewallRuleParams{})
+			fs.EXPECT().CreateIpv6FirewallRule(gomock.Any()).
+				DoAndReturn(func(p *csapi.CreateIpv6FirewallRuleParams) (*csapi.CreateIpv6FirewallRuleResponse, error) {
+					cidrs, _ := p.GetCidrlist()
+					Ω(cidrs).Should(Equal([]string{"2001:db8:2::/48"}))
+					port, _ := p.GetStartport()
+					Ω(port).Should(Equal(6443))
+					return &csapi.CreateIpv6FirewallRuleResponse{Id: "ipv6-endpoint"}, nil
+				})
+			rs.EXPECT().NewCreateTagsParams([]string{"ipv6-endpoint"}, string(cloud.ResourceTypeFirewallRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			Ω(client.ReconcileFirewallPolicy(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+})
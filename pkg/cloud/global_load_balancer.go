@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"sort"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+type GlobalLoadBalancerIface interface {
+	ReconcileGlobalLoadBalancerRule(*infrav1.CloudStackCluster, []string) error
+	DeleteGlobalLoadBalancerRule(*infrav1.CloudStackCluster) error
+}
+
+const (
+	GSLBServiceTypeTCP      = "tcp"
+	GSLBDefaultRegionID     = 1
+	GSLBAlgorithmRoundRobin = "roundrobin"
+)
+
+// gslbRegionID returns the configured region of the global load balancer rule or the default region.
+func gslbRegionID(gslb *infrav1.GlobalLoadBalancer) int {
+	if gslb.RegionID != 0 {
+		return gslb.RegionID
+	}
+	return GSLBDefaultRegionID
+}
+
+// gslbAlgorithm returns the configured global load balancing method or the roundrobin default.
+func gslbAlgorithm(gslb *infrav1.GlobalLoadBalancer) string {
+	if gslb.Algorithm != "" {
+		return gslb.Algorithm
+	}
+	return GSLBAlgorithmRoundRobin
+}
+
+// findGlobalLoadBalancerRule looks up the cluster's global load balancer rule by its GSLB domain name, which is unique
+// within a region. Returns nil without error when there is none.
+func (c *client) findGlobalLoadBalancerRule(csCluster *infrav1.CloudStackCluster) (*cloudstack.GlobalLoadBalancerRule, error) {
+	gslb := csCluster.Spec.GlobalLoadBalancer
+	p := c.cs.LoadBalancer.NewListGlobalLoadBalancerRulesParams()
+	p.SetRegionid(gslbRegionID(gslb))
+	p.SetKeyword(gslb.DomainName)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.LoadBalancer.ListGlobalLoadBalancerRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing global load balancer rules")
+	}
+	for _, rule := range resp.GlobalLoadBalancerRules {
+		if rule.Gslbdomainname == gslb.DomainName {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// ReconcileGlobalLoadBalancerRule creates the cluster's global load balancer rule if missing, and assigns it exactly the
+// passed API server load balancer rules of the cluster's isolated networks.
+func (c *client) ReconcileGlobalLoadBalancerRule(csCluster *infrav1.CloudStackCluster, lbRuleIDs []string) error {
+	gslb := csCluster.Spec.GlobalLoadBalancer
+	rule, err := c.findGlobalLoadBalancerRule(csCluster)
+	if err != nil {
+		return err
+	}
+
+	assigned := map[string]bool{}
+	if rule == nil {
+		p := c.csAsync.LoadBalancer.NewCreateGlobalLoadBalancerRuleParams(
+			gslb.DomainName, GSLBServiceTypeTCP, csCluster.Name+"-"+gslb.DomainName, gslbRegionID(gslb))
+		p.SetGslblbmethod(gslbAlgorithm(gslb))
+		p.SetDescription("Kubernetes API servers of cluster " + csCluster.Name)
+		setIfNotEmpty(c.user.Account.Name, p.SetAccount)
+		setIfNotEmpty(c.user.Account.Domain.ID, p.SetDomainid)
+		resp, err := c.csAsync.LoadBalancer.CreateGlobalLoadBalancerRule(p)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "creating global load balancer rule for domain name %s", gslb.DomainName)
+		}
+		rule = &cloudstack.GlobalLoadBalancerRule{Id: resp.Id, Gslblbmethod: resp.Gslblbmethod}
+	} else {
+		for _, lbRule := range rule.Loadbalancerrule {
+			assigned[lbRule.Id] = true
+		}
+	}
+
+	if rule.Gslblbmethod != gslbAlgorithm(gslb) {
+		p := c.csAsync.LoadBalancer.NewUpdateGlobalLoadBalancerRuleParams(rule.Id)
+		p.SetGslblbmethod(gslbAlgorithm(gslb))
+		if _, err := c.csAsync.LoadBalancer.UpdateGlobalLoadBalancerRule(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "updating global load balancer rule with ID %s", rule.Id)
+		}
+	}
+
+	var toAssign []string
+	wanted := map[string]bool{}
+	for _, id := range lbRuleIDs {
+		wanted[id] = true
+		if !assigned[id] {
+			toAssign = append(toAssign, id)
+		}
+	}
+	var toRemove []string
+	for id := range assigned {
+		if !wanted[id] {
+			toRemove = append(toRemove, id)
+		}
+	}
+	sort.Strings(toRemove)
+
+	if len(toAssign) > 0 {
+		p := c.csAsync.LoadBalancer.NewAssignToGlobalLoadBalancerRuleParams(rule.Id, toAssign)
+		if _, err := c.csAsync.LoadBalancer.AssignToGlobalLoadBalancerRule(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "assigning load balancer rules to global load balancer rule with ID %s", rule.Id)
+		}
+	}
+	if len(toRemove) > 0 {
+		p := c.csAsync.LoadBalancer.NewRemoveFromGlobalLoadBalancerRuleParams(rule.Id, toRemove)
+		if _, err := c.csAsync.LoadBalancer.RemoveFromGlobalLoadBalancerRule(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "removing load balancer rules from global load balancer rule with ID %s", rule.Id)
+		}
+	}
+
+	csCluster.Status.GlobalLoadBalancer = &infrav1.GlobalLoadBalancerStatus{RuleID: rule.Id, LoadBalancerRuleIDs: lbRuleIDs}
+	return nil
+}
+
+// DeleteGlobalLoadBalancerRule deletes the cluster's global load balancer rule, if it exists.
+func (c *client) DeleteGlobalLoadBalancerRule(csCluster *infrav1.CloudStackCluster) error {
+	if csCluster.Spec.GlobalLoadBalancer == nil {
+		return nil
+	}
+	rule, err := c.findGlobalLoadBalancerRule(csCluster)
+	if err != nil || rule == nil {
+		return err
+	}
+	if _, err := c.csAsync.LoadBalancer.DeleteGlobalLoadBalancerRule(
+		c.csAsync.LoadBalancer.NewDeleteGlobalLoadBalancerRuleParams(rule.Id)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "deleting global load balancer rule with ID %s", rule.Id)
+	}
+	csCluster.Status.GlobalLoadBalancer = nil
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Global Load Balancer", func() {
+	var ( // Declare shared vars.
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		lbs        *csapi.MockLoadBalancerServiceIface
+		client     cloud.Client
+	)
+
+	BeforeEach(func() {
+		// Setup new mock services.
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		lbs = mockClient.LoadBalancer.(*csapi.MockLoadBalancerServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		dummies.CSCluster.Spec.GlobalLoadBalancer = &infrav1.GlobalLoadBalancer{
+			DomainName: "cluster1", ServiceDomain: "gslb.example.com"}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("creates the rule and assigns the load balancer rules of all failure domains", func() {
+		lbs.EXPECT().NewListGlobalLoadBalancerRulesParams().Return(&csapi.ListGlobalLoadBalancerRulesParams{})
+		lbs.EXPECT().ListGlobalLoadBalancerRules(gomock.Any()).Return(&csapi.ListGlobalLoadBalancerRulesResponse{
+			GlobalLoadBalancerRules: []*csapi.GlobalLoadBalancerRule{{Id: "other-id", Gslbdomainname: "cluster10"}}}, nil)
+		lbs.EXPECT().NewCreateGlobalLoadBalancerRuleParams(
+			"cluster1", cloud.GSLBServiceTypeTCP, dummies.CSCluster.Name+"-cluster1", cloud.GSLBDefaultRegionID).
+			Return(&csapi.CreateGlobalLoadBalancerRuleParams{})
+		lbs.EXPECT().CreateGlobalLoadBalancerRule(gomock.Any()).
+			DoAndReturn(func(p *csapi.CreateGlobalLoadBalancerRuleParams) (*csapi.CreateGlobalLoadBalancerRuleResponse, error) {
+				method, _ := p.GetGslblbmethod()
+				Ω(method).Should(Equal(cloud.GSLBAlgorithmRoundRobin))
+				return &csapi.CreateGlobalLoadBalancerRuleResponse{Id: "gslb-id", Gslblbmethod: "roundrobin"}, nil
+			})
+		lbs.EXPECT().NewAssignToGlobalLoadBalancerRuleParams("gslb-id", []string{"lb-1", "lb-2"}).
+			Return(&csapi.AssignToGlobalLoadBalancerRuleParams{})
+		lbs.EXPECT().AssignToGlobalLoadBalancerRule(gomock.Any()).Return(&csapi.AssignToGlobalLoadBalancerRuleResponse{}, nil)
+
+		Ω(client.ReconcileGlobalLoadBalancerRule(dummies.CSCluster, []string{"lb-1", "lb-2"})).Should(Succeed())
+		Ω(dummies.CSCluster.Status.GlobalLoadBalancer).Should(Equal(&infrav1.GlobalLoadBalancerStatus{
+			RuleID: "gslb-id", LoadBalancerRuleIDs: []string{"lb-1", "lb-2"}}))
+	})
+
+	It("updates the algorithm and the assigned load balancer rules of an existing rule", func() {
+		dummies.CSCluster.Spec.GlobalLoadBalancer.Algorithm = "proximity"
+		lbs.EXPECT().NewListGlobalLoadBalancerRulesParams().Return(&csapi.ListGlobalLoadBalancerRulesParams{})
+		lbs.EXPECT().ListGlobalLoadBalancerRules(gomock.Any()).Return(&csapi.ListGlobalLoadBalancerRulesResponse{
+			GlobalLoadBalancerRules: []*csapi.GlobalLoadBalancerRule{{Id: "gslb-id", Gslbdomainname: "cluster1",
+				Gslblbmethod: "roundrobin", Loadbalancerrule: []csapi.GlobalLoadBalancerRuleLoadbalancerrule{
+					{Id: "lb-1"}, {Id: "lb-stale"}}}}}, nil)
+		lbs.EXPECT().NewUpdateGlobalLoadBalancerRuleParams("gslb-id").Return(&csapi.UpdateGlobalLoadBalancerRuleParams{})
+		lbs.EXPECT().UpdateGlobalLoadBalancerRule(gomock.Any()).Return(&csapi.UpdateGlobalLoadBalancerRuleResponse{}, nil)
+		lbs.EXPECT().NewAssignToGlobalLoadBalancerRuleParams("gslb-id", []string{"lb-2"}).
+			Return(&csapi.AssignToGlobalLoadBalancerRuleParams{})
+		lbs.EXPECT().AssignToGlobalLoadBalancerRule(gomock.Any()).Return(&csapi.AssignToGlobalLoadBalancerRuleResponse{}, nil)
+		lbs.EXPECT().NewRemoveFromGlobalLoadBalancerRuleParams("gslb-id", []string{"lb-stale"}).
+			Return(&csapi.RemoveFromGlobalLoadBalancerRuleParams{})
+		lbs.EXPECT().RemoveFromGlobalLoadBalancerRule(gomock.Any()).Return(&csapi.RemoveFromGlobalLoadBalancerRuleResponse{}, nil)
+
+		Ω(client.ReconcileGlobalLoadBalancerRule(dummies.CSCluster, []string{"lb-1", "lb-2"})).Should(Succeed())
+	})
+
+	It("deletes the rule", func() {
+		dummies.CSCluster.Status.GlobalLoadBalancer = &infrav1.GlobalLoadBalancerStatus{RuleID: "gslb-id"}
+		lbs.EXPECT().NewListGlobalLoadBalancerRulesParams().Return(&csapi.ListGlobalLoadBalancerRulesParams{})
+		lbs.EXPECT().ListGlobalLoadBalancerRules(gomock.Any()).Return(&csapi.ListGlobalLoadBalancerRulesResponse{
+			GlobalLoadBalancerRules: []*csapi.GlobalLoadBalancerRule{{Id: "gslb-id", Gslbdomainname: "cluster1"}}}, nil)
+		lbs.EXPECT().NewDeleteGlobalLoadBalancerRuleParams("gslb-id").Return(&csapi.DeleteGlobalLoadBalancerRuleParams{})
+		lbs.EXPECT().DeleteGlobalLoadBalancerRule(gomock.Any()).Return(&csapi.DeleteGlobalLoadBalancerRuleResponse{}, nil)
+
+		Ω(client.DeleteGlobalLoadBalancerRule(dummies.CSCluster)).Should(Succeed())
+		Ω(dummies.CSCluster.Status.GlobalLoadBalancer).Should(BeNil())
+	})
+})
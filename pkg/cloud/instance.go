@@ -38,6 +38,10 @@ type VMIface interface {
 	GetOrCreateVMInstance(*infrav1.CloudStackMachine, *clusterv1.Machine, *infrav1.CloudStackCluster, *infrav1.CloudStackFailureDomain, *infrav1.CloudStackAffinityGroup, string) error
 	ResolveVMInstanceDetails(*infrav1.CloudStackMachine) error
 	DestroyVMInstance(*infrav1.CloudStackMachine) error
+	StopVMInstance(*infrav1.CloudStackMachine) error
+	StartVMInstance(*infrav1.CloudStackMachine) error
+	CheckScaleUpLimits(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackMachine, int64) error
+	ReconcileVMTags(*infrav1.CloudStackMachine, *infrav1.CloudStackCluster) error
 }
 
 // Set infrastructure spec and status from the CloudStack API's virtual machine metrics type.
@@ -45,7 +49,19 @@ func setMachineDataFromVMMetrics(vmResponse *cloudstack.VirtualMachinesMetric, c
 	csMachine.Spec.ProviderID = pointer.String(fmt.Sprintf("cloudstack:///%s", vmResponse.Id))
 	// InstanceID is later used as required parameter to destroy VM.
 	csMachine.Spec.InstanceID = pointer.String(vmResponse.Id)
+	// Hosts are only returned to root admins.
+	if vmResponse.Hostid != "" {
+		csMachine.Status.HostID = vmResponse.Hostid
+		csMachine.Status.HostName = vmResponse.Hostname
+	}
 	csMachine.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: vmResponse.Ipaddress}}
+	// NICs on IPv6 enabled networks have an IPv6 address besides the IPv4 one.
+	for _, nic := range vmResponse.Nic {
+		if nic.Ip6address != "" {
+			csMachine.Status.Addresses = append(csMachine.Status.Addresses,
+				corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: nic.Ip6address})
+		}
+	}
 	newInstanceState := vmResponse.State
 	if newInstanceState != csMachine.Status.InstanceState || (newInstanceState != "" && csMachine.Status.InstanceStateLastUpdated.IsZero()) {
 		csMachine.Status.InstanceState = newInstanceState
@@ -309,6 +325,135 @@ func (c *client) CheckLimits(
 	return nil
 }
 
+// CheckScaleUpLimits checks that the account, domain and project have enough VMs, CPU, memory and primary storage
+// available to deploy the given number of additional machines shaped like csMachine in the failure domain. The
+// available amounts are looked up afresh rather than taken from the client's cached user.
+func (c *client) CheckScaleUpLimits(
+	fd *infrav1.CloudStackFailureDomain,
+	csMachine *infrav1.CloudStackMachine,
+	replicas int64,
+) error {
+	if replicas < 1 {
+		return nil
+	}
+
+	offering, err := c.ResolveServiceOffering(csMachine, fd.Spec.Zone.ID)
+	if err != nil {
+		return err
+	}
+	rootDiskSize, err := c.resolveRootDiskSize(offering, csMachine, fd.Spec.Zone.ID)
+	if err != nil {
+		return err
+	}
+	dataDiskSize, err := c.resolveDataDiskSize(csMachine, fd.Spec.Zone.ID)
+	if err != nil {
+		return err
+	}
+
+	cpu := replicas * int64(offering.Cpunumber)
+	memory := replicas * int64(offering.Memory)
+	storage := replicas * (rootDiskSize + dataDiskSize)
+
+	// The limits recorded when the client was created may be up to an hour old, so look up what is available now.
+	// Work on a copy as the client, and with it its user, is shared through the client cache.
+	user := *c.user
+	if err := c.ResolveAccount(&user.Account); err != nil {
+		return errors.Wrapf(err, "resolving account %s limits", user.Account.Name)
+	}
+	if err := c.ResolveProject(&user); err != nil {
+		return errors.Wrapf(err, "resolving project %s limits", user.Project.Name)
+	}
+
+	scopes := []resourcesAvailable{
+		{"account", user.Account.VMAvailable, user.Account.CPUAvailable,
+			user.Account.MemoryAvailable, user.Account.PrimaryStorageAvailable},
+		{"domain", user.Account.Domain.VMAvailable, user.Account.Domain.CPUAvailable,
+			user.Account.Domain.MemoryAvailable, user.Account.Domain.PrimaryStorageAvailable},
+	}
+	if user.Project.ID != "" {
+		scopes = append(scopes, resourcesAvailable{"project", user.Project.VMAvailable, user.Project.CPUAvailable,
+			user.Project.MemoryAvailable, user.Project.PrimaryStorageAvailable})
+	}
+	for _, available := range scopes {
+		if err := available.check("VM", available.vm, replicas, replicas); err != nil {
+			return err
+		}
+		if err := available.check("CPU", available.cpu, replicas, cpu); err != nil {
+			return err
+		}
+		if err := available.check("memory", available.memory, replicas, memory); err != nil {
+			return err
+		}
+		if err := available.check("primary storage", available.primaryStorage, replicas, storage); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resourcesAvailable holds the resource amounts CloudStack reports as still available in an account, domain or project.
+type resourcesAvailable struct {
+	scope          string
+	vm             string
+	cpu            string
+	memory         string
+	primaryStorage string
+}
+
+// check returns an error if the available amount of a resource can't cover the demand of a scale-up.
+func (r resourcesAvailable) check(resource, available string, replicas, demand int64) error {
+	if available == "Unlimited" {
+		return nil
+	}
+	availableAmount, err := strconv.ParseInt(available, 10, 0)
+	if err == nil && demand > availableAmount {
+		return fmt.Errorf("%s available (%d) in %s can't fulfil the scale-up requirement of %d machines: %d",
+			resource, availableAmount, r.scope, replicas, demand)
+	}
+	return nil
+}
+
+// resolveRootDiskSize returns the size in GB of the root disk a machine would be deployed with. Offerings that don't
+// set a root disk size leave it to the template's size.
+func (c *client) resolveRootDiskSize(offering cloudstack.ServiceOffering, csMachine *infrav1.CloudStackMachine, zoneID string) (int64, error) {
+	if offering.Rootdisksize > 0 {
+		return offering.Rootdisksize, nil
+	}
+	templateID, err := c.ResolveTemplate(nil, csMachine, zoneID)
+	if err != nil {
+		return 0, err
+	}
+	csTemplate, count, err := c.cs.Template.GetTemplateByID(templateID, "executable", cloudstack.WithProject(c.user.Project.ID))
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return 0, errors.Wrapf(err, "could not get Template by ID %s", templateID)
+	} else if count != 1 {
+		return 0, errors.Errorf("expected 1 Template with UUID %s, but got %d", templateID, count)
+	}
+	// Template sizes are in bytes.
+	return (csTemplate.Size + 1<<30 - 1) >> 30, nil
+}
+
+// resolveDataDiskSize returns the size in GB of the data disk a machine would be deployed with.
+func (c *client) resolveDataDiskSize(csMachine *infrav1.CloudStackMachine, zoneID string) (int64, error) {
+	if csMachine.Spec.DiskOffering.CustomSize > 0 {
+		return csMachine.Spec.DiskOffering.CustomSize, nil
+	}
+	diskOfferingID, err := c.ResolveDiskOffering(csMachine, zoneID)
+	if err != nil || diskOfferingID == "" {
+		return 0, err
+	}
+	csDiskOffering, count, err := c.cs.DiskOffering.GetDiskOfferingByID(diskOfferingID, cloudstack.WithProject(c.user.Project.ID))
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return 0, errors.Wrapf(err, "could not get DiskOffering by ID %s", diskOfferingID)
+	} else if count != 1 {
+		return 0, errors.Errorf("expected 1 DiskOffering with UUID %s, but got %d", diskOfferingID, count)
+	}
+	return csDiskOffering.Disksize, nil
+}
+
 // DeployVM will create a VM instance,
 // and sets the infrastructure machine spec and status accordingly.
 func (c *client) DeployVM(
@@ -330,7 +475,7 @@ func (c *client) DeployVM(
 	}
 
 	p := c.cs.VirtualMachine.NewDeployVirtualMachineParams(offering.Id, templateID, fd.Spec.Zone.ID)
-	p.SetNetworkids([]string{fd.Spec.Zone.Network.ID})
+	p.SetNetworkids([]string{machineNetworkID(fd, csMachine)})
 	setIfNotEmpty(csMachine.Name, p.SetName)
 	setIfNotEmpty(capiMachine.Name, p.SetDisplayname)
 	setIfNotEmpty(diskOfferingID, p.SetDiskofferingid)
@@ -358,6 +503,10 @@ func (c *client) DeployVM(
 		p.SetDetails(csMachine.Spec.Details)
 	}
 
+	if err := c.setPlacement(p, csMachine, fd); err != nil {
+		return err
+	}
+
 	deployVMResp, err := c.cs.VirtualMachine.DeployVirtualMachine(p)
 	if err != nil {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
@@ -385,6 +534,189 @@ func (c *client) DeployVM(
 	csMachine.Spec.InstanceID = pointer.String(deployVMResp.Id)
 	csMachine.Status.Status = pointer.String(metav1.StatusSuccess)
 
+	return c.tagVMResources(deployVMResp.Id, machineResourceTags(csCluster, csMachine, machineRole(csMachine)))
+}
+
+// setPlacement pins the VM to the pod and cluster of the failure domain's placement, and to the host the placement and
+// the machine select. Machines with an ExplicitDedication affinity group are only placed on the resources dedicated
+// with it.
+func (c *client) setPlacement(
+	p *cloudstack.DeployVirtualMachineParams, csMachine *infrav1.CloudStackMachine, fd *infrav1.CloudStackFailureDomain,
+) error {
+	placement := fd.Spec.Placement
+	var hostID string
+	var hostTags []string
+	if placement != nil {
+		if placement.Pod != nil {
+			setIfNotEmpty(placement.Pod.ID, p.SetPodid)
+		}
+		if placement.Cluster != nil {
+			setIfNotEmpty(placement.Cluster.ID, p.SetClusterid)
+		}
+		hostTags = append(hostTags, placement.HostTags...)
+	}
+	if csMachine.Spec.Host != nil {
+		hostID = csMachine.Spec.Host.ID
+		hostTags = append(hostTags, csMachine.Spec.Host.Tags...)
+	}
+	setIfNotEmpty(csMachine.Spec.DeploymentPlanner, p.SetDeploymentplanner)
+
+	var accept func(*cloudstack.Host) bool
+	if group := csMachine.Spec.ExplicitDedicationAffinityGroup; group != nil {
+		dedicated, err := c.getDedicatedResources(group)
+		if err != nil {
+			return err
+		}
+		groupIDs, _ := p.GetAffinitygroupids()
+		p.SetAffinitygroupids(append(groupIDs, dedicated.affinityGroupID))
+		accept = dedicated.contain
+	}
+	if hostID == "" && len(hostTags) == 0 {
+		return nil
+	}
+	host, err := c.selectHost(fd.Spec.Zone.ID, placement, hostID, hostTags, accept)
+	if err != nil {
+		return err
+	}
+	p.SetHostid(host.Id)
+	return nil
+}
+
+// dedicatedResources are the zones, pods, clusters and hosts explicitly dedicated with an affinity group.
+type dedicatedResources struct {
+	affinityGroupID string
+	zoneIDs         map[string]bool
+	podIDs          map[string]bool
+	clusterIDs      map[string]bool
+	hostIDs         map[string]bool
+}
+
+// contain checks whether the host is one of the dedicated resources, or in one of them.
+func (d *dedicatedResources) contain(host *cloudstack.Host) bool {
+	return d.hostIDs[host.Id] || d.clusterIDs[host.Clusterid] || d.podIDs[host.Podid] || d.zoneIDs[host.Zoneid]
+}
+
+// getDedicatedResources resolves an ExplicitDedication affinity group and lists the resources dedicated with it to the
+// account or its domain. Listing dedicated resources requires a root admin.
+func (c *client) getDedicatedResources(groupID *infrav1.CloudStackResourceIdentifier) (*dedicatedResources, error) {
+	group := &AffinityGroup{ID: groupID.ID, Name: groupID.Name}
+	if err := c.FetchAffinityGroup(group); err != nil {
+		return nil, errors.Wrapf(err, "fetching ExplicitDedication affinity group %s", groupID.Name+groupID.ID)
+	} else if group.Type != ExplicitDedicationAffinityGroupType {
+		return nil, errors.Errorf("affinity group %s is of type %s, not %s",
+			group.Name, group.Type, ExplicitDedicationAffinityGroupType)
+	}
+	dedicated := &dedicatedResources{affinityGroupID: group.ID,
+		zoneIDs: map[string]bool{}, podIDs: map[string]bool{}, clusterIDs: map[string]bool{}, hostIDs: map[string]bool{}}
+
+	zp := c.cs.Zone.NewListDedicatedZonesParams()
+	zp.SetAffinitygroupid(group.ID)
+	zones, err := c.cs.Zone.ListDedicatedZones(zp)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing dedicated zones")
+	}
+	for _, zone := range zones.DedicatedZones {
+		dedicated.zoneIDs[zone.Zoneid] = c.dedicatedToUser(zone.Accountid, zone.Domainid)
+	}
+	pp := c.cs.Pod.NewListDedicatedPodsParams()
+	pp.SetAffinitygroupid(group.ID)
+	pods, err := c.cs.Pod.ListDedicatedPods(pp)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing dedicated pods")
+	}
+	for _, pod := range pods.DedicatedPods {
+		dedicated.podIDs[pod.Podid] = c.dedicatedToUser(pod.Accountid, pod.Domainid)
+	}
+	cp := c.cs.Cluster.NewListDedicatedClustersParams()
+	cp.SetAffinitygroupid(group.ID)
+	clusters, err := c.cs.Cluster.ListDedicatedClusters(cp)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing dedicated clusters")
+	}
+	for _, cluster := range clusters.DedicatedClusters {
+		dedicated.clusterIDs[cluster.Clusterid] = c.dedicatedToUser(cluster.Accountid, cluster.Domainid)
+	}
+	hp := c.cs.Host.NewListDedicatedHostsParams()
+	hp.SetAffinitygroupid(group.ID)
+	hosts, err := c.cs.Host.ListDedicatedHosts(hp)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing dedicated hosts")
+	}
+	for _, host := range hosts.DedicatedHosts {
+		dedicated.hostIDs[host.Hostid] = c.dedicatedToUser(host.Accountid, host.Domainid)
+	}
+
+	if !dedicated.any() {
+		return nil, errors.Errorf("no resources are dedicated to account %s with affinity group %s",
+			c.user.Account.Name, group.Name)
+	}
+	return dedicated, nil
+}
+
+// any checks whether any resource is dedicated.
+func (d *dedicatedResources) any() bool {
+	for _, ids := range []map[string]bool{d.zoneIDs, d.podIDs, d.clusterIDs, d.hostIDs} {
+		for _, dedicated := range ids {
+			if dedicated {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dedicatedToUser checks whether a resource dedicated to the account, or to the domain if no account is given, is
+// dedicated to the user's account or its domain.
+func (c *client) dedicatedToUser(accountID, domainID string) bool {
+	if accountID != "" {
+		return accountID == c.user.Account.ID
+	}
+	return domainID == c.user.Account.Domain.ID
+}
+
+// tagVMResources tags a VM CAPC deployed and its volumes, recording the cluster and machine owning them.
+func (c *client) tagVMResources(instanceID string, tags map[string]string) error {
+	if err := c.AddTags(ResourceTypeVM, instanceID, tags); err != nil {
+		return errors.Wrapf(err, "tagging VM with ID %s", instanceID)
+	}
+	volIDs, err := c.listVMInstanceVolumeIDs(instanceID, "")
+	if err != nil {
+		return errors.Wrapf(err, "listing volumes of VM with ID %s", instanceID)
+	}
+	for _, volID := range volIDs {
+		if err := c.AddTags(ResourceTypeVolume, volID, tags); err != nil {
+			return errors.Wrapf(err, "tagging volume with ID %s", volID)
+		}
+	}
+	return nil
+}
+
+// ReconcileVMTags brings the tags of the machine's VM and volumes in line with the additional tags of the cluster and
+// the machine. Nothing is done if the machine's status records they already are.
+func (c *client) ReconcileVMTags(csMachine *infrav1.CloudStackMachine, csCluster *infrav1.CloudStackCluster) error {
+	instanceID := *csMachine.Spec.InstanceID
+	tags := machineResourceTags(csCluster, csMachine, machineRole(csMachine))
+	hash := tagsHash(tags, instanceID)
+	if csMachine.Status.TagsHash == hash {
+		return nil
+	}
+	if err := c.reconcileResourceTags(ResourceTypeVM, instanceID, csCluster, tags, false); err != nil {
+		return err
+	}
+	volIDs, err := c.listVMInstanceVolumeIDs(instanceID, "")
+	if err != nil {
+		return errors.Wrapf(err, "listing volumes of VM with ID %s", instanceID)
+	}
+	for _, volID := range volIDs {
+		if err := c.reconcileResourceTags(ResourceTypeVolume, volID, csCluster, tags, false); err != nil {
+			return err
+		}
+	}
+	csMachine.Status.TagsHash = hash
 	return nil
 }
 
@@ -423,6 +755,16 @@ func (c *client) GetOrCreateVMInstance(
 	return c.ResolveVMInstanceDetails(csMachine)
 }
 
+// machineNetworkID returns the network a machine is placed in. Worker machines go to the VPC worker tier if there is
+// one, everything else to the failure domain's network.
+func machineNetworkID(fd *infrav1.CloudStackFailureDomain, csMachine *infrav1.CloudStackMachine) string {
+	_, isControlPlane := csMachine.Labels[clusterv1.MachineControlPlaneLabel]
+	if vpc := fd.Spec.Zone.Network.VPC; vpc != nil && vpc.WorkerTier != nil && vpc.WorkerTier.ID != "" && !isControlPlane {
+		return vpc.WorkerTier.ID
+	}
+	return fd.Spec.Zone.Network.ID
+}
+
 // findVirtualMachine retrieves a virtual machine by matching its expected name, template, failure
 // domain zone and failure domain network. If no virtual machine is found it returns nil, nil.
 func findVirtualMachine(
@@ -434,7 +776,7 @@ func findVirtualMachine(
 	params := client.NewListVirtualMachinesParams()
 	params.SetTemplateid(templateID)
 	params.SetZoneid(failureDomain.Spec.Zone.ID)
-	params.SetNetworkid(failureDomain.Spec.Zone.Network.ID)
+	params.SetNetworkid(machineNetworkID(failureDomain, machine))
 	params.SetName(machine.Name)
 	setIfNotEmpty(projectID, params.SetProjectid)
 
@@ -454,7 +796,8 @@ func findVirtualMachine(
 func (c *client) DestroyVMInstance(csMachine *infrav1.CloudStackMachine) error {
 	// Attempt deletion regardless of machine state.
 	p := c.csAsync.VirtualMachine.NewDestroyVirtualMachineParams(*csMachine.Spec.InstanceID)
-	volIDs, err := c.listVMInstanceDatadiskVolumeIDs(*csMachine.Spec.InstanceID)
+	// VM root volumes are destroyed automatically, no need to explicitly include
+	volIDs, err := c.listVMInstanceVolumeIDs(*csMachine.Spec.InstanceID, "DATADISK")
 	if err != nil {
 		return err
 	}
@@ -484,11 +827,31 @@ func (c *client) DestroyVMInstance(csMachine *infrav1.CloudStackMachine) error {
 	return errors.New("VM deletion in progress")
 }
 
-func (c *client) listVMInstanceDatadiskVolumeIDs(instanceID string) ([]string, error) {
+// StopVMInstance requests the VM to stop without waiting for it to reach the Stopped state.
+func (c *client) StopVMInstance(csMachine *infrav1.CloudStackMachine) error {
+	p := c.cs.VirtualMachine.NewStopVirtualMachineParams(*csMachine.Spec.InstanceID)
+	if _, err := c.cs.VirtualMachine.StopVirtualMachine(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "stopping VM %s", *csMachine.Spec.InstanceID)
+	}
+	return nil
+}
+
+// StartVMInstance requests the VM to start without waiting for it to reach the Running state.
+func (c *client) StartVMInstance(csMachine *infrav1.CloudStackMachine) error {
+	p := c.cs.VirtualMachine.NewStartVirtualMachineParams(*csMachine.Spec.InstanceID)
+	if _, err := c.cs.VirtualMachine.StartVirtualMachine(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "starting VM %s", *csMachine.Spec.InstanceID)
+	}
+	return nil
+}
+
+// listVMInstanceVolumeIDs lists the IDs of a VM's volumes of the type, or of all its volumes if the type is empty.
+func (c *client) listVMInstanceVolumeIDs(instanceID string, volumeType string) ([]string, error) {
 	p := c.cs.Volume.NewListVolumesParams()
 	p.SetVirtualmachineid(instanceID)
-	// VM root volumes are destroyed automatically, no need to explicitly include
-	p.SetType("DATADISK")
+	setIfNotEmpty(volumeType, p.SetType)
 	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
 
 	listVOLResp, err := c.csAsync.Volume.ListVolumes(p)
@@ -30,6 +30,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
 )
 
@@ -53,6 +54,7 @@ var _ = Describe("Instance", func() {
 		dos        *cloudstack.MockDiskOfferingServiceIface
 		ts         *cloudstack.MockTemplateServiceIface
 		vs         *cloudstack.MockVolumeServiceIface
+		rs         *cloudstack.MockResourcetagsServiceIface
 		client     cloud.Client
 	)
 
@@ -64,11 +66,40 @@ var _ = Describe("Instance", func() {
 		dos = mockClient.DiskOffering.(*cloudstack.MockDiskOfferingServiceIface)
 		ts = mockClient.Template.(*cloudstack.MockTemplateServiceIface)
 		vs = mockClient.Volume.(*cloudstack.MockVolumeServiceIface)
+		rs = mockClient.Resourcetags.(*cloudstack.MockResourcetagsServiceIface)
 		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
 
 		dummies.SetDummyVars()
 	})
 
+	// vmTags returns the tags CAPC puts on the VM of CSMachine1 and its volumes.
+	vmTags := func() map[string]string {
+		return map[string]string{
+			cloud.CreatedByCAPCTagName:                                 "1",
+			cloud.ClusterTagNamePrefix + string(dummies.CSCluster.UID): "1",
+			cloud.ClusterNameTagName:                                   dummies.ClusterName,
+			cloud.ClusterNamespaceTagName:                              dummies.CSCluster.Namespace,
+			cloud.ClusterUIDTagName:                                    string(dummies.CSCluster.UID),
+			cloud.MachineNameTagName:                                   dummies.CSMachine1.Name,
+			cloud.MachineRoleTagName:                                   cloud.MachineRoleWorker,
+		}
+	}
+
+	// expectVMTagged expects the deployed VM and its volumes to be tagged as created by CAPC for the cluster and machine.
+	expectVMTagged := func() {
+		tags := vmTags()
+		rs.EXPECT().NewCreateTagsParams([]string{*dummies.CSMachine1.Spec.InstanceID}, string(cloud.ResourceTypeVM), tags).
+			Return(&cloudstack.CreateTagsParams{})
+		vs.EXPECT().NewListVolumesParams().Return(&cloudstack.ListVolumesParams{})
+		vs.EXPECT().ListVolumes(gomock.Any()).Return(&cloudstack.ListVolumesResponse{
+			Count: 2, Volumes: []*cloudstack.Volume{{Id: "RootDiskID"}, {Id: "DataDiskID"}}}, nil)
+		rs.EXPECT().NewCreateTagsParams([]string{"RootDiskID"}, string(cloud.ResourceTypeVolume), tags).
+			Return(&cloudstack.CreateTagsParams{})
+		rs.EXPECT().NewCreateTagsParams([]string{"DataDiskID"}, string(cloud.ResourceTypeVolume), tags).
+			Return(&cloudstack.CreateTagsParams{})
+		rs.EXPECT().CreateTags(gomock.Any()).Return(&cloudstack.CreateTagsResponse{}, nil).Times(3)
+	}
+
 	AfterEach(func() {
 		mockCtrl.Finish()
 	})
@@ -93,6 +124,25 @@ var _ = Describe("Instance", func() {
 			Ω(dummies.CSMachine1.Spec.InstanceID).Should(Equal(pointer.String(vmsResp.Id)))
 		})
 
+		It("reports the IPv6 addresses of a dual-stack VM instance", func() {
+			vmsResp := &cloudstack.VirtualMachinesMetric{Id: *dummies.CSMachine1.Spec.InstanceID, Ipaddress: "10.1.0.10",
+				Nic: []cloudstack.Nic{{Ipaddress: "10.1.0.10", Ip6address: "2001:db8::10"}}}
+			vms.EXPECT().GetVirtualMachinesMetricByID(*dummies.CSMachine1.Spec.InstanceID, gomock.Any()).Return(vmsResp, 1, nil)
+			Ω(client.ResolveVMInstanceDetails(dummies.CSMachine1)).Should(Succeed())
+			Ω(dummies.CSMachine1.Status.Addresses).Should(Equal([]corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.1.0.10"},
+				{Type: corev1.NodeInternalIP, Address: "2001:db8::10"},
+			}))
+		})
+
+		It("records the host the VM instance runs on", func() {
+			vmsResp := &cloudstack.VirtualMachinesMetric{Id: *dummies.CSMachine1.Spec.InstanceID, Hostid: "host-id", Hostname: "host1"}
+			vms.EXPECT().GetVirtualMachinesMetricByID(*dummies.CSMachine1.Spec.InstanceID, gomock.Any()).Return(vmsResp, 1, nil)
+			Ω(client.ResolveVMInstanceDetails(dummies.CSMachine1)).Should(Succeed())
+			Ω(dummies.CSMachine1.Status.HostID).Should(Equal("host-id"))
+			Ω(dummies.CSMachine1.Status.HostName).Should(Equal("host1"))
+		})
+
 		It("handles an unknown error when fetching by name", func() {
 			vms.EXPECT().GetVirtualMachinesMetricByID(*dummies.CSMachine1.Spec.InstanceID, gomock.Any()).Return(nil, -1, notFoundError)
 			vms.EXPECT().GetVirtualMachinesMetricByName(dummies.CSMachine1.Name, gomock.Any()).Return(nil, -1, unknownError)
@@ -622,6 +672,7 @@ var _ = Describe("Instance", func() {
 
 						Ω(string(decompressedUserData)).To(Equal(expectUserData))
 					}).Return(deploymentResp, nil)
+				expectVMTagged()
 
 				Ω(client.GetOrCreateVMInstance(
 					dummies.CSMachine1, dummies.CAPIMachine, dummies.CSCluster, dummies.CSFailureDomain1, dummies.CSAffinityGroup, expectUserData)).
@@ -648,6 +699,105 @@ var _ = Describe("Instance", func() {
 				ActionAndAssert()
 			})
 
+			It("pins the VM to the pod and host the failure domain's placement selects", func() {
+				dummies.CSMachine1.Spec.Offering.ID = ""
+				dummies.CSMachine1.Spec.Template.ID = ""
+				dummies.CSMachine1.Spec.Offering.Name = "offering"
+				dummies.CSMachine1.Spec.Template.Name = "template"
+				dummies.CSMachine1.Spec.DiskOffering = infrav1.CloudStackResourceDiskOffering{}
+				dummies.CSFailureDomain1.Spec.Placement = &infrav1.FailureDomainPlacement{
+					Pod:      &infrav1.CloudStackResourceIdentifier{ID: "pod-id"},
+					HostTags: []string{"k8s"},
+				}
+
+				sos.EXPECT().GetServiceOfferingByName(dummies.CSMachine1.Spec.Offering.Name, gomock.Any()).Return(&cloudstack.ServiceOffering{
+					Id:        offeringFakeID,
+					Cpunumber: 1,
+					Memory:    1024,
+				}, 1, nil)
+				ts.EXPECT().GetTemplateID(dummies.CSMachine1.Spec.Template.Name, executableFilter, dummies.Zone1.ID, gomock.Any()).
+					Return(templateFakeID, 1, nil)
+				hs := mockClient.Host.(*cloudstack.MockHostServiceIface)
+				hs.EXPECT().NewListHostsParams().Return(&cloudstack.ListHostsParams{})
+				hs.EXPECT().ListHosts(gomock.Any()).Return(&cloudstack.ListHostsResponse{Hosts: []*cloudstack.Host{
+					{Id: "host-1", Hosttags: "k8s", Memorytotal: 4096, Memoryallocated: 3072},
+					{Id: "host-2", Hosttags: "k8s", Memorytotal: 4096, Memoryallocated: 1024},
+					{Id: "host-3", Memorytotal: 8192}}}, nil)
+				p := &cloudstack.DeployVirtualMachineParams{}
+				vms.EXPECT().NewDeployVirtualMachineParams(offeringFakeID, templateFakeID, dummies.Zone1.ID).Return(p)
+				vms.EXPECT().DeployVirtualMachine(p).Return(
+					&cloudstack.DeployVirtualMachineResponse{Id: *dummies.CSMachine1.Spec.InstanceID}, nil)
+				expectVMTagged()
+
+				Ω(client.GetOrCreateVMInstance(
+					dummies.CSMachine1, dummies.CAPIMachine, dummies.CSCluster, dummies.CSFailureDomain1, dummies.CSAffinityGroup, "")).
+					Should(Succeed())
+				podID, _ := p.GetPodid()
+				Ω(podID).Should(Equal("pod-id"))
+				hostID, _ := p.GetHostid()
+				Ω(hostID).Should(Equal("host-2"))
+			})
+
+			It("deploys a VM with an ExplicitDedication affinity group on a dedicated host with the host tags", func() {
+				dummies.CSMachine1.Spec.Offering.ID = ""
+				dummies.CSMachine1.Spec.Template.ID = ""
+				dummies.CSMachine1.Spec.Offering.Name = "offering"
+				dummies.CSMachine1.Spec.Template.Name = "template"
+				dummies.CSMachine1.Spec.DiskOffering = infrav1.CloudStackResourceDiskOffering{}
+				dummies.CSMachine1.Spec.Host = &infrav1.HostSelector{Tags: []string{"k8s"}}
+				dummies.CSMachine1.Spec.ExplicitDedicationAffinityGroup = &infrav1.CloudStackResourceIdentifier{Name: "DedicatedGrp-account"}
+				dummies.CSMachine1.Spec.DeploymentPlanner = "ImplicitDedicationPlanner"
+				user := &cloud.User{Account: cloud.Account{ID: "account-id", Domain: cloud.Domain{ID: "domain-id",
+					CPUAvailable: "Unlimited", MemoryAvailable: "Unlimited", VMAvailable: "Unlimited"},
+					CPUAvailable: "Unlimited", MemoryAvailable: "Unlimited", VMAvailable: "Unlimited"}}
+				c := cloud.NewClientFromCSAPIClient(mockClient, user)
+
+				sos.EXPECT().GetServiceOfferingByName(dummies.CSMachine1.Spec.Offering.Name, gomock.Any()).Return(&cloudstack.ServiceOffering{
+					Id:        offeringFakeID,
+					Cpunumber: 1,
+					Memory:    1024,
+				}, 1, nil)
+				ts.EXPECT().GetTemplateID(dummies.CSMachine1.Spec.Template.Name, executableFilter, dummies.Zone1.ID, gomock.Any()).
+					Return(templateFakeID, 1, nil)
+				ags := mockClient.AffinityGroup.(*cloudstack.MockAffinityGroupServiceIface)
+				ags.EXPECT().GetAffinityGroupByName("DedicatedGrp-account", gomock.Any()).Return(
+					&cloudstack.AffinityGroup{Id: "dedicated-group-id", Type: cloud.ExplicitDedicationAffinityGroupType}, 1, nil)
+				zs := mockClient.Zone.(*cloudstack.MockZoneServiceIface)
+				zs.EXPECT().NewListDedicatedZonesParams().Return(&cloudstack.ListDedicatedZonesParams{})
+				zs.EXPECT().ListDedicatedZones(gomock.Any()).Return(&cloudstack.ListDedicatedZonesResponse{}, nil)
+				ps := mockClient.Pod.(*cloudstack.MockPodServiceIface)
+				ps.EXPECT().NewListDedicatedPodsParams().Return(&cloudstack.ListDedicatedPodsParams{})
+				ps.EXPECT().ListDedicatedPods(gomock.Any()).Return(&cloudstack.ListDedicatedPodsResponse{}, nil)
+				cs := mockClient.Cluster.(*cloudstack.MockClusterServiceIface)
+				cs.EXPECT().NewListDedicatedClustersParams().Return(&cloudstack.ListDedicatedClustersParams{})
+				cs.EXPECT().ListDedicatedClusters(gomock.Any()).Return(&cloudstack.ListDedicatedClustersResponse{
+					DedicatedClusters: []*cloudstack.DedicatedCluster{{Clusterid: "cluster-1", Domainid: "domain-id"}}}, nil)
+				hs := mockClient.Host.(*cloudstack.MockHostServiceIface)
+				hs.EXPECT().NewListDedicatedHostsParams().Return(&cloudstack.ListDedicatedHostsParams{})
+				hs.EXPECT().ListDedicatedHosts(gomock.Any()).Return(&cloudstack.ListDedicatedHostsResponse{
+					DedicatedHosts: []*cloudstack.DedicatedHost{{Hostid: "host-3", Accountid: "other-account-id"}}}, nil)
+				hs.EXPECT().NewListHostsParams().Return(&cloudstack.ListHostsParams{})
+				hs.EXPECT().ListHosts(gomock.Any()).Return(&cloudstack.ListHostsResponse{Hosts: []*cloudstack.Host{
+					{Id: "host-1", Clusterid: "cluster-1", Hosttags: "k8s", Memorytotal: 4096, Memoryallocated: 3072},
+					{Id: "host-2", Clusterid: "cluster-2", Hosttags: "k8s", Memorytotal: 4096},
+					{Id: "host-3", Clusterid: "cluster-2", Hosttags: "k8s", Memorytotal: 8192}}}, nil)
+				p := &cloudstack.DeployVirtualMachineParams{}
+				vms.EXPECT().NewDeployVirtualMachineParams(offeringFakeID, templateFakeID, dummies.Zone1.ID).Return(p)
+				vms.EXPECT().DeployVirtualMachine(p).Return(
+					&cloudstack.DeployVirtualMachineResponse{Id: *dummies.CSMachine1.Spec.InstanceID}, nil)
+				expectVMTagged()
+
+				Ω(c.GetOrCreateVMInstance(
+					dummies.CSMachine1, dummies.CAPIMachine, dummies.CSCluster, dummies.CSFailureDomain1, dummies.CSAffinityGroup, "")).
+					Should(Succeed())
+				hostID, _ := p.GetHostid()
+				Ω(hostID).Should(Equal("host-1"))
+				groupIDs, _ := p.GetAffinitygroupids()
+				Ω(groupIDs).Should(ContainElement("dedicated-group-id"))
+				planner, _ := p.GetDeploymentplanner()
+				Ω(planner).Should(Equal("ImplicitDedicationPlanner"))
+			})
+
 			It("works with service offering name and template name without disk offering", func() {
 				dummies.CSMachine1.Spec.Offering.ID = ""
 				dummies.CSMachine1.Spec.Template.ID = ""
@@ -858,6 +1008,7 @@ var _ = Describe("Instance", func() {
 					Ω(err).ToNot(HaveOccurred())
 					Ω(string(userData)).To(Equal(expectUserData))
 				}).Return(deploymentResp, nil)
+			expectVMTagged()
 
 			err := client.GetOrCreateVMInstance(
 				dummies.CSMachine1,
@@ -871,6 +1022,215 @@ var _ = Describe("Instance", func() {
 		})
 	})
 
+	Context("when checking limits for a scale-up", func() {
+		BeforeEach(func() {
+			dummies.CSMachine1.Spec.DiskOffering.CustomSize = 10
+			dummies.CSFailureDomain1.Spec.Zone.ID = dummies.Zone1.ID
+		})
+
+		expectOffering := func() {
+			sos.EXPECT().GetServiceOfferingByName(dummies.CSMachine1.Spec.Offering.Name, gomock.Any()).
+				Return(&cloudstack.ServiceOffering{
+					Id:           offeringFakeID,
+					Name:         dummies.CSMachine1.Spec.Offering.Name,
+					Cpunumber:    2,
+					Memory:       1024,
+					Rootdisksize: 20,
+				}, 1, nil)
+		}
+
+		limitedUser := func() *cloud.User {
+			return &cloud.User{
+				Account: cloud.Account{
+					Domain: cloud.Domain{
+						CPUAvailable:            "100",
+						MemoryAvailable:         "102400",
+						VMAvailable:             "100",
+						PrimaryStorageAvailable: "1000",
+					},
+					CPUAvailable:            "100",
+					MemoryAvailable:         "102400",
+					VMAvailable:             "100",
+					PrimaryStorageAvailable: "1000",
+				},
+				Project: cloud.Project{
+					CPUAvailable:            "100",
+					MemoryAvailable:         "102400",
+					VMAvailable:             "100",
+					PrimaryStorageAvailable: "1000",
+				},
+			}
+		}
+
+		// clientWithLimits returns a client whose cached user has no limits, and expects the limits to be looked up
+		// afresh and found to be those of available.
+		clientWithLimits := func(available *cloud.User) cloud.Client {
+			ds := mockClient.Domain.(*cloudstack.MockDomainServiceIface)
+			as := mockClient.Account.(*cloudstack.MockAccountServiceIface)
+			ps := mockClient.Project.(*cloudstack.MockProjectServiceIface)
+			ds.EXPECT().NewListDomainsParams().Return(&cloudstack.ListDomainsParams{})
+			ds.EXPECT().ListDomains(gomock.Any()).Return(&cloudstack.ListDomainsResponse{Count: 1, Domains: []*cloudstack.Domain{{
+				Id:                      "domain-id",
+				Name:                    "domain",
+				Path:                    "ROOT/domain",
+				Cpuavailable:            available.Account.Domain.CPUAvailable,
+				Memoryavailable:         available.Account.Domain.MemoryAvailable,
+				Vmavailable:             available.Account.Domain.VMAvailable,
+				Primarystorageavailable: available.Account.Domain.PrimaryStorageAvailable,
+			}}}, nil)
+			as.EXPECT().NewListAccountsParams().Return(&cloudstack.ListAccountsParams{})
+			as.EXPECT().ListAccounts(gomock.Any()).Return(&cloudstack.ListAccountsResponse{Count: 1, Accounts: []*cloudstack.Account{{
+				Id:                      "account-id",
+				Name:                    "account",
+				Cpuavailable:            available.Account.CPUAvailable,
+				Memoryavailable:         available.Account.MemoryAvailable,
+				Vmavailable:             available.Account.VMAvailable,
+				Primarystorageavailable: available.Account.PrimaryStorageAvailable,
+			}}}, nil)
+			ps.EXPECT().NewListProjectsParams().Return(&cloudstack.ListProjectsParams{})
+			ps.EXPECT().ListProjects(gomock.Any()).Return(&cloudstack.ListProjectsResponse{Count: 1, Projects: []*cloudstack.Project{{
+				Id:                      "project-id",
+				Name:                    "project",
+				Cpuavailable:            available.Project.CPUAvailable,
+				Memoryavailable:         available.Project.MemoryAvailable,
+				Vmavailable:             available.Project.VMAvailable,
+				Primarystorageavailable: available.Project.PrimaryStorageAvailable,
+			}}}, nil)
+
+			return cloud.NewClientFromCSAPIClient(mockClient, &cloud.User{
+				Account: cloud.Account{
+					ID:   "account-id",
+					Name: "account",
+					Domain: cloud.Domain{
+						ID:                      "domain-id",
+						Name:                    "domain",
+						Path:                    "ROOT/domain",
+						CPUAvailable:            "Unlimited",
+						MemoryAvailable:         "Unlimited",
+						VMAvailable:             "Unlimited",
+						PrimaryStorageAvailable: "Unlimited",
+					},
+					CPUAvailable:            "Unlimited",
+					MemoryAvailable:         "Unlimited",
+					VMAvailable:             "Unlimited",
+					PrimaryStorageAvailable: "Unlimited",
+				},
+				Project: cloud.Project{
+					ID:                      "project-id",
+					Name:                    "project",
+					CPUAvailable:            "Unlimited",
+					MemoryAvailable:         "Unlimited",
+					VMAvailable:             "Unlimited",
+					PrimaryStorageAvailable: "Unlimited",
+				},
+			})
+		}
+
+		It("does nothing when not scaling up", func() {
+			Ω(client.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 0)).Should(Succeed())
+		})
+
+		It("succeeds when limits are unlimited", func() {
+			expectOffering()
+			c := clientWithLimits(&cloud.User{
+				Account: cloud.Account{
+					Domain: cloud.Domain{
+						CPUAvailable:            "Unlimited",
+						MemoryAvailable:         "Unlimited",
+						VMAvailable:             "Unlimited",
+						PrimaryStorageAvailable: "Unlimited",
+					},
+					CPUAvailable:            "Unlimited",
+					MemoryAvailable:         "Unlimited",
+					VMAvailable:             "Unlimited",
+					PrimaryStorageAvailable: "Unlimited",
+				},
+				Project: cloud.Project{
+					CPUAvailable:            "Unlimited",
+					MemoryAvailable:         "Unlimited",
+					VMAvailable:             "Unlimited",
+					PrimaryStorageAvailable: "Unlimited",
+				},
+			})
+			Ω(c.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 30)).Should(Succeed())
+		})
+
+		It("succeeds when the limits can fulfil the whole scale-up", func() {
+			expectOffering()
+			c := clientWithLimits(limitedUser())
+			Ω(c.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 10)).Should(Succeed())
+		})
+
+		It("returns errors when the account CPU can't fulfil the whole scale-up", func() {
+			expectOffering()
+			user := limitedUser()
+			user.Account.CPUAvailable = "10"
+			c := clientWithLimits(user)
+			Ω(c.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 10)).
+				Should(MatchError("CPU available (10) in account can't fulfil the scale-up requirement of 10 machines: 20"))
+		})
+
+		It("returns errors when the domain VM limit can't fulfil the whole scale-up", func() {
+			expectOffering()
+			user := limitedUser()
+			user.Account.Domain.VMAvailable = "5"
+			c := clientWithLimits(user)
+			Ω(c.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 10)).
+				Should(MatchError(MatchRegexp("VM available .* in domain can't fulfil the scale-up requirement.*")))
+		})
+
+		It("returns errors when the project primary storage can't fulfil the whole scale-up", func() {
+			expectOffering()
+			user := limitedUser()
+			user.Project.PrimaryStorageAvailable = "200"
+			c := clientWithLimits(user)
+			Ω(c.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 10)).
+				Should(MatchError("primary storage available (200) in project can't fulfil the scale-up requirement of 10 machines: 300"))
+		})
+
+		It("falls back to the template size when the offering doesn't size the root disk", func() {
+			sos.EXPECT().GetServiceOfferingByName(dummies.CSMachine1.Spec.Offering.Name, gomock.Any()).
+				Return(&cloudstack.ServiceOffering{
+					Id:        offeringFakeID,
+					Name:      dummies.CSMachine1.Spec.Offering.Name,
+					Cpunumber: 2,
+					Memory:    1024,
+				}, 1, nil)
+			ts.EXPECT().GetTemplateID(dummies.CSMachine1.Spec.Template.Name, "executable", gomock.Any(), gomock.Any()).
+				Return(templateFakeID, 1, nil)
+			ts.EXPECT().GetTemplateByID(templateFakeID, "executable", gomock.Any()).
+				Return(&cloudstack.Template{Id: templateFakeID, Size: 50 << 30}, 1, nil)
+			user := limitedUser()
+			user.Account.PrimaryStorageAvailable = "599"
+			c := clientWithLimits(user)
+			Ω(c.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 10)).
+				Should(MatchError(MatchRegexp("primary storage available .599. in account .*: 600$")))
+		})
+
+		It("compares against the limits available now rather than those cached with the client", func() {
+			expectOffering()
+			user := limitedUser()
+			user.Account.VMAvailable = "0"
+			c := clientWithLimits(user)
+			Ω(c.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 1)).
+				Should(MatchError("VM available (0) in account can't fulfil the scale-up requirement of 1 machines: 1"))
+		})
+
+		It("includes the disk offering size in the primary storage demand", func() {
+			expectOffering()
+			dummies.CSMachine1.Spec.DiskOffering.CustomSize = 0
+			dos.EXPECT().GetDiskOfferingID(dummies.CSMachine1.Spec.DiskOffering.Name, gomock.Any()).
+				Return(diskOfferingFakeID, 1, nil)
+			dos.EXPECT().GetDiskOfferingByID(diskOfferingFakeID, gomock.Any()).
+				Return(&cloudstack.DiskOffering{Id: diskOfferingFakeID, Disksize: 80}, 1, nil).Times(2)
+			user := limitedUser()
+			user.Account.PrimaryStorageAvailable = "499"
+			c := clientWithLimits(user)
+			Ω(c.CheckScaleUpLimits(dummies.CSFailureDomain1, dummies.CSMachine1, 5)).
+				Should(MatchError(MatchRegexp("primary storage available .499. in account .*: 500$")))
+		})
+	})
+
 	Context("when destroying a VM instance", func() {
 		expungeDestroyParams := &cloudstack.DestroyVirtualMachineParams{}
 		expungeDestroyParams.SetExpunge(true)
@@ -970,4 +1330,130 @@ var _ = Describe("Instance", func() {
 			Ω(client.DestroyVMInstance(dummies.CSMachine1)).Should(MatchError("VM deletion in progress"))
 		})
 	})
+
+	Context("when stopping and starting VMs", func() {
+		It("stops the VM", func() {
+			p := &cloudstack.StopVirtualMachineParams{}
+			vms.EXPECT().NewStopVirtualMachineParams(*dummies.CSMachine1.Spec.InstanceID).Return(p)
+			vms.EXPECT().StopVirtualMachine(p).Return(&cloudstack.StopVirtualMachineResponse{State: "Stopping"}, nil)
+			Ω(client.StopVMInstance(dummies.CSMachine1)).Should(Succeed())
+		})
+
+		It("returns errors encountered when stopping the VM", func() {
+			p := &cloudstack.StopVirtualMachineParams{}
+			vms.EXPECT().NewStopVirtualMachineParams(*dummies.CSMachine1.Spec.InstanceID).Return(p)
+			vms.EXPECT().StopVirtualMachine(p).Return(nil, fmt.Errorf("new error"))
+			Ω(client.StopVMInstance(dummies.CSMachine1)).Should(MatchError(ContainSubstring("new error")))
+		})
+
+		It("starts the VM", func() {
+			p := &cloudstack.StartVirtualMachineParams{}
+			vms.EXPECT().NewStartVirtualMachineParams(*dummies.CSMachine1.Spec.InstanceID).Return(p)
+			vms.EXPECT().StartVirtualMachine(p).Return(&cloudstack.StartVirtualMachineResponse{State: "Starting"}, nil)
+			Ω(client.StartVMInstance(dummies.CSMachine1)).Should(Succeed())
+		})
+
+		It("returns errors encountered when starting the VM", func() {
+			p := &cloudstack.StartVirtualMachineParams{}
+			vms.EXPECT().NewStartVirtualMachineParams(*dummies.CSMachine1.Spec.InstanceID).Return(p)
+			vms.EXPECT().StartVirtualMachine(p).Return(nil, fmt.Errorf("new error"))
+			Ω(client.StartVMInstance(dummies.CSMachine1)).Should(MatchError(ContainSubstring("new error")))
+		})
+	})
+
+	Context("when reconciling VM tags", func() {
+		It("replaces stale additional tags, keeps foreign ones and skips volumes of other clusters", func() {
+			dummies.CSCluster.Spec.AdditionalTags = map[string]string{"env": "prod"}
+			dummies.CSMachine1.Spec.AdditionalTags = map[string]string{"team": "infra"}
+			vmID := *dummies.CSMachine1.Spec.InstanceID
+
+			current := []*cloudstack.Tag{
+				{Key: "team", Value: "storage"},
+				{Key: cloud.AdditionalTagsTagName, Value: "team"},
+				{Key: "backup", Value: "daily"},
+			}
+			for key, value := range vmTags() {
+				current = append(current, &cloudstack.Tag{Key: key, Value: value})
+			}
+			rs.EXPECT().NewListTagsParams().Return(&cloudstack.ListTagsParams{}).Times(2)
+			rs.EXPECT().ListTags(gomock.Any()).Return(&cloudstack.ListTagsResponse{Tags: current}, nil)
+			deleted := &cloudstack.DeleteTagsParams{}
+			rs.EXPECT().NewDeleteTagsParams([]string{vmID}, string(cloud.ResourceTypeVM)).Return(deleted).Times(2)
+			rs.EXPECT().DeleteTags(deleted).Return(&cloudstack.DeleteTagsResponse{}, nil).Times(2)
+			rs.EXPECT().NewCreateTagsParams([]string{vmID}, string(cloud.ResourceTypeVM), map[string]string{
+				"env": "prod", "team": "infra", cloud.AdditionalTagsTagName: "env,team"}).
+				Return(&cloudstack.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&cloudstack.CreateTagsResponse{}, nil)
+
+			vs.EXPECT().NewListVolumesParams().Return(&cloudstack.ListVolumesParams{})
+			vs.EXPECT().ListVolumes(gomock.Any()).Return(&cloudstack.ListVolumesResponse{
+				Count: 1, Volumes: []*cloudstack.Volume{{Id: "SharedVolumeID"}}}, nil)
+			rs.EXPECT().ListTags(gomock.Any()).Return(&cloudstack.ListTagsResponse{Tags: []*cloudstack.Tag{
+				{Key: cloud.ClusterUIDTagName, Value: "other-cluster"}}}, nil)
+
+			Ω(client.ReconcileVMTags(dummies.CSMachine1, dummies.CSCluster)).Should(Succeed())
+			tags, _ := deleted.GetTags()
+			Ω(tags).Should(Equal(map[string]string{"team": "storage", cloud.AdditionalTagsTagName: "team"}))
+		})
+
+		It("only reconciles the tags again once the desired tags change", func() {
+			vmID := *dummies.CSMachine1.Spec.InstanceID
+			current := []*cloudstack.Tag{}
+			for key, value := range vmTags() {
+				current = append(current, &cloudstack.Tag{Key: key, Value: value})
+			}
+			rs.EXPECT().NewListTagsParams().Return(&cloudstack.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&cloudstack.ListTagsResponse{Tags: current}, nil)
+			vs.EXPECT().NewListVolumesParams().Return(&cloudstack.ListVolumesParams{})
+			vs.EXPECT().ListVolumes(gomock.Any()).Return(&cloudstack.ListVolumesResponse{}, nil)
+
+			Ω(client.ReconcileVMTags(dummies.CSMachine1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSMachine1.Status.TagsHash).ShouldNot(BeEmpty())
+			// Nothing is listed as long as the tags are the same.
+			Ω(client.ReconcileVMTags(dummies.CSMachine1, dummies.CSCluster)).Should(Succeed())
+
+			dummies.CSMachine1.Spec.AdditionalTags = map[string]string{"team": "infra"}
+			rs.EXPECT().NewListTagsParams().Return(&cloudstack.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&cloudstack.ListTagsResponse{Tags: current}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{vmID}, string(cloud.ResourceTypeVM), map[string]string{
+				"team": "infra", cloud.AdditionalTagsTagName: "team"}).
+				Return(&cloudstack.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&cloudstack.CreateTagsResponse{}, nil)
+			vs.EXPECT().NewListVolumesParams().Return(&cloudstack.ListVolumesParams{})
+			vs.EXPECT().ListVolumes(gomock.Any()).Return(&cloudstack.ListVolumesResponse{}, nil)
+
+			Ω(client.ReconcileVMTags(dummies.CSMachine1, dummies.CSCluster)).Should(Succeed())
+		})
+
+		It("retags the VM of a cluster clusterctl move recreated with a new UID", func() {
+			const oldUID = "old-cluster-uid"
+			vmID := *dummies.CSMachine1.Spec.InstanceID
+			current := []*cloudstack.Tag{}
+			for key, value := range vmTags() {
+				switch key {
+				case cloud.ClusterTagNamePrefix + string(dummies.CSCluster.UID):
+					key = cloud.ClusterTagNamePrefix + oldUID
+				case cloud.ClusterUIDTagName:
+					value = oldUID
+				}
+				current = append(current, &cloudstack.Tag{Key: key, Value: value})
+			}
+			rs.EXPECT().NewListTagsParams().Return(&cloudstack.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&cloudstack.ListTagsResponse{Tags: current}, nil)
+			deleted := &cloudstack.DeleteTagsParams{}
+			rs.EXPECT().NewDeleteTagsParams([]string{vmID}, string(cloud.ResourceTypeVM)).Return(deleted).Times(2)
+			rs.EXPECT().DeleteTags(deleted).Return(&cloudstack.DeleteTagsResponse{}, nil).Times(2)
+			rs.EXPECT().NewCreateTagsParams([]string{vmID}, string(cloud.ResourceTypeVM), map[string]string{
+				cloud.ClusterTagNamePrefix + string(dummies.CSCluster.UID): "1",
+				cloud.ClusterUIDTagName: string(dummies.CSCluster.UID)}).
+				Return(&cloudstack.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&cloudstack.CreateTagsResponse{}, nil)
+			vs.EXPECT().NewListVolumesParams().Return(&cloudstack.ListVolumesParams{})
+			vs.EXPECT().ListVolumes(gomock.Any()).Return(&cloudstack.ListVolumesResponse{}, nil)
+
+			Ω(client.ReconcileVMTags(dummies.CSMachine1, dummies.CSCluster)).Should(Succeed())
+			tags, _ := deleted.GetTags()
+			Ω(tags).Should(Equal(map[string]string{cloud.ClusterTagNamePrefix + oldUID: "1", cloud.ClusterUIDTagName: oldUID}))
+		})
+	})
 })
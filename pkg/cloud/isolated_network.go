@@ -17,7 +17,7 @@ limitations under the License.
 package cloud
 
 import (
-	"strconv"
+	"net"
 	"strings"
 
 	"github.com/apache/cloudstack-go/v2/cloudstack"
@@ -38,11 +38,12 @@ type IsoNetworkIface interface {
 	AssignVMToLoadBalancerRule(isoNet *infrav1.CloudStackIsolatedNetwork, instanceID string) error
 	DeleteNetwork(infrav1.Network) error
 	DisposeIsoNetResources(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster) error
+	ReconcileIsolatedNetworkTags(*infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster) error
 }
 
-// getOfferingID fetches an offering id.
-func (c *client) getOfferingID() (string, error) {
-	offeringID, count, retErr := c.cs.NetworkOffering.GetNetworkOfferingID(NetOffering)
+// getOfferingID fetches the id of the named network offering.
+func (c *client) getOfferingID(offering string) (string, error) {
+	offeringID, count, retErr := c.cs.NetworkOffering.GetNetworkOfferingID(offering)
 	if retErr != nil {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(retErr)
 		return "", retErr
@@ -64,18 +65,26 @@ func (c *client) AssociatePublicIPAddress(
 		return errors.Wrapf(err, "fetching a public IP address")
 	}
 	isoNet.Spec.ControlPlaneEndpoint.Host = publicAddress.Ipaddress
-	csCluster.Spec.ControlPlaneEndpoint.Host = publicAddress.Ipaddress
+	if csCluster.ControlPlaneEndpointName() == "" { // The endpoint host is a DNS name pointing at the address otherwise.
+		csCluster.Spec.ControlPlaneEndpoint.Host = publicAddress.Ipaddress
+	}
 	isoNet.Status.PublicIPID = publicAddress.Id
 
-	// Check if the address is already associated with the network.
-	if publicAddress.Associatednetworkid == isoNet.Spec.ID {
+	// Check if the address is already associated with the network, or with the VPC the network is a tier of.
+	if isoNet.Status.VPCID != "" && publicAddress.Vpcid == isoNet.Status.VPCID {
+		return nil
+	} else if isoNet.Status.VPCID == "" && publicAddress.Associatednetworkid == isoNet.Spec.ID {
 		return nil
 	}
 
 	// Public IP found, but not yet associated with network -- associate it.
 	p := c.cs.Address.NewAssociateIpAddressParams()
 	p.SetIpaddress(isoNet.Spec.ControlPlaneEndpoint.Host)
-	p.SetNetworkid(isoNet.Spec.ID)
+	if isoNet.Status.VPCID != "" {
+		p.SetVpcid(isoNet.Status.VPCID)
+	} else {
+		p.SetNetworkid(isoNet.Spec.ID)
+	}
 	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
 	if _, err := c.cs.Address.AssociateIpAddress(p); err != nil {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
@@ -95,7 +104,15 @@ func (c *client) AssociatePublicIPAddress(
 // CreateIsolatedNetwork creates an isolated network in the relevant FailureDomain per passed network specification.
 func (c *client) CreateIsolatedNetwork(fd *infrav1.CloudStackFailureDomain, isoNet *infrav1.CloudStackIsolatedNetwork) (retErr error) {
 	// Get network offering ID.
-	offeringID, err := c.getOfferingID()
+	offering := NetOffering
+	if isoNet.Spec.Offering != "" {
+		offering = isoNet.Spec.Offering
+	}
+	offeringID, err := c.getOfferingID(offering)
+	if err != nil {
+		return err
+	}
+	gateway, netmask, err := resolveGatewayAndNetmask(isoNet.Spec.CIDR, isoNet.Spec.Gateway, isoNet.Spec.Netmask)
 	if err != nil {
 		return err
 	}
@@ -103,6 +120,11 @@ func (c *client) CreateIsolatedNetwork(fd *infrav1.CloudStackFailureDomain, isoN
 	// Do isolated network creation.
 	p := c.cs.Network.NewCreateNetworkParams(isoNet.Spec.Name, offeringID, fd.Spec.Zone.ID)
 	p.SetDisplaytext(isoNet.Spec.Name)
+	setIfNotEmpty(gateway, p.SetGateway)
+	setIfNotEmpty(netmask, p.SetNetmask)
+	setIfNotEmpty(isoNet.Spec.NetworkDomain, p.SetNetworkdomain)
+	setDNSServers(isoNet.Spec.DNS, p)
+	setIPv6DNSServers(isoNet.Spec.IPv6DNS, p)
 	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
 	resp, err := c.cs.Network.CreateNetwork(p)
 	if err != nil {
@@ -110,7 +132,59 @@ func (c *client) CreateIsolatedNetwork(fd *infrav1.CloudStackFailureDomain, isoN
 		return errors.Wrapf(err, "creating network with name %s", isoNet.Spec.Name)
 	}
 	isoNet.Spec.ID = resp.Id
-	return c.AddCreatedByCAPCTag(ResourceTypeNetwork, isoNet.Spec.ID)
+	isoNet.Status.IPv6CIDR = resp.Ip6cidr
+	if err := c.AddCreatedByCAPCTag(ResourceTypeNetwork, isoNet.Spec.ID); err != nil {
+		return err
+	}
+	// Tag the network so that it is found by its tags again.
+	if len(isoNet.Spec.Tags) == 0 {
+		return nil
+	}
+	return errors.Wrapf(c.AddTags(ResourceTypeNetwork, isoNet.Spec.ID, isoNet.Spec.Tags),
+		"tagging network with ID %s", isoNet.Spec.ID)
+}
+
+// resolveGatewayAndNetmask derives the gateway and netmask of a network from its CIDR where they aren't set. The
+// gateway defaults to the first address of the CIDR.
+func resolveGatewayAndNetmask(cidr, gateway, netmask string) (string, string, error) {
+	if cidr == "" || (gateway != "" && netmask != "") {
+		return gateway, netmask, nil
+	}
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "parsing network CIDR %s", cidr)
+	}
+	if ipNet.IP.To4() == nil {
+		return "", "", errors.Errorf("network CIDR %s is not an IPv4 CIDR", cidr)
+	}
+	if netmask == "" {
+		netmask = net.IP(ipNet.Mask).String()
+	}
+	if gateway == "" {
+		ip := ipNet.IP.To4()
+		gateway = net.IPv4(ip[0], ip[1], ip[2], ip[3]+1).String()
+	}
+	return gateway, netmask, nil
+}
+
+// setDNSServers sets up to two DNS servers on network creation.
+func setDNSServers(dns []string, p *cloudstack.CreateNetworkParams) {
+	if len(dns) > 0 {
+		p.SetDns1(dns[0])
+	}
+	if len(dns) > 1 {
+		p.SetDns2(dns[1])
+	}
+}
+
+// setIPv6DNSServers sets up to two IPv6 DNS servers on network creation.
+func setIPv6DNSServers(dns []string, p *cloudstack.CreateNetworkParams) {
+	if len(dns) > 0 {
+		p.SetIp6dns1(dns[0])
+	}
+	if len(dns) > 1 {
+		p.SetIp6dns2(dns[1])
+	}
 }
 
 // OpenFirewallRules opens a CloudStack egress firewall for an isolated network.
@@ -139,18 +213,41 @@ func (c *client) OpenFirewallRules(isoNet *infrav1.CloudStackIsolatedNetwork) (r
 }
 
 // GetPublicIP gets a public IP with ID for cluster endpoint.
+// An address retained by an earlier cluster of the same name is preferred when the endpoint isn't specified.
+// When the endpoint host is a DNS name, each isolated network has a public IP of its own rather than the cluster's endpoint.
 func (c *client) GetPublicIP(
 	fd *infrav1.CloudStackFailureDomain,
 	isoNet *infrav1.CloudStackIsolatedNetwork,
 	csCluster *infrav1.CloudStackCluster,
 ) (*cloudstack.PublicIpAddress, error) {
 	ip := csCluster.Spec.ControlPlaneEndpoint.Host
+	if csCluster.ControlPlaneEndpointName() != "" {
+		ip = isoNet.Spec.ControlPlaneEndpoint.Host
+	}
+	policy := csCluster.Spec.ControlPlanePublicIP
+
+	if ip == "" && policy != nil && policy.Retain {
+		p := c.cs.Address.NewListPublicIpAddressesParams()
+		p.SetZoneid(fd.Spec.Zone.ID)
+		p.SetTags(map[string]string{generateRetainedTagName(csCluster): "1"})
+		setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+		retained, err := c.cs.Address.ListPublicIpAddresses(p)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return nil, errors.Wrap(err, "listing retained public IP addresses")
+		} else if retained.Count > 0 {
+			return retained.PublicIpAddresses[0], nil
+		}
+	}
 
 	p := c.cs.Address.NewListPublicIpAddressesParams()
 	p.SetAllocatedonly(false)
 	p.SetZoneid(fd.Spec.Zone.ID)
 	setIfNotEmpty(ip, p.SetIpaddress)
 	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	if ip == "" && policy != nil && policy.Selector != nil {
+		setIfNotEmpty(policy.Selector.VLANID, p.SetVlanid)
+	}
 	publicAddresses, err := c.cs.Address.ListPublicIpAddresses(p)
 	if err != nil {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
@@ -160,7 +257,7 @@ func (c *client) GetPublicIP(
 		return publicAddresses.PublicIpAddresses[0], nil
 	} else if publicAddresses.Count > 0 { // Endpoint not specified.
 		for _, v := range publicAddresses.PublicIpAddresses { // Pick first available address.
-			if v.Allocated == "" { // Found un-allocated Public IP.
+			if v.Allocated == "" && isSelectedPublicIP(policy, v.Ipaddress) { // Found un-allocated Public IP.
 				return v, nil
 			}
 		}
@@ -169,29 +266,18 @@ func (c *client) GetPublicIP(
 	return nil, errors.New("no public addresses found in available networks")
 }
 
-// GetIsolatedNetwork gets an isolated network in the relevant Zone.
-func (c *client) GetIsolatedNetwork(isoNet *infrav1.CloudStackIsolatedNetwork) (retErr error) {
-	netDetails, count, err := c.cs.Network.GetNetworkByName(isoNet.Spec.Name, cloudstack.WithProject(c.user.Project.ID))
-	if err != nil {
-		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
-		retErr = multierror.Append(retErr, errors.Wrapf(err, "could not get Network ID from %s", isoNet.Spec.Name))
-	} else if count != 1 {
-		retErr = multierror.Append(retErr, errors.Errorf(
-			"expected 1 Network with name %s, but got %d", isoNet.Name, count))
-	} else { // Got netID from the network's name.
-		isoNet.Spec.ID = netDetails.Id
-		return nil
+// isSelectedPublicIP checks an address is within the CIDRs of the policy's selector, if any.
+func isSelectedPublicIP(policy *infrav1.PublicIPPolicy, address string) bool {
+	if policy == nil || policy.Selector == nil || len(policy.Selector.CIDRs) == 0 {
+		return true
 	}
-
-	netDetails, count, err = c.cs.Network.GetNetworkByID(isoNet.Spec.ID, cloudstack.WithProject(c.user.Project.ID))
-	if err != nil {
-		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
-		return multierror.Append(retErr, errors.Wrapf(err, "could not get Network by ID %s", isoNet.Spec.ID))
-	} else if count != 1 {
-		return multierror.Append(retErr, errors.Errorf("expected 1 Network with UUID %s, but got %d", isoNet.Spec.ID, count))
+	ip := net.ParseIP(address)
+	for _, cidr := range policy.Selector.CIDRs {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(ip) {
+			return true
+		}
 	}
-	isoNet.Name = netDetails.Name
-	return nil
+	return false
 }
 
 // ResolveLoadBalancerRuleDetails resolves the details of a load balancer rule by PublicIPID and Port.
@@ -200,32 +286,20 @@ func (c *client) ResolveLoadBalancerRuleDetails(
 	isoNet *infrav1.CloudStackIsolatedNetwork,
 	csCluster *infrav1.CloudStackCluster,
 ) error {
-	p := c.cs.LoadBalancer.NewListLoadBalancerRulesParams()
-	p.SetPublicipid(isoNet.Status.PublicIPID)
-	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
-	loadBalancerRules, err := c.cs.LoadBalancer.ListLoadBalancerRules(p)
+	rule, err := c.findLoadBalancerRule(isoNet)
 	if err != nil {
-		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
-		return errors.Wrap(err, "listing load balancer rules")
+		return err
 	}
-	for _, rule := range loadBalancerRules.LoadBalancerRules {
-		if rule.Publicport == strconv.Itoa(int(isoNet.Spec.ControlPlaneEndpoint.Port)) {
-
-			isoNet.Status.LBRuleID = rule.Id
-			return nil
-		}
+	if rule == nil {
+		return errors.New("no load balancer rule found")
 	}
-	return errors.New("no load balancer rule found")
+	isoNet.Status.LBRuleID = rule.Id
+	return nil
 }
 
-// GetOrCreateLoadBalancerRule Create a load balancer rule that can be assigned to instances.
-func (c *client) GetOrCreateLoadBalancerRule(
-	fd *infrav1.CloudStackFailureDomain,
-	isoNet *infrav1.CloudStackIsolatedNetwork,
-	csCluster *infrav1.CloudStackCluster,
-) (retErr error) {
-	// Check/set ports.
-	// Prefer control plane endpoint. Take iso net port if CP missing. Set to default if both missing.
+// setControlPlaneEndpointPort checks/sets ports.
+// Prefer control plane endpoint. Take iso net port if CP missing. Set to default if both missing.
+func setControlPlaneEndpointPort(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) {
 	if csCluster.Spec.ControlPlaneEndpoint.Port != 0 {
 		isoNet.Spec.ControlPlaneEndpoint.Port = csCluster.Spec.ControlPlaneEndpoint.Port
 	} else if isoNet.Spec.ControlPlaneEndpoint.Port != 0 { // Override default public port if endpoint port specified.
@@ -234,27 +308,45 @@ func (c *client) GetOrCreateLoadBalancerRule(
 		csCluster.Spec.ControlPlaneEndpoint.Port = 6443
 		isoNet.Spec.ControlPlaneEndpoint.Port = 6443
 	}
+}
+
+// GetOrCreateLoadBalancerRule Create a load balancer rule that can be assigned to instances.
+// Existing rules are updated to the cluster's API server load balancer configuration when it is set.
+func (c *client) GetOrCreateLoadBalancerRule(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) (retErr error) {
+	setControlPlaneEndpointPort(isoNet, csCluster)
 
 	// Check if rule exists.
-	if err := c.ResolveLoadBalancerRuleDetails(fd, isoNet, csCluster); err == nil ||
-		!strings.Contains(strings.ToLower(err.Error()), "no load balancer rule found") {
+	rule, err := c.findLoadBalancerRule(isoNet)
+	if err != nil {
 		return errors.Wrap(err, "resolving load balancer rule details")
 	}
+	lbConfig := csCluster.Spec.APIServerLoadBalancer
+	if rule == nil {
+		if err := c.createLoadBalancerRule(isoNet, csCluster); err != nil {
+			return err
+		}
+	} else {
+		isoNet.Status.LBRuleID = rule.Id
+		if lbConfig == nil {
+			return nil
+		}
+		if err := c.updateLoadBalancerRule(csCluster, rule); err != nil {
+			return errors.Wrap(err, "updating load balancer rule")
+		}
+	}
+	if lbConfig == nil {
+		return nil
+	}
 
-	p := c.cs.LoadBalancer.NewCreateLoadBalancerRuleParams(
-		"roundrobin", "Kubernetes_API_Server", K8sDefaultAPIPort, K8sDefaultAPIPort)
-	p.SetPublicport(int(csCluster.Spec.ControlPlaneEndpoint.Port))
-	p.SetNetworkid(isoNet.Spec.ID)
-
-	p.SetPublicipid(isoNet.Status.PublicIPID)
-	p.SetProtocol(NetworkProtocolTCP)
-	resp, err := c.cs.LoadBalancer.CreateLoadBalancerRule(p)
-	if err != nil {
-		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
-		return err
+	if err := c.reconcileLBHealthCheckPolicy(isoNet.Status.LBRuleID, lbConfig.HealthCheck); err != nil {
+		return errors.Wrap(err, "reconciling load balancer health check policy")
 	}
-	isoNet.Status.LBRuleID = resp.Id
-	return nil
+	return errors.Wrap(c.reconcileLBStickinessPolicy(isoNet.Status.LBRuleID, lbConfig.Stickiness),
+		"reconciling load balancer stickiness policy")
 }
 
 // GetOrCreateIsolatedNetwork fetches or builds out the necessary structures for isolated network use.
@@ -263,14 +355,19 @@ func (c *client) GetOrCreateIsolatedNetwork(
 	isoNet *infrav1.CloudStackIsolatedNetwork,
 	csCluster *infrav1.CloudStackCluster,
 ) error {
+	if fd.Spec.Zone.Network.VPC != nil {
+		return c.getOrCreateVPCNetwork(fd, isoNet, csCluster)
+	}
+
 	// Get or create the isolated network itself and resolve details into passed custom resources.
 	net := isoNet.Network()
-	if err := c.ResolveNetwork(net); err != nil { // Doesn't exist, create isolated network.
+	if netDetails, err := c.resolveNetworkDetails(fd.Spec.Zone.ID, "", net); err != nil { // Doesn't exist, create isolated network.
 		if err = c.CreateIsolatedNetwork(fd, isoNet); err != nil {
 			return errors.Wrap(err, "creating a new isolated network")
 		}
 	} else { // Network existed and was resolved. Set ID on isoNet CloudStackIsolatedNetwork in case it only had name set.
 		isoNet.Spec.ID = net.ID
+		isoNet.Status.IPv6CIDR = netDetails.Ip6cidr
 	}
 
 	// Tag the created network.
@@ -289,8 +386,73 @@ func (c *client) GetOrCreateIsolatedNetwork(
 		return errors.Wrap(err, "getting or creating load balancing rule")
 	}
 
-	//  Open the Isolated Network on endopint port.
-	return errors.Wrap(c.OpenFirewallRules(isoNet), "opening the isolated network's firewall")
+	// Open the Isolated Network's firewall per the cluster's firewall policy.
+	return errors.Wrap(c.ReconcileFirewallPolicy(isoNet, csCluster), "reconciling the isolated network's firewall")
+}
+
+// getOrCreateVPCNetwork fetches or builds out the VPC, its ACL list and tiers, and the control plane endpoint for
+// clusters placed in a VPC.
+func (c *client) getOrCreateVPCNetwork(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) error {
+	vpc := fd.Spec.Zone.Network.VPC
+	if err := c.GetOrCreateVPC(fd, isoNet, csCluster); err != nil {
+		return errors.Wrap(err, "getting or creating VPC")
+	}
+	if err := c.ReconcileNetworkACLList(fd, isoNet); err != nil {
+		return errors.Wrap(err, "reconciling network ACL list")
+	}
+
+	// The control plane tier is the isolated network itself.
+	gateway, netmask, err := resolveGatewayAndNetmask(isoNet.Spec.CIDR, isoNet.Spec.Gateway, isoNet.Spec.Netmask)
+	if err != nil {
+		return err
+	}
+	controlPlaneTier := &infrav1.VPCTier{
+		ID:       isoNet.Spec.ID,
+		Name:     isoNet.Spec.Name,
+		Gateway:  gateway,
+		Netmask:  netmask,
+		Offering: isoNet.Spec.Offering,
+	}
+	ipv6CIDR, err := c.getOrCreateVPCTier(fd, isoNet, controlPlaneTier)
+	if err != nil {
+		return errors.Wrap(err, "getting or creating control plane VPC tier")
+	}
+	isoNet.Spec.ID = controlPlaneTier.ID
+	isoNet.Status.IPv6CIDR = ipv6CIDR
+	if err := c.AddClusterTag(ResourceTypeNetwork, isoNet.Spec.ID, csCluster); err != nil {
+		return errors.Wrapf(err, "tagging network with id %s", isoNet.Spec.ID)
+	}
+
+	if vpc.WorkerTier != nil {
+		workerTier := vpc.WorkerTier.DeepCopy()
+		if workerTier.ID == "" {
+			workerTier.ID = isoNet.Status.WorkerTierID
+		}
+		if err := c.GetOrCreateVPCTier(fd, isoNet, workerTier); err != nil {
+			return errors.Wrap(err, "getting or creating worker VPC tier")
+		}
+		isoNet.Status.WorkerTierID = workerTier.ID
+		if err := c.AddClusterTag(ResourceTypeNetwork, workerTier.ID, csCluster); err != nil {
+			return errors.Wrapf(err, "tagging network with id %s", workerTier.ID)
+		}
+	}
+
+	if vpc.InternalLoadBalancer {
+		return errors.Wrap(c.GetOrCreateInternalLoadBalancer(fd, isoNet, csCluster),
+			"getting or creating internal load balancer")
+	}
+
+	// Associate Public IP with the VPC.
+	if err := c.AssociatePublicIPAddress(fd, isoNet, csCluster); err != nil {
+		return errors.Wrapf(err, "associating public IP address to csCluster")
+	}
+
+	// Setup a load balancing rule to map VMs to Public IP. Egress is governed by the ACL list, not firewall rules.
+	return errors.Wrap(c.GetOrCreateLoadBalancerRule(fd, isoNet, csCluster), "getting or creating load balancing rule")
 }
 
 // AssignVMToLoadBalancerRule assigns a VM instance to a load balancing rule (specifying lb membership).
@@ -317,6 +479,57 @@ func (c *client) AssignVMToLoadBalancerRule(isoNet *infrav1.CloudStackIsolatedNe
 	return retErr
 }
 
+// ReconcileIsolatedNetworkTags brings the tags of the network, VPC, public IPs and load balancer rules CAPC created for
+// the cluster in line with the cluster's additional tags. Networks, VPCs and public IPs CAPC didn't create are left
+// alone. Load balancer rules are always CAPC's, but weren't tagged as such before. Nothing is done if the network's
+// status records the tags of the same resources are already in line.
+func (c *client) ReconcileIsolatedNetworkTags(
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) error {
+	tags := clusterResourceTags(csCluster)
+	type taggedResource struct {
+		rType                ResourceType
+		id                   string
+		requireCreatedByCAPC bool
+	}
+	resources := []taggedResource{
+		{ResourceTypeNetwork, isoNet.Spec.ID, true},
+		{ResourceTypeVPC, isoNet.Status.VPCID, true},
+		{ResourceTypeIPAddress, isoNet.Status.PublicIPID, true},
+		{ResourceTypeLBRule, isoNet.Status.LBRuleID, false},
+	}
+	for _, rule := range isoNet.Status.WorkloadLBRules {
+		resources = append(resources, taggedResource{ResourceTypeLBRule, rule.ID, false})
+		if rule.DedicatedPublicIP {
+			resources = append(resources, taggedResource{ResourceTypeIPAddress, rule.PublicIPID, true})
+		}
+	}
+	if bastion := csCluster.Status.Bastion; bastion != nil && bastion.FailureDomainName == isoNet.Spec.FailureDomainName {
+		resources = append(resources, taggedResource{ResourceTypeIPAddress, bastion.PublicIPID, true})
+	}
+	ids := []string{}
+	for _, resource := range resources {
+		if resource.id != "" {
+			ids = append(ids, string(resource.rType)+"/"+resource.id)
+		}
+	}
+	hash := tagsHash(tags, ids...)
+	if isoNet.Status.TagsHash == hash {
+		return nil
+	}
+	for _, resource := range resources {
+		if resource.id == "" {
+			continue
+		}
+		if err := c.reconcileResourceTags(resource.rType, resource.id, csCluster, tags, resource.requireCreatedByCAPC); err != nil {
+			return err
+		}
+	}
+	isoNet.Status.TagsHash = hash
+	return nil
+}
+
 // DeleteNetwork deletes an isolated network.
 func (c *client) DeleteNetwork(net infrav1.Network) error {
 	_, err := c.cs.Network.DeleteNetwork(c.cs.Network.NewDeleteNetworkParams(net.ID))
@@ -330,6 +543,14 @@ func (c *client) DisposeIsoNetResources(
 	isoNet *infrav1.CloudStackIsolatedNetwork,
 	csCluster *infrav1.CloudStackCluster,
 ) (retError error) {
+	if err := c.DisposeWorkloadLoadBalancerRules(isoNet, csCluster); err != nil {
+		return err
+	}
+	if isoNet.Status.PublicIPID != "" && csCluster.Spec.ControlPlanePublicIP != nil {
+		if err := c.updateRetainedTags(isoNet, csCluster, csCluster.Spec.ControlPlanePublicIP.Retain); err != nil {
+			return errors.Wrap(err, "updating public IP retention tags")
+		}
+	}
 	if isoNet.Status.PublicIPID != "" {
 		if err := c.DeleteClusterTag(ResourceTypeIPAddress, isoNet.Status.PublicIPID, csCluster); err != nil {
 			return err
@@ -344,10 +565,46 @@ func (c *client) DisposeIsoNetResources(
 	if err := c.DeleteNetworkIfNotInUse(csCluster, *isoNet.Network()); err != nil {
 		return err
 	}
+	if isoNet.Status.VPCID != "" {
+		return c.DisposeVPCResources(zone, isoNet, csCluster)
+	}
 
 	return nil
 }
 
+// updateRetainedTags tags the endpoint's public IP, and the network or VPC it is associated with, as retained for
+// clusters of the same name, or removes those tags. The tags keep the resources from being disposed of.
+func (c *client) updateRetainedTags(
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+	retain bool,
+) error {
+	resources := map[ResourceType]string{ResourceTypeIPAddress: isoNet.Status.PublicIPID, ResourceTypeNetwork: isoNet.Spec.ID}
+	if isoNet.Status.VPCID != "" {
+		resources[ResourceTypeVPC] = isoNet.Status.VPCID
+	}
+	tag := map[string]string{generateRetainedTagName(csCluster): "1"}
+	for _, rType := range []ResourceType{ResourceTypeIPAddress, ResourceTypeNetwork, ResourceTypeVPC} {
+		rID, ok := resources[rType]
+		if !ok {
+			continue
+		}
+		if managedByCAPC, err := c.IsCapcManaged(rType, rID); err != nil {
+			return err
+		} else if !managedByCAPC { // Resources CAPC didn't create are never disposed of anyway.
+			continue
+		}
+		if retain {
+			if err := c.AddTags(rType, rID, tag); err != nil {
+				return err
+			}
+		} else if err := c.DeleteTags(rType, rID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DeleteNetworkIfNotInUse deletes an isolated network if the network is no longer in use (indicated by in use tags).
 func (c *client) DeleteNetworkIfNotInUse(csCluster *infrav1.CloudStackCluster, net infrav1.Network) (retError error) {
 	tags, err := c.GetTags(ResourceTypeNetwork, net.ID)
@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
 	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
 )
@@ -74,8 +75,8 @@ var _ = Describe("Network", func() {
 			nos.EXPECT().GetNetworkOfferingID(gomock.Any()).Return("someOfferingID", 1, nil)
 			ns.EXPECT().NewCreateNetworkParams(gomock.Any(), gomock.Any(), gomock.Any()).
 				Return(&csapi.CreateNetworkParams{})
-			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
-			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 0, nil)
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{}, nil)
 			ns.EXPECT().CreateNetwork(gomock.Any()).Return(&csapi.CreateNetworkResponse{Id: dummies.ISONet1.ID}, nil)
 			as.EXPECT().NewListPublicIpAddressesParams().Return(&csapi.ListPublicIpAddressesParams{})
 			as.EXPECT().ListPublicIpAddresses(gomock.Any()).
@@ -103,6 +104,15 @@ var _ = Describe("Network", func() {
 				fs.EXPECT().CreateEgressFirewallRule(ruleParamsICMP).
 					Return(&csapi.CreateEgressFirewallRuleResponse{}, nil))
 
+			// Without a firewall policy the default rules are opened and tagged.
+			fs.EXPECT().NewListEgressFirewallRulesParams().Return(&csapi.ListEgressFirewallRulesParams{})
+			fs.EXPECT().ListEgressFirewallRules(gomock.Any()).Return(&csapi.ListEgressFirewallRulesResponse{}, nil)
+			fs.EXPECT().NewListFirewallRulesParams().Return(&csapi.ListFirewallRulesParams{})
+			fs.EXPECT().ListFirewallRules(gomock.Any()).Return(&csapi.ListFirewallRulesResponse{
+				Count: 1,
+				FirewallRules: []*csapi.FirewallRule{{Id: "FakeFirewallRuleID", Protocol: "tcp", Cidrlist: cloud.AnyCIDR,
+					Startport: int(dummies.EndPointPort), Endport: int(dummies.EndPointPort)}}}, nil)
+
 			// Will add cluster tag once to Network and once to PublicIP.
 			createdByResponse := &csapi.ListTagsResponse{Tags: []*csapi.Tag{{Key: cloud.CreatedByCAPCTagName, Value: "1"}}}
 			gomock.InOrder(
@@ -111,10 +121,10 @@ var _ = Describe("Network", func() {
 				rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{}),
 				rs.EXPECT().ListTags(gomock.Any()).Return(createdByResponse, nil))
 
-			// Will add creation and cluster tags to network and PublicIP.
+			// Will add creation and cluster tags to network and PublicIP, and creation tags to the default egress rules.
 			rs.EXPECT().NewCreateTagsParams(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(&csapi.CreateTagsParams{}).Times(4)
-			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(4)
+				Return(&csapi.CreateTagsParams{}).Times(7)
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(7)
 
 			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
 			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(
@@ -125,14 +135,35 @@ var _ = Describe("Network", func() {
 		})
 
 		It("fails to get network offering from CloudStack", func() {
-			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
-			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 0, nil)
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{}, nil)
 			nos.EXPECT().GetNetworkOfferingID(gomock.Any()).Return("", -1, fakeError)
 
 			err := client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)
 			Ω(err).ShouldNot(Succeed())
 			Ω(err.Error()).Should(ContainSubstring("creating a new isolated network"))
 		})
+
+		It("uses the network offering specified on the isolated network", func() {
+			dummies.CSISONet1.Spec.Offering = "CustomIsolatedNetworkOffering"
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{}, nil)
+			nos.EXPECT().GetNetworkOfferingID("CustomIsolatedNetworkOffering").Return("", -1, fakeError)
+
+			Ω(client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).
+				ShouldNot(Succeed())
+		})
+
+		It("fails to create the network with an invalid CIDR", func() {
+			dummies.CSISONet1.Spec.CIDR = "10.1.0.0/33"
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{}, nil)
+			nos.EXPECT().GetNetworkOfferingID(cloud.NetOffering).Return("someOfferingID", 1, nil)
+
+			err := client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)
+			Ω(err).ShouldNot(Succeed())
+			Ω(err.Error()).Should(ContainSubstring("parsing network CIDR"))
+		})
 	})
 
 	Context("for a closed firewall", func() {
@@ -238,6 +269,52 @@ var _ = Describe("Network", func() {
 		})
 	})
 
+	Context("with a public IP policy", func() {
+		BeforeEach(func() {
+			dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = ""
+		})
+
+		It("prefers an address retained by an earlier cluster of the same name", func() {
+			dummies.CSCluster.Spec.ControlPlanePublicIP = &infrav1.PublicIPPolicy{Retain: true}
+			as.EXPECT().NewListPublicIpAddressesParams().Return(&csapi.ListPublicIpAddressesParams{})
+			as.EXPECT().ListPublicIpAddresses(gomock.Any()).
+				DoAndReturn(func(p *csapi.ListPublicIpAddressesParams) (*csapi.ListPublicIpAddressesResponse, error) {
+					tags, _ := p.GetTags()
+					Ω(tags).Should(HaveKey(cloud.RetainedTagNamePrefix + dummies.CSCluster.Namespace + "_" + dummies.CSCluster.Name))
+					return &csapi.ListPublicIpAddressesResponse{
+						Count:             1,
+						PublicIpAddresses: []*csapi.PublicIpAddress{{Id: "RetainedIPID", Ipaddress: ipAddress}},
+					}, nil
+				})
+
+			publicIPAddress, err := client.GetPublicIP(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)
+			Ω(err).Should(Succeed())
+			Ω(publicIPAddress.Id).Should(Equal("RetainedIPID"))
+		})
+
+		It("picks an unallocated address within the selector's CIDRs", func() {
+			dummies.CSCluster.Spec.ControlPlanePublicIP = &infrav1.PublicIPPolicy{
+				Selector: &infrav1.PublicIPSelector{VLANID: "FakeVLANID", CIDRs: []string{"192.0.2.0/28"}}}
+			as.EXPECT().NewListPublicIpAddressesParams().Return(&csapi.ListPublicIpAddressesParams{})
+			as.EXPECT().ListPublicIpAddresses(gomock.Any()).
+				DoAndReturn(func(p *csapi.ListPublicIpAddressesParams) (*csapi.ListPublicIpAddressesResponse, error) {
+					vlanID, _ := p.GetVlanid()
+					Ω(vlanID).Should(Equal("FakeVLANID"))
+					return &csapi.ListPublicIpAddressesResponse{
+						Count: 2,
+						PublicIpAddresses: []*csapi.PublicIpAddress{
+							{Id: "OutsideID", Ipaddress: "192.0.2.100"},
+							{Id: "InsideID", Ipaddress: "192.0.2.5"},
+						},
+					}, nil
+				})
+
+			publicIPAddress, err := client.GetPublicIP(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)
+			Ω(err).Should(Succeed())
+			Ω(publicIPAddress.Id).Should(Equal("InsideID"))
+		})
+	})
+
 	Context("Associate Public IP address to Network", func() {
 		It("Successfully Associated Public IP to provided isolated network", func() {
 			as.EXPECT().NewListPublicIpAddressesParams().Return(&csapi.ListPublicIpAddressesParams{})
@@ -470,6 +547,86 @@ var _ = Describe("Network", func() {
 			Ω(client.DisposeIsoNetResources(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).ShouldNot(Succeed())
 		})
 
+		It("keeps a retained public IP and its network", func() {
+			dummies.CSISONet1.Status.PublicIPID = "publicIpId"
+			dummies.CSCluster.Spec.ControlPlanePublicIP = &infrav1.PublicIPPolicy{Retain: true}
+			retainedTagName := cloud.RetainedTagNamePrefix + dummies.CSCluster.Namespace + "_" + dummies.CSCluster.Name
+			rtlp := &csapi.ListTagsParams{}
+			rtdp := &csapi.DeleteTagsParams{}
+			createdByCAPCResponse := &csapi.ListTagsResponse{Tags: []*csapi.Tag{{Key: cloud.CreatedByCAPCTagName, Value: "1"}}}
+			retainedResponse := &csapi.ListTagsResponse{Tags: []*csapi.Tag{
+				{Key: cloud.CreatedByCAPCTagName, Value: "1"}, {Key: retainedTagName, Value: "1"}}}
+
+			// Tag the IP and the network as retained.
+			rs.EXPECT().NewListTagsParams().Return(rtlp).AnyTimes()
+			gomock.InOrder(
+				rs.EXPECT().ListTags(rtlp).Return(createdByCAPCResponse, nil).Times(2),
+				rs.EXPECT().ListTags(rtlp).Return(retainedResponse, nil).AnyTimes(),
+			)
+			rs.EXPECT().NewCreateTagsParams([]string{"publicIpId"}, string(cloud.ResourceTypeIPAddress),
+				map[string]string{retainedTagName: "1"}).Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().NewCreateTagsParams([]string{dummies.CSISONet1.Spec.ID}, string(cloud.ResourceTypeNetwork),
+				map[string]string{retainedTagName: "1"}).Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(2)
+			// Remove the cluster tags, but neither disassociate the IP nor delete the network.
+			rs.EXPECT().NewDeleteTagsParams(gomock.Any(), gomock.Any()).Return(rtdp).AnyTimes()
+			rs.EXPECT().DeleteTags(rtdp).Return(&csapi.DeleteTagsResponse{}, nil).AnyTimes()
+			as.EXPECT().GetPublicIpAddressByID("publicIpId", gomock.Any()).Return(&csapi.PublicIpAddress{}, 1, nil)
+
+			Ω(client.DisposeIsoNetResources(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
+	Context("Reconcile tags of isolated network resources", func() {
+		It("tags the resources CAPC created with the cluster's additional tags and leaves the others alone", func() {
+			dummies.CSCluster.Spec.AdditionalTags = map[string]string{"env": "prod"}
+			dummies.CSISONet1.Status.PublicIPID = "ip-id"
+			dummies.CSISONet1.Status.LBRuleID = "lb-rule-id"
+
+			tagsByID := map[string][]*csapi.Tag{
+				dummies.CSISONet1.Spec.ID: {{Key: cloud.CreatedByCAPCTagName, Value: "1"}},
+				// Not created by CAPC.
+				"ip-id": {{Key: cloud.ClusterTagNamePrefix + string(dummies.CSCluster.UID), Value: "1"}},
+			}
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{}).Times(3)
+			rs.EXPECT().ListTags(gomock.Any()).DoAndReturn(func(p *csapi.ListTagsParams) (*csapi.ListTagsResponse, error) {
+				id, _ := p.GetResourceid()
+				return &csapi.ListTagsResponse{Tags: tagsByID[id]}, nil
+			}).Times(3)
+			rs.EXPECT().NewCreateTagsParams([]string{dummies.CSISONet1.Spec.ID}, string(cloud.ResourceTypeNetwork), gomock.Any()).
+				DoAndReturn(func(_ []string, _ string, tags map[string]string) *csapi.CreateTagsParams {
+					Ω(tags).Should(HaveKeyWithValue("env", "prod"))
+					Ω(tags).ShouldNot(HaveKey(cloud.CreatedByCAPCTagName))
+					return &csapi.CreateTagsParams{}
+				})
+			rs.EXPECT().NewCreateTagsParams([]string{"lb-rule-id"}, string(cloud.ResourceTypeLBRule), gomock.Any()).
+				DoAndReturn(func(_ []string, _ string, tags map[string]string) *csapi.CreateTagsParams {
+					Ω(tags).Should(HaveKeyWithValue(cloud.ClusterUIDTagName, string(dummies.CSCluster.UID)))
+					return &csapi.CreateTagsParams{}
+				})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(2)
+
+			Ω(client.ReconcileIsolatedNetworkTags(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+
+		It("only reconciles the tags again once the desired tags or the resources change", func() {
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&csapi.ListTagsResponse{}, nil)
+
+			Ω(client.ReconcileIsolatedNetworkTags(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.TagsHash).ShouldNot(BeEmpty())
+			// Nothing is listed as long as the tags and resources are the same.
+			Ω(client.ReconcileIsolatedNetworkTags(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+
+			dummies.CSISONet1.Status.LBRuleID = "lb-rule-id"
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{}).Times(2)
+			rs.EXPECT().ListTags(gomock.Any()).Return(&csapi.ListTagsResponse{}, nil).Times(2)
+			rs.EXPECT().NewCreateTagsParams([]string{"lb-rule-id"}, string(cloud.ResourceTypeLBRule), gomock.Any()).
+				Return(&csapi.CreateTagsParams{})
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil)
+
+			Ω(client.ReconcileIsolatedNetworkTags(dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
 	})
 
 	Context("Networking Integ Tests", Label("integ"), func() {
@@ -500,7 +657,7 @@ var _ = Describe("Network", func() {
 			dummies.SetDummyIsoNetToNameOnly()
 			dummies.SetClusterSpecToNet(&dummies.ISONet1)
 
-			Ω(client.ResolveNetwork(&dummies.ISONet1)).Should(Succeed())
+			Ω(client.ResolveNetwork(dummies.Zone1.ID, &dummies.ISONet1)).Should(Succeed())
 			Ω(dummies.ISONet1.ID).ShouldNot(BeEmpty())
 			Ω(dummies.ISONet1.Type).Should(Equal(cloud.NetworkTypeIsolated))
 		})
@@ -510,7 +667,7 @@ var _ = Describe("Network", func() {
 			dummies.SetDummyIsoNetToNameOnly()
 			dummies.SetClusterSpecToNet(&dummies.ISONet1)
 			dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = ""
-			Ω(client.ResolveNetwork(&dummies.ISONet1)).Should(Succeed())
+			Ω(client.ResolveNetwork(dummies.Zone1.ID, &dummies.ISONet1)).Should(Succeed())
 		})
 
 		It("adds an isolated network and doesn't fail when asked to GetOrCreateIsolatedNetwork multiple times", func() {
@@ -518,7 +675,7 @@ var _ = Describe("Network", func() {
 			Ω(client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
 
 			// Network should now exist if it didn't at the start.
-			Ω(client.ResolveNetwork(&dummies.ISONet1)).Should(Succeed())
+			Ω(client.ResolveNetwork(dummies.Zone1.ID, &dummies.ISONet1)).Should(Succeed())
 
 			// Do once more.
 			Ω(client.GetOrCreateIsolatedNetwork(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
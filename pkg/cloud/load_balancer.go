@@ -0,0 +1,533 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+type LoadBalancerIface interface {
+	ReconcileWorkloadLoadBalancerRules(
+		*infrav1.CloudStackFailureDomain, *infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster, map[string][]string) error
+	DisposeWorkloadLoadBalancerRules(*infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster) error
+	RemoveVMFromLoadBalancerRules(*infrav1.CloudStackIsolatedNetwork, string) (bool, error)
+}
+
+const (
+	APIServerLBRuleName       = "Kubernetes_API_Server"
+	APIServerStickinessPolicy = "Kubernetes_API_Server_stickiness"
+	LBAlgorithmRoundRobin     = "roundrobin"
+)
+
+// lbAlgorithm returns the configured API server load balancing algorithm or the roundrobin default.
+func lbAlgorithm(csCluster *infrav1.CloudStackCluster) string {
+	if lb := csCluster.Spec.APIServerLoadBalancer; lb != nil && lb.Algorithm != "" {
+		return lb.Algorithm
+	}
+	return LBAlgorithmRoundRobin
+}
+
+// lbBackendPort returns the configured API server backend port or the Kubernetes default.
+func lbBackendPort(csCluster *infrav1.CloudStackCluster) int {
+	if lb := csCluster.Spec.APIServerLoadBalancer; lb != nil && lb.BackendPort != 0 {
+		return lb.BackendPort
+	}
+	return K8sDefaultAPIPort
+}
+
+// findLoadBalancerRule looks up the load balancer rule of the control plane endpoint's public IP and port.
+// Returns nil without error when there is none.
+func (c *client) findLoadBalancerRule(isoNet *infrav1.CloudStackIsolatedNetwork) (*cloudstack.LoadBalancerRule, error) {
+	p := c.cs.LoadBalancer.NewListLoadBalancerRulesParams()
+	p.SetPublicipid(isoNet.Status.PublicIPID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	loadBalancerRules, err := c.cs.LoadBalancer.ListLoadBalancerRules(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing load balancer rules")
+	}
+	for _, rule := range loadBalancerRules.LoadBalancerRules {
+		if rule.Publicport == strconv.Itoa(int(isoNet.Spec.ControlPlaneEndpoint.Port)) {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// createLoadBalancerRule creates the API server load balancer rule.
+func (c *client) createLoadBalancerRule(isoNet *infrav1.CloudStackIsolatedNetwork, csCluster *infrav1.CloudStackCluster) error {
+	backendPort := lbBackendPort(csCluster)
+	p := c.cs.LoadBalancer.NewCreateLoadBalancerRuleParams(
+		lbAlgorithm(csCluster), APIServerLBRuleName, backendPort, backendPort)
+	p.SetPublicport(int(csCluster.Spec.ControlPlaneEndpoint.Port))
+	p.SetNetworkid(isoNet.Spec.ID)
+
+	p.SetPublicipid(isoNet.Status.PublicIPID)
+	p.SetProtocol(NetworkProtocolTCP)
+	// Firewall rules for the endpoint are managed by ReconcileFirewallPolicy.
+	p.SetOpenfirewall(false)
+	resp, err := c.cs.LoadBalancer.CreateLoadBalancerRule(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return err
+	}
+	isoNet.Status.LBRuleID = resp.Id
+	return nil
+}
+
+// updateLoadBalancerRule brings the algorithm of an existing API server load balancer rule in line with the cluster's
+// configuration. CloudStack can't change the backend port of a rule, and replacing the rule would interrupt the API
+// server, so the webhook rejects backend port changes.
+func (c *client) updateLoadBalancerRule(csCluster *infrav1.CloudStackCluster, rule *cloudstack.LoadBalancerRule) error {
+	if algorithm := lbAlgorithm(csCluster); rule.Algorithm != algorithm {
+		p := c.cs.LoadBalancer.NewUpdateLoadBalancerRuleParams(rule.Id)
+		p.SetAlgorithm(algorithm)
+		if _, err := c.cs.LoadBalancer.UpdateLoadBalancerRule(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "updating algorithm of load balancer rule with ID %s", rule.Id)
+		}
+	}
+	return nil
+}
+
+// reconcileLBHealthCheckPolicy makes the health check policy of a load balancer rule match the given one. The policy
+// is removed when nil.
+func (c *client) reconcileLBHealthCheckPolicy(lbRuleID string, healthCheck *infrav1.LoadBalancerHealthCheck) error {
+	p := c.cs.LoadBalancer.NewListLBHealthCheckPoliciesParams()
+	p.SetLbruleid(lbRuleID)
+	resp, err := c.cs.LoadBalancer.ListLBHealthCheckPolicies(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing health check policies of load balancer rule with ID %s", lbRuleID)
+	}
+	found := false
+	for _, policies := range resp.LBHealthCheckPolicies {
+		for _, existing := range policies.Healthcheckpolicy {
+			if healthCheck != nil && !found && healthCheckMatches(existing, healthCheck) {
+				found = true
+				continue
+			}
+			if _, err := c.cs.LoadBalancer.DeleteLBHealthCheckPolicy(
+				c.cs.LoadBalancer.NewDeleteLBHealthCheckPolicyParams(existing.Id)); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+				return errors.Wrapf(err, "deleting health check policy with ID %s", existing.Id)
+			}
+		}
+	}
+	if healthCheck == nil || found {
+		return nil
+	}
+
+	cp := c.cs.LoadBalancer.NewCreateLBHealthCheckPolicyParams(lbRuleID)
+	setIfNotEmpty(healthCheck.PingPath, cp.SetPingpath)
+	if healthCheck.IntervalSeconds != 0 {
+		cp.SetIntervaltime(healthCheck.IntervalSeconds)
+	}
+	if healthCheck.ResponseTimeoutSeconds != 0 {
+		cp.SetResponsetimeout(healthCheck.ResponseTimeoutSeconds)
+	}
+	if healthCheck.HealthyThreshold != 0 {
+		cp.SetHealthythreshold(healthCheck.HealthyThreshold)
+	}
+	if healthCheck.UnhealthyThreshold != 0 {
+		cp.SetUnhealthythreshold(healthCheck.UnhealthyThreshold)
+	}
+	if _, err := c.cs.LoadBalancer.CreateLBHealthCheckPolicy(cp); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "creating health check policy for load balancer rule with ID %s", lbRuleID)
+	}
+	return nil
+}
+
+// healthCheckMatches compares the fields set in the spec with an existing policy. CloudStack fills in defaults for
+// the others.
+func healthCheckMatches(existing cloudstack.LBHealthCheckPolicyHealthcheckpolicy, healthCheck *infrav1.LoadBalancerHealthCheck) bool {
+	return (healthCheck.PingPath == "" || existing.Pingpath == healthCheck.PingPath) &&
+		(healthCheck.IntervalSeconds == 0 || existing.Healthcheckinterval == healthCheck.IntervalSeconds) &&
+		(healthCheck.ResponseTimeoutSeconds == 0 || existing.Responsetime == healthCheck.ResponseTimeoutSeconds) &&
+		(healthCheck.HealthyThreshold == 0 || existing.Healthcheckthresshold == healthCheck.HealthyThreshold) &&
+		(healthCheck.UnhealthyThreshold == 0 || existing.Unhealthcheckthresshold == healthCheck.UnhealthyThreshold)
+}
+
+// reconcileLBStickinessPolicy makes the stickiness policy of a load balancer rule match the given one. The policy is
+// removed when nil.
+func (c *client) reconcileLBStickinessPolicy(lbRuleID string, stickiness *infrav1.LoadBalancerStickiness) error {
+	p := c.cs.LoadBalancer.NewListLBStickinessPoliciesParams()
+	p.SetLbruleid(lbRuleID)
+	resp, err := c.cs.LoadBalancer.ListLBStickinessPolicies(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing stickiness policies of load balancer rule with ID %s", lbRuleID)
+	}
+	found := false
+	for _, policies := range resp.LBStickinessPolicies {
+		for _, existing := range policies.Stickinesspolicy {
+			if stickiness != nil && !found && stickinessMatches(existing, stickiness) {
+				found = true
+				continue
+			}
+			if _, err := c.cs.LoadBalancer.DeleteLBStickinessPolicy(
+				c.cs.LoadBalancer.NewDeleteLBStickinessPolicyParams(existing.Id)); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+				return errors.Wrapf(err, "deleting stickiness policy with ID %s", existing.Id)
+			}
+		}
+	}
+	if stickiness == nil || found {
+		return nil
+	}
+
+	cp := c.cs.LoadBalancer.NewCreateLBStickinessPolicyParams(lbRuleID, stickiness.Method, APIServerStickinessPolicy)
+	if len(stickiness.Params) > 0 {
+		cp.SetParam(stickiness.Params)
+	}
+	if _, err := c.cs.LoadBalancer.CreateLBStickinessPolicy(cp); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "creating stickiness policy for load balancer rule with ID %s", lbRuleID)
+	}
+	return nil
+}
+
+// stickinessMatches compares the method and the params set in the spec with an existing policy. CloudStack fills in
+// defaults for the other params.
+func stickinessMatches(existing cloudstack.LBStickinessPolicyStickinesspolicy, stickiness *infrav1.LoadBalancerStickiness) bool {
+	if !strings.EqualFold(existing.Methodname, stickiness.Method) {
+		return false
+	}
+	for key, value := range stickiness.Params {
+		if existing.Params[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ReconcileWorkloadLoadBalancerRules makes the isolated network's workload load balancer rules match the cluster's
+// LoadBalancerRules, with the instances given per rule name as members. Rules no longer in the spec are deleted.
+func (c *client) ReconcileWorkloadLoadBalancerRules(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+	members map[string][]string,
+) (retErr error) {
+	current := map[string]infrav1.WorkloadLBRuleStatus{}
+	for _, status := range isoNet.Status.WorkloadLBRules {
+		current[status.Name] = status
+	}
+	// Record whatever was created or deleted, even on failure.
+	defer func() {
+		isoNet.Status.WorkloadLBRules = []infrav1.WorkloadLBRuleStatus{}
+		for _, status := range current {
+			isoNet.Status.WorkloadLBRules = append(isoNet.Status.WorkloadLBRules, status)
+		}
+		sort.Slice(isoNet.Status.WorkloadLBRules, func(i, j int) bool {
+			return isoNet.Status.WorkloadLBRules[i].Name < isoNet.Status.WorkloadLBRules[j].Name
+		})
+	}()
+
+	desired := map[string]bool{}
+	for _, rule := range csCluster.Spec.LoadBalancerRules {
+		desired[rule.Name] = true
+		status, err := c.getOrCreateWorkloadLoadBalancerRule(fd, isoNet, csCluster, rule, current)
+		if err != nil {
+			return errors.Wrapf(err, "getting or creating load balancer rule %s", rule.Name)
+		}
+		if err := c.reconcileLoadBalancerRuleMembers(status.ID, members[rule.Name]); err != nil {
+			return errors.Wrapf(err, "reconciling members of load balancer rule %s", rule.Name)
+		}
+	}
+	for name, status := range current {
+		if desired[name] {
+			continue
+		}
+		if err := c.deleteWorkloadLoadBalancerRule(status, csCluster); err != nil {
+			return errors.Wrapf(err, "deleting load balancer rule %s", name)
+		}
+		delete(current, name)
+	}
+	return nil
+}
+
+// DisposeWorkloadLoadBalancerRules deletes the isolated network's workload load balancer rules and releases their
+// dedicated public IPs.
+func (c *client) DisposeWorkloadLoadBalancerRules(
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) error {
+	for len(isoNet.Status.WorkloadLBRules) > 0 {
+		status := isoNet.Status.WorkloadLBRules[0]
+		if err := c.deleteWorkloadLoadBalancerRule(status, csCluster); err != nil {
+			return errors.Wrapf(err, "deleting load balancer rule %s", status.Name)
+		}
+		isoNet.Status.WorkloadLBRules = isoNet.Status.WorkloadLBRules[1:]
+	}
+	return nil
+}
+
+// getOrCreateWorkloadLoadBalancerRule verifies the recorded CloudStack rule of a workload load balancer rule still
+// matches its spec, and (re)creates it otherwise. The recorded status is updated in place.
+func (c *client) getOrCreateWorkloadLoadBalancerRule(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+	rule infrav1.WorkloadLoadBalancerRule,
+	current map[string]infrav1.WorkloadLBRuleStatus,
+) (infrav1.WorkloadLBRuleStatus, error) {
+	status, found := current[rule.Name]
+	publicIPID := isoNet.Status.PublicIPID
+	if rule.PublicIPAddress != "" {
+		ipID, err := c.getOrAssociateDedicatedPublicIP(fd, isoNet, csCluster, rule.PublicIPAddress)
+		if err != nil {
+			return status, err
+		}
+		publicIPID = ipID
+	}
+	privatePort := rule.PrivatePort
+	if privatePort == 0 {
+		privatePort = rule.PublicPort
+	}
+	protocol := rule.Protocol
+	if protocol == "" {
+		protocol = NetworkProtocolTCP
+	}
+	algorithm := rule.Algorithm
+	if algorithm == "" {
+		algorithm = LBAlgorithmRoundRobin
+	}
+
+	if found {
+		existing, count, err := c.cs.LoadBalancer.GetLoadBalancerRuleByID(status.ID, cloudstack.WithProject(c.user.Project.ID))
+		if err != nil && !strings.Contains(err.Error(), "No match found") {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return status, errors.Wrapf(err, "getting load balancer rule with ID %s", status.ID)
+		}
+		if count == 1 && existing.Publicipid == publicIPID && existing.Publicport == strconv.Itoa(rule.PublicPort) &&
+			existing.Privateport == strconv.Itoa(privatePort) && strings.EqualFold(existing.Protocol, protocol) {
+			if existing.Algorithm == algorithm {
+				return status, nil
+			}
+			p := c.cs.LoadBalancer.NewUpdateLoadBalancerRuleParams(status.ID)
+			p.SetAlgorithm(algorithm)
+			if _, err := c.cs.LoadBalancer.UpdateLoadBalancerRule(p); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+				return status, errors.Wrapf(err, "updating algorithm of load balancer rule with ID %s", status.ID)
+			}
+			return status, nil
+		}
+		// Replace the rule, releasing its dedicated IP if that changed too.
+		if count == 1 {
+			if _, err := c.cs.LoadBalancer.DeleteLoadBalancerRule(
+				c.cs.LoadBalancer.NewDeleteLoadBalancerRuleParams(status.ID)); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+				return status, errors.Wrapf(err, "deleting load balancer rule with ID %s", status.ID)
+			}
+		}
+		if status.DedicatedPublicIP && status.PublicIPID != publicIPID {
+			if err := c.releaseDedicatedPublicIP(status.PublicIPID, csCluster); err != nil {
+				return status, err
+			}
+		}
+		delete(current, rule.Name)
+	}
+
+	networkID := isoNet.Spec.ID
+	if isoNet.Status.WorkerTierID != "" { // Workers of a VPC cluster may be placed in a tier of their own.
+		networkID = isoNet.Status.WorkerTierID
+	}
+	p := c.cs.LoadBalancer.NewCreateLoadBalancerRuleParams(algorithm, rule.Name, privatePort, rule.PublicPort)
+	p.SetNetworkid(networkID)
+	p.SetPublicipid(publicIPID)
+	p.SetProtocol(protocol)
+	// Firewall rules for the public port are managed by ReconcileFirewallPolicy.
+	p.SetOpenfirewall(false)
+	resp, err := c.cs.LoadBalancer.CreateLoadBalancerRule(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return status, errors.Wrapf(err, "creating load balancer rule for public port %d", rule.PublicPort)
+	}
+	status = infrav1.WorkloadLBRuleStatus{
+		Name: rule.Name, ID: resp.Id, PublicIPID: publicIPID, DedicatedPublicIP: rule.PublicIPAddress != ""}
+	current[rule.Name] = status
+	return status, nil
+}
+
+// getOrAssociateDedicatedPublicIP associates a public IP address with the isolated network, or the VPC it is a tier
+// of, and tags it for the cluster.
+func (c *client) getOrAssociateDedicatedPublicIP(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+	ipAddress string,
+) (string, error) {
+	p := c.cs.Address.NewListPublicIpAddressesParams()
+	p.SetAllocatedonly(false)
+	p.SetZoneid(fd.Spec.Zone.ID)
+	p.SetIpaddress(ipAddress)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Address.ListPublicIpAddresses(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return "", errors.Wrapf(err, "listing public IP address %s", ipAddress)
+	} else if resp.Count != 1 {
+		return "", errors.Errorf("expected 1 public IP address %s, but got %d", ipAddress, resp.Count)
+	}
+	publicAddress := resp.PublicIpAddresses[0]
+	if isoNet.Status.VPCID != "" && publicAddress.Vpcid == isoNet.Status.VPCID {
+		return publicAddress.Id, nil
+	} else if isoNet.Status.VPCID == "" && publicAddress.Associatednetworkid == isoNet.Spec.ID {
+		return publicAddress.Id, nil
+	} else if publicAddress.Allocated != "" {
+		return "", errors.Errorf("public IP address %s is already allocated elsewhere", ipAddress)
+	}
+
+	ap := c.cs.Address.NewAssociateIpAddressParams()
+	ap.SetIpaddress(ipAddress)
+	if isoNet.Status.VPCID != "" {
+		ap.SetVpcid(isoNet.Status.VPCID)
+	} else {
+		ap.SetNetworkid(isoNet.Spec.ID)
+	}
+	setIfNotEmpty(c.user.Project.ID, ap.SetProjectid)
+	if _, err := c.cs.Address.AssociateIpAddress(ap); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return "", errors.Wrapf(err, "associating public IP address %s to network with ID %s", ipAddress, isoNet.Spec.ID)
+	} else if err := c.AddClusterTag(ResourceTypeIPAddress, publicAddress.Id, csCluster); err != nil {
+		return "", errors.Wrapf(err, "adding tag to public IP address with ID %s", publicAddress.Id)
+	} else if err := c.AddCreatedByCAPCTag(ResourceTypeIPAddress, publicAddress.Id); err != nil {
+		return "", errors.Wrapf(err, "adding tag to public IP address with ID %s", publicAddress.Id)
+	}
+	return publicAddress.Id, nil
+}
+
+// releaseDedicatedPublicIP disassociates a dedicated public IP once no cluster uses it anymore.
+func (c *client) releaseDedicatedPublicIP(publicIPID string, csCluster *infrav1.CloudStackCluster) error {
+	if err := c.DeleteClusterTag(ResourceTypeIPAddress, publicIPID, csCluster); err != nil {
+		return err
+	}
+	if tagsAllowDisposal, err := c.DoClusterTagsAllowDisposal(ResourceTypeIPAddress, publicIPID); err != nil || !tagsAllowDisposal {
+		return err
+	}
+	if err := c.DeleteCreatedByCAPCTag(ResourceTypeIPAddress, publicIPID); err != nil {
+		return err
+	}
+	if _, err := c.cs.Address.DisassociateIpAddress(c.cs.Address.NewDisassociateIpAddressParams(publicIPID)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "disassociating public IP address with ID %s", publicIPID)
+	}
+	return nil
+}
+
+// deleteWorkloadLoadBalancerRule deletes a workload load balancer rule and releases its dedicated public IP.
+func (c *client) deleteWorkloadLoadBalancerRule(status infrav1.WorkloadLBRuleStatus, csCluster *infrav1.CloudStackCluster) error {
+	if _, err := c.cs.LoadBalancer.DeleteLoadBalancerRule(
+		c.cs.LoadBalancer.NewDeleteLoadBalancerRuleParams(status.ID)); err != nil &&
+		!strings.Contains(strings.ToLower(err.Error()), "unable to find") {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "deleting load balancer rule with ID %s", status.ID)
+	}
+	if status.DedicatedPublicIP {
+		return c.releaseDedicatedPublicIP(status.PublicIPID, csCluster)
+	}
+	return nil
+}
+
+// reconcileLoadBalancerRuleMembers assigns the given instances to a load balancer rule and removes all others.
+func (c *client) reconcileLoadBalancerRuleMembers(lbRuleID string, instanceIDs []string) error {
+	resp, err := c.cs.LoadBalancer.ListLoadBalancerRuleInstances(
+		c.cs.LoadBalancer.NewListLoadBalancerRuleInstancesParams(lbRuleID))
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing instances of load balancer rule with ID %s", lbRuleID)
+	}
+	desired := map[string]bool{}
+	for _, id := range instanceIDs {
+		desired[id] = true
+	}
+	toRemove := []string{}
+	for _, instance := range resp.LoadBalancerRuleInstances {
+		if desired[instance.Id] {
+			delete(desired, instance.Id)
+		} else {
+			toRemove = append(toRemove, instance.Id)
+		}
+	}
+	toAssign := []string{}
+	for _, id := range instanceIDs {
+		if desired[id] {
+			toAssign = append(toAssign, id)
+		}
+	}
+
+	if len(toRemove) > 0 {
+		p := c.cs.LoadBalancer.NewRemoveFromLoadBalancerRuleParams(lbRuleID)
+		p.SetVirtualmachineids(toRemove)
+		if _, err := c.cs.LoadBalancer.RemoveFromLoadBalancerRule(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "removing instances from load balancer rule with ID %s", lbRuleID)
+		}
+	}
+	if len(toAssign) > 0 {
+		p := c.cs.LoadBalancer.NewAssignToLoadBalancerRuleParams(lbRuleID)
+		p.SetVirtualmachineids(toAssign)
+		if _, err := c.cs.LoadBalancer.AssignToLoadBalancerRule(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "assigning instances to load balancer rule with ID %s", lbRuleID)
+		}
+	}
+	return nil
+}
+
+// RemoveVMFromLoadBalancerRules takes an instance out of the isolated network's API server and workload load balancer
+// rules. Returns whether the instance was a member of any of them.
+func (c *client) RemoveVMFromLoadBalancerRules(isoNet *infrav1.CloudStackIsolatedNetwork, instanceID string) (bool, error) {
+	ruleIDs := []string{}
+	if isoNet.Status.LBRuleID != "" {
+		ruleIDs = append(ruleIDs, isoNet.Status.LBRuleID)
+	}
+	for _, status := range isoNet.Status.WorkloadLBRules {
+		ruleIDs = append(ruleIDs, status.ID)
+	}
+
+	removed := false
+	for _, ruleID := range ruleIDs {
+		resp, err := c.cs.LoadBalancer.ListLoadBalancerRuleInstances(
+			c.cs.LoadBalancer.NewListLoadBalancerRuleInstancesParams(ruleID))
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return removed, errors.Wrapf(err, "listing instances of load balancer rule with ID %s", ruleID)
+		}
+		for _, instance := range resp.LoadBalancerRuleInstances {
+			if instance.Id != instanceID {
+				continue
+			}
+			p := c.cs.LoadBalancer.NewRemoveFromLoadBalancerRuleParams(ruleID)
+			p.SetVirtualmachineids([]string{instanceID})
+			if _, err := c.cs.LoadBalancer.RemoveFromLoadBalancerRule(p); err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+				return removed, errors.Wrapf(err, "removing instance %s from load balancer rule with ID %s", instanceID, ruleID)
+			}
+			removed = true
+		}
+	}
+	return removed, nil
+}
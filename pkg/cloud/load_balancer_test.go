@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"strconv"
+
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Load balancer", func() {
+	var ( // Declare shared vars.
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		lbs        *csapi.MockLoadBalancerServiceIface
+		client     cloud.Client
+	)
+
+	BeforeEach(func() {
+		// Setup new mock services.
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		lbs = mockClient.LoadBalancer.(*csapi.MockLoadBalancerServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		dummies.CSISONet1.Status.PublicIPID = dummies.PublicIPID
+		dummies.CSCluster.Spec.APIServerLoadBalancer = &infrav1.APIServerLoadBalancer{
+			Algorithm:   "leastconn",
+			BackendPort: 8443,
+			HealthCheck: &infrav1.LoadBalancerHealthCheck{PingPath: "/healthz", IntervalSeconds: 10},
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("API server load balancer rule", func() {
+		It("creates the rule with the configured algorithm, backend port and health check", func() {
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(&csapi.ListLoadBalancerRulesResponse{}, nil)
+			lbs.EXPECT().NewCreateLoadBalancerRuleParams("leastconn", cloud.APIServerLBRuleName, 8443, 8443).
+				Return(&csapi.CreateLoadBalancerRuleParams{})
+			lbs.EXPECT().CreateLoadBalancerRule(gomock.Any()).
+				Return(&csapi.CreateLoadBalancerRuleResponse{Id: dummies.LBRuleID}, nil)
+
+			lbs.EXPECT().NewListLBHealthCheckPoliciesParams().Return(&csapi.ListLBHealthCheckPoliciesParams{})
+			lbs.EXPECT().ListLBHealthCheckPolicies(gomock.Any()).Return(&csapi.ListLBHealthCheckPoliciesResponse{}, nil)
+			lbs.EXPECT().NewCreateLBHealthCheckPolicyParams(dummies.LBRuleID).Return(&csapi.CreateLBHealthCheckPolicyParams{})
+			lbs.EXPECT().CreateLBHealthCheckPolicy(gomock.Any()).Return(&csapi.CreateLBHealthCheckPolicyResponse{}, nil)
+			lbs.EXPECT().NewListLBStickinessPoliciesParams().Return(&csapi.ListLBStickinessPoliciesParams{})
+			lbs.EXPECT().ListLBStickinessPolicies(gomock.Any()).Return(&csapi.ListLBStickinessPoliciesResponse{}, nil)
+
+			Ω(client.GetOrCreateLoadBalancerRule(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.LBRuleID).Should(Equal(dummies.LBRuleID))
+		})
+
+		It("keeps the backend port of an existing rule and removes stale policies", func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer.HealthCheck = nil
+			dummies.CSCluster.Spec.APIServerLoadBalancer.Stickiness = &infrav1.LoadBalancerStickiness{Method: "SourceBased"}
+
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(&csapi.ListLoadBalancerRulesResponse{
+				LoadBalancerRules: []*csapi.LoadBalancerRule{{
+					Id: dummies.LBRuleID, Algorithm: "leastconn", Privateport: "6443",
+					Publicport: strconv.Itoa(int(dummies.EndPointPort))}}}, nil)
+
+			lbs.EXPECT().NewListLBHealthCheckPoliciesParams().Return(&csapi.ListLBHealthCheckPoliciesParams{})
+			lbs.EXPECT().ListLBHealthCheckPolicies(gomock.Any()).Return(&csapi.ListLBHealthCheckPoliciesResponse{
+				LBHealthCheckPolicies: []*csapi.LBHealthCheckPolicy{{
+					Healthcheckpolicy: []csapi.LBHealthCheckPolicyHealthcheckpolicy{{Id: "stale-hc"}}}}}, nil)
+			lbs.EXPECT().NewDeleteLBHealthCheckPolicyParams("stale-hc").Return(&csapi.DeleteLBHealthCheckPolicyParams{})
+			lbs.EXPECT().DeleteLBHealthCheckPolicy(gomock.Any()).Return(&csapi.DeleteLBHealthCheckPolicyResponse{}, nil)
+			lbs.EXPECT().NewListLBStickinessPoliciesParams().Return(&csapi.ListLBStickinessPoliciesParams{})
+			lbs.EXPECT().ListLBStickinessPolicies(gomock.Any()).Return(&csapi.ListLBStickinessPoliciesResponse{
+				LBStickinessPolicies: []*csapi.LBStickinessPolicy{{
+					Stickinesspolicy: []csapi.LBStickinessPolicyStickinesspolicy{{Id: "kept", Methodname: "SourceBased"}}}}}, nil)
+
+			Ω(client.GetOrCreateLoadBalancerRule(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.LBRuleID).Should(Equal(dummies.LBRuleID))
+		})
+
+		It("updates the algorithm of an existing rule in place", func() {
+			dummies.CSCluster.Spec.APIServerLoadBalancer.HealthCheck = nil
+
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(&csapi.ListLoadBalancerRulesResponse{
+				LoadBalancerRules: []*csapi.LoadBalancerRule{{
+					Id: dummies.LBRuleID, Algorithm: "roundrobin", Privateport: "8443",
+					Publicport: strconv.Itoa(int(dummies.EndPointPort))}}}, nil)
+			lbs.EXPECT().NewUpdateLoadBalancerRuleParams(dummies.LBRuleID).Return(&csapi.UpdateLoadBalancerRuleParams{})
+			lbs.EXPECT().UpdateLoadBalancerRule(gomock.Any()).Return(&csapi.UpdateLoadBalancerRuleResponse{}, nil)
+			lbs.EXPECT().NewListLBHealthCheckPoliciesParams().Return(&csapi.ListLBHealthCheckPoliciesParams{})
+			lbs.EXPECT().ListLBHealthCheckPolicies(gomock.Any()).Return(&csapi.ListLBHealthCheckPoliciesResponse{}, nil)
+			lbs.EXPECT().NewListLBStickinessPoliciesParams().Return(&csapi.ListLBStickinessPoliciesParams{})
+			lbs.EXPECT().ListLBStickinessPolicies(gomock.Any()).Return(&csapi.ListLBStickinessPoliciesResponse{}, nil)
+
+			Ω(client.GetOrCreateLoadBalancerRule(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+		})
+	})
+
+	Context("Workload load balancer rules", func() {
+		It("creates new rules, syncs their members and deletes rules no longer in the spec", func() {
+			dummies.CSCluster.Spec.LoadBalancerRules = []infrav1.WorkloadLoadBalancerRule{
+				{Name: "ingress-https", PublicPort: 443, PrivatePort: 30443}}
+			dummies.CSISONet1.Status.WorkloadLBRules = []infrav1.WorkloadLBRuleStatus{
+				{Name: "removed", ID: "RemovedRuleID", PublicIPID: dummies.PublicIPID}}
+
+			lbs.EXPECT().NewCreateLoadBalancerRuleParams(cloud.LBAlgorithmRoundRobin, "ingress-https", 30443, 443).
+				Return(&csapi.CreateLoadBalancerRuleParams{})
+			lbs.EXPECT().CreateLoadBalancerRule(gomock.Any()).
+				DoAndReturn(func(p *csapi.CreateLoadBalancerRuleParams) (*csapi.CreateLoadBalancerRuleResponse, error) {
+					openFirewall, set := p.GetOpenfirewall()
+					Ω(set).Should(BeTrue())
+					Ω(openFirewall).Should(BeFalse())
+					return &csapi.CreateLoadBalancerRuleResponse{Id: "IngressRuleID"}, nil
+				})
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams("IngressRuleID").
+				Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{
+				LoadBalancerRuleInstances: []*csapi.VirtualMachine{{Id: "worker1"}, {Id: "gone"}}}, nil)
+			lbs.EXPECT().NewRemoveFromLoadBalancerRuleParams("IngressRuleID").Return(&csapi.RemoveFromLoadBalancerRuleParams{})
+			lbs.EXPECT().RemoveFromLoadBalancerRule(gomock.Any()).Return(&csapi.RemoveFromLoadBalancerRuleResponse{}, nil)
+			lbs.EXPECT().NewAssignToLoadBalancerRuleParams("IngressRuleID").Return(&csapi.AssignToLoadBalancerRuleParams{})
+			lbs.EXPECT().AssignToLoadBalancerRule(gomock.Any()).Return(&csapi.AssignToLoadBalancerRuleResponse{}, nil)
+			lbs.EXPECT().NewDeleteLoadBalancerRuleParams("RemovedRuleID").Return(&csapi.DeleteLoadBalancerRuleParams{})
+			lbs.EXPECT().DeleteLoadBalancerRule(gomock.Any()).Return(&csapi.DeleteLoadBalancerRuleResponse{}, nil)
+
+			Ω(client.ReconcileWorkloadLoadBalancerRules(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster,
+				map[string][]string{"ingress-https": {"worker1", "worker2"}})).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.WorkloadLBRules).Should(Equal([]infrav1.WorkloadLBRuleStatus{
+				{Name: "ingress-https", ID: "IngressRuleID", PublicIPID: dummies.PublicIPID}}))
+		})
+
+		It("keeps an unchanged rule", func() {
+			dummies.CSCluster.Spec.LoadBalancerRules = []infrav1.WorkloadLoadBalancerRule{
+				{Name: "dns", PublicPort: 53, Protocol: "udp"}}
+			dummies.CSISONet1.Status.WorkloadLBRules = []infrav1.WorkloadLBRuleStatus{
+				{Name: "dns", ID: "DNSRuleID", PublicIPID: dummies.PublicIPID}}
+
+			lbs.EXPECT().GetLoadBalancerRuleByID("DNSRuleID", gomock.Any()).Return(&csapi.LoadBalancerRule{
+				Id: "DNSRuleID", Publicipid: dummies.PublicIPID, Publicport: "53", Privateport: "53", Protocol: "udp",
+				Algorithm: cloud.LBAlgorithmRoundRobin}, 1, nil)
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams("DNSRuleID").Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{}, nil)
+
+			Ω(client.ReconcileWorkloadLoadBalancerRules(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster,
+				map[string][]string{})).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.WorkloadLBRules).Should(HaveLen(1))
+		})
+
+		It("updates the algorithm of an existing rule in place", func() {
+			dummies.CSCluster.Spec.LoadBalancerRules = []infrav1.WorkloadLoadBalancerRule{
+				{Name: "dns", PublicPort: 53, Protocol: "udp", Algorithm: "source"}}
+			dummies.CSISONet1.Status.WorkloadLBRules = []infrav1.WorkloadLBRuleStatus{
+				{Name: "dns", ID: "DNSRuleID", PublicIPID: dummies.PublicIPID}}
+
+			lbs.EXPECT().GetLoadBalancerRuleByID("DNSRuleID", gomock.Any()).Return(&csapi.LoadBalancerRule{
+				Id: "DNSRuleID", Publicipid: dummies.PublicIPID, Publicport: "53", Privateport: "53", Protocol: "udp",
+				Algorithm: cloud.LBAlgorithmRoundRobin}, 1, nil)
+			lbs.EXPECT().NewUpdateLoadBalancerRuleParams("DNSRuleID").Return(&csapi.UpdateLoadBalancerRuleParams{})
+			lbs.EXPECT().UpdateLoadBalancerRule(gomock.Any()).
+				DoAndReturn(func(p *csapi.UpdateLoadBalancerRuleParams) (*csapi.UpdateLoadBalancerRuleResponse, error) {
+					algorithm, _ := p.GetAlgorithm()
+					Ω(algorithm).Should(Equal("source"))
+					return &csapi.UpdateLoadBalancerRuleResponse{}, nil
+				})
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams("DNSRuleID").Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{}, nil)
+
+			Ω(client.ReconcileWorkloadLoadBalancerRules(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster,
+				map[string][]string{})).Should(Succeed())
+		})
+	})
+
+	Context("Removing an instance from load balancer rules", func() {
+		It("removes the instance only from the rules it is a member of", func() {
+			dummies.CSISONet1.Status.LBRuleID = dummies.LBRuleID
+			dummies.CSISONet1.Status.WorkloadLBRules = []infrav1.WorkloadLBRuleStatus{{Name: "http", ID: "HTTPRuleID"}}
+
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams(dummies.LBRuleID).
+				Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams("HTTPRuleID").
+				Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			gomock.InOrder(
+				lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{
+					LoadBalancerRuleInstances: []*csapi.VirtualMachine{{Id: "cp1"}, {Id: "cp2"}}}, nil),
+				lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{
+					LoadBalancerRuleInstances: []*csapi.VirtualMachine{{Id: "worker1"}}}, nil),
+			)
+			lbs.EXPECT().NewRemoveFromLoadBalancerRuleParams(dummies.LBRuleID).Return(&csapi.RemoveFromLoadBalancerRuleParams{})
+			lbs.EXPECT().RemoveFromLoadBalancerRule(gomock.Any()).Return(&csapi.RemoveFromLoadBalancerRuleResponse{}, nil)
+
+			Ω(client.RemoveVMFromLoadBalancerRules(dummies.CSISONet1, "cp1")).Should(BeTrue())
+		})
+
+		It("reports instances that weren't a member of any rule", func() {
+			dummies.CSISONet1.Status.LBRuleID = dummies.LBRuleID
+			lbs.EXPECT().NewListLoadBalancerRuleInstancesParams(dummies.LBRuleID).
+				Return(&csapi.ListLoadBalancerRuleInstancesParams{})
+			lbs.EXPECT().ListLoadBalancerRuleInstances(gomock.Any()).Return(&csapi.ListLoadBalancerRuleInstancesResponse{}, nil)
+
+			Ω(client.RemoveVMFromLoadBalancerRules(dummies.CSISONet1, "cp1")).Should(BeFalse())
+		})
+	})
+})
@@ -17,14 +17,15 @@ limitations under the License.
 package cloud
 
 import (
+	"strings"
+
 	"github.com/apache/cloudstack-go/v2/cloudstack"
-	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 )
 
 type NetworkIface interface {
-	ResolveNetwork(*infrav1.Network) error
+	ResolveNetwork(string, *infrav1.Network) error
 	RemoveClusterTagFromNetwork(*infrav1.CloudStackCluster, infrav1.Network) error
 }
 
@@ -47,36 +48,57 @@ func NetworkExists(net infrav1.Network) bool {
 	return false
 }
 
-// ResolveNetwork fetches networks' ID, Name, and Type.
-func (c *client) ResolveNetwork(net *infrav1.Network) (retErr error) {
-	// TODO rebuild this to consider cases with networks in many zones.
-	// Use ListNetworks instead.
-	netName := net.Name
-	netDetails, count, err := c.cs.Network.GetNetworkByName(netName, cloudstack.WithProject(c.user.Project.ID))
+// ResolveNetwork fetches networks' ID, Name, and Type within a zone.
+func (c *client) ResolveNetwork(zoneID string, net *infrav1.Network) error {
+	_, err := c.resolveNetworkDetails(zoneID, "", net)
+	return err
+}
+
+// resolveNetworkDetails fetches networks' ID, Name, and Type within a zone, and within a VPC when a VPC ID is passed,
+// and returns the network's details. A network with the ID, when set, is looked up. Otherwise the network is looked up
+// by name and, when set, by tags. Networks of other zones with the same name are ignored.
+func (c *client) resolveNetworkDetails(zoneID, vpcID string, net *infrav1.Network) (*cloudstack.Network, error) {
+	p := c.cs.Network.NewListNetworksParams()
+	setIfNotEmpty(zoneID, p.SetZoneid)
+	setIfNotEmpty(vpcID, p.SetVpcid)
+	if net.ID != "" {
+		p.SetId(net.ID)
+	} else {
+		setIfNotEmpty(net.Name, p.SetKeyword)
+		if len(net.Tags) > 0 {
+			p.SetTags(net.Tags)
+		}
+	}
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Network.ListNetworks(p)
 	if err != nil {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
-		retErr = multierror.Append(retErr, errors.Wrapf(err, "could not get Network ID from %s", netName))
-	} else if count != 1 {
-		retErr = multierror.Append(retErr, errors.Errorf(
-			"expected 1 Network with name %s, but got %d", netName, count))
-	} else { // Got netID from the network's name.
-		net.ID = netDetails.Id
-		net.Type = netDetails.Type
-		return nil
+		return nil, errors.Wrapf(err, "listing networks in zone with ID %s", zoneID)
 	}
 
-	// Now get network details.
-	netDetails, count, err = c.cs.Network.GetNetworkByID(net.ID, cloudstack.WithProject(c.user.Project.ID))
-	if err != nil {
-		return multierror.Append(retErr, errors.Wrapf(err, "could not get Network by ID %s", net.ID))
-	} else if count != 1 {
-		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
-		return multierror.Append(retErr, errors.Errorf("expected 1 Network with UUID %s, but got %d", net.ID, count))
+	// The keyword matches parts of names too.
+	var matches []*cloudstack.Network
+	var matchIDs []string
+	for _, network := range resp.Networks {
+		if net.ID == "" && network.Name != net.Name {
+			continue
+		}
+		matches = append(matches, network)
+		matchIDs = append(matchIDs, network.Id)
 	}
-	net.Name = netDetails.Name
-	net.ID = netDetails.Id
-	net.Type = netDetails.Type
-	return nil
+	switch {
+	case len(matches) == 0 && net.ID != "":
+		return nil, errors.Errorf("no match found for Network with UUID %s in zone with ID %s", net.ID, zoneID)
+	case len(matches) == 0:
+		return nil, errors.Errorf("no match found for Network with name %s in zone with ID %s", net.Name, zoneID)
+	case len(matches) > 1:
+		return nil, errors.Errorf("expected 1 Network with name %s in zone with ID %s, but got %d: %s",
+			net.Name, zoneID, len(matches), strings.Join(matchIDs, ", "))
+	}
+	net.Name = matches[0].Name
+	net.ID = matches[0].Id
+	net.Type = matches[0].Type
+	return matches[0], nil
 }
 
 func generateNetworkTagName(csCluster *infrav1.CloudStackCluster) string {
@@ -53,24 +53,58 @@ var _ = Describe("Network", func() {
 
 	Context("for an existing network", func() {
 		It("resolves network by ID", func() {
-			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(nil, 0, nil)
-			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(dummies.CAPCNetToCSAPINet(&dummies.ISONet1), 1, nil)
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).
+				DoAndReturn(func(p *csapi.ListNetworksParams) (*csapi.ListNetworksResponse, error) {
+					id, _ := p.GetId()
+					Ω(id).Should(Equal(dummies.ISONet1.ID))
+					return &csapi.ListNetworksResponse{Count: 1,
+						Networks: []*csapi.Network{dummies.CAPCNetToCSAPINet(&dummies.ISONet1)}}, nil
+				})
 
-			Ω(client.ResolveNetwork(&dummies.ISONet1)).Should(Succeed())
+			Ω(client.ResolveNetwork(dummies.Zone1.ID, &dummies.ISONet1)).Should(Succeed())
 		})
 
-		It("resolves network by Name", func() {
-			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(dummies.CAPCNetToCSAPINet(&dummies.ISONet1), 1, nil)
+		It("resolves network by Name and tags within the zone", func() {
+			dummies.ISONet1.ID = ""
+			dummies.ISONet1.Tags = map[string]string{"purpose": "k8s"}
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).
+				DoAndReturn(func(p *csapi.ListNetworksParams) (*csapi.ListNetworksResponse, error) {
+					zoneID, _ := p.GetZoneid()
+					Ω(zoneID).Should(Equal(dummies.Zone2.ID))
+					keyword, _ := p.GetKeyword()
+					Ω(keyword).Should(Equal(dummies.ISONet1.Name))
+					tags, _ := p.GetTags()
+					Ω(tags).Should(Equal(map[string]string{"purpose": "k8s"}))
+					// The keyword matches names partially.
+					return &csapi.ListNetworksResponse{Count: 2, Networks: []*csapi.Network{
+						{Id: "other-net-id", Name: dummies.ISONet1.Name + "-other"},
+						{Id: "net-id", Name: dummies.ISONet1.Name, Type: cloud.NetworkTypeIsolated},
+					}}, nil
+				})
 
-			Ω(client.ResolveNetwork(&dummies.ISONet1)).Should(Succeed())
+			Ω(client.ResolveNetwork(dummies.Zone2.ID, &dummies.ISONet1)).Should(Succeed())
+			Ω(dummies.ISONet1.ID).Should(Equal("net-id"))
+			Ω(dummies.ISONet1.Type).Should(Equal(cloud.NetworkTypeIsolated))
 		})
 
-		It("When there exists more than one network with the same name", func() {
-			ns.EXPECT().GetNetworkByName(dummies.ISONet1.Name, gomock.Any()).Return(dummies.CAPCNetToCSAPINet(&dummies.ISONet1), 2, nil)
-			ns.EXPECT().GetNetworkByID(dummies.ISONet1.ID, gomock.Any()).Return(nil, 2, errors.New("There is more then one result for Network UUID"))
-			err := client.ResolveNetwork(&dummies.ISONet1)
-			Ω(err).ShouldNot(Succeed())
-			Ω(err.Error()).Should(ContainSubstring(fmt.Sprintf("expected 1 Network with name %s, but got %d", dummies.ISONet1.Name, 2)))
+		It("lists the candidates when there exists more than one network with the same name", func() {
+			dummies.ISONet1.ID = ""
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{Count: 2, Networks: []*csapi.Network{
+				{Id: "net-id-1", Name: dummies.ISONet1.Name}, {Id: "net-id-2", Name: dummies.ISONet1.Name}}}, nil)
+
+			Ω(client.ResolveNetwork(dummies.Zone1.ID, &dummies.ISONet1)).Should(MatchError(ContainSubstring(
+				fmt.Sprintf("expected 1 Network with name %s in zone with ID %s, but got 2: net-id-1, net-id-2",
+					dummies.ISONet1.Name, dummies.Zone1.ID))))
+		})
+
+		It("fails when listing networks fails", func() {
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(nil, errors.New("list failed"))
+
+			Ω(client.ResolveNetwork(dummies.Zone1.ID, &dummies.ISONet1)).Should(MatchError(ContainSubstring("list failed")))
 		})
 	})
 
@@ -0,0 +1,270 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type OrphanIface interface {
+	ListCAPCResources() ([]CAPCResource, error)
+	DeleteCAPCResource(CAPCResource) error
+}
+
+// CAPCResource is a CloudStack resource CAPC created for clusters.
+type CAPCResource struct {
+	Type ResourceType
+	ID   string
+	Name string
+	// ClusterUIDs are the UIDs of the clusters using the resource, taken from its cluster tags or its name.
+	ClusterUIDs []string
+	// ClusterNamespace and ClusterName identify the cluster owning the resource, taken from its owner tags or its name.
+	// Affinity groups only record the name.
+	ClusterNamespace string
+	ClusterName      string
+	// Retained resources are kept for later clusters of the same name and never orphaned.
+	Retained bool
+	// ManagementCluster identifies the management cluster that created the resource, taken from its management cluster
+	// tag or, for affinity groups, their description. It's empty for resources created before CAPC recorded it.
+	ManagementCluster string
+}
+
+// CAPCResourceDeletionOrder lists resource types in the order orphans can be deleted in: load balancer rules before
+// the public IPs they're on, VMs before their volumes and affinity groups, and everything before the networks.
+var CAPCResourceDeletionOrder = []ResourceType{
+	ResourceTypeLBRule,
+	ResourceTypeVM,
+	ResourceTypeVolume,
+	ResourceTypeAffinityGroup,
+	ResourceTypeIPAddress,
+	ResourceTypeNetwork,
+}
+
+// capcTaggedResourceTypes are the resource types CAPC tags with created_by_CAPC and the clusters using them.
+var capcTaggedResourceTypes = []ResourceType{
+	ResourceTypeVM, ResourceTypeVolume, ResourceTypeNetwork, ResourceTypeIPAddress}
+
+// affinityGroupNameRegexp matches the names of affinity groups CAPC creates, capturing the name and UID of their
+// cluster.
+var affinityGroupNameRegexp = regexp.MustCompile(
+	`^(.+)-([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})-[A-Za-z-]*Affinity-.+$`)
+
+// affinityGroupManagementClusterRegexp matches the descriptions of affinity groups CAPC creates, capturing the
+// management cluster owning them.
+var affinityGroupManagementClusterRegexp = regexp.MustCompile(` of management cluster (\S+)$`)
+
+// LiveClusters identifies the clusters that exist by UID, and by namespace and name. clusterctl move recreates
+// clusters with new UIDs, so resources owned by a cluster of the same namespace and name aren't orphaned either.
+type LiveClusters struct {
+	uids            map[string]bool
+	namespacedNames map[string]bool
+	names           map[string]bool
+}
+
+// NewLiveClusters returns an empty set of live clusters.
+func NewLiveClusters() *LiveClusters {
+	return &LiveClusters{uids: map[string]bool{}, namespacedNames: map[string]bool{}, names: map[string]bool{}}
+}
+
+// Add adds a live cluster.
+func (l *LiveClusters) Add(uid, namespace, name string) {
+	l.uids[uid] = true
+	l.namespacedNames[namespace+"/"+name] = true
+	l.names[name] = true
+}
+
+// owns returns whether a live cluster owns the resource. Resources only recording the name of their cluster are owned
+// by the live clusters of that name in any namespace.
+func (l *LiveClusters) owns(r CAPCResource) bool {
+	for _, uid := range r.ClusterUIDs {
+		if l.uids[uid] {
+			return true
+		}
+	}
+	if r.ClusterName == "" {
+		return false
+	} else if r.ClusterNamespace == "" {
+		return l.names[r.ClusterName]
+	}
+	return l.namespacedNames[r.ClusterNamespace+"/"+r.ClusterName]
+}
+
+// Orphaned returns whether the resource was used by clusters, none of which are live. Retained resources, resources
+// without cluster tags and resources of other management clusters are never orphaned.
+func (r CAPCResource) Orphaned(live *LiveClusters) bool {
+	if r.Retained || len(r.ClusterUIDs) == 0 || (r.ManagementCluster != "" && r.ManagementCluster != ManagementClusterID) {
+		return false
+	}
+	return !live.owns(r)
+}
+
+// Deletable returns whether the resource was created by this management cluster, and may be deleted once orphaned.
+// Resources created before CAPC recorded its management cluster may belong to any management cluster sharing the
+// account, so they're never deleted.
+func (r CAPCResource) Deletable() bool {
+	return ManagementClusterID != "" && r.ManagementCluster == ManagementClusterID
+}
+
+// ListCAPCResources lists the VMs, volumes, networks, public IPs, load balancer rules and affinity groups CAPC created
+// that the client can see. Tagged resources are only listed if they carry the created_by_CAPC tag, load balancer rules
+// if they're on such a public IP, and affinity groups if their name follows CAPC's naming.
+func (c *client) ListCAPCResources() ([]CAPCResource, error) {
+	resources := []CAPCResource{}
+	publicIPs := map[string]CAPCResource{}
+	for _, rType := range capcTaggedResourceTypes {
+		tagged, err := c.listCAPCTaggedResources(rType)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range tagged {
+			if rType == ResourceTypeIPAddress {
+				publicIPs[resource.ID] = resource
+			}
+		}
+		resources = append(resources, tagged...)
+	}
+
+	lbp := c.cs.LoadBalancer.NewListLoadBalancerRulesParams()
+	lbp.SetListall(true)
+	setIfNotEmpty(c.user.Project.ID, lbp.SetProjectid)
+	lbRules, err := c.cs.LoadBalancer.ListLoadBalancerRules(lbp)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing load balancer rules")
+	}
+	for _, rule := range lbRules.LoadBalancerRules {
+		if publicIP, found := publicIPs[rule.Publicipid]; found {
+			resources = append(resources, CAPCResource{
+				Type: ResourceTypeLBRule, ID: rule.Id, Name: rule.Name, ClusterUIDs: publicIP.ClusterUIDs,
+				ClusterNamespace: publicIP.ClusterNamespace, ClusterName: publicIP.ClusterName, Retained: publicIP.Retained,
+				ManagementCluster: publicIP.ManagementCluster})
+		}
+	}
+
+	agp := c.cs.AffinityGroup.NewListAffinityGroupsParams()
+	agp.SetListall(true)
+	setIfNotEmpty(c.user.Project.ID, agp.SetProjectid)
+	groups, err := c.cs.AffinityGroup.ListAffinityGroups(agp)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrap(err, "listing affinity groups")
+	}
+	for _, group := range groups.AffinityGroups {
+		if matches := affinityGroupNameRegexp.FindStringSubmatch(group.Name); matches != nil {
+			resource := CAPCResource{
+				Type: ResourceTypeAffinityGroup, ID: group.Id, Name: group.Name, ClusterUIDs: []string{matches[2]},
+				ClusterName: matches[1]}
+			if matches := affinityGroupManagementClusterRegexp.FindStringSubmatch(group.Description); matches != nil {
+				resource.ManagementCluster = matches[1]
+			}
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+// listCAPCTaggedResources lists the resources of a type carrying the created_by_CAPC tag, with the UIDs of the
+// clusters tagged as using them, the namespace and name of the cluster owning them and the management cluster that
+// created them.
+func (c *client) listCAPCTaggedResources(rType ResourceType) ([]CAPCResource, error) {
+	p := c.cs.Resourcetags.NewListTagsParams()
+	p.SetResourcetype(string(rType))
+	p.SetListall(true)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Resourcetags.ListTags(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return nil, errors.Wrapf(err, "listing tags of %s resources", rType)
+	}
+
+	byID := map[string]*CAPCResource{}
+	createdByCAPC := map[string]bool{}
+	ids := []string{}
+	for _, tag := range resp.Tags {
+		resource, found := byID[tag.Resourceid]
+		if !found {
+			resource = &CAPCResource{Type: rType, ID: tag.Resourceid, ClusterUIDs: []string{}}
+			byID[tag.Resourceid] = resource
+			ids = append(ids, tag.Resourceid)
+		}
+		switch {
+		case tag.Key == CreatedByCAPCTagName:
+			createdByCAPC[tag.Resourceid] = true
+		case tag.Key == ClusterNamespaceTagName:
+			resource.ClusterNamespace = tag.Value
+		case tag.Key == ClusterNameTagName:
+			resource.ClusterName = tag.Value
+		case tag.Key == ManagementClusterTagName:
+			resource.ManagementCluster = tag.Value
+		case strings.HasPrefix(tag.Key, RetainedTagNamePrefix):
+			resource.Retained = true
+		case strings.HasPrefix(tag.Key, ClusterTagNamePrefix):
+			resource.ClusterUIDs = append(resource.ClusterUIDs, strings.TrimPrefix(tag.Key, ClusterTagNamePrefix))
+		}
+	}
+
+	resources := []CAPCResource{}
+	for _, id := range ids {
+		if createdByCAPC[id] {
+			resources = append(resources, *byID[id])
+		}
+	}
+	return resources, nil
+}
+
+// DeleteCAPCResource deletes a resource CAPC created. VMs are expunged along with their data disks. Resources that
+// aren't deletable are refused.
+func (c *client) DeleteCAPCResource(resource CAPCResource) (retErr error) {
+	if !resource.Deletable() {
+		return errors.Errorf("%s with ID %s isn't tagged as created by management cluster %s",
+			resource.Type, resource.ID, ManagementClusterID)
+	}
+	switch resource.Type {
+	case ResourceTypeVM:
+		volIDs, err := c.listVMInstanceVolumeIDs(resource.ID, "DATADISK")
+		if err != nil {
+			return errors.Wrapf(err, "listing data disks of VM with ID %s", resource.ID)
+		}
+		p := c.csAsync.VirtualMachine.NewDestroyVirtualMachineParams(resource.ID)
+		p.SetExpunge(true)
+		setArrayIfNotEmpty(volIDs, p.SetVolumeids)
+		_, retErr = c.csAsync.VirtualMachine.DestroyVirtualMachine(p)
+	case ResourceTypeVolume:
+		_, retErr = c.cs.Volume.DeleteVolume(c.cs.Volume.NewDeleteVolumeParams(resource.ID))
+	case ResourceTypeNetwork:
+		_, retErr = c.cs.Network.DeleteNetwork(c.cs.Network.NewDeleteNetworkParams(resource.ID))
+	case ResourceTypeIPAddress:
+		_, retErr = c.cs.Address.DisassociateIpAddress(c.cs.Address.NewDisassociateIpAddressParams(resource.ID))
+	case ResourceTypeLBRule:
+		_, retErr = c.cs.LoadBalancer.DeleteLoadBalancerRule(c.cs.LoadBalancer.NewDeleteLoadBalancerRuleParams(resource.ID))
+	case ResourceTypeAffinityGroup:
+		p := c.cs.AffinityGroup.NewDeleteAffinityGroupParams()
+		p.SetId(resource.ID)
+		setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+		_, retErr = c.cs.AffinityGroup.DeleteAffinityGroup(p)
+	default:
+		return errors.Errorf("deleting %s resources isn't supported", resource.Type)
+	}
+	if retErr != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(retErr)
+		return errors.Wrapf(retErr, "deleting %s with ID %s", resource.Type, resource.ID)
+	}
+	return nil
+}
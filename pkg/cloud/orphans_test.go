@@ -0,0 +1,278 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+)
+
+var _ = Describe("Orphans", func() {
+	const (
+		liveUID = "11111111-2222-3333-4444-555555555555"
+		deadUID = "66666666-7777-8888-9999-000000000000"
+	)
+
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		rs         *csapi.MockResourcetagsServiceIface
+		lbs        *csapi.MockLoadBalancerServiceIface
+		ags        *csapi.MockAffinityGroupServiceIface
+		as         *csapi.MockAddressServiceIface
+		client     cloud.Client
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+		lbs = mockClient.LoadBalancer.(*csapi.MockLoadBalancerServiceIface)
+		ags = mockClient.AffinityGroup.(*csapi.MockAffinityGroupServiceIface)
+		as = mockClient.Address.(*csapi.MockAddressServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		cloud.ManagementClusterID = "management-cluster"
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+		cloud.ManagementClusterID = ""
+	})
+
+	Context("when listing resources CAPC created", func() {
+		It("lists tagged resources created by CAPC, their load balancer rules and CAPC's affinity groups", func() {
+			rs.EXPECT().NewListTagsParams().DoAndReturn(func() *csapi.ListTagsParams {
+				return &csapi.ListTagsParams{}
+			}).Times(4)
+			tagsByType := map[string][]*csapi.Tag{
+				string(cloud.ResourceTypeVM): {
+					{Resourceid: "VM", Key: cloud.CreatedByCAPCTagName},
+					{Resourceid: "VM", Key: cloud.ClusterTagNamePrefix + deadUID},
+				},
+				string(cloud.ResourceTypeVolume): {},
+				string(cloud.ResourceTypeNetwork): {
+					// Tagged as used by a cluster, but not created by CAPC.
+					{Resourceid: "SharedNetwork", Key: cloud.ClusterTagNamePrefix + deadUID},
+				},
+				string(cloud.ResourceTypeIPAddress): {
+					{Resourceid: "IP", Key: cloud.CreatedByCAPCTagName},
+					{Resourceid: "IP", Key: cloud.ClusterTagNamePrefix + liveUID},
+					{Resourceid: "RetainedIP", Key: cloud.CreatedByCAPCTagName},
+					{Resourceid: "RetainedIP", Key: cloud.RetainedTagNamePrefix + "default_cluster"},
+				},
+			}
+			rs.EXPECT().ListTags(gomock.Any()).DoAndReturn(func(p *csapi.ListTagsParams) (*csapi.ListTagsResponse, error) {
+				rType, _ := p.GetResourcetype()
+				return &csapi.ListTagsResponse{Tags: tagsByType[rType]}, nil
+			}).Times(4)
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(&csapi.ListLoadBalancerRulesResponse{
+				LoadBalancerRules: []*csapi.LoadBalancerRule{
+					{Id: "LBRule", Name: "Kubernetes_API_Server", Publicipid: "IP"},
+					{Id: "OtherLBRule", Name: "other", Publicipid: "OtherIP"},
+				}}, nil)
+			ags.EXPECT().NewListAffinityGroupsParams().Return(&csapi.ListAffinityGroupsParams{})
+			ags.EXPECT().ListAffinityGroups(gomock.Any()).Return(&csapi.ListAffinityGroupsResponse{
+				AffinityGroups: []*csapi.AffinityGroup{
+					{Id: "AG", Name: "my-cluster-" + deadUID + "-AntiAffinity-KubeadmControlPlane-fd1"},
+					{Id: "OtherAG", Name: "manually-created"},
+				}}, nil)
+
+			resources, err := client.ListCAPCResources()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resources).Should(ConsistOf(
+				cloud.CAPCResource{Type: cloud.ResourceTypeVM, ID: "VM", ClusterUIDs: []string{deadUID}},
+				cloud.CAPCResource{Type: cloud.ResourceTypeIPAddress, ID: "IP", ClusterUIDs: []string{liveUID}},
+				cloud.CAPCResource{Type: cloud.ResourceTypeIPAddress, ID: "RetainedIP", ClusterUIDs: []string{}, Retained: true},
+				cloud.CAPCResource{
+					Type: cloud.ResourceTypeLBRule, ID: "LBRule", Name: "Kubernetes_API_Server", ClusterUIDs: []string{liveUID}},
+				cloud.CAPCResource{
+					Type: cloud.ResourceTypeAffinityGroup, ID: "AG",
+					Name: "my-cluster-" + deadUID + "-AntiAffinity-KubeadmControlPlane-fd1", ClusterUIDs: []string{deadUID},
+					ClusterName: "my-cluster"},
+			))
+
+			live := cloud.NewLiveClusters()
+			live.Add(liveUID, "default", "live-cluster")
+			orphans := []string{}
+			for _, resource := range resources {
+				if resource.Orphaned(live) {
+					orphans = append(orphans, resource.ID)
+				}
+			}
+			Ω(orphans).Should(ConsistOf("VM", "AG"))
+		})
+
+		It("matches the resources of a moved cluster by namespace and name", func() {
+			const movedUID = "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+			rs.EXPECT().NewListTagsParams().DoAndReturn(func() *csapi.ListTagsParams {
+				return &csapi.ListTagsParams{}
+			}).Times(4)
+			tagsByType := map[string][]*csapi.Tag{
+				string(cloud.ResourceTypeVM): {
+					{Resourceid: "MovedVM", Key: cloud.CreatedByCAPCTagName},
+					{Resourceid: "MovedVM", Key: cloud.ClusterTagNamePrefix + deadUID},
+					{Resourceid: "MovedVM", Key: cloud.ClusterNamespaceTagName, Value: "default"},
+					{Resourceid: "MovedVM", Key: cloud.ClusterNameTagName, Value: "my-cluster"},
+					{Resourceid: "OtherNamespaceVM", Key: cloud.CreatedByCAPCTagName},
+					{Resourceid: "OtherNamespaceVM", Key: cloud.ClusterTagNamePrefix + deadUID},
+					{Resourceid: "OtherNamespaceVM", Key: cloud.ClusterNamespaceTagName, Value: "other"},
+					{Resourceid: "OtherNamespaceVM", Key: cloud.ClusterNameTagName, Value: "my-cluster"},
+				},
+				string(cloud.ResourceTypeVolume): {
+					// Created by CAPC, but not tagged as used by any cluster.
+					{Resourceid: "UntaggedVolume", Key: cloud.CreatedByCAPCTagName},
+				},
+			}
+			rs.EXPECT().ListTags(gomock.Any()).DoAndReturn(func(p *csapi.ListTagsParams) (*csapi.ListTagsResponse, error) {
+				rType, _ := p.GetResourcetype()
+				return &csapi.ListTagsResponse{Tags: tagsByType[rType]}, nil
+			}).Times(4)
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(&csapi.ListLoadBalancerRulesResponse{}, nil)
+			ags.EXPECT().NewListAffinityGroupsParams().Return(&csapi.ListAffinityGroupsParams{})
+			ags.EXPECT().ListAffinityGroups(gomock.Any()).Return(&csapi.ListAffinityGroupsResponse{
+				AffinityGroups: []*csapi.AffinityGroup{
+					{Id: "MovedAG", Name: "my-cluster-" + deadUID + "-AntiAffinity-KubeadmControlPlane-fd1"},
+					{Id: "OtherAG", Name: "other-cluster-" + deadUID + "-AntiAffinity-KubeadmControlPlane-fd1"},
+				}}, nil)
+
+			resources, err := client.ListCAPCResources()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resources).Should(ContainElement(cloud.CAPCResource{
+				Type: cloud.ResourceTypeVM, ID: "MovedVM", ClusterUIDs: []string{deadUID},
+				ClusterNamespace: "default", ClusterName: "my-cluster"}))
+			Ω(resources).Should(ContainElement(cloud.CAPCResource{
+				Type: cloud.ResourceTypeAffinityGroup, ID: "MovedAG",
+				Name:        "my-cluster-" + deadUID + "-AntiAffinity-KubeadmControlPlane-fd1",
+				ClusterUIDs: []string{deadUID}, ClusterName: "my-cluster"}))
+
+			// clusterctl move recreated the cluster with a new UID.
+			live := cloud.NewLiveClusters()
+			live.Add(movedUID, "default", "my-cluster")
+			orphans := []string{}
+			for _, resource := range resources {
+				if resource.Orphaned(live) {
+					orphans = append(orphans, resource.ID)
+				}
+			}
+			Ω(orphans).Should(ConsistOf("OtherNamespaceVM", "OtherAG"))
+		})
+
+		It("only orphans the resources of its management cluster, and only deletes those recording it", func() {
+			rs.EXPECT().NewListTagsParams().DoAndReturn(func() *csapi.ListTagsParams {
+				return &csapi.ListTagsParams{}
+			}).Times(4)
+			tagsByType := map[string][]*csapi.Tag{
+				string(cloud.ResourceTypeVM): {
+					{Resourceid: "VM", Key: cloud.CreatedByCAPCTagName},
+					{Resourceid: "VM", Key: cloud.ClusterTagNamePrefix + deadUID},
+					{Resourceid: "VM", Key: cloud.ManagementClusterTagName, Value: "management-cluster"},
+					{Resourceid: "OtherVM", Key: cloud.CreatedByCAPCTagName},
+					{Resourceid: "OtherVM", Key: cloud.ClusterTagNamePrefix + deadUID},
+					{Resourceid: "OtherVM", Key: cloud.ManagementClusterTagName, Value: "other-management-cluster"},
+					// Created before CAPC recorded its management cluster.
+					{Resourceid: "OldVM", Key: cloud.CreatedByCAPCTagName},
+					{Resourceid: "OldVM", Key: cloud.ClusterTagNamePrefix + deadUID},
+				},
+			}
+			rs.EXPECT().ListTags(gomock.Any()).DoAndReturn(func(p *csapi.ListTagsParams) (*csapi.ListTagsResponse, error) {
+				rType, _ := p.GetResourcetype()
+				return &csapi.ListTagsResponse{Tags: tagsByType[rType]}, nil
+			}).Times(4)
+			lbs.EXPECT().NewListLoadBalancerRulesParams().Return(&csapi.ListLoadBalancerRulesParams{})
+			lbs.EXPECT().ListLoadBalancerRules(gomock.Any()).Return(&csapi.ListLoadBalancerRulesResponse{}, nil)
+			ags.EXPECT().NewListAffinityGroupsParams().Return(&csapi.ListAffinityGroupsParams{})
+			ags.EXPECT().ListAffinityGroups(gomock.Any()).Return(&csapi.ListAffinityGroupsResponse{
+				AffinityGroups: []*csapi.AffinityGroup{
+					{Id: "AG", Name: "my-cluster-" + deadUID + "-AntiAffinity-KubeadmControlPlane-fd1",
+						Description: cloud.AffinityGroupDescription("default", "my-cluster", deadUID)},
+					{Id: "OtherAG", Name: "other-cluster-" + deadUID + "-AntiAffinity-KubeadmControlPlane-fd1",
+						Description: "Created by CAPC for cluster default/other-cluster (" + deadUID +
+							") of management cluster other-management-cluster"},
+				}}, nil)
+
+			resources, err := client.ListCAPCResources()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resources).Should(ContainElement(cloud.CAPCResource{
+				Type: cloud.ResourceTypeVM, ID: "VM", ClusterUIDs: []string{deadUID},
+				ManagementCluster: "management-cluster"}))
+			Ω(resources).Should(ContainElement(cloud.CAPCResource{
+				Type: cloud.ResourceTypeAffinityGroup, ID: "OtherAG",
+				Name:        "other-cluster-" + deadUID + "-AntiAffinity-KubeadmControlPlane-fd1",
+				ClusterUIDs: []string{deadUID}, ClusterName: "other-cluster", ManagementCluster: "other-management-cluster"}))
+
+			orphans, deletable := []string{}, []string{}
+			for _, resource := range resources {
+				if resource.Orphaned(cloud.NewLiveClusters()) {
+					orphans = append(orphans, resource.ID)
+					if resource.Deletable() {
+						deletable = append(deletable, resource.ID)
+					}
+				}
+			}
+			Ω(orphans).Should(ConsistOf("VM", "OldVM", "AG"))
+			Ω(deletable).Should(ConsistOf("VM", "AG"))
+		})
+
+		It("fails when tags can't be listed", func() {
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(nil, errors.New("listing failed"))
+
+			_, err := client.ListCAPCResources()
+			Ω(err).Should(MatchError(ContainSubstring("listing failed")))
+		})
+	})
+
+	Context("when deleting a resource CAPC created", func() {
+		It("releases a public IP", func() {
+			p := &csapi.DisassociateIpAddressParams{}
+			as.EXPECT().NewDisassociateIpAddressParams("IP").Return(p)
+			as.EXPECT().DisassociateIpAddress(p).Return(&csapi.DisassociateIpAddressResponse{}, nil)
+
+			Ω(client.DeleteCAPCResource(cloud.CAPCResource{
+				Type: cloud.ResourceTypeIPAddress, ID: "IP", ManagementCluster: "management-cluster"})).Should(Succeed())
+		})
+
+		It("reports failures to delete", func() {
+			as.EXPECT().NewDisassociateIpAddressParams("IP").Return(&csapi.DisassociateIpAddressParams{})
+			as.EXPECT().DisassociateIpAddress(gomock.Any()).Return(nil, errors.New("in use"))
+
+			Ω(client.DeleteCAPCResource(cloud.CAPCResource{
+				Type: cloud.ResourceTypeIPAddress, ID: "IP", ManagementCluster: "management-cluster"})).
+				Should(MatchError(ContainSubstring("deleting PublicIpAddress with ID IP: in use")))
+		})
+
+		It("refuses resource types it doesn't know", func() {
+			Ω(client.DeleteCAPCResource(cloud.CAPCResource{
+				Type: cloud.ResourceTypeVPC, ID: "VPC", ManagementCluster: "management-cluster"})).
+				Should(MatchError(ContainSubstring("isn't supported")))
+		})
+
+		It("refuses resources it can't tell are its management cluster's", func() {
+			for _, managementCluster := range []string{"", "other-management-cluster"} {
+				Ω(client.DeleteCAPCResource(cloud.CAPCResource{
+					Type: cloud.ResourceTypeIPAddress, ID: "IP", ManagementCluster: managementCluster})).
+					Should(MatchError(ContainSubstring("isn't tagged as created by management cluster management-cluster")))
+			}
+		})
+	})
+})
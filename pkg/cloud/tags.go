@@ -17,12 +17,17 @@ limitations under the License.
 package cloud
 
 import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 type TagIface interface {
@@ -39,12 +44,43 @@ type TagIface interface {
 type ResourceType string
 
 const (
-	ClusterTagNamePrefix               = "CAPC_cluster_"
-	CreatedByCAPCTagName               = "created_by_CAPC"
-	ResourceTypeNetwork   ResourceType = "Network"
-	ResourceTypeIPAddress ResourceType = "PublicIpAddress"
+	ClusterTagNamePrefix                   = "CAPC_cluster_"
+	RetainedTagNamePrefix                  = ClusterTagNamePrefix + "retained_"
+	BastionTagNamePrefix                   = "CAPC_bastion_"
+	CreatedByCAPCTagName                   = "created_by_CAPC"
+	ResourceTypeNetwork       ResourceType = "Network"
+	ResourceTypeIPAddress     ResourceType = "PublicIpAddress"
+	ResourceTypeVPC           ResourceType = "Vpc"
+	ResourceTypeFirewallRule  ResourceType = "FirewallRule"
+	ResourceTypeVM            ResourceType = "UserVm"
+	ResourceTypeVolume        ResourceType = "Volume"
+	ResourceTypeLBRule        ResourceType = "LoadBalancer"
+	ResourceTypeAffinityGroup ResourceType = "AffinityGroup"
 )
 
+// Tags recording the management cluster, cluster and machine owning a resource CAPC created. They don't share the
+// cluster tag prefix, so they don't count as a cluster using the resource.
+const (
+	OwnerTagNamePrefix       = "CAPC_owner_"
+	ManagementClusterTagName = OwnerTagNamePrefix + "management_cluster"
+	ClusterNameTagName       = OwnerTagNamePrefix + "cluster_name"
+	ClusterNamespaceTagName  = OwnerTagNamePrefix + "cluster_namespace"
+	ClusterUIDTagName        = OwnerTagNamePrefix + "cluster_uid"
+	MachineNameTagName       = OwnerTagNamePrefix + "machine_name"
+	MachineRoleTagName       = OwnerTagNamePrefix + "machine_role"
+	// AdditionalTagsTagName lists the keys of the additional tags CAPC put on a resource, telling them from tags put
+	// there by others once they're removed from the spec.
+	AdditionalTagsTagName = "CAPC_additional_tags"
+
+	MachineRoleControlPlane = "control-plane"
+	MachineRoleWorker       = "worker"
+	MachineRoleBastion      = "bastion"
+)
+
+// ManagementClusterID identifies the management cluster CAPC runs in. It's recorded on the resources CAPC creates, so
+// management clusters sharing an account can tell their resources apart. Resources aren't tagged with it if it's empty.
+var ManagementClusterID string
+
 // ignoreAlreadyPresentErrors returns nil if the error is an already present tag error.
 func ignoreAlreadyPresentErrors(err error, rType ResourceType, rID string) error {
 	matchSubString := strings.ToLower("already on " + string(rType) + " with id " + rID)
@@ -86,10 +122,10 @@ func (c *client) DeleteClusterTag(rType ResourceType, rID string, csCluster *inf
 	return nil
 }
 
-// AddCreatedByCAPCTag adds the tag that indicates that the resource was created by CAPC.
-// This is useful when a resource is disassociated but not deleted.
+// AddCreatedByCAPCTag adds the tag that indicates that the resource was created by CAPC, along with the one recording
+// the management cluster. This is useful when a resource is disassociated but not deleted.
 func (c *client) AddCreatedByCAPCTag(rType ResourceType, rID string) error {
-	return c.AddTags(rType, rID, map[string]string{CreatedByCAPCTagName: "1"})
+	return c.AddTags(rType, rID, createdByCAPCTags())
 }
 
 // DeleteCreatedByCAPCTag deletes the tag that indicates that the resource was created by CAPC.
@@ -163,6 +199,163 @@ func (c *client) DeleteTags(resourceType ResourceType, resourceID string, tagsTo
 	return nil
 }
 
+// createdByCAPCTags returns the tags marking a resource as created by CAPC in this management cluster.
+func createdByCAPCTags() map[string]string {
+	tags := map[string]string{CreatedByCAPCTagName: "1"}
+	if ManagementClusterID != "" {
+		tags[ManagementClusterTagName] = ManagementClusterID
+	}
+	return tags
+}
+
 func generateClusterTagName(csCluster *infrav1.CloudStackCluster) string {
 	return ClusterTagNamePrefix + string(csCluster.UID)
 }
+
+// generateRetainedTagName names the tag keeping a resource for clusters of the csCluster's namespace and name. It shares
+// the cluster tag prefix, so a retained resource counts as in use.
+func generateRetainedTagName(csCluster *infrav1.CloudStackCluster) string {
+	return RetainedTagNamePrefix + csCluster.Namespace + "_" + csCluster.Name
+}
+
+// generateBastionTagName names the tag marking the public IP of the csCluster's bastion.
+func generateBastionTagName(csCluster *infrav1.CloudStackCluster) string {
+	return BastionTagNamePrefix + string(csCluster.UID)
+}
+
+// clusterResourceTags returns the tags of a resource CAPC creates for the cluster: those marking it as created by CAPC
+// in this management cluster and used by the cluster, those recording the cluster owning it, and the additional tags
+// of the cluster and any further additional tags, which override the cluster's.
+func clusterResourceTags(csCluster *infrav1.CloudStackCluster, additionalTags ...map[string]string) map[string]string {
+	tags := map[string]string{}
+	for _, additional := range append([]map[string]string{csCluster.Spec.AdditionalTags}, additionalTags...) {
+		for key, value := range additional {
+			tags[key] = value
+		}
+	}
+	if len(tags) > 0 {
+		keys := make([]string, 0, len(tags))
+		for key := range tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		tags[AdditionalTagsTagName] = strings.Join(keys, ",")
+	}
+
+	clusterName := csCluster.Labels[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		clusterName = csCluster.Name
+	}
+	for key, value := range createdByCAPCTags() {
+		tags[key] = value
+	}
+	tags[generateClusterTagName(csCluster)] = "1"
+	tags[ClusterNameTagName] = clusterName
+	tags[ClusterNamespaceTagName] = csCluster.Namespace
+	tags[ClusterUIDTagName] = string(csCluster.UID)
+	return tags
+}
+
+// machineResourceTags returns the tags of a VM or volume CAPC creates for the machine: the cluster's, those recording
+// the machine and its role, and the machine's additional tags.
+func machineResourceTags(
+	csCluster *infrav1.CloudStackCluster, csMachine *infrav1.CloudStackMachine, role string,
+) map[string]string {
+	tags := clusterResourceTags(csCluster, csMachine.Spec.AdditionalTags)
+	tags[MachineNameTagName] = csMachine.Name
+	tags[MachineRoleTagName] = role
+	return tags
+}
+
+// machineRole returns the role recorded in the tags of a machine's VM and volumes.
+func machineRole(csMachine *infrav1.CloudStackMachine) string {
+	if _, isControlPlane := csMachine.Labels[clusterv1.MachineControlPlaneLabel]; isControlPlane {
+		return MachineRoleControlPlane
+	}
+	return MachineRoleWorker
+}
+
+// tagsHash hashes the desired tags of resources along with their IDs, telling whether their tags were already brought
+// in line with them.
+func tagsHash(tags map[string]string, ids ...string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := fnv.New64a()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%q=%q\n", key, tags[key])
+	}
+	for _, id := range ids {
+		fmt.Fprintf(h, "%q\n", id)
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// reconcileResourceTags brings the tags of a resource CAPC created for the cluster in line with the desired ones.
+// Resources owned by another cluster are left alone, as are those without the created_by_CAPC tag if it's required.
+// Additional tags CAPC put on the resource are removed once no longer desired, other tags are kept. Resources owned by
+// a cluster of the same namespace and name but another UID were created before clusterctl move recreated the cluster,
+// and their tags naming the old UID are replaced.
+func (c *client) reconcileResourceTags(
+	rType ResourceType, rID string, csCluster *infrav1.CloudStackCluster, desired map[string]string,
+	requireCreatedByCAPC bool,
+) error {
+	current, err := c.GetTags(rType, rID)
+	if err != nil {
+		return errors.Wrapf(err, "getting tags of %s with ID %s", rType, rID)
+	}
+	oldOwner := current[ClusterUIDTagName]
+	if oldOwner == string(csCluster.UID) {
+		oldOwner = ""
+	}
+	if oldOwner != "" && (current[ClusterNamespaceTagName] != desired[ClusterNamespaceTagName] ||
+		current[ClusterNameTagName] != desired[ClusterNameTagName]) {
+		return nil
+	} else if requireCreatedByCAPC && current[CreatedByCAPCTagName] == "" {
+		return nil
+	}
+
+	toDelete := map[string]string{}
+	for _, key := range strings.Split(current[AdditionalTagsTagName], ",") {
+		if _, stillDesired := desired[key]; !stillDesired && key != "" && current[key] != "" {
+			toDelete[key] = current[key]
+		}
+	}
+	if _, stillDesired := desired[AdditionalTagsTagName]; !stillDesired && current[AdditionalTagsTagName] != "" {
+		toDelete[AdditionalTagsTagName] = current[AdditionalTagsTagName]
+	}
+	toAdd := map[string]string{}
+	for key, value := range desired {
+		if currentValue, found := current[key]; !found {
+			toAdd[key] = value
+		} else if currentValue != value {
+			// Tags can't be updated in place.
+			toDelete[key] = currentValue
+			toAdd[key] = value
+		}
+	}
+	if oldOwner != "" {
+		for _, prefix := range []string{ClusterTagNamePrefix, BastionTagNamePrefix} {
+			if value, found := current[prefix+oldOwner]; found {
+				toDelete[prefix+oldOwner] = value
+				if _, found := current[prefix+string(csCluster.UID)]; !found {
+					toAdd[prefix+string(csCluster.UID)] = value
+				}
+			}
+		}
+	}
+
+	if len(toDelete) > 0 {
+		if err := c.DeleteTags(rType, rID, toDelete); err != nil {
+			return errors.Wrapf(err, "removing tags from %s with ID %s", rType, rID)
+		}
+	}
+	if len(toAdd) > 0 {
+		if err := c.AddTags(rType, rID, toAdd); err != nil {
+			return errors.Wrapf(err, "tagging %s with ID %s", rType, rID)
+		}
+	}
+	return nil
+}
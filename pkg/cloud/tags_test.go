@@ -164,6 +164,22 @@ var _ = Describe("Tag Unit Tests", func() {
 		})
 	})
 
+	Context("Created by CAPC tag", func() {
+		AfterEach(func() {
+			cloud.ManagementClusterID = ""
+		})
+
+		It("records the management cluster along with the created by CAPC tag", func() {
+			cloud.ManagementClusterID = "management-cluster"
+			ctp := &csapi.CreateTagsParams{}
+			rs.EXPECT().NewCreateTagsParams([]string{dummies.CSISONet1.Spec.ID}, string(cloud.ResourceTypeNetwork),
+				map[string]string{cloud.CreatedByCAPCTagName: "1", cloud.ManagementClusterTagName: "management-cluster"}).
+				Return(ctp)
+			rs.EXPECT().CreateTags(ctp).Return(&csapi.CreateTagsResponse{}, nil)
+			Ω(client.AddCreatedByCAPCTag(cloud.ResourceTypeNetwork, dummies.CSISONet1.Spec.ID)).Should(Succeed())
+		})
+	})
+
 	Context("Delete tags", func() {
 		It("delete resource tags fails", func() {
 			tags := map[string]string{
@@ -33,35 +33,39 @@ type UserCredIFace interface {
 	ResolveUser(*User) error
 	ResolveUserKeys(*User) error
 	GetUserWithKeys(*User) (bool, error)
+	GetUser() *User
 }
 
 // Domain contains specifications that identify a domain.
 type Domain struct {
-	Name            string
-	Path            string
-	ID              string
-	CPUAvailable    string
-	MemoryAvailable string
-	VMAvailable     string
+	Name                    string
+	Path                    string
+	ID                      string
+	CPUAvailable            string
+	MemoryAvailable         string
+	VMAvailable             string
+	PrimaryStorageAvailable string
 }
 
 // Account contains specifications that identify an account.
 type Account struct {
-	Name            string
-	Domain          Domain
-	ID              string
-	CPUAvailable    string
-	MemoryAvailable string
-	VMAvailable     string
+	Name                    string
+	Domain                  Domain
+	ID                      string
+	CPUAvailable            string
+	MemoryAvailable         string
+	VMAvailable             string
+	PrimaryStorageAvailable string
 }
 
 // Project contains specifications that identify a project.
 type Project struct {
-	Name            string
-	ID              string
-	CPUAvailable    string
-	MemoryAvailable string
-	VMAvailable     string
+	Name                    string
+	ID                      string
+	CPUAvailable            string
+	MemoryAvailable         string
+	VMAvailable             string
+	PrimaryStorageAvailable string
 }
 
 // User contains information uniquely identifying and scoping a user.
@@ -74,6 +78,11 @@ type User struct {
 	Project
 }
 
+// GetUser returns the user the client acts as.
+func (c *client) GetUser() *User {
+	return c.user
+}
+
 // ResolveDomain resolves a domain's information.
 func (c *client) ResolveDomain(domain *Domain) error {
 	// A domain can be specified by Id, Name, and or Path.
@@ -124,6 +133,7 @@ func (c *client) ResolveDomain(domain *Domain) error {
 		domain.CPUAvailable = resp.Domains[0].Cpuavailable
 		domain.MemoryAvailable = resp.Domains[0].Memoryavailable
 		domain.VMAvailable = resp.Domains[0].Vmavailable
+		domain.PrimaryStorageAvailable = resp.Domains[0].Primarystorageavailable
 		return nil
 	}
 
@@ -142,6 +152,7 @@ func (c *client) ResolveDomain(domain *Domain) error {
 			domain.CPUAvailable = possibleDomain.Cpuavailable
 			domain.MemoryAvailable = possibleDomain.Memoryavailable
 			domain.VMAvailable = possibleDomain.Vmavailable
+			domain.PrimaryStorageAvailable = possibleDomain.Primarystorageavailable
 			return nil
 		}
 	}
@@ -175,6 +186,7 @@ func (c *client) ResolveAccount(account *Account) error {
 	account.CPUAvailable = resp.Accounts[0].Cpuavailable
 	account.MemoryAvailable = resp.Accounts[0].Memoryavailable
 	account.VMAvailable = resp.Accounts[0].Vmavailable
+	account.PrimaryStorageAvailable = resp.Accounts[0].Primarystorageavailable
 	return nil
 }
 
@@ -205,6 +217,7 @@ func (c *client) ResolveProject(user *User) error {
 	user.Project.CPUAvailable = resp.Projects[0].Cpuavailable
 	user.Project.MemoryAvailable = resp.Projects[0].Memoryavailable
 	user.Project.VMAvailable = resp.Projects[0].Vmavailable
+	user.Project.PrimaryStorageAvailable = resp.Projects[0].Primarystorageavailable
 	return nil
 }
 
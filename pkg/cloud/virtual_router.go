@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+type VirtualRouterIface interface {
+	ResolveVirtualRouters(*infrav1.CloudStackIsolatedNetwork) error
+	RestartIsolatedNetwork(*infrav1.CloudStackIsolatedNetwork) error
+}
+
+const (
+	VirtualRouterStateRunning          = "Running"
+	VirtualRouterRedundantStatePrimary = "PRIMARY"
+)
+
+// ResolveVirtualRouters lists the virtual routers of the isolated network, or of its VPC, into the network's status and
+// records their state in the acs_virtual_router_up metric. Listing routers requires a domain admin or admin account.
+func (c *client) ResolveVirtualRouters(isoNet *infrav1.CloudStackIsolatedNetwork) error {
+	p := c.cs.Router.NewListRoutersParams()
+	if isoNet.Status.VPCID != "" {
+		p.SetVpcid(isoNet.Status.VPCID)
+	} else {
+		p.SetNetworkid(isoNet.Spec.ID)
+	}
+	p.SetListall(true)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Router.ListRouters(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing virtual routers of network with ID %s", isoNet.Spec.ID)
+	}
+
+	c.customMetrics.ResetVirtualRouters(isoNet.Spec.ID)
+	isoNet.Status.VirtualRouters = []infrav1.VirtualRouterStatus{}
+	for _, router := range resp.Routers {
+		isoNet.Status.VirtualRouters = append(isoNet.Status.VirtualRouters, infrav1.VirtualRouterStatus{
+			ID:                 router.Id,
+			Name:               router.Name,
+			State:              router.State,
+			Redundant:          router.Isredundantrouter,
+			RedundantState:     router.Redundantstate,
+			HealthChecksFailed: router.Healthchecksfailed,
+		})
+		c.customMetrics.SetVirtualRouterUp(
+			isoNet.Spec.ID, router.Name, router.Redundantstate, router.State == VirtualRouterStateRunning)
+	}
+	return nil
+}
+
+// RestartIsolatedNetwork restarts the isolated network, or its VPC, with cleanup, replacing its virtual routers.
+// The restart isn't waited for.
+func (c *client) RestartIsolatedNetwork(isoNet *infrav1.CloudStackIsolatedNetwork) error {
+	if isoNet.Status.VPCID != "" {
+		p := c.cs.VPC.NewRestartVPCParams(isoNet.Status.VPCID)
+		p.SetCleanup(true)
+		if _, err := c.cs.VPC.RestartVPC(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "restarting VPC with ID %s", isoNet.Status.VPCID)
+		}
+	} else {
+		p := c.cs.Network.NewRestartNetworkParams(isoNet.Spec.ID)
+		p.SetCleanup(true)
+		if _, err := c.cs.Network.RestartNetwork(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "restarting network with ID %s", isoNet.Spec.ID)
+		}
+	}
+	c.customMetrics.IncrementNetworkRestartCounter(isoNet.Spec.ID)
+	return nil
+}
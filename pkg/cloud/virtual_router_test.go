@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("Virtual Router", func() {
+	var ( // Declare shared vars.
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		rs         *csapi.MockRouterServiceIface
+		ns         *csapi.MockNetworkServiceIface
+		client     cloud.Client
+	)
+
+	BeforeEach(func() {
+		// Setup new mock services.
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		rs = mockClient.Router.(*csapi.MockRouterServiceIface)
+		ns = mockClient.Network.(*csapi.MockNetworkServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("lists the network's virtual routers into its status", func() {
+		rs.EXPECT().NewListRoutersParams().Return(&csapi.ListRoutersParams{})
+		rs.EXPECT().ListRouters(gomock.Any()).
+			DoAndReturn(func(p *csapi.ListRoutersParams) (*csapi.ListRoutersResponse, error) {
+				networkID, _ := p.GetNetworkid()
+				Ω(networkID).Should(Equal(dummies.CSISONet1.Spec.ID))
+				listAll, _ := p.GetListall()
+				Ω(listAll).Should(BeTrue())
+				return &csapi.ListRoutersResponse{Count: 2, Routers: []*csapi.Router{
+					{Id: "r1", Name: "r-1-VM", State: "Running", Isredundantrouter: true, Redundantstate: "PRIMARY"},
+					{Id: "r2", Name: "r-2-VM", State: "Stopped", Isredundantrouter: true, Redundantstate: "UNKNOWN",
+						Healthchecksfailed: true},
+				}}, nil
+			})
+
+		Ω(client.ResolveVirtualRouters(dummies.CSISONet1)).Should(Succeed())
+		Ω(dummies.CSISONet1.Status.VirtualRouters).Should(Equal([]infrav1.VirtualRouterStatus{
+			{ID: "r1", Name: "r-1-VM", State: "Running", Redundant: true, RedundantState: "PRIMARY"},
+			{ID: "r2", Name: "r-2-VM", State: "Stopped", Redundant: true, RedundantState: "UNKNOWN", HealthChecksFailed: true},
+		}))
+	})
+
+	It("lists the virtual routers of a project's network", func() {
+		client = cloud.NewClientFromCSAPIClient(mockClient, &cloud.User{Project: cloud.Project{ID: "ProjectID"}})
+		rs.EXPECT().NewListRoutersParams().Return(&csapi.ListRoutersParams{})
+		rs.EXPECT().ListRouters(gomock.Any()).
+			DoAndReturn(func(p *csapi.ListRoutersParams) (*csapi.ListRoutersResponse, error) {
+				projectID, _ := p.GetProjectid()
+				Ω(projectID).Should(Equal("ProjectID"))
+				return &csapi.ListRoutersResponse{}, nil
+			})
+
+		Ω(client.ResolveVirtualRouters(dummies.CSISONet1)).Should(Succeed())
+		Ω(dummies.CSISONet1.Status.VirtualRouters).Should(BeEmpty())
+	})
+
+	It("restarts the network with cleanup", func() {
+		ns.EXPECT().NewRestartNetworkParams(dummies.CSISONet1.Spec.ID).Return(&csapi.RestartNetworkParams{})
+		ns.EXPECT().RestartNetwork(gomock.Any()).
+			DoAndReturn(func(p *csapi.RestartNetworkParams) (*csapi.RestartNetworkResponse, error) {
+				cleanup, _ := p.GetCleanup()
+				Ω(cleanup).Should(BeTrue())
+				return &csapi.RestartNetworkResponse{}, nil
+			})
+
+		Ω(client.RestartIsolatedNetwork(dummies.CSISONet1)).Should(Succeed())
+	})
+})
@@ -0,0 +1,417 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+type VPCIface interface {
+	GetOrCreateVPC(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster) error
+	ReconcileNetworkACLList(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackIsolatedNetwork) error
+	GetOrCreateVPCTier(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackIsolatedNetwork, *infrav1.VPCTier) error
+	GetOrCreateInternalLoadBalancer(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster) error
+	DisposeVPCResources(*infrav1.CloudStackFailureDomain, *infrav1.CloudStackIsolatedNetwork, *infrav1.CloudStackCluster) error
+}
+
+const (
+	VPCOffering               = "Default VPC offering"
+	VPCTierOffering           = "DefaultIsolatedNetworkOfferingForVpcNetworks"
+	VPCTierOfferingInternalLB = "DefaultIsolatedNetworkOfferingForVpcNetworksWithInternalLB"
+	DefaultAllowACLList       = "default_allow"
+	LBSchemeInternal          = "Internal"
+	ACLActionAllow            = "Allow"
+	ACLTrafficTypeIngress     = "Ingress"
+)
+
+// GetOrCreateVPC resolves the failure domain's VPC by ID or name, creating it when it doesn't exist.
+func (c *client) GetOrCreateVPC(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) error {
+	vpc := fd.Spec.Zone.Network.VPC
+	vpcID := vpc.ID
+	if vpcID == "" {
+		id, count, err := c.cs.VPC.GetVPCID(vpc.Name, cloudstack.WithProject(c.user.Project.ID))
+		if err != nil && !strings.Contains(strings.ToLower(err.Error()), "no match found") {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "resolving VPC with name %s", vpc.Name)
+		} else if count > 1 {
+			return errors.Errorf("expected 1 VPC with name %s, but got %d", vpc.Name, count)
+		}
+		vpcID = id
+	} else if _, count, err := c.cs.VPC.GetVPCByID(vpcID, cloudstack.WithProject(c.user.Project.ID)); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "resolving VPC with ID %s", vpcID)
+	} else if count != 1 {
+		return errors.Errorf("expected 1 VPC with ID %s, but got %d", vpcID, count)
+	}
+
+	if vpcID == "" { // Doesn't exist, create the VPC.
+		if vpc.CIDR == "" {
+			return errors.Errorf("VPC %s not found and no CIDR specified to create it", vpc.Name)
+		}
+		offering := VPCOffering
+		if vpc.Offering != "" {
+			offering = vpc.Offering
+		}
+		offeringID, count, err := c.cs.VPC.GetVPCOfferingID(offering)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "resolving VPC offering %s", offering)
+		} else if count != 1 {
+			return errors.Errorf("expected 1 VPC offering with name %s, but got %d", offering, count)
+		}
+
+		p := c.cs.VPC.NewCreateVPCParams(vpc.CIDR, vpc.Name, vpc.Name, offeringID, fd.Spec.Zone.ID)
+		setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+		resp, err := c.cs.VPC.CreateVPC(p)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "creating VPC with name %s", vpc.Name)
+		}
+		vpcID = resp.Id
+		if err := c.AddCreatedByCAPCTag(ResourceTypeVPC, vpcID); err != nil {
+			return errors.Wrapf(err, "tagging VPC with ID %s", vpcID)
+		}
+	}
+	isoNet.Status.VPCID = vpcID
+
+	return errors.Wrapf(c.AddClusterTag(ResourceTypeVPC, vpcID, csCluster), "tagging VPC with ID %s", vpcID)
+}
+
+// ReconcileNetworkACLList makes sure the VPC tiers' ACL list holds the failure domain's ACL rules. Without rules,
+// the tiers use the VPC's default_allow list, otherwise CAPC manages a list of its own.
+func (c *client) ReconcileNetworkACLList(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+) error {
+	rules := fd.Spec.Zone.Network.VPC.ACLRules
+	name := DefaultAllowACLList
+	if len(rules) > 0 {
+		name = managedNetworkACLListName(isoNet)
+	}
+
+	aclListID, count, err := c.cs.NetworkACL.GetNetworkACLListID(name, cloudstack.WithVPCID(isoNet.Status.VPCID))
+	if err != nil && !strings.Contains(strings.ToLower(err.Error()), "no match found") {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "resolving network ACL list %s", name)
+	} else if count > 1 {
+		return errors.Errorf("expected 1 network ACL list with name %s, but got %d", name, count)
+	} else if count == 0 {
+		if len(rules) == 0 {
+			return errors.Errorf("network ACL list %s not found", name)
+		}
+		p := c.cs.NetworkACL.NewCreateNetworkACLListParams(name, isoNet.Status.VPCID)
+		p.SetDescription(name)
+		resp, err := c.cs.NetworkACL.CreateNetworkACLList(p)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "creating network ACL list %s", name)
+		}
+		aclListID = resp.Id
+	}
+	isoNet.Status.ACLListID = aclListID
+
+	if len(rules) == 0 {
+		return nil
+	}
+	return c.syncNetworkACLRules(aclListID, rules)
+}
+
+// managedNetworkACLListName returns the name of the ACL list CAPC manages for the VPC tiers of an isolated network.
+func managedNetworkACLListName(isoNet *infrav1.CloudStackIsolatedNetwork) string {
+	return isoNet.Name + "-acl"
+}
+
+// replaceNetworkACLList attaches the ACL list to a VPC tier.
+func (c *client) replaceNetworkACLList(tierID, aclListID string) error {
+	p := c.cs.NetworkACL.NewReplaceNetworkACLListParams(aclListID)
+	p.SetNetworkid(tierID)
+	if _, err := c.cs.NetworkACL.ReplaceNetworkACLList(p); err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "attaching network ACL list %s to VPC tier with ID %s", aclListID, tierID)
+	}
+	return nil
+}
+
+// syncNetworkACLRules deletes the rules of an ACL list that don't match the desired rule with the same number, and
+// creates the desired rules that are missing.
+func (c *client) syncNetworkACLRules(aclListID string, rules []infrav1.NetworkACLRule) error {
+	desired := map[int]infrav1.NetworkACLRule{}
+	for _, rule := range rules {
+		desired[rule.Number] = rule
+	}
+
+	p := c.cs.NetworkACL.NewListNetworkACLsParams()
+	p.SetAclid(aclListID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.NetworkACL.ListNetworkACLs(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing rules of network ACL list %s", aclListID)
+	}
+	for _, existing := range resp.NetworkACLs {
+		if rule, found := desired[existing.Number]; found && networkACLRuleMatches(rule, existing) {
+			delete(desired, existing.Number)
+			continue
+		}
+		if _, err := c.cs.NetworkACL.DeleteNetworkACL(c.cs.NetworkACL.NewDeleteNetworkACLParams(existing.Id)); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "deleting network ACL rule %d with ID %s", existing.Number, existing.Id)
+		}
+	}
+
+	for _, rule := range rules {
+		if _, missing := desired[rule.Number]; !missing {
+			continue
+		}
+		p := c.cs.NetworkACL.NewCreateNetworkACLParams(rule.Protocol)
+		p.SetAclid(aclListID)
+		p.SetNumber(rule.Number)
+		p.SetAction(networkACLRuleAction(rule))
+		p.SetTraffictype(networkACLRuleTrafficType(rule))
+		p.SetCidrlist(networkACLRuleCIDRList(rule))
+		switch rule.Protocol {
+		case NetworkProtocolTCP, NetworkProtocolUDP:
+			p.SetStartport(rule.StartPort)
+			p.SetEndport(networkACLRuleEndPort(rule))
+		case NetworkProtocolICMP:
+			p.SetIcmptype(-1)
+			p.SetIcmpcode(-1)
+		}
+		if _, err := c.cs.NetworkACL.CreateNetworkACL(p); err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "creating network ACL rule %d", rule.Number)
+		}
+	}
+	return nil
+}
+
+func networkACLRuleAction(rule infrav1.NetworkACLRule) string {
+	if rule.Action == "" {
+		return ACLActionAllow
+	}
+	return rule.Action
+}
+
+func networkACLRuleTrafficType(rule infrav1.NetworkACLRule) string {
+	if rule.TrafficType == "" {
+		return ACLTrafficTypeIngress
+	}
+	return rule.TrafficType
+}
+
+func networkACLRuleCIDRList(rule infrav1.NetworkACLRule) []string {
+	if len(rule.CIDRList) == 0 {
+		return []string{AnyCIDR}
+	}
+	return rule.CIDRList
+}
+
+func networkACLRuleEndPort(rule infrav1.NetworkACLRule) int {
+	if rule.EndPort == 0 {
+		return rule.StartPort
+	}
+	return rule.EndPort
+}
+
+// networkACLRuleMatches compares a desired ACL rule to one read back from CloudStack.
+func networkACLRuleMatches(rule infrav1.NetworkACLRule, existing *cloudstack.NetworkACL) bool {
+	if !strings.EqualFold(rule.Protocol, existing.Protocol) ||
+		!strings.EqualFold(networkACLRuleAction(rule), existing.Action) ||
+		!strings.EqualFold(networkACLRuleTrafficType(rule), existing.Traffictype) ||
+		strings.Join(networkACLRuleCIDRList(rule), ",") != strings.ReplaceAll(existing.Cidrlist, " ", "") {
+		return false
+	}
+	if rule.Protocol == NetworkProtocolTCP || rule.Protocol == NetworkProtocolUDP {
+		return strconv.Itoa(rule.StartPort) == existing.Startport &&
+			strconv.Itoa(networkACLRuleEndPort(rule)) == existing.Endport
+	}
+	return true
+}
+
+// GetOrCreateVPCTier resolves a tier of the VPC by name, creating it when it doesn't exist.
+func (c *client) GetOrCreateVPCTier(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	tier *infrav1.VPCTier,
+) error {
+	_, err := c.getOrCreateVPCTier(fd, isoNet, tier)
+	return err
+}
+
+// getOrCreateVPCTier gets or creates a tier of the VPC and returns its IPv6 CIDR, empty unless the tier is dual-stack.
+func (c *client) getOrCreateVPCTier(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	tier *infrav1.VPCTier,
+) (string, error) {
+	net := &infrav1.Network{ID: tier.ID, Name: tier.Name}
+	if netDetails, err := c.resolveNetworkDetails(fd.Spec.Zone.ID, isoNet.Status.VPCID, net); err == nil {
+		tier.ID = net.ID
+		// Tiers created before the ACL rules were set, or still holding CAPC's list after they were removed, are
+		// switched to the list in use. Lists users attached otherwise are left alone.
+		if isoNet.Status.ACLListID != "" && netDetails.Aclid != isoNet.Status.ACLListID &&
+			(len(fd.Spec.Zone.Network.VPC.ACLRules) > 0 || netDetails.Aclname == managedNetworkACLListName(isoNet)) {
+			if err := c.replaceNetworkACLList(tier.ID, isoNet.Status.ACLListID); err != nil {
+				return "", err
+			}
+		}
+		return netDetails.Ip6cidr, nil
+	}
+
+	if tier.Gateway == "" || tier.Netmask == "" {
+		return "", errors.Errorf("VPC tier %s not found and no gateway and netmask specified to create it", tier.Name)
+	}
+	offering := VPCTierOffering
+	if tier.Offering != "" {
+		offering = tier.Offering
+	} else if fd.Spec.Zone.Network.VPC.InternalLoadBalancer {
+		offering = VPCTierOfferingInternalLB
+	}
+	offeringID, err := c.getOfferingID(offering)
+	if err != nil {
+		return "", err
+	}
+
+	p := c.cs.Network.NewCreateNetworkParams(tier.Name, offeringID, fd.Spec.Zone.ID)
+	p.SetDisplaytext(tier.Name)
+	p.SetVpcid(isoNet.Status.VPCID)
+	p.SetGateway(tier.Gateway)
+	p.SetNetmask(tier.Netmask)
+	setIfNotEmpty(isoNet.Status.ACLListID, p.SetAclid)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.Network.CreateNetwork(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return "", errors.Wrapf(err, "creating VPC tier with name %s", tier.Name)
+	}
+	tier.ID = resp.Id
+	return resp.Ip6cidr, c.AddCreatedByCAPCTag(ResourceTypeNetwork, tier.ID)
+}
+
+// GetOrCreateInternalLoadBalancer exposes the control plane endpoint with a VPC internal load balancer on the
+// control plane tier.
+func (c *client) GetOrCreateInternalLoadBalancer(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) error {
+	setControlPlaneEndpointPort(isoNet, csCluster)
+
+	p := c.cs.LoadBalancer.NewListLoadBalancersParams()
+	p.SetName(APIServerLBRuleName)
+	p.SetNetworkid(isoNet.Spec.ID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	resp, err := c.cs.LoadBalancer.ListLoadBalancers(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrap(err, "listing internal load balancers")
+	}
+
+	var lbID, sourceIP string
+	if resp.Count > 0 {
+		lbID, sourceIP = resp.LoadBalancers[0].Id, resp.LoadBalancers[0].Sourceipaddress
+	} else {
+		cp := c.cs.LoadBalancer.NewCreateLoadBalancerParams(lbAlgorithm(csCluster), lbBackendPort(csCluster), APIServerLBRuleName,
+			isoNet.Spec.ID, LBSchemeInternal, isoNet.Spec.ID, int(csCluster.Spec.ControlPlaneEndpoint.Port))
+		setIfNotEmpty(csCluster.Spec.ControlPlaneEndpoint.Host, cp.SetSourceipaddress)
+		lb, err := c.cs.LoadBalancer.CreateLoadBalancer(cp)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrap(err, "creating internal load balancer")
+		}
+		lbID, sourceIP = lb.Id, lb.Sourceipaddress
+	}
+
+	isoNet.Status.LBRuleID = lbID
+	isoNet.Spec.ControlPlaneEndpoint.Host = sourceIP
+	if csCluster.ControlPlaneEndpointName() == "" {
+		csCluster.Spec.ControlPlaneEndpoint.Host = sourceIP
+	}
+	return nil
+}
+
+// DisposeVPCResources cleans up the worker tier, the ACL list and the VPC once no cluster uses them anymore.
+// The control plane tier is disposed of like any other isolated network.
+func (c *client) DisposeVPCResources(
+	fd *infrav1.CloudStackFailureDomain,
+	isoNet *infrav1.CloudStackIsolatedNetwork,
+	csCluster *infrav1.CloudStackCluster,
+) error {
+	if isoNet.Status.WorkerTierID != "" {
+		workerTier := infrav1.Network{ID: isoNet.Status.WorkerTierID}
+		if err := c.RemoveClusterTagFromNetwork(csCluster, workerTier); err != nil {
+			return err
+		}
+		if err := c.DeleteNetworkIfNotInUse(csCluster, workerTier); err != nil {
+			return err
+		}
+	}
+
+	if err := c.DeleteClusterTag(ResourceTypeVPC, isoNet.Status.VPCID, csCluster); err != nil {
+		return err
+	}
+	if tagsAllowDisposal, err := c.DoClusterTagsAllowDisposal(ResourceTypeVPC, isoNet.Status.VPCID); err != nil {
+		return err
+	} else if tagsAllowDisposal { // Deleting the VPC also deletes its ACL lists.
+		_, err := c.cs.VPC.DeleteVPC(c.cs.VPC.NewDeleteVPCParams(isoNet.Status.VPCID))
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "deleting VPC with ID %s", isoNet.Status.VPCID)
+	}
+
+	return c.DeleteNetworkACLListIfNotInUse(isoNet)
+}
+
+// DeleteNetworkACLListIfNotInUse deletes the ACL list CAPC created for the VPC tiers once no tier uses it anymore.
+func (c *client) DeleteNetworkACLListIfNotInUse(isoNet *infrav1.CloudStackIsolatedNetwork) error {
+	if isoNet.Status.ACLListID == "" {
+		return nil
+	}
+	aclList, count, err := c.cs.NetworkACL.GetNetworkACLListByID(isoNet.Status.ACLListID)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "resolving network ACL list with ID %s", isoNet.Status.ACLListID)
+	} else if count != 1 || aclList.Name != isoNet.Name+"-acl" { // Only delete the list CAPC created.
+		return nil
+	}
+
+	p := c.cs.Network.NewListNetworksParams()
+	p.SetVpcid(isoNet.Status.VPCID)
+	setIfNotEmpty(c.user.Project.ID, p.SetProjectid)
+	networks, err := c.cs.Network.ListNetworks(p)
+	if err != nil {
+		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+		return errors.Wrapf(err, "listing tiers of VPC with ID %s", isoNet.Status.VPCID)
+	}
+	for _, network := range networks.Networks {
+		if network.Aclid == isoNet.Status.ACLListID {
+			return nil
+		}
+	}
+
+	_, err = c.cs.NetworkACL.DeleteNetworkACLList(c.cs.NetworkACL.NewDeleteNetworkACLListParams(isoNet.Status.ACLListID))
+	c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+	return errors.Wrapf(err, "deleting network ACL list with ID %s", isoNet.Status.ACLListID)
+}
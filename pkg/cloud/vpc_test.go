@@ -0,0 +1,198 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud_test
+
+import (
+	"fmt"
+
+	csapi "github.com/apache/cloudstack-go/v2/cloudstack"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
+	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
+)
+
+var _ = Describe("VPC", func() {
+	const (
+		vpcID     = "FakeVPCID"
+		aclListID = "FakeACLListID"
+	)
+
+	var ( // Declare shared vars.
+		mockCtrl   *gomock.Controller
+		mockClient *csapi.CloudStackClient
+		vs         *csapi.MockVPCServiceIface
+		acls       *csapi.MockNetworkACLServiceIface
+		ns         *csapi.MockNetworkServiceIface
+		lbs        *csapi.MockLoadBalancerServiceIface
+		rs         *csapi.MockResourcetagsServiceIface
+		client     cloud.Client
+	)
+
+	BeforeEach(func() {
+		// Setup new mock services.
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = csapi.NewMockClient(mockCtrl)
+		vs = mockClient.VPC.(*csapi.MockVPCServiceIface)
+		acls = mockClient.NetworkACL.(*csapi.MockNetworkACLServiceIface)
+		ns = mockClient.Network.(*csapi.MockNetworkServiceIface)
+		lbs = mockClient.LoadBalancer.(*csapi.MockLoadBalancerServiceIface)
+		rs = mockClient.Resourcetags.(*csapi.MockResourcetagsServiceIface)
+		client = cloud.NewClientFromCSAPIClient(mockClient, nil)
+		dummies.SetDummyVars()
+		dummies.CSFailureDomain1.Spec.Zone.Network.VPC = &infrav1.VPC{Name: "vpc1", CIDR: "10.0.0.0/16"}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("Get or Create VPC in CloudStack", func() {
+		It("adopts an existing VPC found by name", func() {
+			vs.EXPECT().GetVPCID("vpc1", gomock.Any()).Return(vpcID, 1, nil)
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(&csapi.ListTagsResponse{}, nil)
+
+			Ω(client.GetOrCreateVPC(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.VPCID).Should(Equal(vpcID))
+		})
+
+		It("creates the VPC when not found", func() {
+			vs.EXPECT().GetVPCID("vpc1", gomock.Any()).Return("", 0, fmt.Errorf("No match found for vpc1"))
+			vs.EXPECT().GetVPCOfferingID(cloud.VPCOffering).Return("FakeVPCOfferingID", 1, nil)
+			vs.EXPECT().NewCreateVPCParams("10.0.0.0/16", "vpc1", "vpc1", "FakeVPCOfferingID", gomock.Any()).
+				Return(&csapi.CreateVPCParams{})
+			vs.EXPECT().CreateVPC(gomock.Any()).Return(&csapi.CreateVPCResponse{Id: vpcID}, nil)
+
+			// Will add the creation tag, then the cluster tag since the VPC is now CAPC managed.
+			rs.EXPECT().NewListTagsParams().Return(&csapi.ListTagsParams{})
+			rs.EXPECT().ListTags(gomock.Any()).Return(
+				&csapi.ListTagsResponse{Tags: []*csapi.Tag{{Key: cloud.CreatedByCAPCTagName, Value: "1"}}}, nil)
+			rs.EXPECT().NewCreateTagsParams([]string{vpcID}, string(cloud.ResourceTypeVPC), gomock.Any()).
+				Return(&csapi.CreateTagsParams{}).Times(2)
+			rs.EXPECT().CreateTags(gomock.Any()).Return(&csapi.CreateTagsResponse{}, nil).Times(2)
+
+			Ω(client.GetOrCreateVPC(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.VPCID).Should(Equal(vpcID))
+		})
+
+		It("fails to create the VPC without a CIDR", func() {
+			dummies.CSFailureDomain1.Spec.Zone.Network.VPC.CIDR = ""
+			vs.EXPECT().GetVPCID("vpc1", gomock.Any()).Return("", 0, fmt.Errorf("No match found for vpc1"))
+
+			Ω(client.GetOrCreateVPC(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).
+				Should(MatchError(ContainSubstring("no CIDR specified")))
+		})
+	})
+
+	Context("Reconcile VPC network ACL list", func() {
+		BeforeEach(func() {
+			dummies.CSISONet1.Status.VPCID = vpcID
+		})
+
+		It("uses the default_allow list without rules", func() {
+			acls.EXPECT().GetNetworkACLListID(cloud.DefaultAllowACLList, gomock.Any()).Return(aclListID, 1, nil)
+
+			Ω(client.ReconcileNetworkACLList(dummies.CSFailureDomain1, dummies.CSISONet1)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.ACLListID).Should(Equal(aclListID))
+		})
+
+		It("creates a list and replaces rules that differ", func() {
+			dummies.CSFailureDomain1.Spec.Zone.Network.VPC.ACLRules = []infrav1.NetworkACLRule{
+				{Number: 1, Protocol: "tcp", StartPort: 6443},
+				{Number: 2, Protocol: "icmp"},
+			}
+			acls.EXPECT().GetNetworkACLListID(dummies.CSISONet1.Name+"-acl", gomock.Any()).
+				Return("", 0, fmt.Errorf("No match found for %s-acl", dummies.CSISONet1.Name))
+			acls.EXPECT().NewCreateNetworkACLListParams(dummies.CSISONet1.Name+"-acl", vpcID).
+				Return(&csapi.CreateNetworkACLListParams{})
+			acls.EXPECT().CreateNetworkACLList(gomock.Any()).Return(&csapi.CreateNetworkACLListResponse{Id: aclListID}, nil)
+
+			acls.EXPECT().NewListNetworkACLsParams().Return(&csapi.ListNetworkACLsParams{})
+			acls.EXPECT().ListNetworkACLs(gomock.Any()).Return(&csapi.ListNetworkACLsResponse{
+				Count: 2,
+				NetworkACLs: []*csapi.NetworkACL{
+					{Id: "rule1", Number: 1, Protocol: "tcp", Startport: "6443", Endport: "6443",
+						Cidrlist: "0.0.0.0/0", Action: "Allow", Traffictype: "Ingress"},
+					{Id: "rule2", Number: 2, Protocol: "udp", Startport: "53", Endport: "53",
+						Cidrlist: "0.0.0.0/0", Action: "Allow", Traffictype: "Ingress"},
+				}}, nil)
+			acls.EXPECT().NewDeleteNetworkACLParams("rule2").Return(&csapi.DeleteNetworkACLParams{})
+			acls.EXPECT().DeleteNetworkACL(gomock.Any()).Return(&csapi.DeleteNetworkACLResponse{}, nil)
+			acls.EXPECT().NewCreateNetworkACLParams("icmp").Return(&csapi.CreateNetworkACLParams{})
+			acls.EXPECT().CreateNetworkACL(gomock.Any()).Return(&csapi.CreateNetworkACLResponse{}, nil)
+
+			Ω(client.ReconcileNetworkACLList(dummies.CSFailureDomain1, dummies.CSISONet1)).Should(Succeed())
+			Ω(dummies.CSISONet1.Status.ACLListID).Should(Equal(aclListID))
+		})
+	})
+
+	Context("Get or Create VPC tiers", func() {
+		It("attaches the managed ACL list to an existing tier still on another list", func() {
+			dummies.CSFailureDomain1.Spec.Zone.Network.VPC.ACLRules = []infrav1.NetworkACLRule{{Number: 1, Protocol: "tcp", StartPort: 6443}}
+			dummies.CSISONet1.Status.VPCID = vpcID
+			dummies.CSISONet1.Status.ACLListID = aclListID
+			tier := &infrav1.VPCTier{Name: "workers"}
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{Count: 1, Networks: []*csapi.Network{
+				{Id: "FakeTierID", Name: "workers", Aclid: "FakeDefaultAllowID", Aclname: cloud.DefaultAllowACLList}}}, nil)
+			replaceParams := &csapi.ReplaceNetworkACLListParams{}
+			acls.EXPECT().NewReplaceNetworkACLListParams(aclListID).Return(replaceParams)
+			acls.EXPECT().ReplaceNetworkACLList(replaceParams).Return(&csapi.ReplaceNetworkACLListResponse{}, nil)
+
+			Ω(client.GetOrCreateVPCTier(dummies.CSFailureDomain1, dummies.CSISONet1, tier)).Should(Succeed())
+			Ω(tier.ID).Should(Equal("FakeTierID"))
+		})
+
+		It("leaves the ACL list users attached to an existing tier without ACL rules", func() {
+			dummies.CSISONet1.Status.VPCID = vpcID
+			dummies.CSISONet1.Status.ACLListID = aclListID
+			tier := &infrav1.VPCTier{Name: "workers"}
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{Count: 1, Networks: []*csapi.Network{
+				{Id: "FakeTierID", Name: "workers", Aclid: "FakeUserACLID", Aclname: "user-acl"}}}, nil)
+
+			Ω(client.GetOrCreateVPCTier(dummies.CSFailureDomain1, dummies.CSISONet1, tier)).Should(Succeed())
+		})
+
+		It("fails to create a tier without gateway and netmask", func() {
+			tier := &infrav1.VPCTier{Name: "workers"}
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{}, nil)
+
+			Ω(client.GetOrCreateVPCTier(dummies.CSFailureDomain1, dummies.CSISONet1, tier)).
+				Should(MatchError(ContainSubstring("no gateway and netmask specified")))
+		})
+	})
+
+	Context("Get or Create an internal load balancer", func() {
+		It("uses the source IP of an existing internal load balancer as endpoint", func() {
+			dummies.CSCluster.Spec.ControlPlaneEndpoint.Host = ""
+			lbs.EXPECT().NewListLoadBalancersParams().Return(&csapi.ListLoadBalancersParams{})
+			lbs.EXPECT().ListLoadBalancers(gomock.Any()).Return(&csapi.ListLoadBalancersResponse{
+				Count:         1,
+				LoadBalancers: []*csapi.LoadBalancer{{Id: "FakeLBID", Sourceipaddress: "10.0.1.10"}}}, nil)
+
+			Ω(client.GetOrCreateInternalLoadBalancer(dummies.CSFailureDomain1, dummies.CSISONet1, dummies.CSCluster)).
+				Should(Succeed())
+			Ω(dummies.CSISONet1.Status.LBRuleID).Should(Equal("FakeLBID"))
+			Ω(dummies.CSCluster.Spec.ControlPlaneEndpoint.Host).Should(Equal("10.0.1.10"))
+		})
+	})
+})
@@ -17,6 +17,9 @@ limitations under the License.
 package cloud
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/apache/cloudstack-go/v2/cloudstack"
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
@@ -26,6 +29,8 @@ import (
 type ZoneIFace interface {
 	ResolveZone(*infrav1.CloudStackZoneSpec) error
 	ResolveNetworkForZone(*infrav1.CloudStackZoneSpec) error
+	ListZones(*infrav1.ZoneSelector) ([]*cloudstack.Zone, error)
+	ResolvePlacement(*infrav1.FailureDomainPlacement, string) error
 }
 
 func (c *client) ResolveZone(zSpec *infrav1.CloudStackZoneSpec) (retErr error) {
@@ -53,32 +58,152 @@ func (c *client) ResolveZone(zSpec *infrav1.CloudStackZoneSpec) (retErr error) {
 	return nil
 }
 
-// ResolveNetworkForZone fetches details on Zone's specified network.
-func (c *client) ResolveNetworkForZone(zSpec *infrav1.CloudStackZoneSpec) (retErr error) {
-	netName := zSpec.Network.Name
-	netDetails, count, err := c.cs.Network.GetNetworkByName(netName, cloudstack.WithProject(c.user.Project.ID))
+// ResolveNetworkForZone fetches details on Zone's specified network. The zone must be resolved first.
+func (c *client) ResolveNetworkForZone(zSpec *infrav1.CloudStackZoneSpec) error {
+	return c.ResolveNetwork(zSpec.ID, &zSpec.Network)
+}
+
+// ListZones lists the zones available to the account that the selector selects, or all of them given no selector.
+func (c *client) ListZones(selector *infrav1.ZoneSelector) ([]*cloudstack.Zone, error) {
+	var namePattern *regexp.Regexp
+	p := c.cs.Zone.NewListZonesParams()
+	p.SetAvailable(true)
+	if selector != nil {
+		if len(selector.Tags) > 0 {
+			p.SetTags(selector.Tags)
+		}
+		if selector.NamePattern != "" {
+			var err error
+			if namePattern, err = regexp.Compile(selector.NamePattern); err != nil {
+				return nil, errors.Wrapf(err, "parsing zone name pattern %s", selector.NamePattern)
+			}
+		}
+	}
+	resp, err := c.cs.Zone.ListZones(p)
 	if err != nil {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
-		retErr = multierror.Append(retErr, errors.Wrapf(err, "could not get Network ID from %v", netName))
-	} else if count != 1 {
-		retErr = multierror.Append(retErr, errors.Errorf(
-			"expected 1 Network with name %s, but got %d", netName, count))
-	} else { // Got netID from the network's name.
-		zSpec.Network.ID = netDetails.Id
-		zSpec.Network.Type = netDetails.Type
+		return nil, errors.Wrap(err, "listing zones")
+	}
+	zones := []*cloudstack.Zone{}
+	for _, zone := range resp.Zones {
+		if namePattern == nil || namePattern.MatchString(zone.Name) {
+			zones = append(zones, zone)
+		}
+	}
+	return zones, nil
+}
+
+// ResolvePlacement resolves the pod and cluster of a failure domain's placement in the zone, and verifies there are
+// hosts to deploy machines on. Pods, clusters and hosts can only be listed by a root admin.
+func (c *client) ResolvePlacement(placement *infrav1.FailureDomainPlacement, zoneID string) error {
+	if placement == nil {
 		return nil
 	}
+	if pod := placement.Pod; pod != nil {
+		if pod.ID == "" {
+			podID, count, err := c.cs.Pod.GetPodID(pod.Name, cloudstack.WithZone(zoneID))
+			if err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+				return errors.Wrapf(err, "could not get Pod ID from %s", pod.Name)
+			} else if count != 1 {
+				return errors.Errorf("expected 1 Pod with name %s, but got %d", pod.Name, count)
+			}
+			pod.ID = podID
+		}
+		resp, count, err := c.cs.Pod.GetPodByID(pod.ID)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "could not get Pod by ID %s", pod.ID)
+		} else if count != 1 {
+			return errors.Errorf("expected 1 Pod with UUID %s, but got %d", pod.ID, count)
+		} else if resp.Zoneid != zoneID {
+			return errors.Errorf("Pod %s isn't in zone %s", pod.ID, zoneID)
+		}
+		pod.Name = resp.Name
+	}
+	if cluster := placement.Cluster; cluster != nil {
+		if cluster.ID == "" {
+			clusterID, count, err := c.cs.Cluster.GetClusterID(cluster.Name, cloudstack.WithZone(zoneID))
+			if err != nil {
+				c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+				return errors.Wrapf(err, "could not get Cluster ID from %s", cluster.Name)
+			} else if count != 1 {
+				return errors.Errorf("expected 1 Cluster with name %s, but got %d", cluster.Name, count)
+			}
+			cluster.ID = clusterID
+		}
+		resp, count, err := c.cs.Cluster.GetClusterByID(cluster.ID)
+		if err != nil {
+			c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
+			return errors.Wrapf(err, "could not get Cluster by ID %s", cluster.ID)
+		} else if count != 1 {
+			return errors.Errorf("expected 1 Cluster with UUID %s, but got %d", cluster.ID, count)
+		} else if resp.Zoneid != zoneID {
+			return errors.Errorf("Cluster %s isn't in zone %s", cluster.ID, zoneID)
+		} else if placement.Pod != nil && resp.Podid != placement.Pod.ID {
+			return errors.Errorf("Cluster %s isn't in pod %s", cluster.ID, placement.Pod.ID)
+		}
+		cluster.Name = resp.Name
+	}
+	if len(placement.HostTags) > 0 {
+		if _, err := c.selectHost(zoneID, placement, "", placement.HostTags, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Now get network details.
-	netDetails, count, err = c.cs.Network.GetNetworkByID(zSpec.Network.ID, cloudstack.WithProject(c.user.Project.ID))
+// selectHost selects the enabled host in the zone, within the placement's pod and cluster if given, that has the ID if
+// given and the host tags, and that has the most unallocated memory. Only hosts accepted by the filter, if given, are
+// considered.
+func (c *client) selectHost(
+	zoneID string, placement *infrav1.FailureDomainPlacement, hostID string, hostTags []string, accept func(*cloudstack.Host) bool,
+) (*cloudstack.Host, error) {
+	p := c.cs.Host.NewListHostsParams()
+	p.SetZoneid(zoneID)
+	p.SetType("Routing")
+	p.SetState("Up")
+	p.SetResourcestate("Enabled")
+	setIfNotEmpty(hostID, p.SetId)
+	if placement != nil && placement.Pod != nil {
+		setIfNotEmpty(placement.Pod.ID, p.SetPodid)
+	}
+	if placement != nil && placement.Cluster != nil {
+		setIfNotEmpty(placement.Cluster.ID, p.SetClusterid)
+	}
+	resp, err := c.cs.Host.ListHosts(p)
 	if err != nil {
-		return multierror.Append(retErr, errors.Wrapf(err, "could not get Network by ID %s", zSpec.Network.ID))
-	} else if count != 1 {
 		c.customMetrics.EvaluateErrorAndIncrementAcsReconciliationErrorCounter(err)
-		return multierror.Append(retErr, errors.Errorf("expected 1 Network with UUID %v, but got %d", zSpec.Network.ID, count))
+		return nil, errors.Wrap(err, "listing hosts")
 	}
-	zSpec.Network.Name = netDetails.Name
-	zSpec.Network.ID = netDetails.Id
-	zSpec.Network.Type = netDetails.Type
-	return nil
+	var selected *cloudstack.Host
+	for _, host := range resp.Hosts {
+		if !hasHostTags(host, hostTags) || (accept != nil && !accept(host)) {
+			continue
+		}
+		if selected == nil || host.Memorytotal-host.Memoryallocated > selected.Memorytotal-selected.Memoryallocated {
+			selected = host
+		}
+	}
+	if selected == nil && hostID != "" {
+		return nil, errors.Errorf("host %s isn't an enabled host of zone %s with host tags %s, or can't be used",
+			hostID, zoneID, strings.Join(hostTags, ","))
+	} else if selected == nil {
+		return nil, errors.Errorf("no enabled host in zone %s has host tags %s, or can be used", zoneID, strings.Join(hostTags, ","))
+	}
+	return selected, nil
+}
+
+// hasHostTags checks whether a host has all the host tags.
+func hasHostTags(host *cloudstack.Host, hostTags []string) bool {
+	tags := map[string]bool{}
+	for _, tag := range strings.Split(host.Hosttags, ",") {
+		tags[strings.TrimSpace(tag)] = true
+	}
+	for _, tag := range hostTags {
+		if !tags[tag] {
+			return false
+		}
+	}
+	return true
 }
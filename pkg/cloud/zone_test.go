@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
 	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/cloud"
 	dummies "sigs.k8s.io/cluster-api-provider-cloudstack/test/dummies/v1beta3"
 )
@@ -85,31 +86,123 @@ var _ = Describe("Zone", func() {
 
 	Context("Resolve network for zone", func() {
 		It("get network by name specfied in zone spec", func() {
-			ns.EXPECT().GetNetworkByName(dummies.Zone1.Network.Name, gomock.Any()).Return(&csapi.Network{}, 1, nil)
-
-			Ω(client.ResolveNetworkForZone(&dummies.CSFailureDomain1.Spec.Zone)).Should(Succeed())
+			dummies.Zone1.ID = "FakeZone1ID"
+			dummies.Zone1.Network.ID = ""
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).
+				DoAndReturn(func(p *csapi.ListNetworksParams) (*csapi.ListNetworksResponse, error) {
+					zoneID, _ := p.GetZoneid()
+					Ω(zoneID).Should(Equal("FakeZone1ID"))
+					return &csapi.ListNetworksResponse{Count: 1, Networks: []*csapi.Network{
+						{Id: "net-id", Name: dummies.Zone1.Network.Name}}}, nil
+				})
+
+			Ω(client.ResolveNetworkForZone(&dummies.Zone1)).Should(Succeed())
+			Ω(dummies.Zone1.Network.ID).Should(Equal("net-id"))
 		})
 
-		It("get network by name specfied in zone spec returns > 1 network", func() {
-			ns.EXPECT().GetNetworkByName(dummies.Zone2.Network.Name, gomock.Any()).Return(&csapi.Network{}, 2, nil)
-			ns.EXPECT().GetNetworkByID(dummies.Zone2.Network.ID, gomock.Any()).Return(&csapi.Network{}, 2, nil)
+		It("get network by id specfied in zone spec", func() {
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{Count: 1, Networks: []*csapi.Network{
+				dummies.CAPCNetToCSAPINet(&dummies.Zone2.Network)}}, nil)
 
-			Ω(client.ResolveNetworkForZone(&dummies.CSFailureDomain2.Spec.Zone)).Should(MatchError(And(
-				ContainSubstring(fmt.Sprintf("expected 1 Network with name %s, but got %d", dummies.Zone2.Network.Name, 2)),
-				ContainSubstring(fmt.Sprintf("expected 1 Network with UUID %v, but got %d", dummies.Zone2.Network.ID, 2)))))
+			Ω(client.ResolveNetworkForZone(&dummies.Zone2)).Should(Succeed())
 		})
 
-		It("get network by id specfied in zone spec", func() {
-			ns.EXPECT().GetNetworkByName(dummies.Zone2.Network.Name, gomock.Any()).Return(nil, -1, fakeError)
-			ns.EXPECT().GetNetworkByID(dummies.Zone2.Network.ID, gomock.Any()).Return(&csapi.Network{}, 1, nil)
-			Ω(client.ResolveNetworkForZone(&dummies.CSFailureDomain2.Spec.Zone)).Should(Succeed())
+		It("reports no match when the network doesn't exist in the zone", func() {
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(&csapi.ListNetworksResponse{}, nil)
+
+			Ω(client.ResolveNetworkForZone(&dummies.Zone2)).Should(MatchError(ContainSubstring(
+				fmt.Sprintf("no match found for Network with UUID %s in zone with ID %s", dummies.Zone2.Network.ID, dummies.Zone2.ID))))
 		})
 
 		It("get network by id fails", func() {
-			ns.EXPECT().GetNetworkByName(dummies.Zone2.Network.Name, gomock.Any()).Return(nil, -1, fakeError)
-			ns.EXPECT().GetNetworkByID(dummies.Zone2.Network.ID, gomock.Any()).Return(nil, -1, fakeError)
+			ns.EXPECT().NewListNetworksParams().Return(&csapi.ListNetworksParams{})
+			ns.EXPECT().ListNetworks(gomock.Any()).Return(nil, fakeError)
+
+			Ω(client.ResolveNetworkForZone(&dummies.Zone2)).Should(MatchError(ContainSubstring(errorMessage)))
+		})
+	})
+
+	Context("List zones", func() {
+		It("lists the zones with the tags whose names match the pattern", func() {
+			p := &csapi.ListZonesParams{}
+			zs.EXPECT().NewListZonesParams().Return(p)
+			zs.EXPECT().ListZones(p).Return(&csapi.ListZonesResponse{Zones: []*csapi.Zone{
+				{Id: "zone-1", Name: "site-a-1"}, {Id: "zone-2", Name: "site-b-1"}}}, nil)
+
+			zones, err := client.ListZones(&infrav1.ZoneSelector{
+				NamePattern: "^site-a-", Tags: map[string]string{"capc": "enabled"}})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(zones).Should(HaveLen(1))
+			Ω(zones[0].Id).Should(Equal("zone-1"))
+			tags, _ := p.GetTags()
+			Ω(tags).Should(Equal(map[string]string{"capc": "enabled"}))
+		})
+
+		It("rejects an invalid name pattern before listing", func() {
+			zs.EXPECT().NewListZonesParams().Return(&csapi.ListZonesParams{})
+
+			_, err := client.ListZones(&infrav1.ZoneSelector{NamePattern: "("})
+			Ω(err).Should(MatchError(ContainSubstring("parsing zone name pattern")))
+		})
+	})
+
+	Context("Resolve placement", func() {
+		var (
+			ps *csapi.MockPodServiceIface
+			cs *csapi.MockClusterServiceIface
+			hs *csapi.MockHostServiceIface
+		)
+
+		BeforeEach(func() {
+			ps = mockClient.Pod.(*csapi.MockPodServiceIface)
+			cs = mockClient.Cluster.(*csapi.MockClusterServiceIface)
+			hs = mockClient.Host.(*csapi.MockHostServiceIface)
+		})
+
+		It("resolves the pod and cluster by name and verifies a host has the host tags", func() {
+			placement := &infrav1.FailureDomainPlacement{
+				Pod:      &infrav1.CloudStackResourceIdentifier{Name: "pod1"},
+				Cluster:  &infrav1.CloudStackResourceIdentifier{Name: "cluster1"},
+				HostTags: []string{"k8s"},
+			}
+			ps.EXPECT().GetPodID("pod1", gomock.Any()).Return("pod-id", 1, nil)
+			ps.EXPECT().GetPodByID("pod-id").Return(&csapi.Pod{Id: "pod-id", Name: "pod1", Zoneid: dummies.Zone1.ID}, 1, nil)
+			cs.EXPECT().GetClusterID("cluster1", gomock.Any()).Return("cluster-id", 1, nil)
+			cs.EXPECT().GetClusterByID("cluster-id").Return(
+				&csapi.Cluster{Id: "cluster-id", Name: "cluster1", Podid: "pod-id", Zoneid: dummies.Zone1.ID}, 1, nil)
+			p := &csapi.ListHostsParams{}
+			hs.EXPECT().NewListHostsParams().Return(p)
+			hs.EXPECT().ListHosts(p).Return(&csapi.ListHostsResponse{Hosts: []*csapi.Host{
+				{Id: "host-1", Hosttags: "gpu"}, {Id: "host-2", Hosttags: "gpu,k8s"}}}, nil)
+
+			Ω(client.ResolvePlacement(placement, dummies.Zone1.ID)).Should(Succeed())
+			Ω(placement.Pod.ID).Should(Equal("pod-id"))
+			Ω(placement.Cluster.ID).Should(Equal("cluster-id"))
+			clusterID, _ := p.GetClusterid()
+			Ω(clusterID).Should(Equal("cluster-id"))
+		})
+
+		It("rejects a cluster outside of the pod", func() {
+			placement := &infrav1.FailureDomainPlacement{
+				Pod:     &infrav1.CloudStackResourceIdentifier{ID: "pod-id"},
+				Cluster: &infrav1.CloudStackResourceIdentifier{ID: "cluster-id"},
+			}
+			ps.EXPECT().GetPodByID("pod-id").Return(&csapi.Pod{Id: "pod-id", Zoneid: dummies.Zone1.ID}, 1, nil)
+			cs.EXPECT().GetClusterByID("cluster-id").Return(
+				&csapi.Cluster{Id: "cluster-id", Podid: "pod-2", Zoneid: dummies.Zone1.ID}, 1, nil)
+
+			Ω(client.ResolvePlacement(placement, dummies.Zone1.ID)).Should(MatchError(ContainSubstring("isn't in pod pod-id")))
+		})
+
+		It("fails when no host has the host tags", func() {
+			hs.EXPECT().NewListHostsParams().Return(&csapi.ListHostsParams{})
+			hs.EXPECT().ListHosts(gomock.Any()).Return(&csapi.ListHostsResponse{Hosts: []*csapi.Host{{Id: "host-1"}}}, nil)
 
-			Ω(client.ResolveNetworkForZone(&dummies.CSFailureDomain2.Spec.Zone).Error()).Should(ContainSubstring(fmt.Sprintf("could not get Network by ID %s", dummies.Zone2.Network.ID)))
+			Ω(client.ResolvePlacement(&infrav1.FailureDomainPlacement{HostTags: []string{"k8s"}}, dummies.Zone1.ID)).
+				Should(MatchError(ContainSubstring("no enabled host")))
 		})
 	})
 })
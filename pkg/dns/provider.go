@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dns manages the DNS records of control plane endpoints on external DNS servers.
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TSIGSecretKey is the key of the TSIG secret in the Secret referenced by an RFC2136 provider.
+const TSIGSecretKey = "secret"
+
+// Provider manages the A and AAAA records of a DNS name.
+type Provider interface {
+	// EnsureRecords replaces the A and AAAA records of the name with records of the addresses.
+	EnsureRecords(ctx context.Context, name string, addresses []string) error
+	// DeleteRecords deletes the A and AAAA records of the name.
+	DeleteRecords(ctx context.Context, name string) error
+}
+
+// NewProvider creates the provider the config specifies, reading its credentials from the namespace. Secret references
+// to other namespaces are refused.
+func NewProvider(
+	ctx context.Context, k8sClient client.Client, namespace string, config *infrav1.DNSProvider,
+) (Provider, error) {
+	if config == nil || config.RFC2136 == nil {
+		return nil, errors.New("no DNS provider configured")
+	}
+	rfc2136 := config.RFC2136
+	var secret []byte
+	if rfc2136.TSIGKeyName != "" {
+		if rfc2136.TSIGSecretRef == nil {
+			return nil, errors.Errorf("TSIG key %s has no secret reference", rfc2136.TSIGKeyName)
+		}
+		if ns := rfc2136.TSIGSecretRef.Namespace; ns != "" && ns != namespace {
+			return nil, errors.Errorf("TSIG secret %s/%s is outside of namespace %s", ns, rfc2136.TSIGSecretRef.Name, namespace)
+		}
+		key := client.ObjectKey{Name: rfc2136.TSIGSecretRef.Name, Namespace: namespace}
+		tsigSecret := &corev1.Secret{}
+		if err := k8sClient.Get(ctx, key, tsigSecret); err != nil {
+			return nil, errors.Wrapf(err, "getting TSIG secret %s", key)
+		}
+		var err error
+		if secret, err = base64.StdEncoding.DecodeString(string(tsigSecret.Data[TSIGSecretKey])); err != nil {
+			return nil, errors.Wrapf(err, "decoding TSIG secret %s", key)
+		} else if len(secret) == 0 {
+			return nil, errors.Errorf("TSIG secret %s has no %s key", key, TSIGSecretKey)
+		}
+	}
+	return NewRFC2136Provider(rfc2136, secret)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns_test
+
+import (
+	"context"
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/dns"
+)
+
+var _ = Describe("NewProvider", func() {
+	var (
+		k8sClient client.Client
+		config    *infrav1.DNSProvider
+	)
+
+	BeforeEach(func() {
+		k8sClient = fake.NewClientBuilder().WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "capc-tsig", Namespace: "default"},
+				Data:       map[string][]byte{dns.TSIGSecretKey: []byte(base64.StdEncoding.EncodeToString([]byte("key")))},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "capc-tsig", Namespace: "other"},
+				Data:       map[string][]byte{dns.TSIGSecretKey: []byte(base64.StdEncoding.EncodeToString([]byte("key")))},
+			},
+		).Build()
+		config = &infrav1.DNSProvider{RFC2136: &infrav1.RFC2136Provider{
+			Server: "192.0.2.53", Zone: "example.com", TSIGKeyName: "capc-key",
+			TSIGSecretRef: &corev1.SecretReference{Name: "capc-tsig"},
+		}}
+	})
+
+	It("reads the TSIG secret from the cluster's namespace", func() {
+		_, err := dns.NewProvider(context.Background(), k8sClient, "default", config)
+		Ω(err).ShouldNot(HaveOccurred())
+		config.RFC2136.TSIGSecretRef.Namespace = "default"
+		_, err = dns.NewProvider(context.Background(), k8sClient, "default", config)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("refuses a TSIG secret in another namespace", func() {
+		config.RFC2136.TSIGSecretRef.Namespace = "other"
+		_, err := dns.NewProvider(context.Background(), k8sClient, "default", config)
+		Ω(err).Should(MatchError(ContainSubstring("outside of namespace default")))
+	})
+})
@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+)
+
+const (
+	DefaultRFC2136Port = "53"
+	DefaultRecordTTL   = 60
+	TSIGFudge          = 300
+
+	TSIGAlgorithmHMACSHA1   = "hmac-sha1"
+	TSIGAlgorithmHMACSHA256 = "hmac-sha256"
+	TSIGAlgorithmHMACSHA512 = "hmac-sha512"
+
+	rfc2136Timeout = 10 * time.Second
+)
+
+var tsigAlgorithms = map[string]string{
+	TSIGAlgorithmHMACSHA1:   dns.HmacSHA1,
+	TSIGAlgorithmHMACSHA256: dns.HmacSHA256,
+	TSIGAlgorithmHMACSHA512: dns.HmacSHA512,
+}
+
+// rfc2136Provider updates records with RFC 2136 dynamic updates sent over TCP.
+type rfc2136Provider struct {
+	server    string
+	zone      string
+	ttl       uint32
+	keyName   string
+	algorithm string
+	secret    string
+}
+
+// NewRFC2136Provider creates a provider sending dynamic updates to the configured server, signed with the TSIG secret
+// when the config names a key.
+func NewRFC2136Provider(config *infrav1.RFC2136Provider, secret []byte) (Provider, error) {
+	p := &rfc2136Provider{
+		server: config.Server,
+		zone:   dns.Fqdn(config.Zone),
+		ttl:    DefaultRecordTTL,
+		secret: base64.StdEncoding.EncodeToString(secret),
+	}
+	if _, _, err := net.SplitHostPort(p.server); err != nil {
+		p.server = net.JoinHostPort(strings.Trim(p.server, "[]"), DefaultRFC2136Port)
+	}
+	if config.TTL != 0 {
+		p.ttl = uint32(config.TTL)
+	}
+	algorithm := TSIGAlgorithmHMACSHA256
+	if config.TSIGAlgorithm != "" {
+		algorithm = config.TSIGAlgorithm
+	}
+	var ok bool
+	if p.algorithm, ok = tsigAlgorithms[algorithm]; !ok {
+		return nil, errors.Errorf("unsupported TSIG algorithm %s", algorithm)
+	}
+	if _, ok := dns.IsDomainName(p.zone); !ok {
+		return nil, errors.Errorf("invalid zone %s", config.Zone)
+	}
+	if config.TSIGKeyName != "" {
+		if len(secret) == 0 {
+			return nil, errors.Errorf("TSIG key %s has no secret", config.TSIGKeyName)
+		}
+		p.keyName = dns.CanonicalName(config.TSIGKeyName)
+		if _, ok := dns.IsDomainName(p.keyName); !ok {
+			return nil, errors.Errorf("invalid TSIG key name %s", config.TSIGKeyName)
+		}
+	}
+	return p, nil
+}
+
+// EnsureRecords replaces the A and AAAA records of the name in a single update.
+func (p *rfc2136Provider) EnsureRecords(ctx context.Context, name string, addresses []string) error {
+	msg, err := p.newUpdate(name)
+	if err != nil {
+		return err
+	}
+	var rrs []dns.RR
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		header := dns.RR_Header{Name: msg.Ns[0].Header().Name, Class: dns.ClassINET, Ttl: p.ttl}
+		if ip == nil {
+			return errors.Errorf("invalid address %s", address)
+		} else if ipv4 := ip.To4(); ipv4 != nil {
+			header.Rrtype = dns.TypeA
+			rrs = append(rrs, &dns.A{Hdr: header, A: ipv4})
+		} else {
+			header.Rrtype = dns.TypeAAAA
+			rrs = append(rrs, &dns.AAAA{Hdr: header, AAAA: ip})
+		}
+	}
+	msg.Insert(rrs)
+	return errors.Wrapf(p.exchange(ctx, msg), "updating records of %s", name)
+}
+
+// DeleteRecords deletes the A and AAAA records of the name in a single update.
+func (p *rfc2136Provider) DeleteRecords(ctx context.Context, name string) error {
+	msg, err := p.newUpdate(name)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(p.exchange(ctx, msg), "deleting records of %s", name)
+}
+
+// newUpdate creates an update of the zone deleting the A and AAAA records of the name, see RFC 2136 section 2.5.2.
+func (p *rfc2136Provider) newUpdate(name string) (*dns.Msg, error) {
+	fqdn := dns.Fqdn(name)
+	if _, ok := dns.IsDomainName(fqdn); !ok {
+		return nil, errors.Errorf("invalid DNS name %s", name)
+	} else if !dns.IsSubDomain(p.zone, fqdn) {
+		return nil, errors.Errorf("DNS name %s isn't in zone %s", name, p.zone)
+	}
+	msg := new(dns.Msg).SetUpdate(p.zone)
+	msg.RemoveRRset([]dns.RR{
+		&dns.ANY{Hdr: dns.RR_Header{Name: fqdn, Rrtype: dns.TypeA}},
+		&dns.ANY{Hdr: dns.RR_Header{Name: fqdn, Rrtype: dns.TypeAAAA}},
+	})
+	return msg, nil
+}
+
+// exchange sends the update over TCP and checks the server's response. The client verifies the signature of the
+// response to a signed update.
+func (p *rfc2136Provider) exchange(ctx context.Context, msg *dns.Msg) error {
+	ctx, cancel := context.WithTimeout(ctx, rfc2136Timeout)
+	defer cancel()
+	client := &dns.Client{Net: "tcp"}
+	if p.keyName != "" {
+		client.TsigSecret = map[string]string{p.keyName: p.secret}
+		msg.SetTsig(p.keyName, p.algorithm, TSIGFudge, time.Now().Unix())
+	}
+	resp, _, err := client.ExchangeContext(ctx, msg, p.server)
+	if err != nil {
+		return errors.Wrapf(err, "exchanging update with DNS server %s", p.server)
+	} else if resp.Rcode != dns.RcodeSuccess {
+		return errors.Errorf("DNS server %s refused the update with %s", p.server, dns.RcodeToString[resp.Rcode])
+	} else if p.keyName != "" && resp.IsTsig() == nil {
+		return errors.Errorf("DNS server %s sent an unsigned response", p.server)
+	}
+	return nil
+}
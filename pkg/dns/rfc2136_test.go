@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"time"
+
+	miekgdns "github.com/miekg/dns"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-cloudstack/api/v1beta3"
+	"sigs.k8s.io/cluster-api-provider-cloudstack/pkg/dns"
+)
+
+// fakeServer answers updates with its rcode, signing the response when the update is signed with a known key.
+type fakeServer struct {
+	rcode    int
+	received chan *miekgdns.Msg
+	// tsigStatus is the server's verification of the update's TSIG record.
+	tsigStatus chan error
+	unsigned   bool
+}
+
+func (s *fakeServer) ServeDNS(w miekgdns.ResponseWriter, req *miekgdns.Msg) {
+	s.received <- req
+	resp := new(miekgdns.Msg).SetRcode(req, s.rcode)
+	if tsig := req.IsTsig(); tsig != nil {
+		s.tsigStatus <- w.TsigStatus()
+		if !s.unsigned {
+			resp.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+		}
+	}
+	_ = w.WriteMsg(resp)
+}
+
+var _ = Describe("RFC2136 provider", func() {
+	const keyName = "capc-key."
+	var (
+		secret = []byte("0123456789abcdef")
+		server *miekgdns.Server
+		fake   *fakeServer
+		config *infrav1.RFC2136Provider
+	)
+
+	BeforeEach(func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		fake = &fakeServer{received: make(chan *miekgdns.Msg, 1), tsigStatus: make(chan error, 1)}
+		started := make(chan struct{})
+		server = &miekgdns.Server{
+			Listener:          listener,
+			Handler:           fake,
+			TsigSecret:        map[string]string{keyName: base64.StdEncoding.EncodeToString(secret)},
+			NotifyStartedFunc: func() { close(started) },
+			// The default accept function refuses updates.
+			MsgAcceptFunc: func(miekgdns.Header) miekgdns.MsgAcceptAction { return miekgdns.MsgAccept },
+		}
+		go func() {
+			defer GinkgoRecover()
+			_ = server.ActivateAndServe()
+		}()
+		Eventually(started).Should(BeClosed())
+		config = &infrav1.RFC2136Provider{Server: listener.Addr().String(), Zone: "example.com"}
+	})
+
+	AfterEach(func() {
+		Ω(server.Shutdown()).Should(Succeed())
+	})
+
+	It("replaces the A and AAAA records of the name", func() {
+		provider, err := dns.NewRFC2136Provider(config, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(provider.EnsureRecords(context.Background(), "api.example.com", []string{"192.0.2.10", "2001:db8::10"})).
+			Should(Succeed())
+		msg := <-fake.received
+		Ω(msg.Opcode).Should(Equal(miekgdns.OpcodeUpdate))
+		Ω(msg.Question).Should(ConsistOf(miekgdns.Question{
+			Name: "example.com.", Qtype: miekgdns.TypeSOA, Qclass: miekgdns.ClassINET}))
+		Ω(msg.IsTsig()).Should(BeNil())
+		Ω(msg.Ns).Should(HaveLen(4))
+		Ω(msg.Ns[0].Header()).Should(Equal(&miekgdns.RR_Header{
+			Name: "api.example.com.", Rrtype: miekgdns.TypeA, Class: miekgdns.ClassANY}))
+		Ω(msg.Ns[1].Header()).Should(Equal(&miekgdns.RR_Header{
+			Name: "api.example.com.", Rrtype: miekgdns.TypeAAAA, Class: miekgdns.ClassANY}))
+		Ω(msg.Ns[2].(*miekgdns.A).A.String()).Should(Equal("192.0.2.10"))
+		Ω(msg.Ns[2].Header().Ttl).Should(Equal(uint32(dns.DefaultRecordTTL)))
+		Ω(msg.Ns[3].(*miekgdns.AAAA).AAAA.String()).Should(Equal("2001:db8::10"))
+	})
+
+	It("signs the update with the TSIG key", func() {
+		config.TSIGKeyName = "capc-key"
+		provider, err := dns.NewRFC2136Provider(config, secret)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(provider.DeleteRecords(context.Background(), "api.example.com")).Should(Succeed())
+		msg := <-fake.received
+		Ω(msg.Ns).Should(HaveLen(2))
+		Ω(msg.IsTsig()).ShouldNot(BeNil())
+		Ω(msg.IsTsig().Hdr.Name).Should(Equal(keyName))
+		Ω(msg.IsTsig().Algorithm).Should(Equal(miekgdns.HmacSHA256))
+		Ω(<-fake.tsigStatus).Should(Succeed())
+	})
+
+	It("fails when the server doesn't sign the response to a signed update", func() {
+		fake.unsigned = true
+		config.TSIGKeyName = "capc-key"
+		provider, err := dns.NewRFC2136Provider(config, secret)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(provider.DeleteRecords(context.Background(), "api.example.com")).
+			Should(MatchError(ContainSubstring("unsigned response")))
+	})
+
+	It("fails when the server refuses the update", func() {
+		fake.rcode = miekgdns.RcodeNotAuth
+		provider, err := dns.NewRFC2136Provider(config, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(provider.DeleteRecords(context.Background(), "api.example.com")).Should(MatchError(ContainSubstring("NOTAUTH")))
+	})
+
+	It("rejects names outside the zone and labels that are too long", func() {
+		provider, err := dns.NewRFC2136Provider(config, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(provider.DeleteRecords(context.Background(), "api.example.org")).Should(MatchError(ContainSubstring("isn't in zone")))
+		long := make([]byte, 64)
+		for i := range long {
+			long[i] = 'a'
+		}
+		Ω(provider.DeleteRecords(context.Background(), string(long)+".example.com")).
+			Should(MatchError(ContainSubstring("invalid DNS name")))
+		Consistently(fake.received).ShouldNot(Receive())
+	})
+
+	It("rejects a TSIG key without secret", func() {
+		config.TSIGKeyName = "capc-key"
+		_, err := dns.NewRFC2136Provider(config, nil)
+		Ω(err).Should(MatchError(ContainSubstring("has no secret")))
+	})
+})
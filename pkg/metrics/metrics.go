@@ -26,27 +26,51 @@ import (
 // AcsCustomMetrics encapsulates all CloudStack custom metrics defined for the controller.
 type ACSCustomMetrics struct {
 	acsReconciliationErrorCount *prometheus.CounterVec
+	acsVirtualRouterUp          *prometheus.GaugeVec
+	acsNetworkRestartCount      *prometheus.CounterVec
+	acsOrphanedResources        *prometheus.GaugeVec
+	acsOrphanDeletionCount      *prometheus.CounterVec
 	errorCodeRegexp             *regexp.Regexp
 }
 
 // NewCustomMetrics constructs an ACSCustomMetrics with all desired CloudStack custom metrics and any supporting resources.
 func NewCustomMetrics() ACSCustomMetrics {
 	customMetrics := ACSCustomMetrics{}
-	customMetrics.acsReconciliationErrorCount = prometheus.NewCounterVec(
+	customMetrics.acsReconciliationErrorCount = register(prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "acs_reconciliation_errors",
 			Help: "Count of reconciliation errors caused by ACS issues, bucketed by error code",
 		},
 		[]string{"acs_error_code"},
-	)
-	if err := crtlmetrics.Registry.Register(customMetrics.acsReconciliationErrorCount); err != nil {
-		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
-			customMetrics.acsReconciliationErrorCount = are.ExistingCollector.(*prometheus.CounterVec)
-		} else {
-			// Something else went wrong!
-			panic(err)
-		}
-	}
+	)).(*prometheus.CounterVec)
+	customMetrics.acsVirtualRouterUp = register(prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "acs_virtual_router_up",
+			Help: "Whether a virtual router of an isolated network is running, labeled with its redundancy state",
+		},
+		[]string{"network_id", "router_name", "redundant_state"},
+	)).(*prometheus.GaugeVec)
+	customMetrics.acsNetworkRestartCount = register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "acs_network_restarts",
+			Help: "Count of isolated network restarts CAPC triggered to recover virtual routers",
+		},
+		[]string{"network_id"},
+	)).(*prometheus.CounterVec)
+	customMetrics.acsOrphanedResources = register(prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "acs_orphaned_resources",
+			Help: "Number of resources CAPC created for clusters that no longer exist, by ACS endpoint and resource type",
+		},
+		[]string{"endpoint", "resource_type"},
+	)).(*prometheus.GaugeVec)
+	customMetrics.acsOrphanDeletionCount = register(prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "acs_orphan_deletions",
+			Help: "Count of attempts to delete orphaned resources, by ACS endpoint, resource type and result",
+		},
+		[]string{"endpoint", "resource_type", "result"},
+	)).(*prometheus.CounterVec)
 
 	// ACS standard error messages of the form "CloudStack API error 431 (CSExceptionErrorCode: 9999):..."
 	//  This regexp is used to extract CSExceptionCodes from the message.
@@ -55,6 +79,18 @@ func NewCustomMetrics() ACSCustomMetrics {
 	return customMetrics
 }
 
+// register registers a collector, or returns the one already registered in its stead.
+func register(collector prometheus.Collector) prometheus.Collector {
+	if err := crtlmetrics.Registry.Register(collector); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		// Something else went wrong!
+		panic(err)
+	}
+	return collector
+}
+
 // EvaluateErrorAndIncrementAcsReconciliationErrorCounter accepts a CloudStack error message and increments
 // the custom acs_reconciliation_errors counter, labeled with the error code if present in the error message.
 func (m *ACSCustomMetrics) EvaluateErrorAndIncrementAcsReconciliationErrorCounter(acsError error) {
@@ -67,3 +103,41 @@ func (m *ACSCustomMetrics) EvaluateErrorAndIncrementAcsReconciliationErrorCounte
 		}
 	}
 }
+
+// ResetVirtualRouters removes the acs_virtual_router_up series of a network, e.g. before recording its current routers.
+func (m *ACSCustomMetrics) ResetVirtualRouters(networkID string) {
+	m.acsVirtualRouterUp.DeletePartialMatch(prometheus.Labels{"network_id": networkID})
+}
+
+// SetVirtualRouterUp records whether a virtual router of a network is running.
+func (m *ACSCustomMetrics) SetVirtualRouterUp(networkID, routerName, redundantState string, up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+	m.acsVirtualRouterUp.WithLabelValues(networkID, routerName, redundantState).Set(value)
+}
+
+// IncrementNetworkRestartCounter increments the acs_network_restarts counter of a network.
+func (m *ACSCustomMetrics) IncrementNetworkRestartCounter(networkID string) {
+	m.acsNetworkRestartCount.WithLabelValues(networkID).Inc()
+}
+
+// ResetOrphanedResources removes the acs_orphaned_resources series of an ACS endpoint, e.g. before recording a sweep.
+func (m *ACSCustomMetrics) ResetOrphanedResources(endpoint string) {
+	m.acsOrphanedResources.DeletePartialMatch(prometheus.Labels{"endpoint": endpoint})
+}
+
+// SetOrphanedResources records the number of orphaned resources of a type found at an ACS endpoint.
+func (m *ACSCustomMetrics) SetOrphanedResources(endpoint, resourceType string, count int) {
+	m.acsOrphanedResources.WithLabelValues(endpoint, resourceType).Set(float64(count))
+}
+
+// IncrementOrphanDeletionCounter increments the acs_orphan_deletions counter, labeled with whether the deletion failed.
+func (m *ACSCustomMetrics) IncrementOrphanDeletionCounter(endpoint, resourceType string, deleteErr error) {
+	result := "success"
+	if deleteErr != nil {
+		result = "failure"
+	}
+	m.acsOrphanDeletionCount.WithLabelValues(endpoint, resourceType, result).Inc()
+}
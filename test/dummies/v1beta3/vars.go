@@ -33,6 +33,7 @@ var ( // Declare exported dummy vars.
 	ClusterName             string
 	ClusterNameSpace        string
 	CSMachineTemplate1      *infrav1.CloudStackMachineTemplate
+	CSClusterTemplate       *infrav1.CloudStackClusterTemplate
 	ACSEndpointSecret1      *corev1.Secret
 	ACSEndpointSecret2      *corev1.Secret
 	Zone1                   infrav1.CloudStackZoneSpec
@@ -336,6 +337,12 @@ func SetDummyCAPCClusterVars() {
 		},
 		Status: infrav1.CloudStackClusterStatus{},
 	}
+	CSClusterTemplate = &infrav1.CloudStackClusterTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: ClusterName + "-template", Namespace: "default"},
+		Spec: infrav1.CloudStackClusterTemplateSpec{Template: infrav1.CloudStackClusterTemplateResource{
+			Spec: infrav1.CloudStackClusterSpec{
+				FailureDomains: []infrav1.CloudStackFailureDomainSpec{CSFailureDomain1.Spec, CSFailureDomain2.Spec}}}},
+	}
 	CSISONet1 = &infrav1.CloudStackIsolatedNetwork{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ISONet1.Name,